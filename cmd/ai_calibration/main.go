@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+func main() {
+	days := flag.Int("days", 0, "Only include signals closed in the last N days (0 = all)")
+	profileName := flag.String("profile", "", "Only include signals of this strategy profile")
+	threshold := flag.Int("threshold", 0, "Score threshold for the dual-threshold split (default: the profile's min_score)")
+	flag.Parse()
+
+	config.Load()
+	if err := logger.Setup(config.AppConfig.LogLevel, config.AppConfig.LogFormat); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	databaseService, err := service.NewDatabaseService()
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize Database service: %v", err)
+	}
+	defer databaseService.Close()
+
+	closed, err := databaseService.GetResolvedSignals()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	var since time.Time
+	if *days > 0 {
+		since = time.Now().AddDate(0, 0, -*days)
+	}
+	validations, err := service.NewMongoAIValidationLog(databaseService.GetDB()).LoadValidations(since)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	profile := config.AppConfig.StrategyProfiles[0]
+	if *profileName != "" {
+		profiles, err := config.SelectStrategyProfiles(config.AppConfig.StrategyProfiles, []string{*profileName})
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		profile = profiles[0]
+	}
+	if *threshold == 0 {
+		*threshold = profile.MinScore
+	}
+
+	var selected []model.Signal
+	for _, signal := range closed {
+		if !since.IsZero() && (signal.ClosedAt == nil || signal.ClosedAt.Before(since)) {
+			continue
+		}
+		if *profileName != "" && profileOf(signal.Profile) != *profileName {
+			continue
+		}
+		selected = append(selected, signal)
+	}
+
+	var selectedValidations []service.AIValidationRecord
+	for _, record := range validations {
+		if *profileName != "" && profileOf(record.Profile) != *profileName {
+			continue
+		}
+		selectedValidations = append(selectedValidations, record)
+	}
+
+	report := service.BuildCalibrationReport(selected, selectedValidations, *threshold)
+	fmt.Print(report.Format())
+}
+
+// profileOf maps signals saved before profiles existed to the default profile
+func profileOf(name string) string {
+	if name == "" {
+		return config.DefaultProfileName
+	}
+	return name
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"mrcrypto-go/internal/backtest"
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/monitor"
+	"mrcrypto-go/internal/service"
+)
+
+func main() {
+	defaults := backtest.DefaultConfig()
+
+	symbols := flag.String("symbols", "ETHUSDT", "Comma-separated symbols to replay")
+	dataDir := flag.String("data", defaults.DataDir, "Directory with <SYMBOL>_<interval>.csv kline files")
+	fetch := flag.Bool("fetch", false, "Download history from Binance into -data before replaying")
+	days := flag.Int("days", 30, "Days of history to replay when using -fetch")
+	profileFile := flag.String("profile", "", "Optional YAML/JSON strategy profile file")
+	profileName := flag.String("profile-name", "", "Profile to replay from -profile (defaults to the first one)")
+	minScore := flag.Int("min-score", defaults.MinScore, "Minimum confluence score (overrides the profile)")
+	cooldown := flag.Duration("cooldown", defaults.Cooldown, "Per-symbol cooldown between signals (overrides the profile)")
+	notional := flag.Float64("notional", defaults.NotionalPerTrade, "USDT notional per trade")
+	tieBreak := flag.String("tie-break", string(defaults.TieBreak), "Candle touching both TP and SL: pessimistic or lower_tf (needs <SYMBOL>_1m.csv)")
+	evaluation := flag.String("evaluation", defaults.EvaluationMode, "Evaluation mode: live (forming candles) or closed (closed candles only)")
+	out := flag.String("out", "", "Optional path to write per-trade results as JSON")
+	verbose := flag.Bool("v", false, "Show strategy logs for every evaluation")
+	flag.Parse()
+
+	cfg := defaults
+	cfg.DataDir = *dataDir
+	if *profileFile != "" {
+		cfg.Profile = loadProfile(*profileFile, *profileName)
+		cfg.MinScore = cfg.Profile.MinScore
+		cfg.Cooldown = cfg.Profile.Cooldown.Duration
+		cfg.ScalingInPercent = cfg.Profile.ScalingInPercent
+	}
+	// Explicit flags win over the profile
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "min-score":
+			cfg.MinScore = *minScore
+		case "cooldown":
+			cfg.Cooldown = *cooldown
+		}
+	})
+	cfg.NotionalPerTrade = *notional
+	cfg.TieBreak = monitor.TieBreak(*tieBreak)
+	cfg.EvaluationMode = *evaluation
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			cfg.Symbols = append(cfg.Symbols, s)
+		}
+	}
+
+	if *fetch {
+		config.Load()
+		downloadHistory(cfg, *days)
+	}
+
+	engine, err := backtest.NewEngine(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize backtest: %v", err)
+	}
+
+	// Strategy logs every step at debug level; keep them out of the report unless asked
+	if *verbose {
+		if err := logger.SetupWriter(os.Stderr, "debug", logger.FormatText); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	} else {
+		log.SetOutput(io.Discard)
+	}
+	result, err := engine.Run()
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("❌ Backtest failed: %v", err)
+	}
+
+	printReport(cfg, result)
+
+	if *out != "" {
+		jsonData, _ := json.MarshalIndent(result.Trades, "", "  ")
+		if err := os.WriteFile(*out, jsonData, 0o644); err != nil {
+			log.Fatalf("❌ Failed to write %s: %v", *out, err)
+		}
+		log.Printf("💾 Trades written to %s", *out)
+	}
+}
+
+// loadProfile reads one strategy profile for the replay
+func loadProfile(path, name string) config.StrategyProfile {
+	profiles, err := config.LoadStrategyProfiles(path)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if name != "" {
+		profiles, err = config.SelectStrategyProfiles(profiles, []string{name})
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+	return profiles[0]
+}
+
+// downloadHistory stores enough candles for the replay window plus indicator warm-up
+func downloadHistory(cfg backtest.Config, days int) {
+	binanceService := service.NewBinanceService()
+	replayFrom := time.Now().AddDate(0, 0, -days)
+
+	symbols := cfg.Symbols
+	if !contains(symbols, "BTCUSDT") {
+		symbols = append(symbols, "BTCUSDT") // BTC correlation context
+	}
+
+	for _, symbol := range symbols {
+		for _, interval := range backtest.Intervals {
+			from := replayFrom.Add(-backtest.WarmupDuration(interval))
+			if err := backtest.Download(binanceService, cfg.DataDir, symbol, interval, from); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+		}
+		// 1m candles only serve lower_tf tie-breaks, no warm-up needed
+		if cfg.TieBreak == monitor.TieBreakLowerTF && contains(cfg.Symbols, symbol) {
+			if err := backtest.Download(binanceService, cfg.DataDir, symbol, "1m", replayFrom); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+		}
+	}
+}
+
+func printReport(cfg backtest.Config, result *backtest.Result) {
+	fmt.Println("==========================================")
+	fmt.Println("🧪 BACKTEST TRADES")
+	fmt.Println("==========================================")
+	for _, trade := range result.Trades {
+		sig := trade.Signal
+		tp1 := "   "
+		if sig.TP1Hit {
+			tp1 = "TP1"
+		}
+		fmt.Printf("%s %-10s %-5s score=%3d entry=%s exit=%s %s %-14s %+.2f%%\n",
+			sig.Timestamp.UTC().Format("2006-01-02 15:04"),
+			sig.Symbol, sig.Type, sig.ConfluenceScore,
+			service.FormatPrice(sig.EntryPrice), service.FormatPrice(trade.ExitPrice),
+			tp1, trade.CloseReason, trade.PnLPercent)
+	}
+
+	stats := result.Stats
+	fmt.Println("==========================================")
+	fmt.Println("📈 BACKTEST SUMMARY")
+	fmt.Println("==========================================")
+	fmt.Printf("Profile: %s | Symbols: %s | Min Score: %d | Cooldown: %s | Evaluation: %s\n", cfg.Profile.Name, strings.Join(cfg.Symbols, ","), cfg.MinScore, cfg.Cooldown, cfg.EvaluationMode)
+	fmt.Printf("Evaluations: %d | Strategy Signals: %d | Trades Taken: %d\n", result.Evaluations, result.Candidates, stats.TotalTrades)
+	fmt.Printf("Winning: %d | Losing: %d | Win Rate: %.2f%%\n", stats.WinningTrades, stats.LosingTrades, stats.WinRate)
+	fmt.Printf("Total PnL: $%.2f (%.0f USDT per trade)\n", stats.TotalPnL, cfg.NotionalPerTrade)
+	fmt.Printf("Avg Win: $%.2f | Avg Loss: $%.2f\n", stats.AvgWin, stats.AvgLoss)
+	fmt.Printf("Largest Win: $%.2f | Largest Loss: $%.2f\n", stats.LargestWin, stats.LargestLoss)
+	fmt.Printf("Profit Factor: %.2f | Expected Value: $%.2f\n", stats.ProfitFactor, stats.ExpectedValue)
+	fmt.Printf("Sharpe Ratio: %.2f | Max Drawdown: %.2f%%\n", stats.SharpeRatio, result.MaxDrawdown)
+	fmt.Println("==========================================")
+	fmt.Println("ℹ️  AI validation, funding, order book and perp premium are not replayed.")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+func main() {
+	days := flag.Int("days", 0, "Only include candidates and signals closed in the last N days (0 = all)")
+	profileName := flag.String("profile", "", "Only include this strategy profile")
+	flag.Parse()
+
+	config.Load()
+	if err := logger.Setup(config.AppConfig.LogLevel, config.AppConfig.LogFormat); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	databaseService, err := service.NewDatabaseService()
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize Database service: %v", err)
+	}
+	defer databaseService.Close()
+
+	var since time.Time
+	if *days > 0 {
+		since = time.Now().AddDate(0, 0, -*days)
+	}
+
+	candidates, err := service.NewMongoCandidateJournal(databaseService.GetDB()).ClosedCandidates(since)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	resolved, err := databaseService.GetResolvedSignals()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	var selected []model.Candidate
+	for _, candidate := range candidates {
+		if *profileName != "" && profileOf(candidate.Profile) != *profileName {
+			continue
+		}
+		selected = append(selected, candidate)
+	}
+
+	var sent []model.Signal
+	for _, signal := range resolved {
+		if !since.IsZero() && (signal.ClosedAt == nil || signal.ClosedAt.Before(since)) {
+			continue
+		}
+		if *profileName != "" && profileOf(signal.Profile) != *profileName {
+			continue
+		}
+		sent = append(sent, signal)
+	}
+
+	fmt.Print(service.BuildCandidateReport(selected, sent).Format())
+}
+
+// profileOf maps records saved before profiles existed to the default profile
+func profileOf(name string) string {
+	if name == "" {
+		return config.DefaultProfileName
+	}
+	return name
+}
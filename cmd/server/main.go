@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
-	"os"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
+	"mrcrypto-go/internal/api"
 	"mrcrypto-go/internal/config"
 	"mrcrypto-go/internal/loader"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/monitor"
 	"mrcrypto-go/internal/service"
 )
@@ -19,76 +28,231 @@ func main() {
 
 	// Load configuration
 	config.Load()
+	if err := logger.Setup(config.AppConfig.LogLevel, config.AppConfig.LogFormat); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
 
-	log.Println("🔧 Initializing services...")
+	slog.Info("🔧 Initializing services...")
 
 	// Initialize services
-	binanceService := service.NewBinanceService()
+	exchanges, defaultExchange, err := service.NewExchangeAdapters(config.AppConfig.Exchanges)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize exchanges: %v", err)
+	}
 	signalTracker := service.NewSignalTracker()
-	strategyService := service.NewStrategyService(binanceService, signalTracker)
-	aiService := service.NewAIService()
 
-	// Create Database service first as SymbolManager needs it
+	// Create Database service first as SymbolManager and the kline cache need it
 	databaseService, err := service.NewDatabaseService()
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize Database service: %v", err)
 	}
 	defer databaseService.Close()
 
-	// Initialize Symbol Manager
+	// Pattern stats survive restarts; the first start rebuilds them from closed signals
+	if loaded, err := signalTracker.AttachStore(service.NewMongoPatternStore(databaseService.GetDB())); err != nil {
+		slog.Warn("⚠️  Failed to load pattern stats", "error", err)
+	} else if loaded == 0 {
+		closed, err := databaseService.GetResolvedSignals()
+		if err != nil {
+			slog.Warn("⚠️  Failed to rebuild pattern stats", "error", err)
+		} else {
+			signalTracker.Rebuild(closed)
+		}
+	}
+
+	// AI validator; raw prompts and responses are kept in "ai_audit"
+	signalValidator, err := service.NewSignalValidator(service.NewMongoAIAuditLog(databaseService.GetDB()))
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize AI validator: %v", err)
+	}
+
+	// Initialize Symbol Manager; every watchlist entry selects its exchange (default: the first of EXCHANGES)
 	symbolManager := service.NewSymbolManager(databaseService.GetDB())
+	symbolManager.SetExchanges(slices.Sorted(maps.Keys(exchanges)))
+	market := service.NewExchangeRouter(exchanges, defaultExchange, symbolManager)
+
+	// Kline cache per exchange: scans only download candles newer than what is already stored.
+	// Stored series are keyed by exchange and market, so switching MARKET_TYPE starts a fresh series.
+	klineCaches := make(map[string]service.MarketDataProvider, len(exchanges))
+	var binanceCache *service.KlineCache
+	for name, adapter := range exchanges {
+		var store service.KlineStore
+		if config.AppConfig.KlineCachePersist {
+			store = service.NewMongoKlineStore(databaseService.GetDB(), name, config.AppConfig.MarketType)
+		}
+		cache := service.NewKlineCache(adapter, store, 30*time.Second)
+		if name == service.ExchangeBinance {
+			binanceCache = cache
+		}
+		klineCaches[name] = cache
+	}
+	cachedMarket := service.NewExchangeRouter(klineCaches, defaultExchange, symbolManager)
+
+	// Near-miss journal: rejected setups are kept in "candidates" and tracked forward
+	var candidateJournal *service.MongoCandidateJournal
+	if config.AppConfig.CandidateJournal {
+		candidateJournal = service.NewMongoCandidateJournal(databaseService.GetDB())
+	}
+
+	// Order book depth analyzer; shared so walls are tracked across polls whichever profile fetched the book
+	var depthAnalyzer *service.DepthAnalyzer
+	if config.AppConfig.DepthAnalysis {
+		depthAnalyzer = service.NewDepthAnalyzer(service.DepthConfig{
+			Bands:        config.AppConfig.DepthBands,
+			HalfLife:     config.AppConfig.DepthHalfLife,
+			WallMultiple: config.AppConfig.DepthWallMultiple,
+			WallMinAge:   time.Duration(config.AppConfig.DepthWallMinAge) * time.Minute,
+		})
+	}
 
-	telegramService, err := service.NewTelegramService(binanceService, symbolManager)
+	// One strategy per profile; all profiles share the kline cache, pattern tracker and depth analyzer
+	var strategies []*service.StrategyService
+	for _, profile := range config.AppConfig.StrategyProfiles {
+		strategyService := service.NewStrategyService(cachedMarket, signalTracker)
+		strategyService.SetProfile(profile)
+		if err := strategyService.SetEvaluationMode(config.AppConfig.EvaluationMode); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if candidateJournal != nil {
+			strategyService.SetCandidateJournal(candidateJournal)
+		}
+		if depthAnalyzer != nil {
+			strategyService.SetDepthAnalyzer(depthAnalyzer, config.AppConfig.DepthAggregate)
+		}
+		strategies = append(strategies, strategyService)
+	}
+
+	telegramService, err := service.NewTelegramService(market, symbolManager)
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize Telegram service: %v", err)
 	}
+	telegramService.SetSignalTracker(signalTracker)
 
 	// Initialize Signal Monitor for active trade monitoring
 	signalMonitor := monitor.NewSignalMonitor(
 		databaseService.GetDB(),
-		binanceService,
+		market,
 		telegramService,
 		signalTracker,
 	)
+	signalMonitor.SetTieBreak(monitor.TieBreak(config.AppConfig.TPSLTieBreak), config.AppConfig.MarketType)
 
-	log.Println("✅ All services initialized successfully")
+	slog.Info("✅ All services initialized successfully")
 
 	// Create and start loader
 	loaderService := loader.NewLoader(
-		binanceService,
-		strategyService,
-		aiService,
+		market,
+		strategies,
+		signalValidator,
 		telegramService,
 		databaseService,
 		signalMonitor,
 		symbolManager,
 	)
 
-	// Handle graceful shutdown
-	shutdownChan := make(chan bool, 1)
-	go func() {
-		defer service.RecoverAndLog("shutdown handler")
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
+	// Every AI verdict is kept in "ai_validations" for the calibration report
+	loaderService.SetValidationLog(service.NewMongoAIValidationLog(databaseService.GetDB()))
 
-		log.Println("\n🛑 Received shutdown signal...")
+	if candidateJournal != nil {
+		loaderService.SetCandidateJournal(candidateJournal, monitor.NewCandidateMonitor(candidateJournal, market))
+	}
+
+	// Portfolio risk gate between AI validation and broadcast
+	loaderService.SetRiskGate(
+		monitor.NewRiskMonitor(monitor.RiskLimits{
+			MaxOpenSignals:   config.AppConfig.MaxOpenSignals,
+			MaxAggregateRisk: config.AppConfig.MaxPortfolioRisk,
+			MaxSameDirection: config.AppConfig.MaxSameDirection,
+			DailyLossLimit:   config.AppConfig.DailyLossLimit,
+		}),
+		service.NewRiskManager(databaseService.GetDB()),
+	)
+
+	// SIGINT/SIGTERM cancel ctx: polling, monitoring and streams stop at their next step
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Live streaming (Binance symbols only): candles feed the kline cache, price ticks feed the monitor between polls
+	if config.AppConfig.StreamEnabled && binanceCache != nil {
+		marketStreamURL := config.AppConfig.BinanceFuturesStreamURL
+		if config.AppConfig.MarketType == service.MarketSpot {
+			marketStreamURL = config.AppConfig.BinanceStreamURL
+		}
+		stream := service.NewBinanceStream(marketStreamURL, config.AppConfig.BinanceFuturesStreamURL, binanceCache)
+		stream.OnPriceTicks(signalMonitor.CheckActiveSignalsAgainstTicks)
+		binanceCache.SetPremiumSource(stream) // Mark/index prices for perp/spot divergence
+		loaderService.SetStream(stream)
+		go stream.Run(ctx)
+	}
+
+	// HTTP API: signals, stats, patterns, watchlist and on-demand evaluation, plus metrics and health checks
+	var apiServer *api.Server
+	var health *api.Health
+	if config.AppConfig.APIEnabled {
+		if err := api.CheckExposure(config.AppConfig.APIHost, config.AppConfig.APIToken); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		apiServer = api.NewServer(databaseService, symbolManager, signalTracker, strategies, config.AppConfig.APIToken)
+		metrics.OnScrape(signalMonitor.ReportOpenSignals)
+		apiServer.Handle("GET /metrics", metrics.Handler())
+
+		checks := []api.HealthCheck{{Name: "mongo", Check: databaseService.Ping}}
+		for _, name := range slices.Sorted(maps.Keys(exchanges)) {
+			if pinger, ok := exchanges[name].(interface{ Ping(context.Context) error }); ok {
+				checks = append(checks, api.HealthCheck{Name: name, Check: pinger.Ping})
+			}
+		}
+		checks = append(checks, api.HealthCheck{Name: "telegram", Check: func(context.Context) error { return telegramService.Ping() }})
+		health = api.NewHealth(loaderService.LastSuccessfulPoll, time.Duration(config.AppConfig.HealthMaxPollAge)*time.Minute, checks...)
+		apiServer.Handle("GET /healthz", http.HandlerFunc(health.Healthz))
+		apiServer.Handle("GET /readyz", http.HandlerFunc(health.Readyz))
 
-		// Graceful shutdown with timeout
-		shutdownTimer := time.NewTimer(30 * time.Second)
 		go func() {
-			<-shutdownTimer.C
-			log.Println("⚠️  Shutdown timeout - forcing exit")
-			os.Exit(1)
+			defer service.RecoverAndLog("API server")
+			if err := apiServer.ListenAndServe(net.JoinHostPort(config.AppConfig.APIHost, config.AppConfig.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("❌ API server stopped", "error", err)
+			}
 		}()
+	}
 
-		databaseService.Close()
-		shutdownTimer.Stop()
-		shutdownChan <- true
-		os.Exit(0)
+	// Start the loader; it returns once the scheduler is stopped and the running poll is done
+	slog.Info("🚀 Bot is now running...")
+	loaderDone := make(chan struct{})
+	go func() {
+		defer close(loaderDone)
+		defer service.RecoverAndLog("loader")
+		loaderService.Start(ctx)
 	}()
 
-	// Start the loader (blocks indefinitely)
-	log.Println("🚀 Bot is now running...")
-	loaderService.Start()
+	select {
+	case <-ctx.Done():
+		slog.Info("🛑 Received shutdown signal...")
+	case <-loaderDone:
+		slog.Error("❌ Loader stopped unexpectedly, shutting down...")
+	}
+	stop()
+
+	// Graceful shutdown: no new commands or traffic, the running poll finishes its saves and sends
+	if health != nil {
+		health.SetShuttingDown()
+	}
+	telegramService.Stop()
+
+	timeout := time.Duration(config.AppConfig.ShutdownTimeout) * time.Second
+	select {
+	case <-loaderDone:
+	case <-time.After(timeout):
+		slog.Warn("⚠️  Shutdown timeout - abandoning the running poll", "timeout", timeout)
+	}
+
+	if apiServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := apiServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("⚠️  API server shutdown failed", "error", err)
+		}
+		cancel()
+	}
+
+	// MongoDB is disconnected by the deferred Close
+	slog.Info("👋 Shutdown complete")
 }
@@ -1,30 +1,83 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"time"
 
 	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
 	"mrcrypto-go/internal/service"
 )
 
 func main() {
+	symbolFlag := flag.String("symbol", "ETHUSDT", "Symbol to evaluate")
+	fixture := flag.String("fixture", "", "Optional JSON market fixture to evaluate offline instead of calling Binance")
+	at := flag.String("at", "", "Evaluation time (RFC3339) when using -fixture; defaults to now")
+	profileName := flag.String("profile", "", "Strategy profile to evaluate (from STRATEGY_PROFILE_FILE); defaults to the first one")
+	evaluation := flag.String("evaluation", "", "Evaluation mode: live or closed (defaults to EVALUATION_MODE)")
+	exchange := flag.String("exchange", service.ExchangeBinance, "Exchange to fetch market data from: binance, bybit or okx")
+	flag.Parse()
+
 	// Load config to get API keys if needed
 	config.Load()
+	if err := logger.Setup(config.AppConfig.LogLevel, config.AppConfig.LogFormat); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
 
 	log.Println("🧪 Starting Strategy Verification...")
 
 	// Initialize services
-	// Initialize services
-	binanceService := service.NewBinanceService()
+	adapter, err := service.NewExchangeAdapter(*exchange)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	var market service.MarketDataProvider = adapter
+	if *fixture != "" {
+		fixtureData, err := service.LoadMarketFixture(*fixture)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		market = fixtureData
+		log.Printf("📂 Using market fixture %s", *fixture)
+	}
 	signalTracker := service.NewSignalTracker()
-	strategyService := service.NewStrategyService(binanceService, signalTracker)
+	strategyService := service.NewStrategyService(market, signalTracker)
+
+	profile := config.AppConfig.StrategyProfiles[0]
+	if *profileName != "" {
+		profiles, err := config.SelectStrategyProfiles(config.AppConfig.StrategyProfiles, []string{*profileName})
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		profile = profiles[0]
+	}
+	strategyService.SetProfile(profile)
+	log.Printf("📐 Using strategy profile: %s", profile.Name)
+
+	mode := config.AppConfig.EvaluationMode
+	if *evaluation != "" {
+		mode = *evaluation
+	}
+	if err := strategyService.SetEvaluationMode(mode); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if *at != "" {
+		evalTime, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			log.Fatalf("❌ Invalid -at time: %v", err)
+		}
+		strategyService.SetClock(func() time.Time { return evalTime })
+	}
 
-	symbol := "ETHUSDT"
+	symbol := *symbolFlag
 	log.Printf("🔍 Evaluating %s...", symbol)
 
-	signal, _, err := strategyService.EvaluateSymbol(symbol)
+	signal, _, err := strategyService.EvaluateSymbol(context.Background(), symbol)
 	if err != nil {
 		log.Fatalf("❌ Error evaluating symbol: %v", err)
 	}
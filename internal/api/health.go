@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// healthCheckTimeout bounds each dependency probe
+const healthCheckTimeout = 5 * time.Second
+
+// HealthCheck probes one dependency (Mongo, Binance, Telegram)
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Health serves /healthz and /readyz.
+// Liveness fails only when polling has stalled; readiness fails when a dependency is down or the bot is shutting down.
+type Health struct {
+	checks     []HealthCheck
+	lastPoll   func() time.Time
+	maxPollAge time.Duration
+	startedAt  time.Time
+	stopping   atomic.Bool
+}
+
+// NewHealth creates the health endpoints. lastPoll reports the last successful poll;
+// polling counts as stalled when it is older than maxPollAge (measured from start until the first poll).
+func NewHealth(lastPoll func() time.Time, maxPollAge time.Duration, checks ...HealthCheck) *Health {
+	return &Health{
+		checks:     checks,
+		lastPoll:   lastPoll,
+		maxPollAge: maxPollAge,
+		startedAt:  time.Now(),
+	}
+}
+
+// SetShuttingDown makes /readyz fail so no new traffic is routed to a stopping bot
+func (h *Health) SetShuttingDown() {
+	h.stopping.Store(true)
+}
+
+// checkResult is the outcome of one dependency probe
+type checkResult struct {
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthReport is the body of both endpoints
+type healthReport struct {
+	Status             string                 `json:"status"` // ok, degraded, stalled or shutting_down
+	Checks             map[string]checkResult `json:"checks"`
+	LastSuccessfulPoll *time.Time             `json:"last_successful_poll"`
+	PollAgeSeconds     float64                `json:"poll_age_seconds"`
+}
+
+// Healthz reports 503 only when no poll has succeeded within maxPollAge
+func (h *Health) Healthz(w http.ResponseWriter, r *http.Request) {
+	report, _, stalled := h.report(r.Context())
+	status := http.StatusOK
+	if stalled {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Readyz reports 503 when a dependency check fails or the bot is shutting down
+func (h *Health) Readyz(w http.ResponseWriter, r *http.Request) {
+	report, healthy, _ := h.report(r.Context())
+	status := http.StatusOK
+	if !healthy || h.stopping.Load() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// report runs every check concurrently and returns the report, whether all checks passed and whether polling stalled
+func (h *Health) report(ctx context.Context) (healthReport, bool, bool) {
+	report := healthReport{Checks: make(map[string]checkResult, len(h.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	healthy := true
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+			mu.Lock()
+			report.Checks[check.Name] = result
+			healthy = healthy && result.OK
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	since := h.startedAt
+	if last := h.lastPoll(); !last.IsZero() {
+		report.LastSuccessfulPoll = &last
+		since = last
+	}
+	age := time.Since(since)
+	report.PollAgeSeconds = age.Round(time.Second).Seconds()
+	stalled := age > h.maxPollAge
+
+	switch {
+	case h.stopping.Load():
+		report.Status = "shutting_down"
+	case stalled:
+		report.Status = "stalled"
+	case !healthy:
+		report.Status = "degraded"
+	default:
+		report.Status = "ok"
+	}
+	return report, healthy, stalled
+}
+
+// runCheck probes one dependency; probes that ignore ctx are abandoned after the timeout
+func runCheck(ctx context.Context, check HealthCheck) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", healthCheckTimeout)
+	}
+
+	result := checkResult{OK: err == nil, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+var apiLog = logger.Component("api")
+
+// Server exposes signals, stats, pattern stats and the watchlist over HTTP/JSON -
+// the same data the Telegram commands render
+type Server struct {
+	database      *service.DatabaseService
+	symbolManager *service.SymbolManager
+	tracker       *service.SignalTracker
+	strategies    []*service.StrategyService
+	token         string // Bearer token required on every request, empty = open
+	mux           *http.ServeMux
+	server        *http.Server
+}
+
+// NewServer creates the API server. The first strategy is used for evaluations unless ?profile= selects another.
+func NewServer(
+	database *service.DatabaseService,
+	symbolManager *service.SymbolManager,
+	tracker *service.SignalTracker,
+	strategies []*service.StrategyService,
+	token string,
+) *Server {
+	s := &Server{
+		database:      database,
+		symbolManager: symbolManager,
+		tracker:       tracker,
+		strategies:    strategies,
+		token:         token,
+		mux:           http.NewServeMux(),
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	s.mux.HandleFunc("GET /api/signals", s.handleSignals)
+	s.mux.HandleFunc("GET /api/signals/{id}", s.handleSignal)
+	s.mux.HandleFunc("GET /api/stats", s.handleStats)
+	s.mux.HandleFunc("GET /api/patterns", s.handlePatterns)
+	s.mux.HandleFunc("GET /api/watchlist", s.handleWatchlist)
+	s.mux.HandleFunc("POST /api/watchlist", s.handleAddSymbol)
+	s.mux.HandleFunc("DELETE /api/watchlist/{symbol}", s.handleRemoveSymbol)
+	s.mux.HandleFunc("POST /api/evaluate/{symbol}", s.handleEvaluate)
+	return s
+}
+
+// Handle registers an extra route on the server (e.g. metrics or health checks)
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP checks the token and dispatches to the routes
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// CheckExposure refuses an unauthenticated API on anything but a loopback host
+func CheckExposure(host, token string) error {
+	if token != "" || host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("API_TOKEN is required to serve the API on %q (set API_TOKEN, API_HOST=127.0.0.1 or API_ENABLED=false)", host)
+}
+
+// ListenAndServe serves the API on addr (blocks). Returns http.ErrServerClosed after Shutdown.
+func (s *Server) ListenAndServe(addr string) error {
+	apiLog.Info("🌐 Listening", "addr", addr)
+	s.server.Addr = addr
+	return s.server.ListenAndServe()
+}
+
+// Shutdown stops accepting connections and waits for running requests until ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down API server: %w", err)
+	}
+	apiLog.Info("🔌 Server stopped")
+	return nil
+}
+
+// handleSignals lists signals.
+// Query: status (active, partial, open, closed), symbol, profile, type, tier, since, until (RFC3339), limit (default 50)
+func (s *Server) handleSignals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := service.SignalFilter{
+		Symbol:  strings.ToUpper(query.Get("symbol")),
+		Profile: query.Get("profile"),
+		Type:    model.SignalType(strings.ToUpper(query.Get("type"))),
+		Tier:    model.SignalTier(strings.ToUpper(query.Get("tier"))),
+		Limit:   50,
+	}
+
+	switch strings.ToLower(query.Get("status")) {
+	case "":
+	case "active":
+		filter.Statuses = []string{model.StatusActive}
+	case "partial":
+		filter.Statuses = []string{model.StatusPartial}
+	case "open":
+		filter.Statuses = model.OpenStatuses
+	case "closed":
+		filter.Statuses = []string{model.StatusClosed}
+	default:
+		writeError(w, http.StatusBadRequest, "status must be active, partial, open or closed")
+		return
+	}
+
+	var err error
+	if filter.Since, err = parseTime(query.Get("since")); err != nil {
+		writeError(w, http.StatusBadRequest, "since: "+err.Error())
+		return
+	}
+	if filter.Until, err = parseTime(query.Get("until")); err != nil {
+		writeError(w, http.StatusBadRequest, "until: "+err.Error())
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 1 || limit > 1000 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	signals, err := s.database.FindSignals(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(signals), "signals": signals})
+}
+
+// handleSignal returns one signal by its 5-char ID
+func (s *Server) handleSignal(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToUpper(r.PathValue("id"))
+	signal, err := s.database.GetSignalByID(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("signal %s not found", id))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, signal)
+}
+
+// handleStats returns the PnL summary (/pnl) and all-time performance statistics (/stats)
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	closed := []string{model.StatusClosed}
+
+	// Same windows as /pnl: today and the last 7 days, by signal creation
+	today, err := s.database.FindSignals(service.SignalFilter{Statuses: closed, Since: now.Truncate(24 * time.Hour)})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	week, err := s.database.FindSignals(service.SignalFilter{Statuses: closed, Since: now.AddDate(0, 0, -7)})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	partial, err := s.database.FindSignals(service.SignalFilter{Statuses: []string{model.StatusPartial}})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	all, err := s.database.FindSignals(service.SignalFilter{Statuses: closed})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pnl":         service.SummarizePnL(today, week, partial),
+		"performance": service.CalculatePerformanceStats(all),
+	})
+}
+
+// handlePatterns returns pattern fingerprints ranked by win rate. Query: min_trades (default 0)
+func (s *Server) handlePatterns(w http.ResponseWriter, r *http.Request) {
+	if s.tracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "pattern tracking is disabled")
+		return
+	}
+
+	minTrades := 0
+	if raw := r.URL.Query().Get("min_trades"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "min_trades must be a non-negative integer")
+			return
+		}
+		minTrades = n
+	}
+
+	patterns := s.tracker.RankPatterns(minTrades)
+	if patterns == nil {
+		patterns = []service.PatternStats{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(patterns), "patterns": patterns})
+}
+
+// handleWatchlist returns the active watchlist; entries carry the exchange of each symbol
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.symbolManager.GetWatchlistEntries()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	symbols := make([]string, len(entries))
+	for i, e := range entries {
+		symbols[i] = e.Symbol
+	}
+	if entries == nil {
+		entries = []service.WatchedSymbol{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"symbols": symbols, "entries": entries})
+}
+
+// handleAddSymbol adds {"symbol": "BTCUSDT", "exchange": "bybit"} to the watchlist (exchange optional)
+func (s *Server) handleAddSymbol(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Symbol   string `json:"symbol"`
+		Exchange string `json:"exchange"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Symbol == "" {
+		writeError(w, http.StatusBadRequest, `body must be {"symbol": "BTCUSDT"}`)
+		return
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(body.Symbol))
+	exchange := strings.ToLower(strings.TrimSpace(body.Exchange))
+	if err := s.symbolManager.AddSymbol(symbol, exchange); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"symbol": symbol, "exchange": exchange})
+}
+
+// handleRemoveSymbol removes a symbol from the watchlist
+func (s *Server) handleRemoveSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	if err := s.symbolManager.RemoveSymbol(symbol); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvaluate runs the strategy on a symbol now and returns the signal or why it was rejected.
+// Nothing is saved or sent. Query: profile (default: first profile)
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+
+	strategy := s.strategies[0]
+	if name := r.URL.Query().Get("profile"); name != "" {
+		strategy = nil
+		for _, candidate := range s.strategies {
+			if candidate.Profile().Name == name {
+				strategy = candidate
+				break
+			}
+		}
+		if strategy == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("profile %q is not running", name))
+			return
+		}
+	}
+
+	apiLog.Info("🌐 On-demand evaluation", "symbol", symbol, "profile", strategy.Profile().Name)
+	signal, candidate, err := strategy.Preview(r.Context(), symbol)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	response := map[string]any{
+		"symbol":  symbol,
+		"profile": strategy.Profile().Name,
+		"signal":  signal,
+	}
+	if candidate != nil {
+		response["rejected"] = map[string]any{
+			"stage":           candidate.Stage,
+			"reason":          candidate.Reason,
+			"type":            candidate.Type,
+			"lean":            candidate.Lean,
+			"price":           candidate.EntryPrice,
+			"score":           candidate.ConfluenceScore,
+			"score_breakdown": candidate.ScoreBreakdown,
+		}
+	} else if signal == nil {
+		response["rejected"] = map[string]any{"stage": "NO_DATA", "reason": "Insufficient market data"}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseTime accepts RFC3339 timestamps or YYYY-MM-DD dates; empty means unset
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("use RFC3339 or YYYY-MM-DD")
+	}
+	return t, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		apiLog.Warn("⚠️  Failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
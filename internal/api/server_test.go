@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	s := NewServer(nil, nil, nil, nil, "secret")
+	s.Handle("GET /api/ping", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	s.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+
+	tests := []struct {
+		path, auth string
+		want       int
+	}{
+		{"/api/ping", "Bearer secret", http.StatusOK},
+		{"/api/ping", "secret", http.StatusUnauthorized}, // The scheme is required
+		{"/api/ping", "Bearer secre", http.StatusUnauthorized},
+		{"/api/ping", "", http.StatusUnauthorized},
+		{"/healthz", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s with %q = %d, want %d", tt.path, tt.auth, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestCheckExposure(t *testing.T) {
+	tests := []struct {
+		host, token string
+		ok          bool
+	}{
+		{"127.0.0.1", "", true},
+		{"::1", "", true},
+		{"localhost", "", true},
+		{"0.0.0.0", "", false},
+		{"", "", false}, // All interfaces
+		{"0.0.0.0", "secret", true},
+	}
+	for _, tt := range tests {
+		if err := CheckExposure(tt.host, tt.token); (err == nil) != tt.ok {
+			t.Errorf("CheckExposure(%q, %q) = %v", tt.host, tt.token, err)
+		}
+	}
+}
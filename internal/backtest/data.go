@@ -0,0 +1,149 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+// Intervals replayed by the backtester (same set EvaluateSymbol fetches)
+var Intervals = []string{"1d", "4h", "1h", "15m", "5m"}
+
+// windowSizes mirrors the candle counts StrategyService fetches per timeframe
+var windowSizes = map[string]int{
+	"1d":  100,
+	"4h":  500,
+	"1h":  500,
+	"15m": 500,
+	"5m":  500,
+}
+
+// WarmupDuration returns how much history an interval needs before the first evaluation
+func WarmupDuration(interval string) time.Duration {
+	return time.Duration(windowSizes[interval]) * service.IntervalDuration(interval)
+}
+
+// KlinePath returns the CSV path for a symbol/interval pair: <dir>/<SYMBOL>_<interval>.csv
+func KlinePath(dir, symbol, interval string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.csv", symbol, interval))
+}
+
+var csvHeader = []string{"open_time", "open", "high", "low", "close", "volume", "close_time"}
+
+// LoadKlinesCSV reads klines stored by SaveKlinesCSV
+func LoadKlinesCSV(path string) ([]model.Kline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	klines := make([]model.Kline, 0, len(records))
+	for idx, rec := range records {
+		if idx == 0 && len(rec) > 0 && rec[0] == csvHeader[0] {
+			continue // Header
+		}
+		if len(rec) < len(csvHeader) {
+			return nil, fmt.Errorf("%s line %d: expected %d fields, got %d", path, idx+1, len(csvHeader), len(rec))
+		}
+
+		openTime, err1 := strconv.ParseInt(rec[0], 10, 64)
+		open, err2 := strconv.ParseFloat(rec[1], 64)
+		high, err3 := strconv.ParseFloat(rec[2], 64)
+		low, err4 := strconv.ParseFloat(rec[3], 64)
+		closePrice, err5 := strconv.ParseFloat(rec[4], 64)
+		volume, err6 := strconv.ParseFloat(rec[5], 64)
+		closeTime, err7 := strconv.ParseInt(rec[6], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil {
+			return nil, fmt.Errorf("%s line %d: parse error", path, idx+1)
+		}
+
+		klines = append(klines, model.Kline{
+			OpenTime:  openTime,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			CloseTime: closeTime,
+		})
+	}
+
+	return klines, nil
+}
+
+// SaveKlinesCSV writes klines to a CSV file (creating parent directories)
+func SaveKlinesCSV(path string, klines []model.Kline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write(csvHeader)
+	for _, k := range klines {
+		w.Write([]string{
+			strconv.FormatInt(k.OpenTime, 10),
+			strconv.FormatFloat(k.Open, 'f', -1, 64),
+			strconv.FormatFloat(k.High, 'f', -1, 64),
+			strconv.FormatFloat(k.Low, 'f', -1, 64),
+			strconv.FormatFloat(k.Close, 'f', -1, 64),
+			strconv.FormatFloat(k.Volume, 'f', -1, 64),
+			strconv.FormatInt(k.CloseTime, 10),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Download pages through Binance history from `from` until now and stores it as CSV
+func Download(binance *service.BinanceService, dir, symbol, interval string, from time.Time) error {
+	const pageLimit = 1000
+
+	var all []model.Kline
+	startTime := from.UnixMilli()
+	now := time.Now().UnixMilli()
+
+	for startTime < now {
+		page, err := binance.GetKlinesRange(symbol, interval, startTime, pageLimit)
+		if err != nil {
+			return fmt.Errorf("failed to download %s %s: %w", symbol, interval, err)
+		}
+		all = append(all, page...)
+
+		next := page[len(page)-1].CloseTime + 1
+		if len(page) < pageLimit || next <= startTime {
+			break
+		}
+		startTime = next
+	}
+
+	// Drop the still-forming candle so stored history only contains closed candles
+	if len(all) > 0 && all[len(all)-1].CloseTime >= now {
+		all = all[:len(all)-1]
+	}
+
+	path := KlinePath(dir, symbol, interval)
+	if err := SaveKlinesCSV(path, all); err != nil {
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+
+	backtestLog.Info("💾 Stored klines", "symbol", symbol, "interval", interval, "count", len(all), "path", path)
+	return nil
+}
@@ -0,0 +1,447 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	internalmath "mrcrypto-go/internal/math"
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/monitor"
+	"mrcrypto-go/internal/service"
+)
+
+var backtestLog = logger.Component("backtest")
+
+// Config controls a backtest run
+type Config struct {
+	DataDir          string
+	Profile          config.StrategyProfile // Strategy thresholds (MinScore/Cooldown/ScalingInPercent below are applied on top)
+	Symbols          []string
+	MinScore         int              // Minimum confluence score (live: 80)
+	Cooldown         time.Duration    // Per-symbol cooldown between signals (live: 4h)
+	ScalingInPercent float64          // Same-direction re-entry allowed beyond this % (live: 1.5)
+	NotionalPerTrade float64          // USDT notional per trade used for PnL stats
+	InitialEquity    float64          // Starting equity for drawdown calculation
+	TieBreak         monitor.TieBreak // Candle touching both TP and SL: pessimistic or lower_tf (<SYMBOL>_1m.csv)
+	EvaluationMode   string           // live (forming higher-TF candles rebuilt from 5m) or closed
+	Start            time.Time        // Optional replay start (zero = as soon as warm-up allows)
+	End              time.Time        // Optional replay end (zero = end of data)
+}
+
+// DefaultConfig returns settings that mirror the live Loader
+func DefaultConfig() Config {
+	profile := config.DefaultStrategyProfile()
+	return Config{
+		DataDir:          "data/backtest",
+		Profile:          profile,
+		MinScore:         profile.MinScore,
+		Cooldown:         profile.Cooldown.Duration,
+		ScalingInPercent: profile.ScalingInPercent,
+		NotionalPerTrade: 1000,
+		InitialEquity:    10000,
+		TieBreak:         monitor.TieBreakPessimistic,
+		EvaluationMode:   service.EvaluationLive,
+	}
+}
+
+// Trade is a simulated signal from entry to close
+type Trade struct {
+	Signal      *model.Signal
+	ExitPrice   float64
+	ExitTime    time.Time
+	CloseReason string
+	PnLPercent  float64
+	Result      internalmath.TradeResult
+}
+
+// Result summarises a backtest run
+type Result struct {
+	Trades      []Trade
+	Stats       internalmath.PnLStats
+	MaxDrawdown float64 // % of peak equity
+	Evaluations int     // Number of EvaluateSnapshot calls
+	Candidates  int     // Signals produced before cooldown/duplicate filters
+}
+
+// symbolData holds the replayed history for one symbol
+type symbolData struct {
+	symbol string
+	klines map[string][]model.Kline
+	lower  []model.Kline // Optional 1m candles for lower_tf tie-breaks
+	cursor int           // Index of the next 5m candle to replay
+}
+
+// Engine replays stored klines through StrategyService.EvaluateSnapshot
+// and closes trades with the same TP/SL rules as the live SignalMonitor.
+// AI validation is not simulated; every signal passing the strategy is taken.
+type Engine struct {
+	cfg      Config
+	strategy *service.StrategyService
+	tracker  *service.SignalTracker
+	resolver monitor.CandleResolver
+	data     []*symbolData
+	btc      map[string][]model.Kline
+
+	open       []*Trade
+	closed     []Trade
+	lastSignal map[string]time.Time
+}
+
+// NewEngine loads history for every configured symbol
+func NewEngine(cfg Config) (*Engine, error) {
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("no symbols configured")
+	}
+
+	tracker := service.NewSignalTracker()
+	strategy := service.NewStrategyService(nil, tracker)
+	if cfg.Profile.Name != "" {
+		strategy.SetProfile(cfg.Profile)
+	}
+	if cfg.MinScore > 0 {
+		strategy.SetMinScore(cfg.MinScore)
+	}
+	if cfg.EvaluationMode != "" {
+		if err := strategy.SetEvaluationMode(cfg.EvaluationMode); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &Engine{
+		cfg:        cfg,
+		strategy:   strategy,
+		tracker:    tracker,
+		lastSignal: make(map[string]time.Time),
+	}
+	e.resolver = monitor.CandleResolver{TieBreak: cfg.TieBreak, LowerTF: e.lowerTimeframe}
+
+	for _, symbol := range cfg.Symbols {
+		data := &symbolData{symbol: symbol, klines: make(map[string][]model.Kline)}
+		for _, interval := range Intervals {
+			klines, err := LoadKlinesCSV(KlinePath(cfg.DataDir, symbol, interval))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s %s: %w", symbol, interval, err)
+			}
+			if len(klines) == 0 {
+				return nil, fmt.Errorf("no %s klines stored for %s", interval, symbol)
+			}
+			data.klines[interval] = klines
+		}
+		if cfg.TieBreak == monitor.TieBreakLowerTF {
+			lower, err := LoadKlinesCSV(KlinePath(cfg.DataDir, symbol, "1m"))
+			if err != nil {
+				backtestLog.Warn("⚠️  No 1m history found - ambiguous candles resolved pessimistically", "symbol", symbol)
+			}
+			data.lower = lower
+		}
+		e.data = append(e.data, data)
+	}
+
+	// BTC context is optional, exactly like the live scan
+	e.btc = make(map[string][]model.Kline)
+	for _, interval := range []string{"4h", "5m"} {
+		if klines, err := LoadKlinesCSV(KlinePath(cfg.DataDir, "BTCUSDT", interval)); err == nil {
+			e.btc[interval] = klines
+		}
+	}
+	if len(e.btc["4h"]) == 0 {
+		backtestLog.Warn("⚠️  No BTCUSDT 4h history found - BTC correlation disabled")
+	}
+
+	return e, nil
+}
+
+// Run replays every 5m close in chronological order
+func (e *Engine) Run() (*Result, error) {
+	start, end := e.replayWindow()
+	if !start.Before(end) {
+		return nil, fmt.Errorf("not enough history: replay window %s → %s is empty",
+			start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	backtestLog.Info("🧪 Replaying history", "symbols", len(e.data),
+		"from", start.Format("2006-01-02 15:04"), "to", end.Format("2006-01-02 15:04"))
+
+	result := &Result{}
+	step := service.IntervalDuration("5m")
+
+	for t := start; !t.After(end); t = t.Add(step) {
+		nowMs := t.UnixMilli()
+
+		for _, data := range e.data {
+			klines5m := data.klines["5m"]
+
+			// Advance to the 5m candle that closes exactly at t
+			for data.cursor < len(klines5m) && klines5m[data.cursor].CloseTime < nowMs-1 {
+				data.cursor++
+			}
+			if data.cursor >= len(klines5m) || klines5m[data.cursor].CloseTime != nowMs-1 {
+				continue // Gap in data for this symbol
+			}
+			// Piggyback monitoring first, same as Loader.poll
+			e.checkOpenTrades(data.symbol, klines5m[data.cursor])
+
+			snapshot := e.buildSnapshot(data, nowMs, t)
+			signal, _, err := e.strategy.EvaluateSnapshot(snapshot)
+			result.Evaluations++
+			if err != nil {
+				backtestLog.Warn("⚠️  Evaluation error", "symbol", data.symbol, "at", t.Format(time.RFC3339), "error", err)
+				continue
+			}
+			if signal == nil {
+				continue
+			}
+
+			result.Candidates++
+			e.openTrade(signal, t)
+		}
+	}
+
+	// Mark remaining trades to the last price
+	for len(e.open) > 0 {
+		trade := e.open[0]
+		klines5m := e.symbolData(trade.Signal.Symbol).klines["5m"]
+		last := klines5m[len(klines5m)-1]
+		e.closeTrade(0, "END_OF_DATA", last.Close, time.UnixMilli(last.CloseTime+1))
+	}
+
+	result.Trades = e.closed
+	e.summarise(result)
+	return result, nil
+}
+
+// replayWindow computes the first time every series has a full warm-up window and the last common 5m close
+func (e *Engine) replayWindow() (time.Time, time.Time) {
+	var start, end time.Time
+
+	for _, data := range e.data {
+		for _, interval := range Intervals {
+			klines := data.klines[interval]
+			ready := time.UnixMilli(klines[0].OpenTime).Add(WarmupDuration(interval))
+			if ready.After(start) {
+				start = ready
+			}
+		}
+
+		last := data.klines["5m"]
+		lastClose := time.UnixMilli(last[len(last)-1].CloseTime + 1)
+		if end.IsZero() || lastClose.Before(end) {
+			end = lastClose
+		}
+	}
+
+	if !e.cfg.Start.IsZero() && e.cfg.Start.After(start) {
+		start = e.cfg.Start
+	}
+	if !e.cfg.End.IsZero() && e.cfg.End.Before(end) {
+		end = e.cfg.End
+	}
+
+	// Align to the next 5m boundary
+	if aligned := start.Truncate(service.IntervalDuration("5m")); aligned.Before(start) {
+		start = aligned.Add(service.IntervalDuration("5m"))
+	}
+
+	return start, end
+}
+
+// buildSnapshot assembles what a live scan at time t would have seen
+func (e *Engine) buildSnapshot(data *symbolData, nowMs int64, t time.Time) *service.MarketSnapshot {
+	base := data.klines["5m"]
+	snapshot := &service.MarketSnapshot{
+		Symbol:    data.symbol,
+		Time:      t,
+		Klines1d:  window(data.klines["1d"], base, nowMs, windowSizes["1d"]),
+		Klines4h:  window(data.klines["4h"], base, nowMs, windowSizes["4h"]),
+		Klines1h:  window(data.klines["1h"], base, nowMs, windowSizes["1h"]),
+		Klines15m: window(data.klines["15m"], base, nowMs, windowSizes["15m"]),
+		Klines5m:  window(base, base, nowMs, windowSizes["5m"]),
+	}
+
+	if data.symbol != "BTCUSDT" && len(e.btc["4h"]) > 0 {
+		snapshot.BTCKlines4h = window(e.btc["4h"], e.btc["5m"], nowMs, windowSizes["4h"])
+	}
+
+	// Funding, order book and perp premium have no stored history: they stay nil,
+	// which the strategy treats the same way as a failed live fetch.
+	return snapshot
+}
+
+// window returns the last `size` candles visible at nowMs.
+// Closed candles are taken as stored; a still-forming higher-timeframe candle
+// is rebuilt from the 5m candles closed so far, just like Binance would return it live.
+func window(klines, base []model.Kline, nowMs int64, size int) []model.Kline {
+	closed := sort.Search(len(klines), func(i int) bool { return klines[i].CloseTime >= nowMs })
+
+	var forming *model.Kline
+	if closed < len(klines) && klines[closed].OpenTime < nowMs {
+		forming = buildFormingCandle(klines[closed], base, nowMs)
+	}
+
+	keep := size
+	if forming != nil {
+		keep--
+	}
+	from := closed - keep
+	if from < 0 {
+		from = 0
+	}
+
+	out := make([]model.Kline, 0, size)
+	out = append(out, klines[from:closed]...)
+	if forming != nil {
+		out = append(out, *forming)
+	}
+	return out
+}
+
+// buildFormingCandle aggregates the 5m candles of a partially elapsed higher-timeframe candle
+func buildFormingCandle(parent model.Kline, base []model.Kline, nowMs int64) *model.Kline {
+	from := sort.Search(len(base), func(i int) bool { return base[i].OpenTime >= parent.OpenTime })
+	if from >= len(base) || base[from].CloseTime >= nowMs {
+		return nil
+	}
+
+	candle := model.Kline{
+		OpenTime:  parent.OpenTime,
+		Open:      base[from].Open,
+		High:      base[from].High,
+		Low:       base[from].Low,
+		CloseTime: parent.CloseTime,
+	}
+	for i := from; i < len(base) && base[i].CloseTime < nowMs; i++ {
+		candle.High = math.Max(candle.High, base[i].High)
+		candle.Low = math.Min(candle.Low, base[i].Low)
+		candle.Close = base[i].Close
+		candle.Volume += base[i].Volume
+	}
+	return &candle
+}
+
+// openTrade applies the Loader's cooldown and duplicate filters, then opens the trade
+func (e *Engine) openTrade(signal *model.Signal, t time.Time) {
+	if last, ok := e.lastSignal[signal.Symbol]; ok && t.Sub(last) < e.cfg.Cooldown {
+		return
+	}
+
+	// Duplicate check against the latest active signal of the same direction
+	for i := len(e.open) - 1; i >= 0; i-- {
+		existing := e.open[i].Signal
+		if existing.Symbol != signal.Symbol || existing.Type != signal.Type {
+			continue
+		}
+		percentDiff := math.Abs(existing.EntryPrice-signal.EntryPrice) / existing.EntryPrice * 100
+		if percentDiff <= e.cfg.ScalingInPercent {
+			return
+		}
+		break
+	}
+
+	signal.CreatedAt = t
+	e.lastSignal[signal.Symbol] = t
+	e.open = append(e.open, &Trade{Signal: signal})
+}
+
+// checkOpenTrades resolves TP1/TP2/SL for every open trade of a symbol from the candle's high/low
+func (e *Engine) checkOpenTrades(symbol string, k model.Kline) {
+	for i := 0; i < len(e.open); i++ {
+		signal := e.open[i].Signal
+		if signal.Symbol != symbol {
+			continue
+		}
+
+		for _, fill := range e.resolver.Resolve(signal, k) {
+			if fill.Final() {
+				e.closeTrade(i, fill.Reason, fill.Price, fill.Time)
+				i--
+				break
+			}
+		}
+	}
+}
+
+// lowerTimeframe returns the stored 1m candles inside a 5m candle
+func (e *Engine) lowerTimeframe(symbol string, k model.Kline) []model.Kline {
+	data := e.symbolData(symbol)
+	if data == nil || len(data.lower) == 0 {
+		return nil
+	}
+
+	from := sort.Search(len(data.lower), func(i int) bool { return data.lower[i].OpenTime >= k.OpenTime })
+	to := sort.Search(len(data.lower), func(i int) bool { return data.lower[i].OpenTime > k.CloseTime })
+	return data.lower[from:to]
+}
+
+// closeTrade finalises an open trade and feeds the outcome back into the tracker
+func (e *Engine) closeTrade(idx int, reason string, exitPrice float64, t time.Time) {
+	trade := e.open[idx]
+	e.open = append(e.open[:idx], e.open[idx+1:]...)
+
+	signal := trade.Signal
+	direction := string(signal.Type)
+	quantity := e.cfg.NotionalPerTrade / signal.EntryPrice
+
+	result := internalmath.CalculatePnL(signal.EntryPrice, exitPrice, direction, quantity)
+	result.Symbol = signal.Symbol
+	result.EntryTime = signal.Timestamp
+	result.ExitTime = t
+
+	// Blend in the half booked at TP1
+	if signal.TP1Hit {
+		result.PnLPercent = signal.BlendedPnL(result.PnLPercent)
+		result.PnL = e.cfg.NotionalPerTrade * result.PnLPercent / 100
+		result.IsWin = result.PnL > 0
+	}
+
+	closedAt := t
+	signal.Status = model.StatusClosed
+	signal.CloseReason = reason
+	signal.ClosedAt = &closedAt
+	signal.ResolvedAt = &closedAt
+	signal.ExitPrice = exitPrice
+	signal.PnL = result.PnLPercent
+	signal.PnLAmount = result.PnL
+
+	trade.ExitPrice = exitPrice
+	trade.ExitTime = t
+	trade.CloseReason = reason
+	trade.PnLPercent = result.PnLPercent
+	trade.Result = result
+
+	e.tracker.RecordSignalOutcome(signal, result.PnLPercent > 0)
+	e.closed = append(e.closed, *trade)
+}
+
+// summarise fills aggregate statistics
+func (e *Engine) summarise(result *Result) {
+	// Equity curve follows close order (the order PnL is realised in)
+	tradeResults := make([]internalmath.TradeResult, len(result.Trades))
+	equity := make([]float64, len(result.Trades)+1)
+	equity[0] = e.cfg.InitialEquity
+
+	for i, trade := range result.Trades {
+		tradeResults[i] = trade.Result
+		equity[i+1] = equity[i] + trade.Result.PnL
+	}
+
+	result.Stats = internalmath.CalculatePnLStats(tradeResults)
+	result.MaxDrawdown = internalmath.CalculateMaxDrawdown(equity)
+
+	// Report trades in entry order
+	sort.SliceStable(result.Trades, func(i, j int) bool {
+		return result.Trades[i].Signal.Timestamp.Before(result.Trades[j].Signal.Timestamp)
+	})
+}
+
+func (e *Engine) symbolData(symbol string) *symbolData {
+	for _, data := range e.data {
+		if data.symbol == symbol {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+var replayStart = time.Date(2025, 10, 9, 0, 0, 0, 0, time.UTC)
+
+// fiveMinute builds n 5m candles from replayStart, each opening where the last closed
+func fiveMinute(n int) []model.Kline {
+	klines := make([]model.Kline, n)
+	for i := range klines {
+		openTime := replayStart.Add(time.Duration(i) * 5 * time.Minute)
+		open := 100 + float64(i)
+		klines[i] = model.Kline{
+			OpenTime: openTime.UnixMilli(), Open: open, High: open + 2, Low: open - 1, Close: open + 1,
+			Volume: float64(i + 1), CloseTime: openTime.Add(5*time.Minute).UnixMilli() - 1,
+		}
+	}
+	return klines
+}
+
+// aggregate builds the stored candles of a higher timeframe from the 5m ones
+func aggregate(base []model.Kline, interval time.Duration) []model.Kline {
+	per := int(interval / (5 * time.Minute))
+	var klines []model.Kline
+	for i := 0; i+per <= len(base); i += per {
+		k := base[i]
+		for _, b := range base[i+1 : i+per] {
+			k.High, k.Low = math.Max(k.High, b.High), math.Min(k.Low, b.Low)
+			k.Close, k.Volume = b.Close, k.Volume+b.Volume
+		}
+		k.CloseTime = base[i+per-1].CloseTime
+		klines = append(klines, k)
+	}
+	return klines
+}
+
+func TestWindowNeverLooksAhead(t *testing.T) {
+	base := fiveMinute(12 * 6) // 6 hours
+	hourly := aggregate(base, time.Hour)
+
+	for step := 1; step <= len(base); step++ {
+		nowMs := replayStart.Add(time.Duration(step) * 5 * time.Minute).UnixMilli()
+		closedBase := base[:step] // The 5m candles closed at nowMs
+
+		got := window(hourly, base, nowMs, 3)
+		for i, k := range got[:len(got)-1] {
+			if k.CloseTime >= nowMs {
+				t.Fatalf("step %d: candle %d closes at %d, after now %d", step, i, k.CloseTime, nowMs)
+			}
+		}
+
+		last := got[len(got)-1]
+		if last.CloseTime < nowMs {
+			// Hour boundary: the last hour is closed and taken as stored
+			if step%12 != 0 || last != hourly[step/12-1] {
+				t.Fatalf("step %d: last candle %+v, want the stored closed hour", step, last)
+			}
+			continue
+		}
+
+		// Forming hour: only the 5m candles closed so far, never the stored (future) close
+		formingFrom := step / 12 * 12
+		want := aggregate(closedBase[formingFrom:], time.Duration(step-formingFrom)*5*time.Minute)[0]
+		want.CloseTime = hourly[step/12].CloseTime
+		if last != want {
+			t.Fatalf("step %d: forming candle %+v, want %+v", step, last, want)
+		}
+		if len(got) != min(3, step/12+1) {
+			t.Fatalf("step %d: %d candles, want the forming one plus the closed hours", step, len(got))
+		}
+	}
+}
+
+func TestWindowMidCandle(t *testing.T) {
+	base := fiveMinute(24)
+	hourly := aggregate(base, time.Hour)
+
+	// 27 minutes into the second hour: five 5m candles closed, the sixth still open
+	nowMs := replayStart.Add(time.Hour + 27*time.Minute).UnixMilli()
+	got := window(hourly, base, nowMs, 500)
+	if len(got) != 2 || got[0] != hourly[0] {
+		t.Fatalf("window = %+v, want the first hour and a forming one", got)
+	}
+	if forming := got[1]; forming.Open != base[12].Open || forming.Close != base[16].Close || forming.High != base[16].High ||
+		forming.Low != base[12].Low || forming.Volume != 13+14+15+16+17 {
+		t.Errorf("forming = %+v, want candles 12-16 only", forming)
+	}
+
+	// 3 minutes in: no 5m candle of the hour has closed, so there is nothing to show yet
+	nowMs = replayStart.Add(time.Hour + 3*time.Minute).UnixMilli()
+	if got := window(hourly, base, nowMs, 500); len(got) != 1 || got[0] != hourly[0] {
+		t.Errorf("window = %+v, want only the closed first hour", got)
+	}
+	if k := buildFormingCandle(hourly[1], base, nowMs); k != nil {
+		t.Errorf("buildFormingCandle = %+v, want nil before any 5m close", k)
+	}
+
+	// The 5m window itself only holds closed candles
+	got = window(base, base, nowMs, 500)
+	if last := got[len(got)-1]; len(got) != 12 || last != base[11] {
+		t.Errorf("5m window ends with %+v (%d candles), want the last closed one", last, len(got))
+	}
+}
+
+func TestOpenTradeFilters(t *testing.T) {
+	e := &Engine{cfg: Config{Cooldown: 4 * time.Hour, ScalingInPercent: 1.5}, lastSignal: make(map[string]time.Time)}
+	signal := func(symbol string, direction model.SignalType, entry float64) *model.Signal {
+		return &model.Signal{Symbol: symbol, Type: direction, EntryPrice: entry}
+	}
+
+	steps := []struct {
+		name   string
+		after  time.Duration // Since replayStart
+		signal *model.Signal
+		opened bool
+	}{
+		{"first signal", 0, signal("ETHUSDT", model.SignalTypeLong, 100), true},
+		{"cooldown", 3*time.Hour + 59*time.Minute, signal("ETHUSDT", model.SignalTypeLong, 110), false},
+		{"cooldown is per symbol", time.Hour, signal("BTCUSDT", model.SignalTypeLong, 100), true},
+		{"cooldown over, duplicate at the scaling limit", 4 * time.Hour, signal("ETHUSDT", model.SignalTypeLong, 101.5), false},
+		{"scaling in beyond the limit", 4 * time.Hour, signal("ETHUSDT", model.SignalTypeLong, 102), true},
+		{"opposite direction is no duplicate", 8 * time.Hour, signal("ETHUSDT", model.SignalTypeShort, 102), true},
+		// Compared with the latest open LONG (102), not the first one at the same price (100)
+		{"duplicate of the latest entry", 12 * time.Hour, signal("ETHUSDT", model.SignalTypeLong, 100), true},
+		{"duplicate blocked", 16 * time.Hour, signal("ETHUSDT", model.SignalTypeLong, 101), false},
+	}
+	for _, step := range steps {
+		before := len(e.open)
+		at := replayStart.Add(step.after)
+		e.openTrade(step.signal, at)
+
+		if opened := len(e.open) > before; opened != step.opened {
+			t.Fatalf("%s: opened = %v, want %v", step.name, opened, step.opened)
+		}
+		if step.opened && (!step.signal.CreatedAt.Equal(at) || !e.lastSignal[step.signal.Symbol].Equal(at)) {
+			t.Errorf("%s: created %v, last signal %v, want %v", step.name, step.signal.CreatedAt, e.lastSignal[step.signal.Symbol], at)
+		}
+	}
+
+	// Once the open trades close, the same price is allowed again (only open signals are duplicates)
+	e.open = nil
+	e.openTrade(signal("ETHUSDT", model.SignalTypeLong, 101), replayStart.Add(20*time.Hour))
+	if len(e.open) != 1 {
+		t.Error("a closed trade should not block a new signal")
+	}
+}
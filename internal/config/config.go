@@ -2,22 +2,77 @@ package config
 
 import (
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	NodeEnv          string
-	Port             string
-	MongoURI         string
-	BinanceAPIKey    string
-	BinanceSecretKey string
-	BinanceBaseURL   string
-	TelegramBotToken string
-	TelegramChatID   string
-	GeminiAPIKeys    []string // Supports multiple keys for rotation
+	NodeEnv           string
+	Port              string
+	MongoURI          string
+	BinanceAPIKey     string
+	BinanceSecretKey  string
+	BinanceBaseURL    string
+	BinanceFuturesURL string
+	MarketType        string   // "futures" (USDT-M perpetuals) or "spot"
+	Exchanges         []string // Enabled exchanges; the first serves watchlist entries without one
+	BybitBaseURL      string
+	OKXBaseURL        string
+	TelegramBotToken  string
+	TelegramChatID    string
+	GeminiAPIKeys     []string // Supports multiple keys for rotation
+	GeminiModels      []string // Batch validation models, tried in order
+	KlineCachePersist bool     // Persist the kline cache to MongoDB between restarts
+	TPSLTieBreak      string   // Candle touching both TP and SL: "pessimistic" or "lower_tf"
+	EvaluationMode    string   // "live" (forming candles included) or "closed" (closed candles only)
+	CandidateJournal  bool     // Journal rejected setups to "candidates" and track them forward
+
+	// Logging
+	LogLevel  string // debug, info, warn or error
+	LogFormat string // text or json
+
+	// HTTP API on Port
+	APIEnabled       bool
+	APIHost          string // Interface the HTTP server binds to; anything but loopback needs APIToken
+	APIToken         string // Bearer token for /api/*, empty = no auth (loopback only)
+	HealthMaxPollAge int    // Minutes without a successful poll before /healthz fails
+	ShutdownTimeout  int    // Seconds a running poll gets to finish on shutdown
+
+	// WebSocket streaming (live candles + price ticks for the monitor)
+	StreamEnabled           bool
+	BinanceStreamURL        string
+	BinanceFuturesStreamURL string
+
+	// AI validation backend and what to do when it is unavailable
+	AIValidator         string // "gemini", "openai" (any OpenAI-compatible server) or "rules"
+	AIUnavailablePolicy string // "skip" the cycle, "pass" signals through flagged, or "fallback" to rules
+	OpenAIBaseURL       string
+	OpenAIAPIKey        string
+	OpenAIModel         string
+
+	// Portfolio risk gate (0 disables a limit)
+	MaxOpenSignals   int
+	MaxPortfolioRisk float64 // % of account at risk across open signals
+	MaxSameDirection int
+	DailyLossLimit   float64 // Realised loss per day, % of account
+
+	// Order book depth analysis (distance-weighted imbalance, bands and persistent walls)
+	DepthAnalysis     bool
+	DepthBands        []float64 // Imbalance bands in % from mid
+	DepthHalfLife     float64   // % from mid at which a level counts half
+	DepthWallMultiple float64   // Wall = level this many times the median level size
+	DepthWallMinAge   int       // Minutes a wall must stand before it is trusted
+	DepthAggregate    bool      // Combine the books of every enabled exchange
+
+	// Strategy profiles: thresholds for scoring, SL/TP, cooldown and scaling-in.
+	// Every selected profile scans side by side; signals record the profile name.
+	StrategyProfileFile  string   // YAML or JSON file, empty = built-in default profile
+	StrategyProfileNames []string // Profiles to run from the file, empty = all
+	StrategyProfiles     []StrategyProfile
 }
 
 var AppConfig *Config
@@ -26,22 +81,79 @@ var AppConfig *Config
 func Load() {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+		slog.Info("No .env file found, using system environment variables")
 	}
 
 	AppConfig = &Config{
-		NodeEnv:          getEnv("NODE_ENV", "development"),
-		Port:             getEnv("PORT", "8080"),
-		MongoURI:         getEnv("MONGO_URI", "mongodb://localhost:27017/mrcrypto"),
-		BinanceAPIKey:    getEnv("BINANCE_API_KEY", ""),
-		BinanceSecretKey: getEnv("BINANCE_SECRET_KEY", ""),
-		BinanceBaseURL:   getEnv("BINANCE_BASE_URL", "https://api.binance.com"),
-		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
-		TelegramChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
-		GeminiAPIKeys:    getEnvAsSlice("GEMINI_API_KEY", ""),
-	}
-
-	log.Println("✅ Configuration loaded successfully")
+		NodeEnv:           getEnv("NODE_ENV", "development"),
+		Port:              getEnv("PORT", "8080"),
+		MongoURI:          getEnv("MONGO_URI", "mongodb://localhost:27017/mrcrypto"),
+		BinanceAPIKey:     getEnv("BINANCE_API_KEY", ""),
+		BinanceSecretKey:  getEnv("BINANCE_SECRET_KEY", ""),
+		BinanceBaseURL:    getEnv("BINANCE_BASE_URL", "https://api.binance.com"),
+		BinanceFuturesURL: getEnv("BINANCE_FUTURES_URL", "https://fapi.binance.com"),
+		MarketType:        getEnv("MARKET_TYPE", "futures"),
+		Exchanges:         getEnvAsSlice("EXCHANGES", "binance"),
+		BybitBaseURL:      getEnv("BYBIT_BASE_URL", "https://api.bybit.com"),
+		OKXBaseURL:        getEnv("OKX_BASE_URL", "https://www.okx.com"),
+		TelegramBotToken:  getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:    getEnv("TELEGRAM_CHAT_ID", ""),
+		GeminiAPIKeys:     getEnvAsSlice("GEMINI_API_KEY", ""),
+		GeminiModels:      getEnvAsSlice("GEMINI_MODELS", "gemini-3-pro-preview,gemini-3-flash-preview,gemini-2.5-flash,gemini-2.5-flash-lite,gemini-2.5-pro"),
+		KlineCachePersist: getEnv("KLINE_CACHE_PERSIST", "false") == "true",
+		TPSLTieBreak:      getEnv("TP_SL_TIE_BREAK", "pessimistic"),
+		EvaluationMode:    getEnv("EVALUATION_MODE", "live"),
+		CandidateJournal:  getEnv("CANDIDATE_JOURNAL", "true") == "true",
+
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "text"),
+
+		APIEnabled:       getEnv("API_ENABLED", "true") == "true",
+		APIHost:          getEnv("API_HOST", "127.0.0.1"),
+		APIToken:         getEnv("API_TOKEN", ""),
+		HealthMaxPollAge: getEnvAsInt("HEALTH_MAX_POLL_AGE", 10),
+		ShutdownTimeout:  getEnvAsInt("SHUTDOWN_TIMEOUT", 30),
+
+		StreamEnabled:           getEnv("STREAM_ENABLED", "true") == "true",
+		BinanceStreamURL:        getEnv("BINANCE_STREAM_URL", "wss://stream.binance.com:9443/stream"),
+		BinanceFuturesStreamURL: getEnv("BINANCE_FUTURES_STREAM_URL", "wss://fstream.binance.com/stream"),
+
+		AIValidator:         getEnv("AI_VALIDATOR", "gemini"),
+		AIUnavailablePolicy: getEnv("AI_UNAVAILABLE_POLICY", "skip"),
+		OpenAIBaseURL:       getEnv("OPENAI_BASE_URL", "http://localhost:11434/v1"),
+		OpenAIAPIKey:        getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:         getEnv("OPENAI_MODEL", "llama3.1"),
+
+		MaxOpenSignals:   getEnvAsInt("MAX_OPEN_SIGNALS", 5),
+		MaxPortfolioRisk: getEnvAsFloat("MAX_PORTFOLIO_RISK", 6),
+		MaxSameDirection: getEnvAsInt("MAX_SAME_DIRECTION", 3),
+		DailyLossLimit:   getEnvAsFloat("DAILY_LOSS_LIMIT", 10),
+
+		DepthAnalysis:     getEnv("DEPTH_ANALYSIS", "true") == "true",
+		DepthBands:        getEnvAsFloatSlice("DEPTH_BANDS", []float64{0.5, 1, 2}),
+		DepthHalfLife:     getEnvAsFloat("DEPTH_HALF_LIFE", 1),
+		DepthWallMultiple: getEnvAsFloat("DEPTH_WALL_MULTIPLE", 5),
+		DepthWallMinAge:   getEnvAsInt("DEPTH_WALL_MIN_AGE", 3),
+		DepthAggregate:    getEnv("DEPTH_AGGREGATE", "false") == "true",
+
+		StrategyProfileFile:  getEnv("STRATEGY_PROFILE_FILE", ""),
+		StrategyProfileNames: getEnvAsSlice("STRATEGY_PROFILES", ""),
+	}
+
+	AppConfig.StrategyProfiles = []StrategyProfile{DefaultStrategyProfile()}
+	if AppConfig.StrategyProfileFile != "" {
+		profiles, err := LoadStrategyProfiles(AppConfig.StrategyProfileFile)
+		if err == nil {
+			profiles, err = SelectStrategyProfiles(profiles, AppConfig.StrategyProfileNames)
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to load strategy profiles: %v", err)
+		}
+		AppConfig.StrategyProfiles = profiles
+		slog.Info("✅ Loaded strategy profiles", "profiles", len(profiles), "file", AppConfig.StrategyProfileFile)
+	}
+
+	slog.Info("✅ Configuration loaded successfully")
 }
 
 func getEnv(key, defaultValue string) string {
@@ -52,6 +164,22 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+func getEnvAsInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, ""), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsSlice(key, defaultValue string) []string {
 	value := getEnv(key, defaultValue)
 	if value == "" {
@@ -60,3 +188,19 @@ func getEnvAsSlice(key, defaultValue string) []string {
 	// Split by comma
 	return strings.Split(value, ",")
 }
+
+func getEnvAsFloatSlice(key string, defaultValue []float64) []float64 {
+	parts := getEnvAsSlice(key, "")
+	if len(parts) == 0 {
+		return defaultValue
+	}
+	values := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return defaultValue
+		}
+		values = append(values, value)
+	}
+	return values
+}
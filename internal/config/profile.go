@@ -0,0 +1,315 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfileName is used for the built-in profile and for signals saved before profiles existed
+const DefaultProfileName = "default"
+
+// StrategyProfile holds every tunable threshold of the strategy and the signal pipeline.
+// Fields missing from a profile file keep their DefaultStrategyProfile value.
+type StrategyProfile struct {
+	Name string `yaml:"name" json:"name"`
+
+	MinScore     int `yaml:"min_score" json:"min_score"`         // Minimum confluence (and AI) score
+	PremiumScore int `yaml:"premium_score" json:"premium_score"` // PREMIUM tier, may enter away from key levels
+
+	// SL/TP percentages by 1h ATR volatility
+	Risk RiskProfile `yaml:"risk" json:"risk"`
+
+	// ADX regime cutoffs (average of 1h and 15m)
+	Regime RegimeProfile `yaml:"regime" json:"regime"`
+
+	// Order book imbalance bands (%) and their score impact
+	OrderBook OrderBookProfile `yaml:"order_book" json:"order_book"`
+
+	// Funding sentiment score weights
+	Funding FundingWeights `yaml:"funding" json:"funding"`
+
+	Cooldown         Duration `yaml:"cooldown" json:"cooldown"`                     // Per-symbol cooldown between signals
+	ScalingInPercent float64  `yaml:"scaling_in_percent" json:"scaling_in_percent"` // Same-direction re-entry allowed beyond this % move
+}
+
+// RiskProfile selects SL/TP levels from the 1h ATR as % of price
+type RiskProfile struct {
+	HighVolatilityATR float64    `yaml:"high_volatility_atr" json:"high_volatility_atr"` // ATR % above this uses HighVolatility
+	LowVolatilityATR  float64    `yaml:"low_volatility_atr" json:"low_volatility_atr"`   // ATR % below this uses LowVolatility
+	Normal            RiskLevels `yaml:"normal" json:"normal"`
+	HighVolatility    RiskLevels `yaml:"high_volatility" json:"high_volatility"`
+	LowVolatility     RiskLevels `yaml:"low_volatility" json:"low_volatility"`
+}
+
+// RiskLevels are SL/TP distances in % of entry
+type RiskLevels struct {
+	StopLoss    float64 `yaml:"stop_loss" json:"stop_loss"`
+	TakeProfit1 float64 `yaml:"take_profit_1" json:"take_profit_1"`
+	TakeProfit2 float64 `yaml:"take_profit_2" json:"take_profit_2"`
+}
+
+// RegimeProfile holds the ADX cutoffs for regime detection
+type RegimeProfile struct {
+	ChoppyADX float64 `yaml:"choppy_adx" json:"choppy_adx"` // Below: CHOPPY (skipped)
+	TrendADX  float64 `yaml:"trend_adx" json:"trend_adx"`   // Below: RANGING, above: TRENDING
+}
+
+// OrderBookProfile scores the bid/ask imbalance against the signal direction
+type OrderBookProfile struct {
+	ModerateImbalance float64 `yaml:"moderate_imbalance" json:"moderate_imbalance"`
+	StrongImbalance   float64 `yaml:"strong_imbalance" json:"strong_imbalance"`
+	ModerateBonus     int     `yaml:"moderate_bonus" json:"moderate_bonus"`
+	StrongBonus       int     `yaml:"strong_bonus" json:"strong_bonus"`
+	OpposingPenalty   int     `yaml:"opposing_penalty" json:"opposing_penalty"` // Moderate imbalance against the trade
+	WallBonus         int     `yaml:"wall_bonus" json:"wall_bonus"`             // Persistent wall behind the trade (depth analyzer)
+	WallPenalty       int     `yaml:"wall_penalty" json:"wall_penalty"`         // Persistent wall in the way of the trade
+}
+
+// FundingWeights are the score adjustments for funding sentiment vs trade direction
+type FundingWeights struct {
+	ExtremeCrowdedPenalty int `yaml:"extreme_crowded_penalty" json:"extreme_crowded_penalty"` // Trading with an extreme crowd
+	ContrarianBonus       int `yaml:"contrarian_bonus" json:"contrarian_bonus"`               // Trading against an extreme crowd
+	CrowdedPenalty        int `yaml:"crowded_penalty" json:"crowded_penalty"`                 // Trading with a bullish/bearish crowd
+}
+
+// Duration is a time.Duration written as "4h" or "90m" in profile files
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) parse(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// UnmarshalYAML reads a duration string
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+// UnmarshalJSON reads a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"4h\": %w", err)
+	}
+	return d.parse(value)
+}
+
+// MarshalYAML writes the duration as a string
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// DefaultStrategyProfile returns the thresholds the bot has always traded with
+func DefaultStrategyProfile() StrategyProfile {
+	return StrategyProfile{
+		Name:         DefaultProfileName,
+		MinScore:     80,
+		PremiumScore: 90,
+		Risk: RiskProfile{
+			HighVolatilityATR: 3.0,
+			LowVolatilityATR:  1.0,
+			Normal:            RiskLevels{StopLoss: 3.0, TakeProfit1: 4.5, TakeProfit2: 9.0},
+			HighVolatility:    RiskLevels{StopLoss: 4.5, TakeProfit1: 6.75, TakeProfit2: 13.5},
+			LowVolatility:     RiskLevels{StopLoss: 2.5, TakeProfit1: 3.75, TakeProfit2: 7.5},
+		},
+		Regime: RegimeProfile{
+			ChoppyADX: 20,
+			TrendADX:  25,
+		},
+		OrderBook: OrderBookProfile{
+			ModerateImbalance: 15,
+			StrongImbalance:   30,
+			ModerateBonus:     6,
+			StrongBonus:       12,
+			OpposingPenalty:   15,
+			WallBonus:         5,
+			WallPenalty:       8,
+		},
+		Funding: FundingWeights{
+			ExtremeCrowdedPenalty: 15,
+			ContrarianBonus:       10,
+			CrowdedPenalty:        5,
+		},
+		Cooldown:         Duration{4 * time.Hour},
+		ScalingInPercent: 1.5,
+	}
+}
+
+// LevelsForATR returns the SL/TP distances for a 1h ATR (% of price)
+func (r RiskProfile) LevelsForATR(atrPercent float64) RiskLevels {
+	switch {
+	case atrPercent > r.HighVolatilityATR:
+		return r.HighVolatility
+	case atrPercent < r.LowVolatilityATR:
+		return r.LowVolatility
+	default:
+		return r.Normal
+	}
+}
+
+// profileFile is the layout of a profile file: one profile, or several under "profiles"
+type profileFile struct {
+	Profiles []yaml.Node `yaml:"profiles"`
+}
+
+type jsonProfileFile struct {
+	Profiles []json.RawMessage `json:"profiles"`
+}
+
+// LoadStrategyProfiles reads profiles from a YAML (.yaml/.yml) or JSON (.json) file.
+// The file holds either a single profile or a "profiles" list; each profile starts from the defaults.
+func LoadStrategyProfiles(path string) ([]StrategyProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategy profile: %w", err)
+	}
+
+	var profiles []StrategyProfile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		profiles, err = decodeJSONProfiles(data)
+	case ".yaml", ".yml":
+		profiles, err = decodeYAMLProfiles(data)
+	default:
+		return nil, fmt.Errorf("unsupported strategy profile format %q (use .yaml, .yml or .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse strategy profile %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range profiles {
+		if profiles[i].Name == "" {
+			profiles[i].Name = DefaultProfileName
+		}
+		if seen[profiles[i].Name] {
+			return nil, fmt.Errorf("duplicate strategy profile %q in %s", profiles[i].Name, path)
+		}
+		seen[profiles[i].Name] = true
+
+		if err := profiles[i].Validate(); err != nil {
+			return nil, fmt.Errorf("strategy profile %q: %w", profiles[i].Name, err)
+		}
+	}
+	return profiles, nil
+}
+
+func decodeYAMLProfiles(data []byte) ([]StrategyProfile, error) {
+	var file profileFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if len(file.Profiles) == 0 {
+		profile := DefaultStrategyProfile()
+		if err := yaml.Unmarshal(data, &profile); err != nil {
+			return nil, err
+		}
+		return []StrategyProfile{profile}, nil
+	}
+
+	profiles := make([]StrategyProfile, len(file.Profiles))
+	for i := range file.Profiles {
+		profiles[i] = DefaultStrategyProfile()
+		if err := file.Profiles[i].Decode(&profiles[i]); err != nil {
+			return nil, err
+		}
+	}
+	return profiles, nil
+}
+
+func decodeJSONProfiles(data []byte) ([]StrategyProfile, error) {
+	var file jsonProfileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if len(file.Profiles) == 0 {
+		profile := DefaultStrategyProfile()
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return nil, err
+		}
+		return []StrategyProfile{profile}, nil
+	}
+
+	profiles := make([]StrategyProfile, len(file.Profiles))
+	for i := range file.Profiles {
+		profiles[i] = DefaultStrategyProfile()
+		if err := json.Unmarshal(file.Profiles[i], &profiles[i]); err != nil {
+			return nil, err
+		}
+	}
+	return profiles, nil
+}
+
+// Validate rejects profiles that would silently break the strategy
+func (p StrategyProfile) Validate() error {
+	if p.MinScore < 0 || p.MinScore > 100 || p.PremiumScore < p.MinScore || p.PremiumScore > 100 {
+		return fmt.Errorf("need 0 <= min_score <= premium_score <= 100 (got %d/%d)", p.MinScore, p.PremiumScore)
+	}
+	for name, levels := range map[string]RiskLevels{
+		"normal":          p.Risk.Normal,
+		"high_volatility": p.Risk.HighVolatility,
+		"low_volatility":  p.Risk.LowVolatility,
+	} {
+		if levels.StopLoss <= 0 || levels.TakeProfit1 <= 0 || levels.TakeProfit2 < levels.TakeProfit1 {
+			return fmt.Errorf("risk.%s: need stop_loss > 0 and 0 < take_profit_1 <= take_profit_2", name)
+		}
+	}
+	if p.Risk.LowVolatilityATR > p.Risk.HighVolatilityATR {
+		return fmt.Errorf("risk: low_volatility_atr must not exceed high_volatility_atr")
+	}
+	if p.Regime.ChoppyADX > p.Regime.TrendADX {
+		return fmt.Errorf("regime: choppy_adx must not exceed trend_adx")
+	}
+	if p.OrderBook.ModerateImbalance > p.OrderBook.StrongImbalance {
+		return fmt.Errorf("order_book: moderate_imbalance must not exceed strong_imbalance")
+	}
+	if p.OrderBook.WallBonus < 0 || p.OrderBook.WallPenalty < 0 {
+		return fmt.Errorf("order_book: wall_bonus and wall_penalty must not be negative")
+	}
+	if p.Cooldown.Duration < 0 || p.ScalingInPercent < 0 {
+		return fmt.Errorf("cooldown and scaling_in_percent must not be negative")
+	}
+	return nil
+}
+
+// SelectStrategyProfiles keeps the named profiles in the given order (all when names is empty)
+func SelectStrategyProfiles(profiles []StrategyProfile, names []string) ([]StrategyProfile, error) {
+	if len(names) == 0 {
+		return profiles, nil
+	}
+
+	byName := make(map[string]StrategyProfile, len(profiles))
+	for _, profile := range profiles {
+		byName[profile.Name] = profile
+	}
+
+	selected := make([]StrategyProfile, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		profile, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("strategy profile %q not found", name)
+		}
+		selected = append(selected, profile)
+	}
+	return selected, nil
+}
@@ -3,94 +3,109 @@ package indicator
 import (
 	"math"
 	internalmath "mrcrypto-go/internal/math"
+	"mrcrypto-go/internal/model"
 )
 
 func CalculateTrueRange(high, low, close []float64) []float64 {
 	return internalmath.CalculateTrueRange(high, low, close)
 }
 
-// CalculateADX calculates the Average Directional Index
-func CalculateADX(high, low, close []float64, period int) []float64 {
-	if len(high) < period+1 || len(low) < period+1 || len(close) < period+1 {
-		return []float64{}
-	}
+// ADX is an incremental Average Directional Index
+type ADX struct {
+	prev    model.Kline
+	count   int
+	plusDM  wilder
+	minusDM wilder
+	tr      wilder
+	adx     wilder
+	plusDI  float64
+	minusDI float64
+}
 
-	// Calculate +DM and -DM
-	plusDM := make([]float64, len(high)-1)
-	minusDM := make([]float64, len(high)-1)
+// NewADX creates an ADX that is ready after 2*period candles
+func NewADX(period int) *ADX {
+	return &ADX{
+		plusDM:  wilder{period: period},
+		minusDM: wilder{period: period},
+		tr:      wilder{period: period},
+		adx:     wilder{period: period},
+	}
+}
 
-	for i := 1; i < len(high); i++ {
-		highDiff := high[i] - high[i-1]
-		lowDiff := low[i-1] - low[i]
+// Update advances the ADX by one candle and returns it (0 until ready)
+func (a *ADX) Update(kline model.Kline) float64 {
+	a.count++
+	prev := a.prev
+	a.prev = kline
+	if a.count == 1 {
+		return 0
+	}
 
-		if highDiff > lowDiff && highDiff > 0 {
-			plusDM[i-1] = highDiff
-		} else {
-			plusDM[i-1] = 0
-		}
+	// Calculate +DM and -DM
+	highDiff := kline.High - prev.High
+	lowDiff := prev.Low - kline.Low
 
-		if lowDiff > highDiff && lowDiff > 0 {
-			minusDM[i-1] = lowDiff
-		} else {
-			minusDM[i-1] = 0
-		}
+	plusDM, minusDM := 0.0, 0.0
+	if highDiff > lowDiff && highDiff > 0 {
+		plusDM = highDiff
+	}
+	if lowDiff > highDiff && lowDiff > 0 {
+		minusDM = lowDiff
 	}
 
-	// Calculate True Range
-	tr := CalculateTrueRange(high, low, close)
-
-	// Calculate smoothed +DM, -DM, and TR
-	smoothedPlusDM := smoothArray(plusDM, period)
-	smoothedMinusDM := smoothArray(minusDM, period)
-	smoothedTR := smoothArray(tr[1:], period)
+	a.plusDM.update(plusDM)
+	a.minusDM.update(minusDM)
+	a.tr.update(trueRange(kline.High, kline.Low, prev.Close))
+	if !a.tr.ready() {
+		return 0
+	}
 
 	// Calculate +DI and -DI
-	plusDI := make([]float64, len(smoothedTR))
-	minusDI := make([]float64, len(smoothedTR))
-
-	for i := 0; i < len(smoothedTR); i++ {
-		if smoothedTR[i] != 0 {
-			plusDI[i] = (smoothedPlusDM[i] / smoothedTR[i]) * 100
-			minusDI[i] = (smoothedMinusDM[i] / smoothedTR[i]) * 100
-		}
+	a.plusDI, a.minusDI = 0, 0
+	if a.tr.value != 0 {
+		a.plusDI = (a.plusDM.value / a.tr.value) * 100
+		a.minusDI = (a.minusDM.value / a.tr.value) * 100
 	}
 
-	// Calculate DX
-	dx := make([]float64, len(plusDI))
-	for i := 0; i < len(plusDI); i++ {
-		diSum := plusDI[i] + minusDI[i]
-		if diSum != 0 {
-			dx[i] = (math.Abs(plusDI[i]-minusDI[i]) / diSum) * 100
-		}
+	// Calculate DX and smooth it into ADX
+	dx := 0.0
+	if diSum := a.plusDI + a.minusDI; diSum != 0 {
+		dx = (math.Abs(a.plusDI-a.minusDI) / diSum) * 100
 	}
+	a.adx.update(dx)
 
-	// Calculate ADX (smoothed DX)
-	adx := smoothArray(dx, period)
-
-	return adx
+	return a.Value()
 }
 
-// smoothArray applies Wilder's smoothing
-func smoothArray(values []float64, period int) []float64 {
-	if len(values) < period {
-		return []float64{}
+// Value returns the current ADX (0 until ready)
+func (a *ADX) Value() float64 {
+	if !a.adx.ready() {
+		return 0
 	}
+	return a.adx.value
+}
 
-	smoothed := make([]float64, len(values)-period+1)
+// DI returns the current +DI and -DI
+func (a *ADX) DI() (plusDI, minusDI float64) { return a.plusDI, a.minusDI }
 
-	// First value is average
-	var sum float64
-	for i := 0; i < period; i++ {
-		sum += values[i]
-	}
-	smoothed[0] = sum / float64(period)
+// Ready reports whether the ADX has seen 2*period candles
+func (a *ADX) Ready() bool { return a.adx.ready() }
 
-	// Apply Wilder's smoothing
-	for i := 1; i < len(smoothed); i++ {
-		smoothed[i] = (smoothed[i-1]*float64(period-1) + values[i+period-1]) / float64(period)
+// CalculateADX calculates the Average Directional Index
+func CalculateADX(high, low, close []float64, period int) []float64 {
+	if len(high) < period+1 || len(low) < period+1 || len(close) < period+1 {
+		return []float64{}
 	}
 
-	return smoothed
+	adx := []float64{}
+	state := NewADX(period)
+	for i := range high {
+		state.Update(model.Kline{High: high[i], Low: low[i], Close: close[i]})
+		if state.Ready() {
+			adx = append(adx, state.Value())
+		}
+	}
+	return adx
 }
 
 // GetLastADX returns the most recent ADX value
@@ -0,0 +1,71 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateADXGolden(t *testing.T) {
+	klines := loadFixture(t)
+	highs, lows, closes, _ := ohlcv(klines)
+	want := loadGolden(t, "adx_14.csv")
+
+	// ADX starts at candle 2*period-1 and ends at the last candle
+	adx := CalculateADX(highs, lows, closes, 14)
+	if len(adx) != len(klines)-27 {
+		t.Fatalf("len = %d, want %d", len(adx), len(klines)-27)
+	}
+	assertGolden(t, "adx", alignRight(adx, len(klines)), want["adx"])
+}
+
+func TestCalculateADXEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		if got := CalculateADX(ramp(14, 101, 1), ramp(14, 99, 1), ramp(14, 100, 1), 14); len(got) != 0 {
+			t.Errorf("len = %d, want 0", len(got))
+		}
+		// Enough for +DI/-DI but not for the DX average
+		if got := CalculateADX(ramp(27, 101, 1), ramp(27, 99, 1), ramp(27, 100, 1), 14); len(got) != 0 {
+			t.Errorf("len = %d, want 0 below 2*period candles", len(got))
+		}
+		if got := GetLastADX(ramp(27, 101, 1), ramp(27, 99, 1), ramp(27, 100, 1), 14); got != 0 {
+			t.Errorf("GetLastADX = %v, want 0", got)
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		for i, v := range CalculateADX(flat(40, 100), flat(40, 100), flat(40, 100), 14) {
+			if v != 0 {
+				t.Errorf("adx[%d] = %v, want 0", i, v)
+			}
+		}
+	})
+
+	t.Run("steady trend", func(t *testing.T) {
+		// Only +DM, never -DM: DX is 100 throughout
+		got := GetLastADX(ramp(40, 101, 1), ramp(40, 99, 1), ramp(40, 100, 1), 14)
+		if math.Abs(got-100) > 1e-9 {
+			t.Errorf("adx = %v, want 100", got)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		highs, lows, closes, _ := ohlcv(loadFixture(t))
+		for i, v := range CalculateADX(highs, lows, closes, 14) {
+			if v < 0 || v > 100 {
+				t.Errorf("adx[%d] = %v, outside [0, 100]", i, v)
+			}
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		adx := CalculateADX(withNaN(ramp(40, 101, 1), 20), ramp(40, 99, 1), ramp(40, 100, 1), 14)
+		if len(adx) == 0 {
+			t.Fatal("no values")
+		}
+		for i, v := range adx {
+			if !math.IsNaN(v) {
+				t.Errorf("adx[%d] = %v, want NaN when a high in the window is NaN", i, v)
+			}
+		}
+	})
+}
@@ -5,39 +5,79 @@ import (
 	"mrcrypto-go/internal/model"
 )
 
+// ATR is an incremental Average True Range using Wilder's smoothing
+type ATR struct {
+	prevClose float64
+	count     int
+	tr        wilder
+}
+
+// NewATR creates an ATR that is ready after period+1 candles
+func NewATR(period int) *ATR {
+	return &ATR{tr: wilder{period: period}}
+}
+
+// Update advances the ATR by one candle and returns it (0 until ready)
+func (a *ATR) Update(kline model.Kline) float64 {
+	a.count++
+	prevClose := a.prevClose
+	a.prevClose = kline.Close
+	if a.count > 1 {
+		a.tr.update(trueRange(kline.High, kline.Low, prevClose))
+	}
+	return a.Value()
+}
+
+// Value returns the current ATR (0 until ready)
+func (a *ATR) Value() float64 {
+	if !a.tr.ready() {
+		return 0
+	}
+	return a.tr.value
+}
+
+// Ready reports whether the ATR has seen period+1 candles
+func (a *ATR) Ready() bool { return a.tr.ready() }
+
 // CalculateATR calculates the Average True Range
 func CalculateATR(klines []model.Kline, period int) float64 {
 	if len(klines) < period+1 {
 		return 0
 	}
 
-	trValues := make([]float64, len(klines))
-
-	// Calculate TR (True Range) for each candle
-	for i := 1; i < len(klines); i++ {
-		high := klines[i].High
-		low := klines[i].Low
-		prevClose := klines[i-1].Close
+	state := NewATR(period)
+	for _, k := range klines {
+		state.Update(k)
+	}
+	return state.Value()
+}
 
-		tr1 := high - low
-		tr2 := math.Abs(high - prevClose)
-		tr3 := math.Abs(low - prevClose)
+// trueRange is the largest of high-low and the gaps from the previous close
+func trueRange(high, low, prevClose float64) float64 {
+	tr1 := high - low
+	tr2 := math.Abs(high - prevClose)
+	tr3 := math.Abs(low - prevClose)
+	return math.Max(tr1, math.Max(tr2, tr3))
+}
 
-		trValues[i] = math.Max(tr1, math.Max(tr2, tr3))
-	}
+// wilder applies Wilder's smoothing incrementally; the first value is the average of period inputs
+type wilder struct {
+	period int
+	count  int
+	sum    float64
+	value  float64
+}
 
-	// First ATR is simple average of TR for the period
-	sumTR := 0.0
-	for i := 1; i <= period; i++ {
-		sumTR += trValues[i]
+func (w *wilder) update(v float64) {
+	w.count++
+	if w.count > w.period {
+		w.value = (w.value*float64(w.period-1) + v) / float64(w.period)
+		return
 	}
-	currentATR := sumTR / float64(period)
-
-	// Subsequent ATRs using smoothing: ATR = ((Prior ATR * (n-1)) + Current TR) / n
-	// Start from period + 1
-	for i := period + 1; i < len(klines); i++ {
-		currentATR = ((currentATR * float64(period-1)) + trValues[i]) / float64(period)
+	w.sum += v
+	if w.count == w.period {
+		w.value = w.sum / float64(w.period)
 	}
-
-	return currentATR
 }
+
+func (w *wilder) ready() bool { return w.count >= w.period }
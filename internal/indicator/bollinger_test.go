@@ -0,0 +1,58 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateBollingerBandsGolden(t *testing.T) {
+	_, _, closes, _ := ohlcv(loadFixture(t))
+	want := loadGolden(t, "bollinger_20_2.csv")
+
+	upper, middle, lower := CalculateBollingerBands(closes, 20, 2)
+	assertGolden(t, "upper", upper, want["upper"])
+	assertGolden(t, "middle", middle, want["middle"])
+	assertGolden(t, "lower", lower, want["lower"])
+}
+
+func TestCalculateBollingerBandsEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		upper, middle, lower := CalculateBollingerBands(ramp(19, 100, 1), 20, 2)
+		if len(upper) != 0 || len(middle) != 0 || len(lower) != 0 {
+			t.Errorf("lengths = %d/%d/%d, want empty", len(upper), len(middle), len(lower))
+		}
+		if u, m, l := GetLastBollingerBands(ramp(19, 100, 1), 20, 2); u != 0 || m != 0 || l != 0 {
+			t.Errorf("GetLastBollingerBands = %v/%v/%v, want zeros", u, m, l)
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		u, m, l := GetLastBollingerBands(flat(30, 100), 20, 2)
+		if u != 100 || m != 100 || l != 100 {
+			t.Errorf("bands = %v/%v/%v, want all 100", u, m, l)
+		}
+	})
+
+	t.Run("population deviation", func(t *testing.T) {
+		// Alternating 99/101: mean 100, population std 1
+		closes := make([]float64, 20)
+		for i := range closes {
+			closes[i] = 99 + 2*float64(i%2)
+		}
+		u, m, l := GetLastBollingerBands(closes, 20, 2)
+		if math.Abs(u-102) > 1e-9 || m != 100 || math.Abs(l-98) > 1e-9 {
+			t.Errorf("bands = %v/%v/%v, want 102/100/98", u, m, l)
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		// Only the windows containing the bad close are NaN
+		upper, _, _ := CalculateBollingerBands(withNaN(ramp(45, 100, 1), 25), 20, 2)
+		for i := 19; i < len(upper); i++ {
+			inWindow := i >= 25 && i < 45
+			if math.IsNaN(upper[i]) != inWindow {
+				t.Errorf("upper[%d] = %v, NaN expected: %v", i, upper[i], inWindow)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+func TestCalculateCVDGolden(t *testing.T) {
+	want := loadGolden(t, "cvd.csv")
+
+	assertGolden(t, "cvd", CalculateCVD(loadFixture(t)), want["cvd"])
+}
+
+// candles builds klines from open/close pairs with the given volumes
+func candles(opens, closes, volumes []float64) []model.Kline {
+	klines := make([]model.Kline, len(opens))
+	for i := range opens {
+		klines[i] = model.Kline{
+			Open:   opens[i],
+			High:   math.Max(opens[i], closes[i]) + 1,
+			Low:    math.Min(opens[i], closes[i]) - 1,
+			Close:  closes[i],
+			Volume: volumes[i],
+		}
+	}
+	return klines
+}
+
+func TestCalculateCVDEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		if got := CalculateCVD(candles([]float64{100}, []float64{101}, []float64{5})); len(got) != 0 {
+			t.Errorf("len = %d, want 0", len(got))
+		}
+		if value, trend := GetLastCVDTrend(candles(flat(3, 100), flat(3, 101), flat(3, 5)), 5); value != 0 || trend != 0 {
+			t.Errorf("GetLastCVDTrend = %v/%v, want 0/0 below lookback+1 candles", value, trend)
+		}
+		if got := GetCVDDivergence(candles(flat(9, 100), flat(9, 101), flat(9, 5)), 5); got != "" {
+			t.Errorf("GetCVDDivergence = %q, want none below 2*lookback candles", got)
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		// Every candle is a doji: no delta
+		for i, v := range CalculateCVD(candles(flat(10, 100), flat(10, 100), flat(10, 50))) {
+			if v != 0 {
+				t.Errorf("cvd[%d] = %v, want 0", i, v)
+			}
+		}
+	})
+
+	t.Run("zero volume", func(t *testing.T) {
+		for i, v := range CalculateCVD(candles(ramp(10, 100, 1), ramp(10, 101, 1), flat(10, 0))) {
+			if v != 0 {
+				t.Errorf("cvd[%d] = %v, want 0", i, v)
+			}
+		}
+	})
+
+	t.Run("signed volume", func(t *testing.T) {
+		got := CalculateCVD(candles([]float64{100, 101, 100, 100}, []float64{101, 100, 100, 102}, []float64{10, 4, 7, 3}))
+		want := []float64{10, 6, 6, 9}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("cvd[%d] = %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("trend and divergence", func(t *testing.T) {
+		// Price grinds lower on light red candles while one green candle carries the volume
+		opens := []float64{110, 109, 108, 107, 106, 105, 104, 103, 102, 101}
+		closes := []float64{109, 108, 107, 106, 105, 104, 103, 102, 101, 100}
+		volumes := flat(10, 1)
+		opens[7], closes[7], volumes[7] = 102, 103, 50 // one heavy green candle
+		klines := candles(opens, closes, volumes)
+
+		value, trend := GetLastCVDTrend(klines, 5)
+		if value != 41 || trend != 46 {
+			t.Errorf("GetLastCVDTrend = %v/%v, want 41/46", value, trend)
+		}
+		if got := GetCVDDivergence(klines, 5); got != "Bullish CVD Divergence" {
+			t.Errorf("GetCVDDivergence = %q, want bullish", got)
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		// A NaN close cannot be classified and counts as a doji
+		klines := candles(flat(4, 100), []float64{101, 101, 101, 101}, flat(4, 5))
+		klines[2].Close = math.NaN()
+		got := CalculateCVD(klines)
+		if got[3] != 15 {
+			t.Errorf("cvd[3] = %v, want 15", got[3])
+		}
+
+		// A NaN volume poisons everything after it
+		klines = candles(flat(4, 100), flat(4, 101), withNaN(flat(4, 5), 2))
+		got = CalculateCVD(klines)
+		if got[1] != 10 || !math.IsNaN(got[2]) || !math.IsNaN(got[3]) {
+			t.Errorf("cvd = %v, want [5 10 NaN NaN]", got)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package indicator
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+// goldenTolerance is the allowed difference relative to max(1, |want|); the reference
+// implementations in testdata/reference.py use the same float64 arithmetic
+const goldenTolerance = 1e-9
+
+// loadFixture reads testdata/klines_1h.csv (open_time,open,high,low,close,volume)
+func loadFixture(t *testing.T) []model.Kline {
+	t.Helper()
+	rows := readCSV(t, filepath.Join("testdata", "klines_1h.csv"))
+
+	klines := make([]model.Kline, len(rows))
+	for i, row := range rows {
+		values := make([]float64, len(row))
+		for j, cell := range row {
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				t.Fatalf("klines_1h.csv row %d: %v", i+2, err)
+			}
+			values[j] = v
+		}
+		klines[i] = model.Kline{
+			OpenTime:  int64(values[0]),
+			Open:      values[1],
+			High:      values[2],
+			Low:       values[3],
+			Close:     values[4],
+			Volume:    values[5],
+			CloseTime: int64(values[0]) + 3_600_000 - 1,
+		}
+	}
+	return klines
+}
+
+// loadGolden reads testdata/golden/<name> and returns its columns by header (empty cells = NaN)
+func loadGolden(t *testing.T, name string) map[string][]float64 {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "golden", name))
+	if err != nil {
+		t.Fatalf("open golden file: %v", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if len(records) < 2 {
+		t.Fatalf("%s has no rows", name)
+	}
+
+	columns := make(map[string][]float64, len(records[0]))
+	for _, row := range records[1:] {
+		for j, header := range records[0] {
+			v := math.NaN()
+			if row[j] != "" {
+				if v, err = strconv.ParseFloat(row[j], 64); err != nil {
+					t.Fatalf("%s column %s: %v", name, header, err)
+				}
+			}
+			columns[header] = append(columns[header], v)
+		}
+	}
+	return columns
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return records[1:]
+}
+
+// ohlcv splits klines into price and volume series
+func ohlcv(klines []model.Kline) (highs, lows, closes, volumes []float64) {
+	for _, k := range klines {
+		highs = append(highs, k.High)
+		lows = append(lows, k.Low)
+		closes = append(closes, k.Close)
+		volumes = append(volumes, k.Volume)
+	}
+	return highs, lows, closes, volumes
+}
+
+// alignRight pads a series that ends at the last candle with NaN so it has one value per candle
+func alignRight(values []float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	copy(out[n-len(values):], values)
+	return out
+}
+
+// assertGolden compares got with want wherever want is defined; got must have one value per candle
+func assertGolden(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d values, want %d", name, len(got), len(want))
+	}
+
+	defined, failures := 0, 0
+	for i := range want {
+		if math.IsNaN(want[i]) {
+			continue
+		}
+		defined++
+		if !closeTo(got[i], want[i]) {
+			failures++
+			if failures <= 5 {
+				t.Errorf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+			}
+		}
+	}
+	if failures > 5 {
+		t.Errorf("%s: %d more mismatches", name, failures-5)
+	}
+	if defined == 0 {
+		t.Fatalf("%s: golden file has no defined values", name)
+	}
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) <= goldenTolerance*math.Max(1, math.Abs(want))
+}
+
+// flat returns n copies of v
+func flat(n int, v float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+// ramp returns n values rising by step from start
+func ramp(n int, start, step float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = start + float64(i)*step
+	}
+	return out
+}
+
+// withNaN returns a copy of values with values[i] = NaN
+func withNaN(values []float64, i int) []float64 {
+	out := append([]float64(nil), values...)
+	out[i] = math.NaN()
+	return out
+}
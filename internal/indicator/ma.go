@@ -1,5 +1,78 @@
 package indicator
 
+// EMA is an incremental Exponential Moving Average, seeded with the SMA of the first period values
+type EMA struct {
+	period     int
+	multiplier float64
+	count      int
+	sum        float64
+	value      float64
+}
+
+// NewEMA creates an EMA that is ready after period updates
+func NewEMA(period int) *EMA {
+	return &EMA{period: period, multiplier: 2.0 / float64(period+1)}
+}
+
+// Update advances the EMA by one value and returns it (0 until ready)
+func (e *EMA) Update(value float64) float64 {
+	e.count++
+	switch {
+	case e.count < e.period:
+		e.sum += value
+	case e.count == e.period:
+		e.sum += value
+		e.value = e.sum / float64(e.period)
+	default:
+		e.value = (value-e.value)*e.multiplier + e.value
+	}
+	return e.value
+}
+
+// Value returns the current EMA (0 until ready)
+func (e *EMA) Value() float64 { return e.value }
+
+// Ready reports whether the EMA has seen a full period
+func (e *EMA) Ready() bool { return e.count >= e.period }
+
+// SMA is an incremental Simple Moving Average over the last period values
+type SMA struct {
+	period int
+	window []float64 // Ring buffer; next is the oldest slot once full
+	next   int
+	count  int
+	value  float64
+}
+
+// NewSMA creates an SMA that is ready after period updates
+func NewSMA(period int) *SMA {
+	return &SMA{period: period, window: make([]float64, period)}
+}
+
+// Update advances the SMA by one value and returns it (0 until ready)
+func (s *SMA) Update(value float64) float64 {
+	s.window[s.next] = value
+	s.next = (s.next + 1) % s.period
+	s.count++
+	if s.count < s.period {
+		return s.value
+	}
+
+	// Sum oldest to newest so results match a fresh sum over the window
+	sum := 0.0
+	for i := 0; i < s.period; i++ {
+		sum += s.window[(s.next+i)%s.period]
+	}
+	s.value = sum / float64(s.period)
+	return s.value
+}
+
+// Value returns the current SMA (0 until ready)
+func (s *SMA) Value() float64 { return s.value }
+
+// Ready reports whether the SMA has seen a full period
+func (s *SMA) Ready() bool { return s.count >= s.period }
+
 // CalculateEMA calculates the Exponential Moving Average
 func CalculateEMA(closes []float64, period int) []float64 {
 	if len(closes) < period {
@@ -7,20 +80,10 @@ func CalculateEMA(closes []float64, period int) []float64 {
 	}
 
 	ema := make([]float64, len(closes))
-	multiplier := 2.0 / float64(period+1)
-
-	// First EMA is SMA
-	var sum float64
-	for i := 0; i < period; i++ {
-		sum += closes[i]
+	state := NewEMA(period)
+	for i, c := range closes {
+		ema[i] = state.Update(c)
 	}
-	ema[period-1] = sum / float64(period)
-
-	// Calculate subsequent EMAs
-	for i := period; i < len(closes); i++ {
-		ema[i] = (closes[i]-ema[i-1])*multiplier + ema[i-1]
-	}
-
 	return ema
 }
 
@@ -31,12 +94,9 @@ func CalculateSMA(closes []float64, period int) []float64 {
 	}
 
 	sma := make([]float64, len(closes))
-	for i := period - 1; i < len(closes); i++ {
-		sum := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			sum += closes[j]
-		}
-		sma[i] = sum / float64(period)
+	state := NewSMA(period)
+	for i, c := range closes {
+		sma[i] = state.Update(c)
 	}
 	return sma
 }
@@ -1,30 +1,62 @@
 package indicator
 
+// MACD is an incremental Moving Average Convergence Divergence
+type MACD struct {
+	fast      *EMA
+	slow      *EMA
+	signal    *EMA
+	macd      float64
+	histogram float64
+}
+
+// NewMACD creates a MACD whose line is ready after slowPeriod closes and signal after slowPeriod+signalPeriod-1
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) *MACD {
+	return &MACD{fast: NewEMA(fastPeriod), slow: NewEMA(slowPeriod), signal: NewEMA(signalPeriod)}
+}
+
+// Update advances the MACD by one close and returns the MACD, signal and histogram values
+func (m *MACD) Update(close float64) (macd, signal, histogram float64) {
+	m.fast.Update(close)
+	m.slow.Update(close)
+	if m.slow.Ready() {
+		m.macd = m.fast.Value() - m.slow.Value()
+		m.histogram = m.macd - m.signal.Update(m.macd)
+	}
+	return m.Value()
+}
+
+// Value returns the current MACD, signal and histogram (0 until the respective line is ready)
+func (m *MACD) Value() (macd, signal, histogram float64) {
+	return m.macd, m.signal.Value(), m.histogram
+}
+
+// Ready reports whether the signal line has seen a full period
+func (m *MACD) Ready() bool { return m.signal.Ready() }
+
 // CalculateMACD calculates the Moving Average Convergence Divergence
 func CalculateMACD(closes []float64, fastPeriod, slowPeriod, signalPeriod int) (macd, signal, histogram []float64) {
 	if len(closes) < slowPeriod {
 		return []float64{}, []float64{}, []float64{}
 	}
 
-	// Calculate fast and slow EMAs
-	fastEMA := CalculateEMA(closes, fastPeriod)
-	slowEMA := CalculateEMA(closes, slowPeriod)
-
-	// Calculate MACD line
+	// Signal and histogram start at the first MACD value
 	macdLine := make([]float64, len(closes))
-	for i := slowPeriod - 1; i < len(closes); i++ {
-		macdLine[i] = fastEMA[i] - slowEMA[i]
-	}
-
-	// Calculate signal line (EMA of MACD)
-	signalLine := CalculateEMA(macdLine[slowPeriod-1:], signalPeriod)
+	signalLine := make([]float64, 0, len(closes)-slowPeriod+1)
+	histogramLine := make([]float64, 0, len(closes)-slowPeriod+1)
 
-	// Calculate histogram
-	histogramLine := make([]float64, len(signalLine))
-	for i := 0; i < len(signalLine); i++ {
-		histogramLine[i] = macdLine[slowPeriod-1+i] - signalLine[i]
+	state := NewMACD(fastPeriod, slowPeriod, signalPeriod)
+	for i, c := range closes {
+		m, s, h := state.Update(c)
+		macdLine[i] = m
+		if i >= slowPeriod-1 {
+			signalLine = append(signalLine, s)
+			histogramLine = append(histogramLine, h)
+		}
 	}
 
+	if len(signalLine) < signalPeriod {
+		return macdLine, []float64{}, []float64{}
+	}
 	return macdLine, signalLine, histogramLine
 }
 
@@ -0,0 +1,59 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateMACDGolden(t *testing.T) {
+	klines := loadFixture(t)
+	_, _, closes, _ := ohlcv(klines)
+	want := loadGolden(t, "macd_12_26_9.csv")
+
+	macd, signal, histogram := CalculateMACD(closes, 12, 26, 9)
+	assertGolden(t, "macd", macd, want["macd"])
+	assertGolden(t, "signal", alignRight(signal, len(klines)), want["signal"])
+	assertGolden(t, "histogram", alignRight(histogram, len(klines)), want["histogram"])
+}
+
+func TestCalculateMACDEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		macd, signal, histogram := CalculateMACD(ramp(25, 100, 1), 12, 26, 9)
+		if len(macd) != 0 || len(signal) != 0 || len(histogram) != 0 {
+			t.Errorf("lengths = %d/%d/%d, want empty below the slow period", len(macd), len(signal), len(histogram))
+		}
+		// A MACD line but not enough of it for the signal EMA
+		if m, s, h := GetLastMACD(ramp(30, 100, 1), 12, 26, 9); m != 0 || s != 0 || h != 0 {
+			t.Errorf("GetLastMACD = %v/%v/%v, want zeros", m, s, h)
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		macd, signal, histogram := CalculateMACD(flat(60, 100), 12, 26, 9)
+		for _, series := range [][]float64{macd, signal, histogram} {
+			for i, v := range series {
+				if v != 0 {
+					t.Errorf("value[%d] = %v, want 0", i, v)
+				}
+			}
+		}
+	})
+
+	t.Run("linear trend", func(t *testing.T) {
+		// On a straight line each EMA lags by (period-1)/2 steps, so MACD converges to (26-12)/2 * step
+		m, s, h := GetLastMACD(ramp(300, 100, 1), 12, 26, 9)
+		if math.Abs(m-7) > 1e-6 || math.Abs(s-7) > 1e-6 || math.Abs(h) > 1e-6 {
+			t.Errorf("GetLastMACD = %v/%v/%v, want 7/7/0", m, s, h)
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		macd, _, histogram := CalculateMACD(withNaN(ramp(60, 100, 1), 40), 12, 26, 9)
+		if math.IsNaN(macd[39]) {
+			t.Error("macd[39] = NaN, want a value before the bad close")
+		}
+		if !math.IsNaN(macd[len(macd)-1]) || !math.IsNaN(histogram[len(histogram)-1]) {
+			t.Error("want NaN after the bad close, the EMAs never recover")
+		}
+	})
+}
@@ -2,66 +2,72 @@ package indicator
 
 import "math"
 
-// CalculateRSI calculates the Relative Strength Index
-func CalculateRSI(closes []float64, period int) []float64 {
-	if len(closes) < period+1 {
-		return []float64{}
-	}
+// RSI is an incremental Relative Strength Index using Wilder's smoothing
+type RSI struct {
+	prevClose float64
+	count     int
+	avgGain   wilder
+	avgLoss   wilder
+	value     float64
+}
 
-	const epsilon = 1e-10 // Threshold for near-zero values
+// NewRSI creates an RSI that is ready after period+1 closes
+func NewRSI(period int) *RSI {
+	return &RSI{avgGain: wilder{period: period}, avgLoss: wilder{period: period}}
+}
 
-	rsi := make([]float64, len(closes))
-	gains := make([]float64, len(closes)-1)
-	losses := make([]float64, len(closes)-1)
+// Update advances the RSI by one close and returns it (0 until ready)
+func (r *RSI) Update(close float64) float64 {
+	const epsilon = 1e-10 // Threshold for near-zero values
 
-	// Calculate gains and losses
-	for i := 1; i < len(closes); i++ {
-		change := closes[i] - closes[i-1]
-		if change > 0 {
-			gains[i-1] = change
-			losses[i-1] = 0
-		} else {
-			gains[i-1] = 0
-			losses[i-1] = math.Abs(change)
-		}
+	r.count++
+	prevClose := r.prevClose
+	r.prevClose = close
+	if r.count == 1 {
+		return r.value
 	}
 
-	// Calculate first average gain and loss
-	var avgGain, avgLoss float64
-	for i := 0; i < period; i++ {
-		avgGain += gains[i]
-		avgLoss += losses[i]
+	change := close - prevClose
+	if change > 0 {
+		r.avgGain.update(change)
+		r.avgLoss.update(0)
+	} else {
+		r.avgGain.update(0)
+		r.avgLoss.update(math.Abs(change))
+	}
+	if !r.avgLoss.ready() {
+		return r.value
 	}
-	avgGain /= float64(period)
-	avgLoss /= float64(period)
 
-	// Calculate RSI for first period
-	if avgLoss < epsilon {
-		rsi[period] = 100
+	if r.avgLoss.value < epsilon {
+		r.value = 100
 	} else {
-		rs := avgGain / avgLoss
-		rsi[period] = 100 - (100 / (1 + rs))
+		rs := r.avgGain.value / r.avgLoss.value
+		r.value = 100 - (100 / (1 + rs))
 	}
 
 	// Clamp to valid range
-	rsi[period] = math.Max(0, math.Min(100, rsi[period]))
+	r.value = math.Max(0, math.Min(100, r.value))
+	return r.value
+}
 
-	// Calculate subsequent RSI values using smoothed averages
-	for i := period; i < len(gains); i++ {
-		avgGain = (avgGain*float64(period-1) + gains[i]) / float64(period)
-		avgLoss = (avgLoss*float64(period-1) + losses[i]) / float64(period)
+// Value returns the current RSI (0 until ready)
+func (r *RSI) Value() float64 { return r.value }
 
-		if avgLoss < epsilon {
-			rsi[i+1] = 100
-		} else {
-			rs := avgGain / avgLoss
-			rsi[i+1] = 100 - (100 / (1 + rs))
-		}
+// Ready reports whether the RSI has seen period+1 closes
+func (r *RSI) Ready() bool { return r.avgLoss.ready() }
 
-		// Clamp to valid range
-		rsi[i+1] = math.Max(0, math.Min(100, rsi[i+1]))
+// CalculateRSI calculates the Relative Strength Index
+func CalculateRSI(closes []float64, period int) []float64 {
+	if len(closes) < period+1 {
+		return []float64{}
 	}
 
+	rsi := make([]float64, len(closes))
+	state := NewRSI(period)
+	for i, c := range closes {
+		rsi[i] = state.Update(c)
+	}
 	return rsi
 }
 
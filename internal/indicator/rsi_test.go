@@ -0,0 +1,70 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateRSIGolden(t *testing.T) {
+	_, _, closes, _ := ohlcv(loadFixture(t))
+	want := loadGolden(t, "rsi_14.csv")
+
+	assertGolden(t, "rsi", CalculateRSI(closes, 14), want["rsi"])
+}
+
+func TestCalculateRSIEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		if got := CalculateRSI(ramp(14, 100, 1), 14); len(got) != 0 {
+			t.Errorf("len = %d, want 0 (needs period+1 closes)", len(got))
+		}
+		if got := GetLastRSI(ramp(14, 100, 1), 14); got != 0 {
+			t.Errorf("GetLastRSI = %v, want 0", got)
+		}
+	})
+
+	t.Run("warm-up is zero", func(t *testing.T) {
+		rsi := CalculateRSI(ramp(20, 100, 1), 14)
+		for i := 0; i < 14; i++ {
+			if rsi[i] != 0 {
+				t.Errorf("rsi[%d] = %v, want 0 before the first full period", i, rsi[i])
+			}
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		// No losses means RSI 100, as on TradingView (TA-Lib reports 0)
+		for i, v := range CalculateRSI(flat(30, 100), 14)[14:] {
+			if v != 100 {
+				t.Errorf("rsi[%d] = %v, want 100", i+14, v)
+			}
+		}
+	})
+
+	t.Run("only losses", func(t *testing.T) {
+		if got := GetLastRSI(ramp(30, 100, -1), 14); got != 0 {
+			t.Errorf("rsi = %v, want 0", got)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		_, _, closes, _ := ohlcv(loadFixture(t))
+		for i, v := range CalculateRSI(closes, 14) {
+			if v < 0 || v > 100 {
+				t.Errorf("rsi[%d] = %v, outside [0, 100]", i, v)
+			}
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		// A bad close must not turn into a plausible RSI
+		rsi := CalculateRSI(withNaN(ramp(30, 100, 1), 20), 14)
+		if math.IsNaN(rsi[19]) {
+			t.Errorf("rsi[19] = NaN, want a value before the bad close")
+		}
+		for i := 20; i < len(rsi); i++ {
+			if !math.IsNaN(rsi[i]) {
+				t.Errorf("rsi[%d] = %v, want NaN after the bad close", i, rsi[i])
+			}
+		}
+	})
+}
@@ -1,24 +1,37 @@
 package indicator
 
-// CalculateStochRSI calculates Stochastic RSI (K and D)
-// rsiValues: Input RSI array
-// period: Length of StochRSI (usually 14)
-// smoothK: Smoothing for %K (usually 3)
-// smoothD: Smoothing for %D (usually 3)
-func CalculateStochRSI(rsiValues []float64, period, smoothK, smoothD int) ([]float64, []float64) {
-	if len(rsiValues) < period {
-		return []float64{}, []float64{}
+// StochRSI is an incremental Stochastic RSI fed with RSI values
+type StochRSI struct {
+	period int
+	window []float64 // Ring buffer of the last period RSI values
+	next   int
+	count  int
+	k      *SMA
+	d      *SMA
+}
+
+// NewStochRSI creates a StochRSI; %K and %D include the zero raw values before the first full period
+func NewStochRSI(period, smoothK, smoothD int) *StochRSI {
+	return &StochRSI{
+		period: period,
+		window: make([]float64, period),
+		k:      NewSMA(smoothK),
+		d:      NewSMA(smoothD),
 	}
+}
 
-	stochRSI := make([]float64, len(rsiValues))
+// Update advances the StochRSI by one RSI value and returns %K and %D (0 until ready)
+func (s *StochRSI) Update(rsi float64) (k, d float64) {
+	s.window[s.next] = rsi
+	s.next = (s.next + 1) % s.period
+	s.count++
 
-	// Calculate raw StochRSI
-	for i := period - 1; i < len(rsiValues); i++ {
+	raw := 0.0
+	if s.count >= s.period {
 		// Find Min and Max RSI in period
 		minRSI := 100.0
 		maxRSI := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			val := rsiValues[j]
+		for _, val := range s.window {
 			if val < minRSI {
 				minRSI = val
 			}
@@ -28,18 +41,49 @@ func CalculateStochRSI(rsiValues []float64, period, smoothK, smoothD int) ([]flo
 		}
 
 		if maxRSI-minRSI == 0 {
-			stochRSI[i] = 100 // Edge case: flat RSI
+			raw = 100 // Edge case: flat RSI
 		} else {
-			stochRSI[i] = ((rsiValues[i] - minRSI) / (maxRSI - minRSI)) * 100
+			raw = ((rsi - minRSI) / (maxRSI - minRSI)) * 100
 		}
 	}
 
-	// Calculate %K (SMA of StochRSI)
-	kLine := CalculateSMA(stochRSI, smoothK)
+	k = s.k.Update(raw)
+	d = s.d.Update(k)
+	return k, d
+}
 
-	// Calculate %D (SMA of %K)
-	dLine := CalculateSMA(kLine, smoothD)
+// Value returns the current %K and %D
+func (s *StochRSI) Value() (k, d float64) { return s.k.Value(), s.d.Value() }
 
+// Ready reports whether %D covers full StochRSI windows
+func (s *StochRSI) Ready() bool {
+	return s.count >= s.period+s.k.period+s.d.period-2
+}
+
+// CalculateStochRSI calculates Stochastic RSI (K and D)
+// rsiValues: Input RSI array
+// period: Length of StochRSI (usually 14)
+// smoothK: Smoothing for %K (usually 3)
+// smoothD: Smoothing for %D (usually 3)
+func CalculateStochRSI(rsiValues []float64, period, smoothK, smoothD int) ([]float64, []float64) {
+	if len(rsiValues) < period {
+		return []float64{}, []float64{}
+	}
+
+	kLine := make([]float64, len(rsiValues))
+	dLine := make([]float64, len(rsiValues))
+	state := NewStochRSI(period, smoothK, smoothD)
+	for i, rsi := range rsiValues {
+		kLine[i], dLine[i] = state.Update(rsi)
+	}
+
+	// Match the SMA warm-up rules: each line needs at least its smoothing length
+	if len(kLine) < smoothK {
+		return []float64{}, []float64{}
+	}
+	if len(dLine) < smoothD {
+		return kLine, []float64{}
+	}
 	return kLine, dLine
 }
 
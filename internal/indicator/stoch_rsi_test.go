@@ -0,0 +1,64 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateStochRSIGolden(t *testing.T) {
+	klines := loadFixture(t)
+	_, _, closes, _ := ohlcv(klines)
+	want := loadGolden(t, "stoch_rsi_14_3_3.csv")
+
+	// Fed with the defined RSI values only; K and D end at the last candle
+	k, d := CalculateStochRSI(CalculateRSI(closes, 14)[14:], 14, 3, 3)
+	assertGolden(t, "k", alignRight(k, len(klines)), want["k"])
+	assertGolden(t, "d", alignRight(d, len(klines)), want["d"])
+}
+
+func TestCalculateStochRSIEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		if k, d := CalculateStochRSI(ramp(13, 40, 1), 14, 3, 3); len(k) != 0 || len(d) != 0 {
+			t.Errorf("lengths = %d/%d, want empty", len(k), len(d))
+		}
+		if k, d := GetLastStochRSI(ramp(13, 40, 1), 14, 3, 3); k != 0 || d != 0 {
+			t.Errorf("GetLastStochRSI = %v/%v, want 0/0", k, d)
+		}
+	})
+
+	t.Run("flat RSI", func(t *testing.T) {
+		// No range to measure against: treated as the top of the range
+		k, d := GetLastStochRSI(flat(30, 50), 14, 3, 3)
+		if k != 100 || d != 100 {
+			t.Errorf("k/d = %v/%v, want 100/100", k, d)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		_, _, closes, _ := ohlcv(loadFixture(t))
+		k, d := CalculateStochRSI(CalculateRSI(closes, 14), 14, 3, 3)
+		for i := range k {
+			if k[i] < 0 || k[i] > 100 || d[i] < 0 || d[i] > 100 {
+				t.Errorf("k/d[%d] = %v/%v, outside [0, 100]", i, k[i], d[i])
+			}
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		// A NaN RSI only spoils the smoothing windows that contain it
+		k, d := CalculateStochRSI(withNaN(ramp(40, 40, 1), 20), 14, 3, 3)
+		for i := 20; i <= 22; i++ {
+			if !math.IsNaN(k[i]) {
+				t.Errorf("k[%d] = %v, want NaN", i, k[i])
+			}
+		}
+		for i := 20; i <= 24; i++ {
+			if !math.IsNaN(d[i]) {
+				t.Errorf("d[%d] = %v, want NaN", i, d[i])
+			}
+		}
+		if k[23] != 100 || d[25] != 100 {
+			t.Errorf("k[23]/d[25] = %v/%v, want 100/100 once the NaN is out of the windows", k[23], d[25])
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+// assertStep checks a streaming value against the golden value for candle i; the indicator
+// must be ready exactly where the golden file starts defining values
+func assertStep(t *testing.T, name string, i int, ready bool, got, want float64) {
+	t.Helper()
+	if ready != !math.IsNaN(want) {
+		t.Fatalf("%s: ready = %v at candle %d, golden defined = %v", name, ready, i, !math.IsNaN(want))
+	}
+	if ready && !closeTo(got, want) {
+		t.Fatalf("%s[%d] = %v, want %v", name, i, got, want)
+	}
+}
+
+func TestStreamingMatchesGolden(t *testing.T) {
+	klines := loadFixture(t)
+	rsiWant := loadGolden(t, "rsi_14.csv")
+	adxWant := loadGolden(t, "adx_14.csv")
+	macdWant := loadGolden(t, "macd_12_26_9.csv")
+	stochWant := loadGolden(t, "stoch_rsi_14_3_3.csv")
+	vwapWant := loadGolden(t, "vwap.csv")
+
+	rsi, adx, macd, stoch, vwap := NewRSI(14), NewADX(14), NewMACD(12, 26, 9), NewStochRSI(14, 3, 3), NewVWAP()
+	for i, k := range klines {
+		rsi.Update(k.Close)
+		adx.Update(k)
+		macd.Update(k.Close)
+		vwap.Update(k)
+		if rsi.Ready() {
+			stoch.Update(rsi.Value())
+		}
+
+		assertStep(t, "rsi", i, rsi.Ready(), rsi.Value(), rsiWant["rsi"][i])
+		assertStep(t, "adx", i, adx.Ready(), adx.Value(), adxWant["adx"][i])
+		m, s, h := macd.Value()
+		assertStep(t, "signal", i, macd.Ready(), s, macdWant["signal"][i])
+		assertStep(t, "histogram", i, macd.Ready(), h, macdWant["histogram"][i])
+		if macd.Ready() {
+			assertStep(t, "macd", i, true, m, macdWant["macd"][i])
+		}
+		kv, dv := stoch.Value()
+		assertStep(t, "d", i, stoch.Ready(), dv, stochWant["d"][i])
+		if stoch.Ready() {
+			assertStep(t, "k", i, true, kv, stochWant["k"][i])
+		}
+		assertStep(t, "vwap", i, true, vwap.Value(), vwapWant["vwap"][i])
+	}
+}
+
+func TestStreamingSeedThenAdvance(t *testing.T) {
+	klines := loadFixture(t)
+	highs, lows, closes, _ := ohlcv(klines)
+
+	// Seed from the first 200 candles, then advance one candle at a time
+	ema, atr, adx := NewEMA(50), NewATR(14), NewADX(14)
+	for _, k := range klines[:200] {
+		ema.Update(k.Close)
+		atr.Update(k)
+		adx.Update(k)
+	}
+	for n := 201; n <= len(klines); n++ {
+		k := klines[n-1]
+		emaBatch := CalculateEMA(closes[:n], 50)
+		if got := ema.Update(k.Close); got != emaBatch[n-1] {
+			t.Fatalf("ema after %d candles = %v, want %v", n, got, emaBatch[n-1])
+		}
+		if got, want := atr.Update(k), CalculateATR(klines[:n], 14); got != want {
+			t.Fatalf("atr after %d candles = %v, want %v", n, got, want)
+		}
+		if got, want := adx.Update(k), GetLastADX(highs[:n], lows[:n], closes[:n], 14); got != want {
+			t.Fatalf("adx after %d candles = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestStreamingWarmUp(t *testing.T) {
+	t.Run("ema", func(t *testing.T) {
+		ema := NewEMA(3)
+		for i, v := range []float64{1, 2} {
+			if got := ema.Update(v); got != 0 || ema.Ready() {
+				t.Errorf("update %d = %v ready=%v, want 0 and not ready", i, got, ema.Ready())
+			}
+		}
+		if got := ema.Update(6); got != 3 || !ema.Ready() {
+			t.Errorf("seed = %v ready=%v, want the SMA 3", got, ema.Ready())
+		}
+	})
+
+	t.Run("sma", func(t *testing.T) {
+		sma := NewSMA(2)
+		want := []float64{0, 1.5, 2.5, 3.5}
+		for i, v := range []float64{1, 2, 3, 4} {
+			if got := sma.Update(v); got != want[i] {
+				t.Errorf("sma[%d] = %v, want %v", i, got, want[i])
+			}
+		}
+	})
+
+	t.Run("atr", func(t *testing.T) {
+		atr := NewATR(2)
+		for _, k := range candles(flat(2, 100), flat(2, 100), flat(2, 1)) {
+			atr.Update(k)
+		}
+		if atr.Ready() || atr.Value() != 0 {
+			t.Errorf("ATR ready=%v value=%v after 2 candles, want not ready", atr.Ready(), atr.Value())
+		}
+	})
+
+	t.Run("vwap", func(t *testing.T) {
+		vwap := NewVWAP()
+		if got := vwap.Update(candles([]float64{100}, []float64{100}, []float64{0})[0]); got != 0 {
+			t.Errorf("vwap = %v, want 0 before volume trades", got)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+#!/usr/bin/env python3
+"""Writes the golden indicator outputs for klines_1h.csv.
+
+The reference implementations below are written from the published definitions, independently of
+the Go code, and use no third-party packages:
+
+- RSI, ADX: Wilder's smoothing seeded with the simple average of the first `period` values
+  (Wilder, "New Concepts in Technical Trading Systems"; TradingView ta.rsi / ta.dmi).
+  TA-Lib seeds ADX's directional movement with period-1 values, so its first ~100 ADX values differ.
+- MACD: EMA(12) - EMA(26), each EMA seeded with the SMA of its first `period` closes, signal = EMA(9)
+  of the MACD line (TradingView ta.macd). TA-Lib aligns the fast EMA's seed with the slow one instead.
+- Stochastic RSI: TA-Lib STOCH(rsi, rsi, rsi, 14, 3, SMA, 3, SMA) - %K is the 3-period SMA of the raw
+  stochastic, %D the 3-period SMA of %K (TradingView "Stoch RSI").
+- Bollinger Bands: TA-Lib BBANDS(20, 2, 2, SMA) with the population standard deviation.
+- VWAP: typical-price VWAP anchored at the first candle.
+- CVD: +volume for bullish candles, -volume for bearish ones, 0 for dojis, accumulated.
+- Volume profile: each candle's volume binned at its typical price; POC is the fullest bin and the
+  value area grows from the POC towards the fuller neighbour (ties go up) until it holds 70% of volume.
+
+Usage (from internal/indicator): python3 testdata/reference.py
+Undefined values (warm-up) are written as empty cells.
+"""
+
+import csv
+import math
+import os
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+
+def load_klines(path):
+    with open(path) as f:
+        rows = list(csv.DictReader(f))
+    return [{k: (int(v) if k == "open_time" else float(v)) for k, v in row.items()} for row in rows]
+
+
+def sma(values, period):
+    """SMA over a series that may start with None; None until `period` defined values."""
+    out = [None] * len(values)
+    for i in range(len(values)):
+        window = values[i - period + 1 : i + 1] if i >= period - 1 else []
+        if len(window) == period and all(v is not None for v in window):
+            out[i] = sum(window) / period
+    return out
+
+
+def ema(values, period):
+    """EMA seeded with the SMA of the first `period` defined values."""
+    out = [None] * len(values)
+    start = next((i for i, v in enumerate(values) if v is not None), None)
+    if start is None or len(values) - start < period:
+        return out
+    seed_at = start + period - 1
+    out[seed_at] = sum(values[start : seed_at + 1]) / period
+    alpha = 2.0 / (period + 1)
+    for i in range(seed_at + 1, len(values)):
+        out[i] = alpha * values[i] + (1 - alpha) * out[i - 1]
+    return out
+
+
+def rma(values, period, start):
+    """Wilder's moving average of values[start:], seeded with their first `period`-value mean."""
+    out = [None] * len(values)
+    seed_at = start + period - 1
+    if seed_at >= len(values):
+        return out
+    out[seed_at] = sum(values[start : seed_at + 1]) / period
+    for i in range(seed_at + 1, len(values)):
+        out[i] = (out[i - 1] * (period - 1) + values[i]) / period
+    return out
+
+
+def rsi(closes, period):
+    gains = [0.0] + [max(closes[i] - closes[i - 1], 0.0) for i in range(1, len(closes))]
+    losses = [0.0] + [max(closes[i - 1] - closes[i], 0.0) for i in range(1, len(closes))]
+    avg_gain = rma(gains, period, 1)
+    avg_loss = rma(losses, period, 1)
+    out = [None] * len(closes)
+    for i in range(len(closes)):
+        if avg_gain[i] is None:
+            continue
+        if avg_loss[i] == 0:
+            out[i] = 100.0
+        else:
+            out[i] = 100.0 - 100.0 / (1.0 + avg_gain[i] / avg_loss[i])
+    return out
+
+
+def adx(highs, lows, closes, period):
+    n = len(closes)
+    plus_dm, minus_dm, tr = [0.0] * n, [0.0] * n, [0.0] * n
+    for i in range(1, n):
+        up = highs[i] - highs[i - 1]
+        down = lows[i - 1] - lows[i]
+        plus_dm[i] = up if up > down and up > 0 else 0.0
+        minus_dm[i] = down if down > up and down > 0 else 0.0
+        tr[i] = max(highs[i] - lows[i], abs(highs[i] - closes[i - 1]), abs(lows[i] - closes[i - 1]))
+    s_plus, s_minus, s_tr = rma(plus_dm, period, 1), rma(minus_dm, period, 1), rma(tr, period, 1)
+    dx = [0.0] * n
+    first_dx = period
+    for i in range(first_dx, n):
+        plus_di = 100 * s_plus[i] / s_tr[i] if s_tr[i] else 0.0
+        minus_di = 100 * s_minus[i] / s_tr[i] if s_tr[i] else 0.0
+        total = plus_di + minus_di
+        dx[i] = 100 * abs(plus_di - minus_di) / total if total else 0.0
+    return rma(dx, period, first_dx)
+
+
+def macd(closes, fast, slow, signal):
+    fast_ema, slow_ema = ema(closes, fast), ema(closes, slow)
+    line = [f - s if f is not None and s is not None else None for f, s in zip(fast_ema, slow_ema)]
+    signal_line = ema(line, signal)
+    hist = [m - s if s is not None else None for m, s in zip(line, signal_line)]
+    return line, signal_line, hist
+
+
+def stoch_rsi(rsi_values, period, smooth_k, smooth_d):
+    raw = [None] * len(rsi_values)
+    for i in range(len(rsi_values)):
+        window = rsi_values[i - period + 1 : i + 1] if i >= period - 1 else []
+        if len(window) < period or any(v is None for v in window):
+            continue
+        lo, hi = min(window), max(window)
+        raw[i] = 100.0 * (rsi_values[i] - lo) / (hi - lo)
+    k = sma(raw, smooth_k)
+    return k, sma(k, smooth_d)
+
+
+def bollinger(closes, period, mult):
+    upper, middle, lower = [None] * len(closes), [None] * len(closes), [None] * len(closes)
+    for i in range(period - 1, len(closes)):
+        window = closes[i - period + 1 : i + 1]
+        mean = sum(window) / period
+        std = math.sqrt(sum((c - mean) ** 2 for c in window) / period)
+        upper[i], middle[i], lower[i] = mean + mult * std, mean, mean - mult * std
+    return upper, middle, lower
+
+
+def vwap(klines):
+    out, pv, vol = [], 0.0, 0.0
+    for k in klines:
+        pv += (k["high"] + k["low"] + k["close"]) / 3.0 * k["volume"]
+        vol += k["volume"]
+        out.append(pv / vol if vol else None)
+    return out
+
+
+def cvd(klines):
+    out, total = [], 0.0
+    for k in klines:
+        if k["close"] > k["open"]:
+            total += k["volume"]
+        elif k["close"] < k["open"]:
+            total -= k["volume"]
+        out.append(total)
+    return out
+
+
+def volume_profile(klines, bins):
+    lo = min(k["low"] for k in klines)
+    hi = max(k["high"] for k in klines)
+    size = (hi - lo) / bins
+    volumes = [0.0] * bins
+    for k in klines:
+        idx = int(((k["high"] + k["low"] + k["close"]) / 3.0 - lo) / size)
+        volumes[min(max(idx, 0), bins - 1)] += k["volume"]
+
+    def mid(i):
+        return lo + i * size + size / 2
+
+    poc = max(range(bins), key=lambda i: (volumes[i], -i))  # first fullest bin
+    target = sum(k["volume"] for k in klines) * 0.70
+    acc, up, down = volumes[poc], poc, poc
+    while acc < target and (up < bins - 1 or down > 0):
+        next_up = volumes[up + 1] if up < bins - 1 else None
+        next_down = volumes[down - 1] if down > 0 else None
+        if next_down is None or (next_up is not None and next_up >= next_down):
+            acc += next_up
+            up += 1
+        else:
+            acc += next_down
+            down -= 1
+    levels = [(mid(i), volumes[i]) for i in range(bins)]
+    return levels, mid(poc), mid(up), mid(down)
+
+
+def fmt(v):
+    return "" if v is None else repr(v)
+
+
+def write(name, header, rows):
+    with open(os.path.join(HERE, "golden", name), "w", newline="") as f:
+        w = csv.writer(f, lineterminator="\n")
+        w.writerow(header)
+        for row in rows:
+            w.writerow([row[0]] + [fmt(v) for v in row[1:]])
+
+
+def main():
+    klines = load_klines(os.path.join(HERE, "klines_1h.csv"))
+    times = [k["open_time"] for k in klines]
+    highs = [k["high"] for k in klines]
+    lows = [k["low"] for k in klines]
+    closes = [k["close"] for k in klines]
+
+    rsi14 = rsi(closes, 14)
+    write("rsi_14.csv", ["open_time", "rsi"], zip(times, rsi14))
+    write("adx_14.csv", ["open_time", "adx"], zip(times, adx(highs, lows, closes, 14)))
+    write("macd_12_26_9.csv", ["open_time", "macd", "signal", "histogram"], zip(times, *macd(closes, 12, 26, 9)))
+    write("stoch_rsi_14_3_3.csv", ["open_time", "k", "d"], zip(times, *stoch_rsi(rsi14, 14, 3, 3)))
+    write("bollinger_20_2.csv", ["open_time", "upper", "middle", "lower"], zip(times, *bollinger(closes, 20, 2.0)))
+    write("vwap.csv", ["open_time", "vwap"], zip(times, vwap(klines)))
+    write("cvd.csv", ["open_time", "cvd"], zip(times, cvd(klines)))
+
+    levels, poc, va_high, va_low = volume_profile(klines, 24)
+    write("volume_profile_24.csv", ["level", "price", "volume"], [(i, p, v) for i, (p, v) in enumerate(levels)])
+    write("volume_profile_24_summary.csv", ["bins", "poc", "va_high", "va_low"], [(24, poc, va_high, va_low)])
+
+
+if __name__ == "__main__":
+    main()
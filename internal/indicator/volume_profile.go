@@ -57,7 +57,10 @@ func CalculateVolumeProfile(klines []model.Kline, numBins int) VolumeProfile {
 	// Let's stick to Close price for now for performance, or better, (High+Low+Close)/3
 	for _, k := range klines {
 		avgPrice := (k.High + k.Low + k.Close) / 3.0
-		binIndex := int((avgPrice - minPrice) / binSize)
+		binIndex := 0
+		if binSize > 0 { // Flat range: everything lands in the first bin
+			binIndex = int((avgPrice - minPrice) / binSize)
+		}
 		if binIndex >= numBins {
 			binIndex = numBins - 1
 		}
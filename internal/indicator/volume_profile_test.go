@@ -0,0 +1,93 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateVolumeProfileGolden(t *testing.T) {
+	levels := loadGolden(t, "volume_profile_24.csv")
+	summary := loadGolden(t, "volume_profile_24_summary.csv")
+
+	vp := CalculateVolumeProfile(loadFixture(t), 24)
+	if len(vp.Levels) != 24 {
+		t.Fatalf("levels = %d, want 24", len(vp.Levels))
+	}
+	prices := make([]float64, len(vp.Levels))
+	volumes := make([]float64, len(vp.Levels))
+	for i, level := range vp.Levels {
+		prices[i], volumes[i] = level.Price, level.Volume
+	}
+	assertGolden(t, "price", prices, levels["price"])
+	assertGolden(t, "volume", volumes, levels["volume"])
+	assertGolden(t, "poc", []float64{vp.POC}, summary["poc"])
+	assertGolden(t, "va_high", []float64{vp.VAHigh}, summary["va_high"])
+	assertGolden(t, "va_low", []float64{vp.VALow}, summary["va_low"])
+}
+
+func TestCalculateVolumeProfileEdgeCases(t *testing.T) {
+	t.Run("short series", func(t *testing.T) {
+		if vp := CalculateVolumeProfile(nil, 24); len(vp.Levels) != 0 || vp.POC != 0 {
+			t.Errorf("profile = %+v, want zero value", vp)
+		}
+	})
+
+	t.Run("default bins", func(t *testing.T) {
+		if vp := CalculateVolumeProfile(loadFixture(t), 0); len(vp.Levels) != 50 {
+			t.Errorf("levels = %d, want 50", len(vp.Levels))
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		klines := candles(flat(5, 100), flat(5, 100), flat(5, 10))
+		for i := range klines {
+			klines[i].High, klines[i].Low = 100, 100
+		}
+		vp := CalculateVolumeProfile(klines, 10)
+		if vp.POC != 100 || vp.VAHigh != 100 || vp.VALow != 100 {
+			t.Errorf("POC/VA = %v/%v/%v, want 100", vp.POC, vp.VAHigh, vp.VALow)
+		}
+		if vp.Levels[0].Volume != 50 {
+			t.Errorf("first level volume = %v, want all 50", vp.Levels[0].Volume)
+		}
+	})
+
+	t.Run("zero volume", func(t *testing.T) {
+		// Nothing traded: the value area collapses onto the first level
+		vp := CalculateVolumeProfile(candles(ramp(10, 100, 1), ramp(10, 101, 1), flat(10, 0)), 10)
+		if vp.POC != vp.Levels[0].Price || vp.VAHigh != vp.POC || vp.VALow != vp.POC {
+			t.Errorf("POC/VA = %v/%v/%v, want the first level %v", vp.POC, vp.VAHigh, vp.VALow, vp.Levels[0].Price)
+		}
+	})
+
+	t.Run("value area", func(t *testing.T) {
+		vp := CalculateVolumeProfile(loadFixture(t), 24)
+		if !(vp.VALow <= vp.POC && vp.POC <= vp.VAHigh) {
+			t.Fatalf("POC %v outside value area %v-%v", vp.POC, vp.VALow, vp.VAHigh)
+		}
+		var inArea, total float64
+		for _, level := range vp.Levels {
+			total += level.Volume
+			if level.Price >= vp.VALow && level.Price <= vp.VAHigh {
+				inArea += level.Volume
+			}
+		}
+		if inArea < 0.7*total {
+			t.Errorf("value area holds %.1f%% of volume, want at least 70%%", inArea/total*100)
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		klines := candles(ramp(10, 100, 1), ramp(10, 101, 1), withNaN(flat(10, 5), 4))
+		vp := CalculateVolumeProfile(klines, 10)
+		if math.IsNaN(vp.POC) || vp.POC < 99 || vp.POC > 111 {
+			t.Errorf("POC = %v, want a price inside the range", vp.POC)
+		}
+	})
+
+	t.Run("POC distance", func(t *testing.T) {
+		if got := GetPOCDistance(110, 100); math.Abs(got-9.090909090909092) > 1e-12 {
+			t.Errorf("GetPOCDistance = %v, want 9.09", got)
+		}
+	})
+}
@@ -1,5 +1,35 @@
 package indicator
 
+import "mrcrypto-go/internal/model"
+
+// VWAP is an incremental cumulative Volume Weighted Average Price
+type VWAP struct {
+	cumulativeTPV    float64 // Cumulative Typical Price * Volume
+	cumulativeVolume float64
+	value            float64
+}
+
+// NewVWAP creates a VWAP anchored at the first candle it sees
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+// Update advances the VWAP by one candle and returns it (0 until volume trades)
+func (v *VWAP) Update(kline model.Kline) float64 {
+	typicalPrice := (kline.High + kline.Low + kline.Close) / 3.0
+	v.cumulativeTPV += typicalPrice * kline.Volume
+	v.cumulativeVolume += kline.Volume
+
+	v.value = 0
+	if v.cumulativeVolume != 0 {
+		v.value = v.cumulativeTPV / v.cumulativeVolume
+	}
+	return v.value
+}
+
+// Value returns the current VWAP (0 until volume trades)
+func (v *VWAP) Value() float64 { return v.value }
+
 // CalculateVWAP calculates the Volume Weighted Average Price
 func CalculateVWAP(high, low, close, volume []float64) []float64 {
 	if len(high) != len(low) || len(low) != len(close) || len(close) != len(volume) {
@@ -7,21 +37,10 @@ func CalculateVWAP(high, low, close, volume []float64) []float64 {
 	}
 
 	vwap := make([]float64, len(close))
-	cumulativeTPV := 0.0 // Cumulative Typical Price * Volume
-	cumulativeVolume := 0.0
-
-	for i := 0; i < len(close); i++ {
-		typicalPrice := (high[i] + low[i] + close[i]) / 3.0
-		tpv := typicalPrice * volume[i]
-
-		cumulativeTPV += tpv
-		cumulativeVolume += volume[i]
-
-		if cumulativeVolume != 0 {
-			vwap[i] = cumulativeTPV / cumulativeVolume
-		}
+	state := NewVWAP()
+	for i := range close {
+		vwap[i] = state.Update(model.Kline{High: high[i], Low: low[i], Close: close[i], Volume: volume[i]})
 	}
-
 	return vwap
 }
 
@@ -0,0 +1,63 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateVWAPGolden(t *testing.T) {
+	highs, lows, closes, volumes := ohlcv(loadFixture(t))
+	want := loadGolden(t, "vwap.csv")
+
+	assertGolden(t, "vwap", CalculateVWAP(highs, lows, closes, volumes), want["vwap"])
+}
+
+func TestCalculateVWAPEdgeCases(t *testing.T) {
+	highs, lows, closes := ramp(5, 102, 1), ramp(5, 98, 1), ramp(5, 100, 1) // Typical price 100, 101, ...
+
+	t.Run("short series", func(t *testing.T) {
+		if got := CalculateVWAP(nil, nil, nil, nil); len(got) != 0 {
+			t.Errorf("len = %d, want 0", len(got))
+		}
+		if got := GetLastVWAP(nil, nil, nil, nil); got != 0 {
+			t.Errorf("GetLastVWAP = %v, want 0", got)
+		}
+	})
+
+	t.Run("mismatched lengths", func(t *testing.T) {
+		if got := CalculateVWAP(highs, lows, closes, flat(4, 1)); len(got) != 0 {
+			t.Errorf("len = %d, want 0", len(got))
+		}
+	})
+
+	t.Run("flat prices", func(t *testing.T) {
+		for i, v := range CalculateVWAP(flat(5, 100), flat(5, 100), flat(5, 100), ramp(5, 1, 1)) {
+			if v != 100 {
+				t.Errorf("vwap[%d] = %v, want 100", i, v)
+			}
+		}
+	})
+
+	t.Run("zero volume", func(t *testing.T) {
+		// 0 until volume trades; zero-volume candles leave VWAP unchanged
+		got := CalculateVWAP(highs, lows, closes, []float64{0, 0, 10, 0, 10})
+		want := []float64{0, 0, 102, 102, 103}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("vwap[%d] = %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("NaN input", func(t *testing.T) {
+		got := CalculateVWAP(highs, lows, closes, withNaN(flat(5, 1), 2))
+		if math.IsNaN(got[1]) {
+			t.Error("vwap[1] = NaN, want a value before the bad volume")
+		}
+		for i := 2; i < len(got); i++ {
+			if !math.IsNaN(got[i]) {
+				t.Errorf("vwap[%d] = %v, want NaN once the running totals hold NaN", i, got[i])
+			}
+		}
+	})
+}
@@ -1,10 +1,14 @@
 package loader
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"sync/atomic"
 	"time"
 
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 	"mrcrypto-go/internal/monitor"
 	"mrcrypto-go/internal/service"
@@ -13,31 +17,47 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+var loaderLog = logger.Component("loader")
+
 type Loader struct {
-	binance       *service.BinanceService
-	strategy      *service.StrategyService
-	ai            *service.AIService
-	telegram      *service.TelegramService
-	database      *service.DatabaseService
-	signalMonitor *monitor.SignalMonitor
-	symbolManager *service.SymbolManager
-	isPolling     bool
+	market           service.MarketDataProvider
+	strategies       []*service.StrategyService // One per strategy profile, scanned side by side
+	profiles         map[string]config.StrategyProfile
+	ai               service.SignalValidator
+	telegram         *service.TelegramService
+	database         *service.DatabaseService
+	signalMonitor    *monitor.SignalMonitor
+	symbolManager    *service.SymbolManager
+	riskMonitor      *monitor.RiskMonitor     // Optional portfolio risk gate
+	riskManager      *service.RiskManager     // Dynamic position sizing for the risk gate
+	stream           *service.BinanceStream   // Optional live data stream
+	validations      service.AIValidationLog  // Optional per-signal validation records
+	candidates       service.CandidateJournal // Optional near-miss journal
+	candidateMonitor *monitor.CandidateMonitor
+	isPolling        bool
+	lastPoll         atomic.Int64 // Unix ms of the last poll that scanned and monitored
 }
 
 // NewLoader creates a new loader instance
 // NewLoader creates a new loader instance
 func NewLoader(
-	binance *service.BinanceService,
-	strategy *service.StrategyService,
-	ai *service.AIService,
+	market service.MarketDataProvider,
+	strategies []*service.StrategyService,
+	ai service.SignalValidator,
 	telegram *service.TelegramService,
 	database *service.DatabaseService,
 	signalMonitor *monitor.SignalMonitor,
 	symbolManager *service.SymbolManager,
 ) *Loader {
+	profiles := make(map[string]config.StrategyProfile, len(strategies))
+	for _, strategy := range strategies {
+		profiles[strategy.Profile().Name] = strategy.Profile()
+	}
+
 	return &Loader{
-		binance:       binance,
-		strategy:      strategy,
+		market:        market,
+		strategies:    strategies,
+		profiles:      profiles,
 		ai:            ai,
 		telegram:      telegram,
 		database:      database,
@@ -47,9 +67,41 @@ func NewLoader(
 	}
 }
 
-// Start begins the scheduled polling
-func (l *Loader) Start() {
-	log.Println("🚀 Starting Trading Signal Loader...")
+// SetStream attaches a live market data stream; its symbols follow the watchlist
+func (l *Loader) SetStream(stream *service.BinanceStream) {
+	l.stream = stream
+}
+
+// SetRiskGate enables portfolio limits and dynamic position sizing for new signals
+func (l *Loader) SetRiskGate(riskMonitor *monitor.RiskMonitor, riskManager *service.RiskManager) {
+	l.riskMonitor = riskMonitor
+	l.riskManager = riskManager
+}
+
+// SetValidationLog records every AI verdict and what the loader did with the signal
+func (l *Loader) SetValidationLog(validations service.AIValidationLog) {
+	l.validations = validations
+}
+
+// SetCandidateJournal records signals rejected after the scan (cooldown, AI, duplicate, risk gate)
+// and tracks every journaled candidate forward on each poll
+func (l *Loader) SetCandidateJournal(candidates service.CandidateJournal, candidateMonitor *monitor.CandidateMonitor) {
+	l.candidates = candidates
+	l.candidateMonitor = candidateMonitor
+}
+
+// LastSuccessfulPoll returns when the last poll completed its scan and monitoring (zero = none yet)
+func (l *Loader) LastSuccessfulPoll() time.Time {
+	if ms := l.lastPoll.Load(); ms > 0 {
+		return time.UnixMilli(ms)
+	}
+	return time.Time{}
+}
+
+// Start runs the scheduled polling until ctx is cancelled, then stops the scheduler and
+// waits for a running poll to finish its in-flight saves and Telegram sends
+func (l *Loader) Start(ctx context.Context) {
+	loaderLog.Info("🚀 Starting Trading Signal Loader...")
 
 	c := cron.New()
 
@@ -57,181 +109,339 @@ func (l *Loader) Start() {
 	// Monitoring is now "piggybacked" on this poll cycle
 	c.AddFunc("@every 1m", func() {
 		if l.isPolling {
-			log.Println("⏭️  Skipping cycle - previous poll still running")
+			loaderLog.Warn("⏭️  Skipping cycle - previous poll still running")
+			metrics.PollSkipped.Inc()
 			return
 		}
 
-		l.poll()
+		l.poll(ctx)
 	})
 
 	// Daily Cleanup Task: Run at 5:45 AM (15 mins before Asia session starts)
 	// Clears old "active" signals from the Dead Zone / prev day so new day starts fresh
 	c.AddFunc("45 5 * * *", func() {
-		log.Println("🧹 Executing Daily Cleanup Task...")
+		loaderLog.Info("🧹 Executing Daily Cleanup Task...")
 		count, err := l.database.CloseAllActiveSignals("DAILY_CLEANUP_AUTO")
 		if err != nil {
-			log.Printf("❌ Failed to perform daily cleanup: %v", err)
+			loaderLog.Error("❌ Failed to perform daily cleanup", "error", err)
 			return
 		}
 		if count > 0 {
-			log.Printf("🧹 Closed %d old signals.", count)
+			loaderLog.Info("🧹 Closed old signals", "count", count)
 			// Optional: Notify admin/channel
 			l.telegram.SendMessage(fmt.Sprintf("🧹 <b>ডেইলি ক্লিনআপ:</b> %d টি পুরানো সিগন্যাল ক্লোজ করা হয়েছে। নতুন দিনের জন্য প্রস্তুত! 🌅", count))
 		} else {
-			log.Println("🧹 No active signals to clean up.")
+			loaderLog.Info("🧹 No active signals to clean up")
 		}
 	})
 
 	c.Start()
 
-	log.Println("⏰ Scheduler started - scanning & monitoring every 1 minute")
+	loaderLog.Info("⏰ Scheduler started - scanning & monitoring every 1 minute")
 
-	// Keep the program running
-	select {}
+	<-ctx.Done()
+	loaderLog.Info("🛑 Stopping scheduler, waiting for the running poll...")
+	<-c.Stop().Done()
+	loaderLog.Info("✅ Scheduler stopped")
 }
 
-// poll executes one complete polling cycle
-func (l *Loader) poll() {
+// poll executes one complete polling cycle. A cancelled ctx ends it at the next stage boundary
+// or signal; a signal already being saved and sent is completed. Everything logged during the
+// cycle carries its cycle ID.
+func (l *Loader) poll(ctx context.Context) {
 	// Critical: Add panic recovery to prevent bot crash
 	defer service.RecoverAndLog("Loader.poll")
 
+	if ctx.Err() != nil {
+		return
+	}
+
+	ctx = logger.WithCycle(ctx, logger.NewCycleID())
 	l.isPolling = true
+	start := time.Now()
 	defer func() {
 		l.isPolling = false
+		metrics.PollDuration.Observe(time.Since(start).Seconds())
 	}()
 
-	log.Println("===========================================")
-	log.Printf("🔄 Polling started at %s", time.Now().Format("15:04:05"))
-	log.Println("===========================================")
+	loaderLog.InfoContext(ctx, "🔄 Polling started")
 
 	// Fetch watchlist
 	symbols, err := l.symbolManager.GetWatchlist()
 	if err != nil {
-		log.Printf("❌ Failed to fetch watchlist: %v", err)
+		loaderLog.ErrorContext(ctx, "❌ Failed to fetch watchlist", "error", err)
 		return
 	}
 
 	if len(symbols) == 0 {
-		log.Println("⚠️  Watchlist is empty. Add symbols using /symbol add")
+		loaderLog.WarnContext(ctx, "⚠️  Watchlist is empty. Add symbols using /symbol add")
+		l.markPolled()
 		return
 	}
 
-	log.Printf("📊 Scanning %d symbols", len(symbols))
+	loaderLog.InfoContext(ctx, "📊 Scanning symbols", "symbols", len(symbols), "profiles", len(l.strategies))
 
-	// Create worker pool with 10 workers
-	log.Printf("🔄 [Loader] Creating worker pool with 10 workers...")
-	pool := worker.NewPool(10, l.strategy)
-	pool.Start()
+	if l.stream != nil {
+		l.stream.SetSymbols(l.streamedSymbols(symbols))
+	}
 
-	// Add all symbols as jobs
-	log.Printf("⏳ [Loader] Distributing %d jobs to workers...", len(symbols))
-	for _, symbol := range symbols {
-		pool.AddJob(symbol)
+	// Scan with every strategy profile; the kline cache lets later profiles reuse the same candles
+	var signals []*model.Signal
+	for _, strategy := range l.strategies {
+		signals = append(signals, l.scan(ctx, strategy, symbols)...)
 	}
 
-	// Wait for all workers to complete and collect signals AND prices
-	signals, prices := pool.Wait()
+	// PIGGYBACK MONITORING: Resolve active signals from the 1m candles closed since the last check
+	if l.signalMonitor != nil {
+		loaderLog.DebugContext(ctx, "👀 Triggering piggyback monitoring...")
+		l.signalMonitor.CheckActiveSignals(ctx)
+	}
+	if l.candidateMonitor != nil {
+		l.candidateMonitor.CheckCandidates(ctx)
+	}
 
-	// PIGGYBACK MONITORING: Check active signals using the fresh prices we just fetched
-	if l.signalMonitor != nil && len(prices) > 0 {
-		log.Println("👀 [Loader] Triggering Piggyback Monitoring...")
-		l.signalMonitor.CheckActiveSignalsAgainstPrices(prices)
+	if ctx.Err() != nil {
+		loaderLog.InfoContext(ctx, "🛑 Shutdown - poll interrupted before AI validation")
+		return
 	}
+	l.markPolled()
 
-	log.Printf("📈 Generated %d potential signals", len(signals))
+	loaderLog.InfoContext(ctx, "📈 Scan complete", "signals", len(signals))
 
 	if len(signals) == 0 {
-		log.Println("===========================================")
-		log.Println("✨ Polling complete - 0 signals generated")
-		log.Println("===========================================")
+		loaderLog.InfoContext(ctx, "✨ Polling complete - 0 signals generated", "duration", time.Since(start).Round(time.Millisecond))
 		return
 	}
 
-	// Filter signals by cooldown first
-	log.Printf("⏳ [Loader] Filtering %d signals by cooldown (4h)...", len(signals))
+	// Filter signals by cooldown first (per profile)
 	var validForAI []*model.Signal
 	for _, signal := range signals {
-		if l.database.CheckCooldown(signal.Symbol, 4*time.Hour) {
-			log.Printf("⏱️  %s - Skipped (cooldown)", signal.Symbol)
+		signalCtx := signalContext(ctx, signal)
+		profile := l.profileOf(signal)
+		if l.database.CheckCooldown(signalCtx, profile.Name, signal.Symbol, profile.Cooldown.Duration) {
+			loaderLog.InfoContext(signalCtx, "⏱️  Skipped (cooldown)", "profile", profile.Name)
+			l.nearMiss(signalCtx, signal, model.StageCooldown, fmt.Sprintf("Cooldown (%s)", profile.Cooldown.Duration))
 			continue
 		}
 		validForAI = append(validForAI, signal)
 	}
 
 	if len(validForAI) == 0 {
-		log.Println("===========================================")
-		log.Printf("✨ Polling complete - All %d signals in cooldown", len(signals))
-		log.Println("===========================================")
+		loaderLog.InfoContext(ctx, "✨ Polling complete - all signals in cooldown", "signals", len(signals), "duration", time.Since(start).Round(time.Millisecond))
 		return
 	}
 
-	log.Printf("✅ [Loader] %d signals passed cooldown filter", len(validForAI))
+	loaderLog.InfoContext(ctx, "✅ Signals passed cooldown filter", "signals", len(validForAI))
 
 	// BATCH AI VALIDATION (Optimized - Single API Call)
-	log.Printf("🤖 Batch validating %d signals with AI...", len(validForAI))
-	aiResults, err := l.ai.BatchValidateSignals(validForAI)
+	aiResults, err := l.ai.BatchValidateSignals(ctx, validForAI)
 	if err != nil {
-		log.Printf("❌ Batch AI validation failed: %v", err)
+		if ctx.Err() != nil {
+			loaderLog.InfoContext(ctx, "🛑 Shutdown - AI validation interrupted")
+			return
+		}
+		loaderLog.ErrorContext(ctx, "❌ Batch AI validation failed, skipping batch", "validator", l.ai.Name(), "error", err)
+		for _, signal := range validForAI {
+			l.nearMiss(signalContext(ctx, signal), signal, model.StageAINoAnswer, "AI validation failed")
+		}
 		return
 	}
 
-	// Validate AI results length matches signals
+	// Results are matched to signals by ID inside the validator; a count mismatch means
+	// the pairing can no longer be trusted, so the whole batch is dropped
 	if len(aiResults) != len(validForAI) {
-		log.Printf("⚠️  AI validation mismatch: got %d results for %d signals", len(aiResults), len(validForAI))
-		// Adjust to minimum to prevent out of bounds
-		minLen := len(aiResults)
-		if len(validForAI) < minLen {
-			minLen = len(validForAI)
+		loaderLog.ErrorContext(ctx, "❌ AI validation mismatch, skipping batch", "results", len(aiResults), "signals", len(validForAI))
+		for _, signal := range validForAI {
+			l.nearMiss(signalContext(ctx, signal), signal, model.StageAINoAnswer, "AI result count mismatch")
+		}
+		return
+	}
+
+	// One validation record per signal; outcomes are filled in below and saved when the poll ends
+	callAt := time.Now()
+	records := make([]service.AIValidationRecord, len(validForAI))
+	for idx, signal := range validForAI {
+		records[idx] = service.NewAIValidationRecord(signal, aiResults[idx], callAt)
+	}
+	defer l.saveValidations(ctx, records)
+	decide := func(idx int, outcome string) {
+		records[idx].Outcome = outcome
+		records[idx].CreatedAt = time.Now()
+	}
+
+	// Open signals for the risk gate; accepted signals are appended as the batch is processed
+	var openSignals []*model.Signal
+	todayPnL := 0.0
+	if l.riskMonitor != nil {
+		openSignals, err = l.database.GetOpenSignals()
+		if err != nil {
+			loaderLog.ErrorContext(ctx, "❌ Failed to load open signals for risk gate", "error", err)
+			return
+		}
+		// Fail closed: without today's result the daily loss limit can't be checked
+		_, _, todayPnL, err = l.riskManager.GetTodayStats()
+		if err != nil {
+			loaderLog.ErrorContext(ctx, "❌ Failed to load today's PnL for risk gate", "error", err)
+			return
 		}
-		validForAI = validForAI[:minLen]
 	}
 
 	// Process validated signals
-	log.Printf("⏳ [Loader] Processing %d AI validation results...", len(aiResults))
 	validSignals := 0
 	for idx, signal := range validForAI {
-		// Double-check bounds (defensive programming)
-		if idx >= len(aiResults) {
-			log.Printf("⚠️  %s - Skipped (AI result missing at index %d)", signal.Symbol, idx)
+		if ctx.Err() != nil {
+			loaderLog.InfoContext(ctx, "🛑 Shutdown - validated signals left unprocessed", "signals", len(validForAI)-idx)
+			break // Their validation records stay PENDING
+		}
+		signalCtx := signalContext(ctx, signal)
+		result := aiResults[idx]
+		if result.Missing {
+			loaderLog.WarnContext(signalCtx, "⚠️  Skipped (no valid AI answer)")
+			decide(idx, service.ValidationNoAnswer)
+			l.nearMiss(signalCtx, signal, model.StageAINoAnswer, "No valid AI answer")
 			continue
 		}
 
-		result := aiResults[idx]
 		signal.AIScore = result.Score
 		signal.AIConfidence = result.Confidence
 		signal.AITier = result.Tier
 		signal.AIReason = result.Reason
-
-		// Strict Score Filtering: Both must be >= 80
-		if result.Score < 80 || signal.ConfluenceScore < 80 {
-			log.Printf("❌ %s - Scores too low (AI: %d, System: %d). Both must be >= 80.",
-				signal.Symbol, result.Score, signal.ConfluenceScore)
+		signal.AIValidator = result.Validator
+		signal.AIModel = result.Model
+		signal.AIUnvalidated = result.Unvalidated
+
+		// Strict Score Filtering: Both must reach the profile minimum (80 by default).
+		// Unvalidated signals (AI_UNAVAILABLE_POLICY=pass) are judged on the system score alone.
+		profile := l.profileOf(signal)
+		aiTooLow := !result.Unvalidated && result.Score < profile.MinScore
+		if aiTooLow || signal.ConfluenceScore < profile.MinScore {
+			loaderLog.InfoContext(signalCtx, "❌ Scores too low",
+				"ai_score", result.Score, "system_score", signal.ConfluenceScore, "min_score", profile.MinScore)
+			decide(idx, service.ValidationLowScore)
+			l.nearMiss(signalCtx, signal, model.StageAIRejected, fmt.Sprintf("AI score %d, system score %d (min %d)", result.Score, signal.ConfluenceScore, profile.MinScore))
 			continue
 		}
 
-		log.Printf("✅ %s - Valid signal! AI Score: %d/100", signal.Symbol, result.Score)
+		if result.Unvalidated {
+			loaderLog.WarnContext(signalCtx, "⚠️  Valid signal (unvalidated)", "system_score", signal.ConfluenceScore)
+		} else {
+			loaderLog.InfoContext(signalCtx, "✅ Valid signal", "ai_score", result.Score, "validator", result.Validator)
+		}
 
 		// Check for duplicate active signal BEFORE saving (Pass EntryPrice for Scaling Check)
-		if l.database.CheckDuplicateActiveSignal(signal.Symbol, signal.Type, signal.EntryPrice) {
+		if l.database.CheckDuplicateActiveSignal(signalCtx, profile.Name, signal.Symbol, signal.Type, signal.EntryPrice, profile.ScalingInPercent) {
+			decide(idx, service.ValidationDuplicate)
+			l.nearMiss(signalCtx, signal, model.StageDuplicate, "Same-direction signal already open")
 			continue
 		}
 
+		// Portfolio risk gate: size the position, then check it fits the open book
+		if l.riskMonitor != nil {
+			sizeInfo := l.riskManager.CalculateDynamicPositionSize(signal.Tier)
+			signal.RecommendedSize = sizeInfo.RecommendedSize
+
+			if ok, reason := l.riskMonitor.CheckRiskLimits(openSignals, signal, todayPnL); !ok {
+				loaderLog.InfoContext(signalCtx, "🛡️  Rejected by risk gate", "reason", reason)
+				decide(idx, service.ValidationRiskGate)
+				l.nearMiss(signalCtx, signal, model.StageRiskGate, reason)
+				continue
+			}
+			loaderLog.InfoContext(signalCtx, "🛡️  Risk gate passed", "size_pct", signal.RecommendedSize, "reason", sizeInfo.Reason)
+		}
+
 		// Save to database
-		if err := l.database.SaveSignal(signal); err != nil {
-			log.Printf("⚠️  %s - Failed to save signal: %v", signal.Symbol, err)
+		if err := l.database.SaveSignal(signalCtx, signal); err != nil {
+			loaderLog.ErrorContext(signalCtx, "⚠️  Failed to save signal", "error", err)
+			decide(idx, service.ValidationSaveFailed)
 			continue
 		}
+		decide(idx, service.ValidationSent)
+
+		openSignals = append(openSignals, signal)
 
 		// Send to Telegram
-		if err := l.telegram.SendSignal(signal); err != nil {
-			log.Printf("⚠️  %s - Failed to send Telegram notification: %v", signal.Symbol, err)
+		if err := l.telegram.SendSignal(signalCtx, signal); err != nil {
+			loaderLog.ErrorContext(signalCtx, "⚠️  Failed to send Telegram notification", "error", err)
 			continue
 		}
 
+		metrics.SignalsSent.Inc(signal.Profile, string(signal.Tier))
 		validSignals++
 	}
 
-	log.Println("===========================================")
-	log.Printf("✨ Polling complete - %d valid signals sent", validSignals)
-	log.Println("===========================================")
+	loaderLog.InfoContext(ctx, "✨ Polling complete", "sent", validSignals, "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// signalContext tags the poll context with a signal's symbol and ID
+func signalContext(ctx context.Context, signal *model.Signal) context.Context {
+	return logger.WithSignal(logger.WithSymbol(ctx, signal.Symbol), signal.ID)
+}
+
+// markPolled records a poll that completed its scan and monitoring
+func (l *Loader) markPolled() {
+	now := time.Now()
+	l.lastPoll.Store(now.UnixMilli())
+	metrics.LastSuccessfulPoll.Set(float64(now.Unix()))
+}
+
+// saveValidations writes the validation records of one poll
+func (l *Loader) saveValidations(ctx context.Context, records []service.AIValidationRecord) {
+	if l.validations == nil {
+		return
+	}
+	if err := l.validations.SaveValidations(records); err != nil {
+		loaderLog.ErrorContext(ctx, "⚠️  Failed to save validation records", "error", err)
+	}
+}
+
+// nearMiss counts a signal rejected after the scan and journals it
+func (l *Loader) nearMiss(ctx context.Context, signal *model.Signal, stage, reason string) {
+	metrics.SignalsRejected.Inc(signal.Profile, stage)
+	if l.candidates == nil {
+		return
+	}
+	candidate := &model.Candidate{Signal: *signal, Stage: stage, Reason: reason}
+	if err := l.candidates.RecordCandidate(ctx, candidate); err != nil {
+		loaderLog.WarnContext(ctx, "⚠️  Failed to journal candidate", "error", err)
+	}
+}
+
+// streamedSymbols keeps the symbols served by Binance, the only exchange with a live stream
+func (l *Loader) streamedSymbols(symbols []string) []string {
+	router, ok := l.market.(service.ExchangeResolver)
+	if !ok {
+		return symbols
+	}
+
+	var streamed []string
+	for _, symbol := range symbols {
+		if router.ExchangeFor(symbol) == service.ExchangeBinance {
+			streamed = append(streamed, symbol)
+		}
+	}
+	return streamed
+}
+
+// scan evaluates every symbol with one strategy profile on a 10-worker pool
+func (l *Loader) scan(ctx context.Context, strategy *service.StrategyService, symbols []string) []*model.Signal {
+	loaderLog.DebugContext(ctx, "🔄 Creating worker pool", "workers", 10, "profile", strategy.Profile().Name)
+	pool := worker.NewPool(10, strategy)
+	pool.Start(ctx)
+
+	// Add all symbols as jobs
+	for _, symbol := range symbols {
+		pool.AddJob(symbol)
+	}
+
+	// Wait for all workers to complete and collect signals
+	signals, _ := pool.Wait()
+	return signals
+}
+
+// profileOf returns the strategy profile that produced a signal
+func (l *Loader) profileOf(signal *model.Signal) config.StrategyProfile {
+	if profile, ok := l.profiles[signal.Profile]; ok {
+		return profile
+	}
+	return config.DefaultStrategyProfile()
 }
@@ -0,0 +1,148 @@
+// Package logger configures structured (slog) logging and carries the poll cycle ID
+// and symbol of the work in progress through context.Context
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Formats
+const (
+	FormatText = "text" // key=value lines for the console
+	FormatJSON = "json" // One JSON object per line for log aggregators
+)
+
+// Setup installs the default slog logger writing to stdout. Standard log calls go through it too.
+func Setup(level, format string) error {
+	return SetupWriter(os.Stdout, level, format)
+}
+
+// SetupWriter installs the default slog logger writing to w
+func SetupWriter(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL %q (use debug, info, warn or error)", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q (use text or json)", format)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// Component returns a logger tagged with component=name. It writes through whatever default
+// logger is installed at call time, so package-level loggers may be created before Setup.
+func Component(name string) *slog.Logger {
+	return slog.New(&defaultHandler{attrs: []slog.Attr{slog.String("component", name)}})
+}
+
+// NewCycleID returns a short random ID for one poll cycle
+func NewCycleID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// Attributes carried in the context
+type ctxKey int
+
+const (
+	cycleKey ctxKey = iota
+	symbolKey
+	signalKey
+)
+
+// WithCycle tags everything logged with ctx by the poll cycle ID
+func WithCycle(ctx context.Context, cycleID string) context.Context {
+	return context.WithValue(ctx, cycleKey, cycleID)
+}
+
+// WithSymbol tags everything logged with ctx by the symbol being processed
+func WithSymbol(ctx context.Context, symbol string) context.Context {
+	return context.WithValue(ctx, symbolKey, symbol)
+}
+
+// WithSignal tags everything logged with ctx by a signal ID
+func WithSignal(ctx context.Context, signalID string) context.Context {
+	return context.WithValue(ctx, signalKey, signalID)
+}
+
+// contextHandler adds the cycle, symbol and signal ID from the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if v, ok := ctx.Value(cycleKey).(string); ok {
+			r.AddAttrs(slog.String("cycle", v))
+		}
+		if v, ok := ctx.Value(symbolKey).(string); ok {
+			r.AddAttrs(slog.String("symbol", v))
+		}
+		if v, ok := ctx.Value(signalKey).(string); ok {
+			r.AddAttrs(slog.String("signal_id", v))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// defaultHandler resolves slog.Default() on every record
+type defaultHandler struct {
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *defaultHandler) resolve() slog.Handler {
+	handler := slog.Default().Handler().WithAttrs(h.attrs)
+	for _, group := range h.groups {
+		handler = handler.WithGroup(group)
+	}
+	return handler
+}
+
+func (h *defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *defaultHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.resolve().Handle(ctx, r)
+}
+
+func (h *defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(h.groups) > 0 {
+		// Attributes after a group belong to it; bind to the current default from here on
+		return h.resolve().WithAttrs(attrs)
+	}
+	return &defaultHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *defaultHandler) WithGroup(name string) slog.Handler {
+	return &defaultHandler{attrs: h.attrs, groups: append(append([]string{}, h.groups...), name)}
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// capture installs a logger writing to a buffer for the duration of the test
+func capture(t *testing.T, level, format string) *bytes.Buffer {
+	t.Helper()
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var buf bytes.Buffer
+	if err := SetupWriter(&buf, level, format); err != nil {
+		t.Fatalf("SetupWriter: %v", err)
+	}
+	return &buf
+}
+
+// records decodes one JSON object per line
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+func TestContextAttributesInJSON(t *testing.T) {
+	log := Component("loader") // Created before Setup, like the package-level loggers
+	buf := capture(t, "debug", "json")
+
+	ctx := WithSignal(WithSymbol(WithCycle(context.Background(), "c0ffee00"), "ETHUSDT"), "ab12c")
+	log.InfoContext(ctx, "✅ Valid signal", "ai_score", 88)
+	log.With("profile", "scalp").WarnContext(WithCycle(context.Background(), "c0ffee01"), "grouped")
+	log.Debug("no context")
+
+	got := records(t, buf)
+	if len(got) != 3 {
+		t.Fatalf("records = %v, want 3", got)
+	}
+	want := map[string]any{
+		"level": "INFO", "msg": "✅ Valid signal", "component": "loader",
+		"cycle": "c0ffee00", "symbol": "ETHUSDT", "signal_id": "ab12c", "ai_score": float64(88),
+	}
+	for key, value := range want {
+		if got[0][key] != value {
+			t.Errorf("%s = %v, want %v", key, got[0][key], value)
+		}
+	}
+	if got[1]["cycle"] != "c0ffee01" || got[1]["profile"] != "scalp" || got[1]["component"] != "loader" || got[1]["symbol"] != nil {
+		t.Errorf("record with attrs = %v", got[1])
+	}
+	if _, ok := got[2]["cycle"]; ok || got[2]["level"] != "DEBUG" {
+		t.Errorf("record without context = %v", got[2])
+	}
+}
+
+func TestSetupLevelAndFormat(t *testing.T) {
+	buf := capture(t, "WARN", "text")
+	log := Component("stream")
+	log.InfoContext(WithSymbol(context.Background(), "BTCUSDT"), "filtered")
+	log.WarnContext(WithSymbol(context.Background(), "BTCUSDT"), "kept")
+
+	out := buf.String()
+	if strings.Contains(out, "filtered") || !strings.Contains(out, "level=WARN msg=kept component=stream symbol=BTCUSDT") {
+		t.Errorf("text output = %q", out)
+	}
+}
+
+func TestSetupRejectsInvalidSettings(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	tests := []struct {
+		level, format, want string
+	}{
+		{"verbose", "json", "invalid LOG_LEVEL"},
+		{"", "json", "invalid LOG_LEVEL"},
+		{"info", "xml", "invalid LOG_FORMAT"},
+	}
+	for _, tt := range tests {
+		err := SetupWriter(&bytes.Buffer{}, tt.level, tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SetupWriter(%q, %q) = %v, want %s", tt.level, tt.format, err, tt.want)
+		}
+		if slog.Default() != previous {
+			t.Errorf("SetupWriter(%q, %q) replaced the logger despite the error", tt.level, tt.format)
+		}
+	}
+}
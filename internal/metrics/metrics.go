@@ -0,0 +1,57 @@
+// Package metrics exports scanner and monitor metrics in the Prometheus text format (GET /metrics)
+package metrics
+
+// Poll cycle
+var (
+	PollDuration = NewHistogram("mrcrypto_poll_duration_seconds",
+		"Duration of a full poll cycle (scan, monitoring, AI validation, sending)",
+		[]float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300})
+	PollSkipped = NewCounter("mrcrypto_poll_skipped_total",
+		"Poll cycles skipped because the previous poll was still running")
+	LastSuccessfulPoll = NewGauge("mrcrypto_last_successful_poll_timestamp_seconds",
+		"Unix time of the last poll that completed its scan and monitoring")
+)
+
+// Strategy scan
+var (
+	EvaluationDuration = NewHistogram("mrcrypto_symbol_evaluation_seconds",
+		"Duration of one symbol evaluation, including market data requests",
+		[]float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20}, "profile", "symbol")
+	EvaluationErrors = NewCounter("mrcrypto_symbol_evaluation_errors_total",
+		"Symbol evaluations that failed", "profile", "symbol")
+)
+
+// Binance REST
+var (
+	BinanceRequests = NewCounter("mrcrypto_binance_requests_total",
+		`Binance REST requests by endpoint and HTTP status code ("error" = no response)`, "endpoint", "code")
+	BinanceUsedWeight = NewGauge("mrcrypto_binance_used_weight",
+		"Request weight used in the current minute as reported by Binance (X-MBX-USED-WEIGHT-1M)", "market")
+)
+
+// Bybit and OKX REST
+var (
+	ExchangeRequests = NewCounter("mrcrypto_exchange_requests_total",
+		`Bybit and OKX REST requests by exchange, endpoint and HTTP status code ("error" = no response)`, "exchange", "endpoint", "code")
+)
+
+// Gemini
+var (
+	GeminiDuration = NewHistogram("mrcrypto_gemini_request_seconds",
+		"Duration of Gemini calls per model and key (key = client number, as in the logs)",
+		[]float64{0.5, 1, 2, 5, 10, 20, 30, 60}, "model", "key")
+	GeminiFailures = NewCounter("mrcrypto_gemini_failures_total",
+		"Failed Gemini calls per model and key", "model", "key")
+)
+
+// Signals
+var (
+	SignalsGenerated = NewCounter("mrcrypto_signals_generated_total",
+		"Signals produced by the strategy scan", "profile")
+	SignalsRejected = NewCounter("mrcrypto_signals_rejected_total",
+		"Setups rejected per pipeline stage (scan, cooldown, AI, duplicate, risk gate)", "profile", "stage")
+	SignalsSent = NewCounter("mrcrypto_signals_sent_total",
+		"Signals saved and sent to Telegram", "profile", "tier")
+	OpenSignals = NewGauge("mrcrypto_open_signals",
+		"Open (ACTIVE or PARTIAL) signals by tier and direction", "tier", "direction")
+)
@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// kind is the Prometheus metric type of a family
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// series is one labelled time series of a family
+type series struct {
+	labelValues []string
+	value       float64  // Counter and gauge value
+	buckets     []uint64 // Histogram: observations per upper bound (not cumulative)
+	sum         float64
+	count       uint64
+}
+
+// family is a metric with its label names and all its series
+type family struct {
+	name       string
+	help       string
+	kind       kind
+	labelNames []string
+	bounds     []float64 // Histogram bucket upper bounds, ascending
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// registry holds every metric in registration order
+var registry struct {
+	mu       sync.Mutex
+	families []*family
+	onScrape []func()
+}
+
+func register(name, help string, k kind, bounds []float64, labelNames []string) *family {
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       k,
+		labelNames: labelNames,
+		bounds:     bounds,
+		series:     make(map[string]*series),
+	}
+	registry.mu.Lock()
+	registry.families = append(registry.families, f)
+	registry.mu.Unlock()
+	return f
+}
+
+// get returns the series for the label values, creating it on first use. Caller holds f.mu.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == kindHistogram {
+			s.buckets = make([]uint64, len(f.bounds))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is a monotonically increasing value per label combination
+type Counter struct{ f *family }
+
+// NewCounter registers a counter with the given label names
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{register(name, help, kindCounter, nil, labelNames)}
+}
+
+// Inc adds one to the series of the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v (>= 0) to the series of the label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.f.mu.Lock()
+	c.f.get(labelValues).value += v
+	c.f.mu.Unlock()
+}
+
+// Gauge is a value that can go up and down per label combination
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge with the given label names
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{register(name, help, kindGauge, nil, labelNames)}
+}
+
+// Set sets the series of the label values
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	g.f.get(labelValues).value = v
+	g.f.mu.Unlock()
+}
+
+// Reset drops every series, e.g. before the gauge is rebuilt from a fresh snapshot
+func (g *Gauge) Reset() {
+	g.f.mu.Lock()
+	g.f.series = make(map[string]*series)
+	g.f.mu.Unlock()
+}
+
+// Histogram counts observations into buckets per label combination
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram with ascending bucket upper bounds (+Inf is implicit)
+func NewHistogram(name, help string, bounds []float64, labelNames ...string) *Histogram {
+	return &Histogram{register(name, help, kindHistogram, bounds, labelNames)}
+}
+
+// Observe records one observation in the series of the label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mu.Lock()
+	s := h.f.get(labelValues)
+	if i := sort.SearchFloat64s(h.f.bounds, v); i < len(h.f.bounds) {
+		s.buckets[i]++
+	}
+	s.sum += v
+	s.count++
+	h.f.mu.Unlock()
+}
+
+// OnScrape registers a function run before every scrape, for gauges read from elsewhere (e.g. the database)
+func OnScrape(fn func()) {
+	registry.mu.Lock()
+	registry.onScrape = append(registry.onScrape, fn)
+	registry.mu.Unlock()
+}
+
+// Handler serves every registered metric in the Prometheus text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry.mu.Lock()
+		hooks := append([]func(){}, registry.onScrape...)
+		families := append([]*family{}, registry.families...)
+		registry.mu.Unlock()
+
+		for _, hook := range hooks {
+			hook()
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		out := bufio.NewWriter(w)
+		for _, f := range families {
+			f.write(out)
+		}
+		out.Flush()
+	})
+}
+
+// write renders one family, series sorted by label values
+func (f *family) write(out *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(out, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(out, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != kindHistogram {
+			fmt.Fprintf(out, "%s%s %s\n", f.name, f.labels(s.labelValues, "", ""), formatValue(s.value))
+			continue
+		}
+
+		cumulative := uint64(0)
+		for i, bound := range f.bounds {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(out, "%s_sum%s %s\n", f.name, f.labels(s.labelValues, "", ""), formatValue(s.sum))
+		fmt.Fprintf(out, "%s_count%s %d\n", f.name, f.labels(s.labelValues, "", ""), s.count)
+	}
+}
+
+// labels renders {name="value",...}, with an optional extra label (the histogram "le")
+func (f *family) labels(values []string, extraName, extraValue string) string {
+	if len(values) == 0 && extraName == "" {
+		return ""
+	}
+	pairs := make([]string, 0, len(values)+1)
+	for i, name := range f.labelNames {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escapeLabel(values[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, extraValue))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+
+func escapeHelp(v string) string { return helpEscaper.Replace(v) }
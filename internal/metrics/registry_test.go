@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// scrape returns the exposition lines of the named families
+func scrape(t *testing.T, names ...string) []string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		for _, name := range names {
+			if strings.HasPrefix(line, name) || strings.HasPrefix(line, "# HELP "+name+" ") || strings.HasPrefix(line, "# TYPE "+name+" ") {
+				lines = append(lines, line)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+func checkLines(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("exposition:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExpositionCounterAndGauge(t *testing.T) {
+	counter := NewCounter("test_events_total", "Events seen.\nSecond line with a \\ backslash", "source", "kind")
+	counter.Inc("b", "x")
+	counter.Add(2.5, "a", `say "hi"`)
+	counter.Add(-1, "a", `say "hi"`) // Counters never go down
+	counter.Inc("c\\d", "multi\nline")
+
+	gauge := NewGauge("test_open_signals", "Open signals.")
+	gauge.Set(3)
+	gauge.Set(-1.25)
+
+	checkLines(t, scrape(t, "test_events_total", "test_open_signals"), []string{
+		`# HELP test_events_total Events seen.\nSecond line with a \\ backslash`,
+		`# TYPE test_events_total counter`,
+		`test_events_total{source="a",kind="say \"hi\""} 2.5`,
+		`test_events_total{source="b",kind="x"} 1`,
+		`test_events_total{source="c\\d",kind="multi\nline"} 1`,
+		`# HELP test_open_signals Open signals.`,
+		`# TYPE test_open_signals gauge`,
+		`test_open_signals -1.25`,
+	})
+
+	gauge.Reset()
+	checkLines(t, scrape(t, "test_open_signals"), []string{
+		`# HELP test_open_signals Open signals.`,
+		`# TYPE test_open_signals gauge`,
+	})
+}
+
+func TestExpositionHistogram(t *testing.T) {
+	histogram := NewHistogram("test_duration_seconds", "Duration.", []float64{0.5, 1, 2.5}, "stage")
+	for _, v := range []float64{0.1, 0.5, 0.7, 2, 10} {
+		histogram.Observe(v, "scan")
+	}
+	histogram.Observe(3, "ai")
+
+	// Buckets are cumulative and inclusive (0.5 counts in le="0.5"); +Inf equals _count
+	checkLines(t, scrape(t, "test_duration_seconds"), []string{
+		`# HELP test_duration_seconds Duration.`,
+		`# TYPE test_duration_seconds histogram`,
+		`test_duration_seconds_bucket{stage="ai",le="0.5"} 0`,
+		`test_duration_seconds_bucket{stage="ai",le="1"} 0`,
+		`test_duration_seconds_bucket{stage="ai",le="2.5"} 0`,
+		`test_duration_seconds_bucket{stage="ai",le="+Inf"} 1`,
+		`test_duration_seconds_sum{stage="ai"} 3`,
+		`test_duration_seconds_count{stage="ai"} 1`,
+		`test_duration_seconds_bucket{stage="scan",le="0.5"} 2`,
+		`test_duration_seconds_bucket{stage="scan",le="1"} 3`,
+		`test_duration_seconds_bucket{stage="scan",le="2.5"} 4`,
+		`test_duration_seconds_bucket{stage="scan",le="+Inf"} 5`,
+		`test_duration_seconds_sum{stage="scan"} 13.3`,
+		`test_duration_seconds_count{stage="scan"} 5`,
+	})
+}
+
+func TestOnScrapeRunsBeforeRendering(t *testing.T) {
+	gauge := NewGauge("test_scraped_value", "Set on scrape.")
+	OnScrape(func() { gauge.Set(42) })
+
+	checkLines(t, scrape(t, "test_scraped_value"), []string{
+		`# HELP test_scraped_value Set on scrape.`,
+		`# TYPE test_scraped_value gauge`,
+		`test_scraped_value 42`,
+	})
+}
@@ -0,0 +1,34 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Rejection stages, in pipeline order
+const (
+	StageDeadZone        = "DEAD_ZONE"        // Dead zone session
+	StageChoppy          = "CHOPPY"           // Choppy regime (ADX too low)
+	StageNoDirection     = "NO_DIRECTION"     // Trending/ranging but no clear LONG/SHORT setup
+	StageLowScore        = "LOW_SCORE"        // Confluence score below the profile minimum
+	StageKeyLevel        = "KEY_LEVEL"        // Not near a pivot/fib level
+	StageRiskReward      = "RISK_REWARD"      // R:R below 2
+	StagePatternDisabled = "PATTERN_DISABLED" // Pattern fingerprint disabled by the tracker
+	StageCooldown        = "COOLDOWN"         // Symbol still in cooldown
+	StageAINoAnswer      = "AI_NO_ANSWER"     // Validator failed or gave no usable answer
+	StageAIRejected      = "AI_REJECTED"      // AI score below the profile minimum
+	StageDuplicate       = "DUPLICATE"        // Same-direction signal already open
+	StageRiskGate        = "RISK_GATE"        // Portfolio risk limits
+)
+
+// CloseReasonExpired closes a candidate that hit neither TP nor SL within the tracking window
+const CloseReasonExpired = "EXPIRED"
+
+// Candidate is a setup rejected before it became a signal (a near-miss).
+// It carries the trade plan it would have had and is tracked forward with the same TP/SL
+// rules as a real signal; Status, CloseReason and PnL record what it would have done.
+type Candidate struct {
+	ObjectID primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Signal   `bson:",inline"`
+
+	Stage  string `json:"stage" bson:"stage"`   // Rejection stage (Stage* constants)
+	Reason string `json:"reason" bson:"reason"` // Human-readable rejection reason
+	Lean   bool   `json:"lean" bson:"lean"`     // Direction is the 4H EMA50 lean; the strategy found none
+}
@@ -10,6 +10,19 @@ const (
 	SignalTypeShort SignalType = "SHORT"
 )
 
+// Signal lifecycle statuses
+const (
+	StatusActive  = "ACTIVE"  // Full position open
+	StatusPartial = "PARTIAL" // TP1 booked, remainder running with stop at breakeven
+	StatusClosed  = "CLOSED"
+)
+
+// OpenStatuses are the statuses of signals that still have a position running
+var OpenStatuses = []string{StatusActive, StatusPartial}
+
+// TP1CloseFraction is the share of the position booked at TP1
+const TP1CloseFraction = 0.5
+
 // SignalTier represents the quality level of the signal
 type SignalTier string
 
@@ -105,8 +118,30 @@ type TechnicalContext struct {
 	CVDDivergence    string  `json:"cvd_divergence" bson:"cvd_divergence"`       // Bullish/Bearish CVD divergence
 	OrderBookSignal  string  `json:"order_book_signal" bson:"order_book_signal"` // Buy/Sell pressure from order book
 	OrderBookImbalance float64 `json:"order_book_imbalance" bson:"order_book_imbalance"` // Bid-Ask imbalance %
+	OrderBookBands     string  `json:"order_book_bands,omitempty" bson:"order_book_bands,omitempty"` // Imbalance per % band from mid (depth analyzer)
+	OrderBookWalls     string  `json:"order_book_walls,omitempty" bson:"order_book_walls,omitempty"` // Nearest persistent bid/ask walls
 	PerpSpotPremium  float64 `json:"perp_spot_premium" bson:"perp_spot_premium"` // Perp vs Spot premium/discount %
 	PerpSpotSentiment string  `json:"perp_spot_sentiment" bson:"perp_spot_sentiment"` // Market sentiment from perp-spot
+
+	// Open Interest & Positioning (futures data; empty regime when unavailable)
+	OIRegime          string  `json:"oi_regime,omitempty" bson:"oi_regime,omitempty"`                     // NEW_LONGS, NEW_SHORTS, SHORT_COVERING, LONG_LIQUIDATION, NEUTRAL
+	OIChange          float64 `json:"oi_change" bson:"oi_change"`                                         // Open interest change % over the last 4h
+	OIPriceChange     float64 `json:"oi_price_change" bson:"oi_price_change"`                             // Price change % over the same window
+	LongShortRatio    float64 `json:"long_short_ratio" bson:"long_short_ratio"`                           // Global long/short account ratio
+	TakerBuySellRatio float64 `json:"taker_buy_sell_ratio" bson:"taker_buy_sell_ratio"`                   // Taker buy/sell volume ratio
+
+	// Cross-Exchange (only with several exchanges enabled)
+	CrossPriceSpread   float64 `json:"cross_price_spread" bson:"cross_price_spread"`     // Highest vs lowest perp mark price %
+	CrossFundingSpread float64 `json:"cross_funding_spread" bson:"cross_funding_spread"` // Highest minus lowest funding rate (pp)
+	CrossExchange      string  `json:"cross_exchange" bson:"cross_exchange"`             // Per-exchange mark price and funding
+}
+
+// ScoreFactor is one line of a signal's score breakdown.
+// The Points of all factors sum to the signal's ConfluenceScore.
+type ScoreFactor struct {
+	Name   string `json:"name" bson:"name"`
+	Value  string `json:"value" bson:"value"`   // Raw indicator reading the points were based on
+	Points int    `json:"points" bson:"points"` // Points added (negative for penalties)
 }
 
 // Signal represents a trading signal
@@ -127,6 +162,9 @@ type Signal struct {
 	AIConfidence     int              `json:"ai_confidence" bson:"ai_confidence"` // 0-100 Confidence
 	AITier           string           `json:"ai_tier" bson:"ai_tier"`             // Standard, Premium, or Reject
 	AIReason         string           `json:"ai_reason" bson:"ai_reason"`
+	AIValidator      string           `json:"ai_validator" bson:"ai_validator"`     // Validator backend (gemini, openai, rules)
+	AIModel          string           `json:"ai_model" bson:"ai_model"`             // Model that answered, if any
+	AIUnvalidated    bool             `json:"ai_unvalidated" bson:"ai_unvalidated"` // Sent without AI validation (policy: pass)
 
 	// Identity
 	ID string `json:"id" bson:"id"` // Short 5-char unique ID
@@ -141,22 +179,51 @@ type Signal struct {
 	TP2Percent       float64 `json:"tp2_percent" bson:"tp2_percent"`                 // % distance to TP2
 	NearestLevelDist float64 `json:"nearest_level_dist" bson:"nearest_level_dist"`   // % distance to nearest key level
 
+	// Explainability
+	ScoreBreakdown []ScoreFactor `json:"score_breakdown,omitempty" bson:"score_breakdown,omitempty"` // Every factor that made up ConfluenceScore
+
 	// Monitoring State
 	TPAlertSent       bool      `json:"tp_alert_sent" bson:"tp_alert_sent"`
 	SLAlertSent       bool      `json:"sl_alert_sent" bson:"sl_alert_sent"`
 	ReversalAlertSent bool      `json:"reversal_alert_sent" bson:"reversal_alert_sent"`
 	TrailingAlertSent bool      `json:"trailing_alert_sent" bson:"trailing_alert_sent"`
 	LastAlertTime     time.Time `json:"last_alert_time" bson:"last_alert_time"`
-
-	Status      string     `json:"status" bson:"status"`                       // ACTIVE, CLOSED
-	CloseReason string     `json:"close_reason,omitempty" bson:"close_reason"` // TP_HIT, SL_HIT, MANUAL, REVERSED
+	LastCheckedAt     time.Time `json:"last_checked_at" bson:"last_checked_at"` // Candles up to here are resolved
+
+	// Partial Take-Profit State
+	TP1Hit          bool       `json:"tp1_hit" bson:"tp1_hit"`
+	TP1HitAt        *time.Time `json:"tp1_hit_at,omitempty" bson:"tp1_hit_at"`
+	TP1Price        float64    `json:"tp1_price,omitempty" bson:"tp1_price"`                 // Price the TP1 half was booked at
+	TP1PnL          float64    `json:"tp1_pnl,omitempty" bson:"tp1_pnl"`                     // PnL % of the half booked at TP1
+	InitialStopLoss float64    `json:"initial_stop_loss,omitempty" bson:"initial_stop_loss"` // SL before it was moved to breakeven
+
+	Profile     string     `json:"profile" bson:"profile"`                     // Strategy profile that produced the signal
+	EvaluationMode  string           `json:"evaluation_mode" bson:"evaluation_mode"`                         // live or closed
+	CandleOpenTimes map[string]int64 `json:"candle_open_times,omitempty" bson:"candle_open_times,omitempty"` // Last candle OpenTime used per series (e.g. "5m", "BTCUSDT_4h")
+	Status      string     `json:"status" bson:"status"`                       // ACTIVE, PARTIAL, CLOSED
+	CloseReason string     `json:"close_reason,omitempty" bson:"close_reason"` // TP_HIT, SL_HIT, BREAKEVEN_STOP, MANUAL, REVERSED
 	ClosedAt    *time.Time `json:"closed_at,omitempty" bson:"closed_at"`
-	PnL         float64    `json:"pnl,omitempty" bson:"pnl"`               // Profit/Loss percentage
+	ExitPrice   float64    `json:"exit_price,omitempty" bson:"exit_price,omitempty"`   // Fill price of the closing leg
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"` // Close of the candle (or tick window) that hit the exit
+	PnL         float64    `json:"pnl,omitempty" bson:"pnl"`               // Profit/Loss percentage (blended across TP1 + remainder)
 	PnLAmount   float64    `json:"pnl_amount,omitempty" bson:"pnl_amount"` // PnL in USD
 	Timestamp   time.Time  `json:"timestamp" bson:"timestamp"`
 	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
 }
 
+// BlendedPnL combines the PnL % booked at TP1 with the PnL % of the leg closing now
+func (s *Signal) BlendedPnL(closingPnL float64) float64 {
+	if !s.TP1Hit {
+		return closingPnL
+	}
+	return s.TP1PnL*TP1CloseFraction + closingPnL*(1-TP1CloseFraction)
+}
+
+// IsOpen reports whether the signal still has a position running
+func (s *Signal) IsOpen() bool {
+	return s.Status == StatusActive || s.Status == StatusPartial
+}
+
 // Kline represents a candlestick data point
 type Kline struct {
 	OpenTime  int64
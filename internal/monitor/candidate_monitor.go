@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+// CandidateMaxAge is how long a candidate is tracked before it expires at the market price
+const CandidateMaxAge = 72 * time.Hour
+
+// CandidateMonitor tracks journaled near-miss candidates forward with the same
+// TP1/TP2/SL rules as real signals, so each rejection stage gets a would-have-been outcome.
+// Candidates send no alerts and never touch the signal tracker.
+type CandidateMonitor struct {
+	journal  service.CandidateStore
+	market   service.MarketDataProvider
+	resolver CandleResolver
+	maxAge   time.Duration
+}
+
+// NewCandidateMonitor creates a candidate tracker. Ties resolve pessimistically (no lower-timeframe requests).
+func NewCandidateMonitor(journal service.CandidateStore, market service.MarketDataProvider) *CandidateMonitor {
+	return &CandidateMonitor{
+		journal:  journal,
+		market:   market,
+		resolver: CandleResolver{TieBreak: TieBreakPessimistic},
+		maxAge:   CandidateMaxAge,
+	}
+}
+
+// CheckCandidates resolves open candidates from the 1m candles closed since their last check.
+// Candles are fetched once per symbol; a cancelled ctx stops before the next symbol.
+func (cm *CandidateMonitor) CheckCandidates(ctx context.Context) {
+	candidates, err := cm.journal.OpenCandidates()
+	if err != nil {
+		candidateLog.ErrorContext(ctx, "❌ Failed to load open candidates", "error", err)
+		return
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	bySymbol := make(map[string][]*model.Candidate)
+	for i := range candidates {
+		candidate := &candidates[i]
+		bySymbol[candidate.Symbol] = append(bySymbol[candidate.Symbol], candidate)
+	}
+
+	candidateLog.DebugContext(ctx, "📓 Tracking candidates", "candidates", len(candidates), "symbols", len(bySymbol))
+
+	now := time.Now()
+	for symbol, group := range bySymbol {
+		if ctx.Err() != nil {
+			return
+		}
+		symbolCtx := logger.WithSymbol(ctx, symbol)
+		from := now
+		for _, candidate := range group {
+			if start := checkpoint(&candidate.Signal); start.Before(from) {
+				from = start
+			}
+		}
+
+		klines, err := fetchMinuteCandles(cm.market, symbol, from, now)
+		if err != nil {
+			candidateLog.WarnContext(symbolCtx, "⚠️  Failed to fetch 1m candles", "error", err)
+			continue
+		}
+
+		for _, candidate := range group {
+			cm.track(symbolCtx, candidate, klines, now)
+		}
+	}
+}
+
+// track walks one candidate through the closed candles since its checkpoint and saves the result
+func (cm *CandidateMonitor) track(ctx context.Context, candidate *model.Candidate, klines []model.Kline, now time.Time) {
+	signal := &candidate.Signal
+	if signal.EntryPrice <= 0 {
+		return
+	}
+
+	from := checkpoint(signal)
+	checkedUntil := from
+	lastClose := 0.0
+	for _, k := range klines {
+		if k.OpenTime < from.UnixMilli() || k.CloseTime >= now.UnixMilli() {
+			continue
+		}
+		for _, fill := range cm.resolver.Resolve(signal, k) {
+			// TP1 fills are already applied to the signal by the resolver
+			if fill.Final() {
+				cm.close(ctx, candidate, fill.Reason, fill.Price, signal.BlendedPnL(fill.PnL), fill.Time)
+				return
+			}
+		}
+		checkedUntil = time.UnixMilli(k.CloseTime + 1)
+		lastClose = k.Close
+	}
+	signal.LastCheckedAt = checkedUntil
+
+	// Setups that go nowhere are closed at the last price once the window is over
+	if now.Sub(signal.Timestamp) > cm.maxAge && lastClose > 0 {
+		cm.close(ctx, candidate, model.CloseReasonExpired, lastClose, signal.BlendedPnL(LegPnL(signal, lastClose)), checkedUntil)
+		return
+	}
+
+	if err := cm.journal.UpdateCandidate(candidate); err != nil {
+		candidateLog.WarnContext(ctx, "⚠️  Failed to update candidate", "error", err)
+	}
+}
+
+// close records the would-have-been outcome of a candidate
+func (cm *CandidateMonitor) close(ctx context.Context, candidate *model.Candidate, reason string, exitPrice, pnl float64, at time.Time) {
+	closedAt := at
+	candidate.Status = model.StatusClosed
+	candidate.CloseReason = reason
+	candidate.ClosedAt = &closedAt
+	candidate.ResolvedAt = &closedAt
+	candidate.ExitPrice = exitPrice
+	candidate.PnL = pnl
+
+	if err := cm.journal.UpdateCandidate(candidate); err != nil {
+		candidateLog.WarnContext(ctx, "⚠️  Failed to update candidate", "error", err)
+		return
+	}
+	candidateLog.InfoContext(ctx, "📓 Candidate would have closed", "type", candidate.Type, "stage", candidate.Stage,
+		"reason", reason, "pnl_pct", pnl)
+}
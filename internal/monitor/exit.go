@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"time"
+
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+// Exit reasons produced by CandleResolver
+const (
+	ReasonTP1Hit        = "TP1_HIT"        // Half booked, signal stays open (PARTIAL)
+	ReasonTPHit         = "TP_HIT"         // Final target reached, position closed
+	ReasonSLHit         = "SL_HIT"         // Stop hit before TP1, position closed
+	ReasonBreakevenStop = "BREAKEVEN_STOP" // Remainder stopped at entry after TP1
+)
+
+// TieBreak decides the order of events when one candle touches both a stop and a target
+type TieBreak string
+
+const (
+	TieBreakPessimistic TieBreak = "pessimistic" // Assume the stop filled first
+	TieBreakLowerTF     TieBreak = "lower_tf"    // Replay the candle on a lower timeframe (pessimistic if unavailable)
+)
+
+// ExitFill is one lifecycle step resolved from a candle
+type ExitFill struct {
+	Reason string
+	Price  float64 // Fill price: the level, or the open when the candle gapped through it
+	PnL    float64 // PnL % of the leg filled
+	Time   time.Time
+}
+
+// Final reports whether the fill closes the whole position
+func (f ExitFill) Final() bool {
+	return f.Reason != ReasonTP1Hit
+}
+
+// CandleResolver resolves TP/SL outcomes from candle high/low ranges:
+//
+//	ACTIVE  → TP1_HIT (book 50%, move SL to breakeven) or SL_HIT
+//	PARTIAL → TP_HIT (TP2) or BREAKEVEN_STOP
+//
+// Signals without TP1 (legacy) go straight to TP_HIT at TakeProfit.
+// Shared by the live monitor and the backtester so both close trades identically.
+type CandleResolver struct {
+	TieBreak TieBreak
+	// LowerTF returns the lower-timeframe candles covering k, or nil when unavailable
+	LowerTF func(symbol string, k model.Kline) []model.Kline
+}
+
+// Resolve walks the position through one candle, applying TP1 to the signal as it happens.
+// Returns the fills in order; a Final fill ends the position.
+func (r CandleResolver) Resolve(signal *model.Signal, k model.Kline) []ExitFill {
+	if signal.EntryPrice <= 0 {
+		return nil
+	}
+
+	if r.TieBreak == TieBreakLowerTF && r.LowerTF != nil && isAmbiguous(signal, k) {
+		if sub := r.LowerTF(signal.Symbol, k); len(sub) > 0 {
+			var fills []ExitFill
+			for _, sk := range sub {
+				stepFills := resolveCandle(signal, sk)
+				fills = append(fills, stepFills...)
+				if len(stepFills) > 0 && stepFills[len(stepFills)-1].Final() {
+					break
+				}
+			}
+			return fills
+		}
+	}
+
+	return resolveCandle(signal, k)
+}
+
+// resolveCandle applies the pessimistic ordering: within a candle the stop is checked before the target
+func resolveCandle(signal *model.Signal, k model.Kline) []ExitFill {
+	var fills []ExitFill
+	at := time.UnixMilli(k.CloseTime)
+	firstStage := true
+
+	for {
+		target, targetReason := nextTarget(signal)
+		stopReason := ReasonSLHit
+		if signal.TP1Hit {
+			stopReason = ReasonBreakevenStop
+		}
+
+		switch {
+		case stopTouched(signal, k, signal.StopLoss):
+			price := signal.StopLoss
+			// Gap through the stop at the open fills at the (worse) open
+			if firstStage && stopTouched(signal, model.Kline{High: k.Open, Low: k.Open}, signal.StopLoss) {
+				price = k.Open
+			}
+			return append(fills, ExitFill{Reason: stopReason, Price: price, PnL: LegPnL(signal, price), Time: at})
+
+		case targetTouched(signal, k, target):
+			price := target
+			// Gap through the target at the open fills at the (better) open
+			if firstStage && targetTouched(signal, model.Kline{High: k.Open, Low: k.Open}, target) {
+				price = k.Open
+			}
+			fill := ExitFill{Reason: targetReason, Price: price, PnL: LegPnL(signal, price), Time: at}
+			fills = append(fills, fill)
+			if fill.Final() {
+				return fills
+			}
+			ApplyTP1(signal, fill.Price, fill.PnL, at)
+			firstStage = false
+
+		default:
+			return fills
+		}
+	}
+}
+
+// isAmbiguous reports whether a candle touches both sides of the position's next step
+func isAmbiguous(signal *model.Signal, k model.Kline) bool {
+	target, reason := nextTarget(signal)
+	if !targetTouched(signal, k, target) {
+		return false
+	}
+	if stopTouched(signal, k, signal.StopLoss) {
+		return true
+	}
+	// After TP1 the stop moves to entry, so touching entry in the same candle is ambiguous too
+	return reason == ReasonTP1Hit && stopTouched(signal, k, signal.EntryPrice)
+}
+
+// nextTarget returns the level the position is working towards and the reason hitting it produces
+func nextTarget(signal *model.Signal) (float64, string) {
+	if !signal.TP1Hit && signal.TakeProfit1 > 0 {
+		return signal.TakeProfit1, ReasonTP1Hit
+	}
+	if signal.TakeProfit2 > 0 {
+		return signal.TakeProfit2, ReasonTPHit
+	}
+	return signal.TakeProfit, ReasonTPHit
+}
+
+func targetTouched(signal *model.Signal, k model.Kline, level float64) bool {
+	if level <= 0 {
+		return false
+	}
+	if signal.Type == model.SignalTypeLong {
+		return k.High >= level
+	}
+	return k.Low <= level
+}
+
+func stopTouched(signal *model.Signal, k model.Kline, level float64) bool {
+	if level <= 0 {
+		return false
+	}
+	if signal.Type == model.SignalTypeLong {
+		return k.Low <= level
+	}
+	return k.High >= level
+}
+
+// ApplyTP1 books the TP1 half on the in-memory signal: records its PnL,
+// moves the stop to breakeven and marks the signal PARTIAL.
+func ApplyTP1(signal *model.Signal, price, pnl float64, at time.Time) {
+	hitAt := at
+	signal.TP1Hit = true
+	signal.TP1HitAt = &hitAt
+	signal.TP1Price = price
+	signal.TP1PnL = pnl
+	signal.InitialStopLoss = signal.StopLoss
+	signal.StopLoss = signal.EntryPrice
+	signal.Status = model.StatusPartial
+}
+
+// LegPnL is the clamped PnL % of a position leg closed at price
+func LegPnL(signal *model.Signal, price float64) float64 {
+	var pnl float64
+	if signal.Type == model.SignalTypeLong {
+		pnl = ((price - signal.EntryPrice) / signal.EntryPrice) * 100
+	} else {
+		pnl = ((signal.EntryPrice - price) / signal.EntryPrice) * 100
+	}
+	// Clamp PnL to reasonable bounds
+	return service.ClampFloat64(pnl, -100, 10000)
+}
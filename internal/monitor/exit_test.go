@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+var exitCandleOpen = time.Date(2025, 10, 9, 14, 0, 0, 0, time.UTC)
+
+// candle is a 1m kline opening `minute` minutes after exitCandleOpen
+func candle(minute int, open, high, low, close float64) model.Kline {
+	openTime := exitCandleOpen.Add(time.Duration(minute) * time.Minute)
+	return model.Kline{
+		OpenTime: openTime.UnixMilli(), Open: open, High: high, Low: low, Close: close,
+		CloseTime: openTime.Add(time.Minute).UnixMilli() - 1,
+	}
+}
+
+// Prices below are written for a LONG from 100 (SL 98, TP1 102, TP2 104);
+// mirrorPrice reflects them around the entry for the matching SHORT
+func mirrorPrice(direction model.SignalType, price float64) float64 {
+	if direction == model.SignalTypeShort {
+		return 200 - price
+	}
+	return price
+}
+
+func mirrorCandle(direction model.SignalType, k model.Kline) model.Kline {
+	if direction == model.SignalTypeShort {
+		k.Open, k.High, k.Low, k.Close = 200-k.Open, 200-k.Low, 200-k.High, 200-k.Close
+	}
+	return k
+}
+
+// exitSignal is a fresh ACTIVE signal; legacy signals only have TakeProfit
+func exitSignal(direction model.SignalType, legacy bool) *model.Signal {
+	signal := &model.Signal{
+		Symbol: "ETHUSDT", Type: direction, Status: model.StatusActive,
+		EntryPrice: 100, StopLoss: mirrorPrice(direction, 98), TakeProfit: mirrorPrice(direction, 104),
+	}
+	if !legacy {
+		signal.TakeProfit1 = mirrorPrice(direction, 102)
+		signal.TakeProfit2 = mirrorPrice(direction, 104)
+	}
+	return signal
+}
+
+type wantFill struct {
+	reason string
+	price  float64 // LONG price, mirrored for SHORT
+	pnl    float64
+}
+
+func checkFills(t *testing.T, direction model.SignalType, got []ExitFill, want []wantFill) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("fills = %+v, want %+v", got, want)
+	}
+	for i, w := range want {
+		if got[i].Reason != w.reason || math.Abs(got[i].Price-mirrorPrice(direction, w.price)) > 1e-9 || math.Abs(got[i].PnL-w.pnl) > 1e-9 {
+			t.Errorf("fill %d = %s @ %v (%v%%), want %s @ %v (%v%%)",
+				i, got[i].Reason, got[i].Price, got[i].PnL, w.reason, mirrorPrice(direction, w.price), w.pnl)
+		}
+	}
+}
+
+func TestResolvePessimistic(t *testing.T) {
+	tests := []struct {
+		name   string
+		legacy bool
+		candle model.Kline
+		want   []wantFill
+		status string
+	}{
+		{"no level touched", false, candle(0, 100, 101.5, 98.5, 101), nil, model.StatusActive},
+		{"stop and TP1 in one candle", false, candle(0, 100, 103, 97, 101),
+			[]wantFill{{ReasonSLHit, 98, -2}}, model.StatusActive},
+		{"gap through the stop", false, candle(0, 97, 97.5, 96, 97),
+			[]wantFill{{ReasonSLHit, 97, -3}}, model.StatusActive},
+		{"gap through TP1", false, candle(0, 103, 103.5, 102.5, 103),
+			[]wantFill{{ReasonTP1Hit, 103, 3}}, model.StatusPartial},
+		{"TP1 then breakeven stop", false, candle(0, 101, 102.5, 99.5, 100),
+			[]wantFill{{ReasonTP1Hit, 102, 2}, {ReasonBreakevenStop, 100, 0}}, model.StatusPartial},
+		{"TP1 then TP2", false, candle(0, 101, 105, 100.5, 104),
+			[]wantFill{{ReasonTP1Hit, 102, 2}, {ReasonTPHit, 104, 4}}, model.StatusPartial},
+		{"legacy straight to TakeProfit", true, candle(0, 101, 104.5, 100.5, 104),
+			[]wantFill{{ReasonTPHit, 104, 4}}, model.StatusActive},
+		{"legacy gap through TakeProfit", true, candle(0, 105, 105.5, 104.5, 105),
+			[]wantFill{{ReasonTPHit, 105, 5}}, model.StatusActive},
+	}
+	for _, direction := range []model.SignalType{model.SignalTypeLong, model.SignalTypeShort} {
+		for _, tt := range tests {
+			t.Run(string(direction)+"/"+tt.name, func(t *testing.T) {
+				signal := exitSignal(direction, tt.legacy)
+				k := mirrorCandle(direction, tt.candle)
+				fills := CandleResolver{TieBreak: TieBreakPessimistic}.Resolve(signal, k)
+				checkFills(t, direction, fills, tt.want)
+
+				for _, f := range fills {
+					if !f.Time.Equal(time.UnixMilli(k.CloseTime)) {
+						t.Errorf("%s at %v, want the candle close", f.Reason, f.Time)
+					}
+				}
+				if signal.Status != tt.status {
+					t.Errorf("status = %s, want %s", signal.Status, tt.status)
+				}
+				if signal.TP1Hit && (signal.StopLoss != 100 || signal.InitialStopLoss != mirrorPrice(direction, 98) || signal.TP1PnL != tt.want[0].pnl) {
+					t.Errorf("after TP1: SL %v, initial SL %v, TP1 PnL %v", signal.StopLoss, signal.InitialStopLoss, signal.TP1PnL)
+				}
+			})
+		}
+	}
+}
+
+func TestResolveAfterTP1(t *testing.T) {
+	for _, direction := range []model.SignalType{model.SignalTypeLong, model.SignalTypeShort} {
+		signal := exitSignal(direction, false)
+		ApplyTP1(signal, mirrorPrice(direction, 102), 2, exitCandleOpen)
+
+		// TP1 is behind: only TP2 and the breakeven stop count now
+		resolver := CandleResolver{TieBreak: TieBreakPessimistic}
+		checkFills(t, direction, resolver.Resolve(signal, mirrorCandle(direction, candle(1, 101, 103, 100.5, 102))), nil)
+		checkFills(t, direction, resolver.Resolve(signal, mirrorCandle(direction, candle(2, 101, 101.5, 99, 99.5))),
+			[]wantFill{{ReasonBreakevenStop, 100, 0}})
+	}
+}
+
+func TestResolveLowerTimeframe(t *testing.T) {
+	ambiguous := candle(0, 100, 104.5, 97, 101) // Touches the stop, TP1 and TP2
+	replay := []model.Kline{
+		candle(0, 100.5, 102.5, 100.2, 102), // TP1
+		candle(1, 102, 104.5, 101, 104),     // TP2 closes the position
+		candle(2, 104, 104, 97, 98),         // Never reached
+	}
+
+	for _, direction := range []model.SignalType{model.SignalTypeLong, model.SignalTypeShort} {
+		t.Run(string(direction), func(t *testing.T) {
+			var calls int
+			lowerTF := func(sub []model.Kline) func(string, model.Kline) []model.Kline {
+				return func(symbol string, k model.Kline) []model.Kline {
+					calls++
+					if symbol != "ETHUSDT" || k.OpenTime != ambiguous.OpenTime {
+						t.Errorf("LowerTF(%s, %d)", symbol, k.OpenTime)
+					}
+					var mirrored []model.Kline
+					for _, sk := range sub {
+						mirrored = append(mirrored, mirrorCandle(direction, sk))
+					}
+					return mirrored
+				}
+			}
+
+			// The replay decides the order: TP1 then TP2, at the closes of the sub-candles
+			resolver := CandleResolver{TieBreak: TieBreakLowerTF, LowerTF: lowerTF(replay)}
+			fills := resolver.Resolve(exitSignal(direction, false), mirrorCandle(direction, ambiguous))
+			checkFills(t, direction, fills, []wantFill{{ReasonTP1Hit, 102, 2}, {ReasonTPHit, 104, 4}})
+			if len(fills) == 2 && !fills[1].Time.Equal(time.UnixMilli(replay[1].CloseTime)) {
+				t.Errorf("TP2 at %v, want the second sub-candle close", fills[1].Time)
+			}
+
+			// No lower timeframe data: pessimistic
+			resolver.LowerTF = lowerTF(nil)
+			checkFills(t, direction, resolver.Resolve(exitSignal(direction, false), mirrorCandle(direction, ambiguous)),
+				[]wantFill{{ReasonSLHit, 98, -2}})
+
+			// Unambiguous candles are never replayed
+			calls = 0
+			checkFills(t, direction, resolver.Resolve(exitSignal(direction, false), mirrorCandle(direction, candle(3, 97, 97.5, 96, 97))),
+				[]wantFill{{ReasonSLHit, 97, -3}})
+			if calls != 0 {
+				t.Errorf("LowerTF called %d times for an unambiguous candle", calls)
+			}
+		})
+	}
+}
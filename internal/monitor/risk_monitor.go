@@ -2,66 +2,86 @@ package monitor
 
 import (
 	"fmt"
-	"log"
 	"mrcrypto-go/internal/model"
 )
 
+// defaultSignalRisk is assumed for open signals saved without a position size (% of account)
+const defaultSignalRisk = 1.0
+
+// RiskLimits are the portfolio limits a new signal has to fit in
+type RiskLimits struct {
+	MaxOpenSignals   int     // Open (ACTIVE + PARTIAL) signals
+	MaxAggregateRisk float64 // Sum of open RecommendedSize (% of account at risk)
+	MaxSameDirection int     // Open signals in the same direction (LONG/SHORT)
+	DailyLossLimit   float64 // Realised loss today (% of account, size-weighted) at which new signals stop
+}
+
 type RiskMonitor struct {
-	maxRiskPercentage float64
-	maxOpenTrades     int
-	accountBalance    float64
+	limits RiskLimits
 }
 
-func NewRiskMonitor(accountBalance, maxRiskPercentage float64, maxOpenTrades int) *RiskMonitor {
+func NewRiskMonitor(limits RiskLimits) *RiskMonitor {
 	return &RiskMonitor{
-		maxRiskPercentage: maxRiskPercentage,
-		maxOpenTrades:     maxOpenTrades,
-		accountBalance:    accountBalance,
+		limits: limits,
 	}
 }
 
-// CalculatePortfolioRisk calculates total risk exposure
+// CalculatePortfolioRisk calculates total risk exposure (% of account)
 func (rm *RiskMonitor) CalculatePortfolioRisk(activeSignals []*model.Signal) float64 {
 	totalRisk := 0.0
 
 	for _, signal := range activeSignals {
-		riskPerTrade := calculateSignalRisk(signal)
-		totalRisk += riskPerTrade
+		totalRisk += calculateSignalRisk(signal)
 	}
 
-	return (totalRisk / rm.accountBalance) * 100
+	return totalRisk
 }
 
 // CheckRiskLimits checks if adding a new signal exceeds risk limits
-func (rm *RiskMonitor) CheckRiskLimits(activeSignals []*model.Signal, newSignal *model.Signal) (bool, string) {
-	// Check max open trades
-	if len(activeSignals) >= rm.maxOpenTrades {
-		return false, "Max open trades limit reached"
+func (rm *RiskMonitor) CheckRiskLimits(activeSignals []*model.Signal, newSignal *model.Signal, todayPnL float64) (bool, string) {
+	// Check daily loss limit
+	if rm.limits.DailyLossLimit > 0 && todayPnL <= -rm.limits.DailyLossLimit {
+		return false, fmt.Sprintf("Daily loss limit reached (%.2f%%, max: -%.2f%%)", todayPnL, rm.limits.DailyLossLimit)
 	}
 
-	// Calculate current risk
-	currentRisk := rm.CalculatePortfolioRisk(activeSignals)
+	// Check max open trades
+	if rm.limits.MaxOpenSignals > 0 && len(activeSignals) >= rm.limits.MaxOpenSignals {
+		return false, fmt.Sprintf("Max open signals limit reached (%d/%d)", len(activeSignals), rm.limits.MaxOpenSignals)
+	}
 
-	// Calculate new signal risk
-	newRisk := calculateSignalRisk(newSignal)
-	newRiskPercentage := (newRisk / rm.accountBalance) * 100
+	// Check same-direction exposure (correlated alts tend to move together)
+	if rm.limits.MaxSameDirection > 0 {
+		sameDirection := 0
+		for _, signal := range activeSignals {
+			if signal.Type == newSignal.Type {
+				sameDirection++
+			}
+		}
+		if sameDirection >= rm.limits.MaxSameDirection {
+			return false, fmt.Sprintf("Max %s exposure reached (%d/%d)", newSignal.Type, sameDirection, rm.limits.MaxSameDirection)
+		}
+	}
 
 	// Check if total risk would exceed limit
-	totalRisk := currentRisk + newRiskPercentage
-	if totalRisk > rm.maxRiskPercentage {
-		log.Printf("⚠️  Risk limit exceeded: %.2f%% (max: %.2f%%)", totalRisk, rm.maxRiskPercentage)
-		return false, "Portfolio risk limit exceeded"
+	totalRisk := rm.CalculatePortfolioRisk(activeSignals) + calculateSignalRisk(newSignal)
+	if rm.limits.MaxAggregateRisk > 0 && totalRisk > rm.limits.MaxAggregateRisk {
+		monitorLog.Warn("⚠️  Risk limit exceeded", "risk_pct", totalRisk, "max_pct", rm.limits.MaxAggregateRisk)
+		return false, fmt.Sprintf("Portfolio risk limit exceeded (%.2f%%, max: %.2f%%)", totalRisk, rm.limits.MaxAggregateRisk)
 	}
 
 	return true, "Risk within limits"
 }
 
+// calculateSignalRisk returns the % of account a signal can still lose
 func calculateSignalRisk(signal *model.Signal) float64 {
-	// Risk is the distance between entry and stop loss
-	if signal.EntryPrice > signal.StopLoss {
-		return signal.EntryPrice - signal.StopLoss
+	// After TP1 the stop sits at breakeven - nothing left at risk
+	if signal.TP1Hit {
+		return 0
+	}
+	if signal.RecommendedSize <= 0 {
+		return defaultSignalRisk
 	}
-	return signal.StopLoss - signal.EntryPrice
+	return signal.RecommendedSize
 }
 
 // GetRiskSummary returns a risk summary
@@ -70,10 +90,10 @@ func (rm *RiskMonitor) GetRiskSummary(activeSignals []*model.Signal) string {
 
 	summary := "📊 Risk Monitor Summary\n"
 	summary += "------------------------\n"
-	summary += fmt.Sprintf("Active Trades: %d/%d\n", len(activeSignals), rm.maxOpenTrades)
+	summary += fmt.Sprintf("Active Trades: %d/%d\n", len(activeSignals), rm.limits.MaxOpenSignals)
 	summary += fmt.Sprintf("Total Risk: %.2f%%\n", totalRisk)
-	summary += fmt.Sprintf("Risk Limit: %.2f%%\n", rm.maxRiskPercentage)
-	summary += fmt.Sprintf("Available Risk: %.2f%%", rm.maxRiskPercentage-totalRisk)
+	summary += fmt.Sprintf("Risk Limit: %.2f%%\n", rm.limits.MaxAggregateRisk)
+	summary += fmt.Sprintf("Available Risk: %.2f%%", rm.limits.MaxAggregateRisk-totalRisk)
 
 	return summary
 }
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+// openBook returns open signals of the given directions, each risking size % of the account
+func openBook(size float64, directions ...model.SignalType) []*model.Signal {
+	signals := make([]*model.Signal, len(directions))
+	for i, direction := range directions {
+		signals[i] = &model.Signal{Symbol: "ETHUSDT", Type: direction, RecommendedSize: size}
+	}
+	return signals
+}
+
+func TestCheckRiskLimits(t *testing.T) {
+	long, short := model.SignalTypeLong, model.SignalTypeShort
+	newLong := &model.Signal{Symbol: "SOLUSDT", Type: long, RecommendedSize: 1}
+
+	tests := []struct {
+		name     string
+		limits   RiskLimits
+		open     []*model.Signal
+		todayPnL float64
+		ok       bool
+		reason   string // Prefix of the rejection
+	}{
+		// Daily loss limit (% of account, realised today)
+		{"loss below the limit", RiskLimits{DailyLossLimit: 3}, nil, -2.99, true, ""},
+		{"loss at the limit", RiskLimits{DailyLossLimit: 3}, nil, -3, false, "Daily loss limit reached"},
+		{"loss beyond the limit", RiskLimits{DailyLossLimit: 3}, nil, -7.5, false, "Daily loss limit reached"},
+		{"daily limit disabled", RiskLimits{}, nil, -50, true, ""},
+
+		// Open signal count
+		{"one slot left", RiskLimits{MaxOpenSignals: 3}, openBook(1, long, short), 0, true, ""},
+		{"open signals at the limit", RiskLimits{MaxOpenSignals: 3}, openBook(1, long, short, short), 0, false, "Max open signals limit reached"},
+		{"open count disabled", RiskLimits{}, openBook(0.5, short, short, short, short, short, short), 0, true, ""},
+
+		// Same-direction cap
+		{"same direction below the cap", RiskLimits{MaxSameDirection: 2}, openBook(1, long, short, short), 0, true, ""},
+		{"same direction at the cap", RiskLimits{MaxSameDirection: 2}, openBook(1, long, long, short), 0, false, "Max LONG exposure reached"},
+		{"same direction disabled", RiskLimits{}, openBook(0.5, long, long, long, long), 0, true, ""},
+
+		// Aggregate risk: open sizes plus the new signal's
+		{"risk reaching the limit exactly", RiskLimits{MaxAggregateRisk: 4}, openBook(1.5, short, short), 0, true, ""},
+		{"risk over the limit", RiskLimits{MaxAggregateRisk: 4}, openBook(1.6, short, short), 0, false, "Portfolio risk limit exceeded"},
+		{"unsized signals count as 1%", RiskLimits{MaxAggregateRisk: 3.5}, openBook(0, short, short, short), 0, false, "Portfolio risk limit exceeded"},
+		{"aggregate risk disabled", RiskLimits{}, openBook(3, short, short, short), 0, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := NewRiskMonitor(tt.limits).CheckRiskLimits(tt.open, newLong, tt.todayPnL)
+			if ok != tt.ok || (!ok && !strings.HasPrefix(reason, tt.reason)) {
+				t.Errorf("CheckRiskLimits = %v, %q, want %v %q", ok, reason, tt.ok, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCalculatePortfolioRisk(t *testing.T) {
+	open := openBook(2, model.SignalTypeLong, model.SignalTypeShort)
+	open = append(open,
+		&model.Signal{Type: model.SignalTypeLong},                                   // Unsized: default 1%
+		&model.Signal{Type: model.SignalTypeLong, RecommendedSize: 3, TP1Hit: true}, // Stop at breakeven
+	)
+	if risk := NewRiskMonitor(RiskLimits{}).CalculatePortfolioRisk(open); risk != 5 {
+		t.Errorf("portfolio risk = %v, want 5 (2 + 2 + 1 + 0 after TP1)", risk)
+	}
+}
@@ -3,10 +3,12 @@ package monitor
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
+	"sync"
 	"time"
 
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 	"mrcrypto-go/internal/service"
 
@@ -14,30 +16,126 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var (
+	monitorLog   = logger.Component("monitor")
+	candidateLog = logger.Component("candidates")
+)
+
 type SignalMonitor struct {
 	collection *mongo.Collection
-	binance    *service.BinanceService
+	market     service.MarketDataProvider
 	telegram   *service.TelegramService
 	tracker    *service.SignalTracker
+	resolver   CandleResolver
+	checkMu    sync.Mutex // Poll cycle and stream ticks must not close the same signal twice
 }
 
-func NewSignalMonitor(db *mongo.Database, binance *service.BinanceService, telegram *service.TelegramService, tracker *service.SignalTracker) *SignalMonitor {
-	return &SignalMonitor{
+func NewSignalMonitor(db *mongo.Database, market service.MarketDataProvider, telegram *service.TelegramService, tracker *service.SignalTracker) *SignalMonitor {
+	sm := &SignalMonitor{
 		collection: db.Collection("signals"),
-		binance:    binance,
+		market:     market,
 		telegram:   telegram,
 		tracker:    tracker,
 	}
+	sm.resolver = CandleResolver{TieBreak: TieBreakPessimistic, LowerTF: sm.lowerTimeframe}
+	return sm
 }
 
-// CheckActiveSignalsAgainstPrices checks active signals using externally provided prices
-func (sm *SignalMonitor) CheckActiveSignalsAgainstPrices(prices map[string]float64) {
+// maxMonitorCandles caps the 1m candles fetched per request (Binance limit)
+const maxMonitorCandles = 1000
+
+// SetTieBreak selects how candles touching both TP and SL are resolved (default pessimistic).
+// lower_tf replays the candle on 1s klines, which only spot has: on futures it falls back to pessimistic.
+func (sm *SignalMonitor) SetTieBreak(tieBreak TieBreak, market string) {
+	if tieBreak == TieBreakLowerTF && market != service.MarketSpot {
+		monitorLog.Info("ℹ️  1s candles exist on spot only, TP/SL ties resolve pessimistically", "market", market)
+		tieBreak = TieBreakPessimistic
+	}
+	sm.resolver.TieBreak = tieBreak
+}
+
+// fetchMinuteCandles returns the 1m candles from `from` until now. A gap longer than one request
+// (e.g. after downtime) is paged forward from `from`, so no TP/SL touch in between is skipped.
+func fetchMinuteCandles(market service.MarketDataProvider, symbol string, from, now time.Time) ([]model.Kline, error) {
+	limit := int(now.Sub(from)/time.Minute) + 2
+	if limit <= maxMonitorCandles {
+		return market.GetKlines(symbol, "1m", limit)
+	}
+
+	ranged, ok := market.(service.KlineRangeProvider)
+	if !ok {
+		monitorLog.Warn("⚠️  Gap since last check, only the latest candles are resolved", "symbol", symbol,
+			"gap", now.Sub(from).Round(time.Minute), "candles", maxMonitorCandles)
+		return market.GetKlines(symbol, "1m", maxMonitorCandles)
+	}
+
+	monitorLog.Info("⏩ Catching up on 1m candles", "symbol", symbol, "gap", now.Sub(from).Round(time.Minute))
+	var klines []model.Kline
+	start := from.UnixMilli()
+	for start <= now.UnixMilli() {
+		batch, err := ranged.GetKlinesRange(symbol, "1m", start, maxMonitorCandles)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 || batch[len(batch)-1].OpenTime < start {
+			break // Caught up (or the exchange has nothing newer)
+		}
+		klines = append(klines, batch...)
+		start = batch[len(batch)-1].OpenTime + time.Minute.Milliseconds()
+	}
+	return klines, nil
+}
+
+// CheckActiveSignals resolves open signals from the 1m candles closed since their last check,
+// so TP/SL wicks between polls are caught and exits fill at the level price.
+// Once ctx is cancelled no further signal is started; a signal being closed finishes its save and alert.
+func (sm *SignalMonitor) CheckActiveSignals(ctx context.Context) {
+	sm.checkMu.Lock()
+	defer sm.checkMu.Unlock()
+
+	signals := sm.fetchMonitoredSignals()
+	if len(signals) == 0 {
+		return // No active signals to monitor
+	}
+
+	monitorLog.DebugContext(ctx, "👀 Checking active signals against 1m candles", "signals", len(signals))
+
+	for i := range signals {
+		if ctx.Err() != nil {
+			monitorLog.InfoContext(ctx, "🛑 Shutdown - signals left for the next start", "signals", len(signals)-i)
+			return
+		}
+		sm.checkSignal(&signals[i])
+	}
+}
+
+// CheckActiveSignalsAgainstTicks checks active signals against streamed price ticks.
+// The tick high/low catch TP/SL wicks that happen between poll cycles.
+func (sm *SignalMonitor) CheckActiveSignalsAgainstTicks(ticks []service.PriceTick) {
+	sm.checkMu.Lock()
+	defer sm.checkMu.Unlock()
+
+	bySymbol := make(map[string]service.PriceTick, len(ticks))
+	for _, tick := range ticks {
+		bySymbol[tick.Symbol] = tick
+	}
+
+	signals := sm.fetchMonitoredSignals()
+	for _, signal := range signals {
+		if tick, ok := bySymbol[signal.Symbol]; ok {
+			sm.checkSignalWithTick(&signal, tick)
+		}
+	}
+}
+
+// fetchMonitoredSignals loads active signals whose final alert has not been sent
+func (sm *SignalMonitor) fetchMonitoredSignals() []model.Signal {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Find all active signals created TODAY that haven't had their final alert sent
+	// Find all open signals (ACTIVE or PARTIAL) that haven't had their final alert sent
 	filter := bson.M{
-		"status": "ACTIVE",
+		"status": bson.M{"$in": model.OpenStatuses},
 		// Only monitor signals that haven't hit TP or SL yet
 		// This prevents race condition where signal is re-monitored before DB update completes
 		"$and": []bson.M{
@@ -54,100 +152,147 @@ func (sm *SignalMonitor) CheckActiveSignalsAgainstPrices(prices map[string]float
 
 	cursor, err := sm.collection.Find(ctx, filter)
 	if err != nil {
-		log.Printf("❌ Failed to fetch active signals: %v", err)
-		return
+		monitorLog.Error("❌ Failed to fetch active signals", "error", err)
+		return nil
 	}
 	defer cursor.Close(ctx)
 
 	var signals []model.Signal
 	if err := cursor.All(ctx, &signals); err != nil {
-		log.Printf("❌ Failed to decode signals: %v", err)
-		return
-	}
-
-	if len(signals) == 0 {
-		return // No active signals to monitor
-	}
-
-	log.Printf("👀 [Monitor] Checking %d active signals against scanned prices...", len(signals))
-
-	// Check each signal if we have a fresh price for it
-	for _, signal := range signals {
-		if price, ok := prices[signal.Symbol]; ok {
-			sm.checkSignalWithPrice(&signal, price)
-		}
+		monitorLog.Error("❌ Failed to decode signals", "error", err)
+		return nil
 	}
 
+	return signals
 }
 
-// checkSignalWithPrice checks individual signal using provided price
-func (sm *SignalMonitor) checkSignalWithPrice(signal *model.Signal, currentPrice float64) {
-	// Check TP/SL based on signal type
-	if signal.Type == model.SignalTypeLong {
-		sm.checkLongSignal(signal, currentPrice)
-	} else {
-		sm.checkShortSignal(signal, currentPrice)
+// checkpoint returns the time from which a signal's candles still need resolving
+func checkpoint(signal *model.Signal) time.Time {
+	from := signal.Timestamp
+	if signal.LastCheckedAt.After(from) {
+		from = signal.LastCheckedAt
+	}
+	if signal.TP1HitAt != nil && signal.TP1HitAt.After(from) {
+		from = *signal.TP1HitAt
 	}
+	return from
 }
 
-// checkSignal checks individual signal for TP/SL/Reversal
+// checkSignal resolves TP/SL from the closed 1m candles since the last check, then runs guidance alerts
 func (sm *SignalMonitor) checkSignal(signal *model.Signal) {
-	log.Printf("⏳ [Monitor] Checking %s (ID: %s, Type: %s, Entry: %s)...",
-		signal.Symbol, signal.ID, signal.Type, formatPrice(signal.EntryPrice))
-	// Fetch current price from Binance
-	klines, err := sm.binance.GetKlines(signal.Symbol, "1m", 1)
-	if err != nil {
-		log.Printf("⚠️  Failed to fetch price for %s: %v", signal.Symbol, err)
+	// Validate entry price to prevent division by zero
+	if signal.EntryPrice <= 0 {
+		monitorLog.Warn("⚠️  Invalid entry price", "symbol", signal.Symbol, "signal_id", signal.ID, "entry", signal.EntryPrice)
 		return
 	}
 
+	from := checkpoint(signal)
+	now := time.Now()
+
+	// On failure the checkpoint stays put, so the same candles are retried next cycle
+	klines, err := fetchMinuteCandles(sm.market, signal.Symbol, from, now)
+	if err != nil {
+		monitorLog.Warn("⚠️  Failed to fetch 1m candles", "symbol", signal.Symbol, "signal_id", signal.ID, "error", err)
+		return
+	}
 	if len(klines) == 0 {
 		return
 	}
 
-	currentPrice := klines[0].Close
+	checkedUntil := from
+	for _, k := range klines {
+		// Only closed candles that opened after the checkpoint (never pre-entry prices)
+		if k.OpenTime < from.UnixMilli() || k.CloseTime >= now.UnixMilli() {
+			continue
+		}
+		if sm.applyFills(signal, sm.resolver.Resolve(signal, k)) {
+			return
+		}
+		checkedUntil = time.UnixMilli(k.CloseTime + 1)
+	}
 
-	// Check TP/SL based on signal type
-	if signal.Type == model.SignalTypeLong {
-		sm.checkLongSignal(signal, currentPrice)
-	} else {
-		sm.checkShortSignal(signal, currentPrice)
+	if checkedUntil.After(from) {
+		signal.LastCheckedAt = checkedUntil
+		sm.updateAlertStatus(signal, bson.M{"$set": bson.M{"last_checked_at": checkedUntil}})
 	}
+
+	// Guidance alerts use the latest (still forming) candle close
+	sm.checkSignalWithPrice(signal, klines[len(klines)-1].Close)
 }
 
-// checkLongSignal checks LONG signal conditions
-func (sm *SignalMonitor) checkLongSignal(signal *model.Signal, currentPrice float64) {
-	// Validate entry price to prevent division by zero
+// checkSignalWithTick resolves a streamed tick window like a candle, then runs guidance alerts
+func (sm *SignalMonitor) checkSignalWithTick(signal *model.Signal, tick service.PriceTick) {
 	if signal.EntryPrice <= 0 {
-		log.Printf("⚠️  Invalid entry price for %s: %.2f", signal.Symbol, signal.EntryPrice)
 		return
 	}
 
-	// Take Profit Hit
-	if currentPrice >= signal.TakeProfit {
-		if !signal.TPAlertSent {
-			pnl := ((currentPrice - signal.EntryPrice) / signal.EntryPrice) * 100
-			// Clamp PnL to reasonable bounds
-			pnl = service.ClampFloat64(pnl, -100, 10000)
-			sm.closeSignal(signal, "TP_HIT", currentPrice, pnl)
-			// Pass update flag to strictly track alert status (although clodeSignal handles status)
-			sm.sendTPAlert(signal, currentPrice, pnl)
-		}
+	window := model.Kline{
+		Open:      tick.Open,
+		High:      tick.High,
+		Low:       tick.Low,
+		Close:     tick.Price,
+		CloseTime: tick.Time.UnixMilli(),
+	}
+	// No lower timeframe exists for a tick window - always pessimistic
+	if sm.applyFills(signal, resolveCandle(signal, window)) {
 		return
 	}
 
-	// Stop Loss Hit
-	if currentPrice <= signal.StopLoss {
-		if !signal.SLAlertSent {
-			pnl := ((currentPrice - signal.EntryPrice) / signal.EntryPrice) * 100
-			// Clamp PnL to reasonable bounds
-			pnl = service.ClampFloat64(pnl, -100, 10000)
-			sm.closeSignal(signal, "SL_HIT", currentPrice, pnl)
-			sm.sendSLAlert(signal, currentPrice, pnl)
+	sm.checkSignalWithPrice(signal, tick.Price)
+}
+
+// applyFills persists and announces resolved fills. Returns true once the position is closed.
+func (sm *SignalMonitor) applyFills(signal *model.Signal, fills []ExitFill) bool {
+	for _, fill := range fills {
+		if !fill.Final() {
+			sm.bookTP1(signal, fill)
+			continue
+		}
+		sm.handleExit(signal, fill)
+		return true
+	}
+	return false
+}
+
+// lowerTimeframe returns the 1s candles inside a 1m candle for tie-break resolution.
+// Only available when the provider can page history (SetTieBreak keeps futures pessimistic).
+func (sm *SignalMonitor) lowerTimeframe(symbol string, k model.Kline) []model.Kline {
+	ranged, ok := sm.market.(service.KlineRangeProvider)
+	if !ok {
+		return nil
+	}
+
+	klines, err := ranged.GetKlinesRange(symbol, "1s", k.OpenTime, 60)
+	if err != nil {
+		monitorLog.Warn("⚠️  Lower timeframe unavailable, resolving pessimistically", "symbol", symbol, "error", err)
+		return nil
+	}
+
+	inside := make([]model.Kline, 0, len(klines))
+	for _, sk := range klines {
+		if sk.OpenTime >= k.OpenTime && sk.OpenTime <= k.CloseTime {
+			inside = append(inside, sk)
 		}
+	}
+	return inside
+}
+
+// checkSignalWithPrice runs guidance alerts (reversal, trailing) for the current price
+func (sm *SignalMonitor) checkSignalWithPrice(signal *model.Signal, currentPrice float64) {
+	// After TP1 the stop already sits at breakeven - no further guidance needed
+	if signal.TP1Hit {
 		return
 	}
 
+	if signal.Type == model.SignalTypeLong {
+		sm.checkLongSignal(signal, currentPrice)
+	} else {
+		sm.checkShortSignal(signal, currentPrice)
+	}
+}
+
+// checkLongSignal checks LONG signal guidance conditions
+func (sm *SignalMonitor) checkLongSignal(signal *model.Signal, currentPrice float64) {
 	// Quick Reversal Detection (price dropped 1% from entry within 5 min)
 	if !signal.ReversalAlertSent && signal.Timestamp.Add(5*time.Minute).After(time.Now()) {
 		drop := ((signal.EntryPrice - currentPrice) / signal.EntryPrice) * 100
@@ -165,38 +310,8 @@ func (sm *SignalMonitor) checkLongSignal(signal *model.Signal, currentPrice floa
 	}
 }
 
-// checkShortSignal checks SHORT signal conditions
+// checkShortSignal checks SHORT signal guidance conditions
 func (sm *SignalMonitor) checkShortSignal(signal *model.Signal, currentPrice float64) {
-	// Validate entry price to prevent division by zero
-	if signal.EntryPrice <= 0 {
-		log.Printf("⚠️  Invalid entry price for %s: %.2f", signal.Symbol, signal.EntryPrice)
-		return
-	}
-
-	// Take Profit Hit (price goes down)
-	if currentPrice <= signal.TakeProfit {
-		if !signal.TPAlertSent {
-			pnl := ((signal.EntryPrice - currentPrice) / signal.EntryPrice) * 100
-			// Clamp PnL to reasonable bounds
-			pnl = service.ClampFloat64(pnl, -100, 10000)
-			sm.closeSignal(signal, "TP_HIT", currentPrice, pnl)
-			sm.sendTPAlert(signal, currentPrice, pnl)
-		}
-		return
-	}
-
-	// Stop Loss Hit (price goes up)
-	if currentPrice >= signal.StopLoss {
-		if !signal.SLAlertSent {
-			pnl := ((signal.EntryPrice - currentPrice) / signal.EntryPrice) * 100
-			// Clamp PnL to reasonable bounds
-			pnl = service.ClampFloat64(pnl, -100, 10000)
-			sm.closeSignal(signal, "SL_HIT", currentPrice, pnl)
-			sm.sendSLAlert(signal, currentPrice, pnl)
-		}
-		return
-	}
-
 	// Quick Reversal Detection
 	if !signal.ReversalAlertSent && signal.Timestamp.Add(5*time.Minute).After(time.Now()) {
 		rise := ((currentPrice - signal.EntryPrice) / signal.EntryPrice) * 100
@@ -214,23 +329,70 @@ func (sm *SignalMonitor) checkShortSignal(signal *model.Signal, currentPrice flo
 	}
 }
 
-// closeSignal updates signal status in MongoDB
-func (sm *SignalMonitor) closeSignal(signal *model.Signal, reason string, exitPrice, pnl float64) {
+// bookTP1 persists the 50% partial close (already applied by the resolver) and alerts
+func (sm *SignalMonitor) bookTP1(signal *model.Signal, fill ExitFill) {
+	sm.updateAlertStatus(signal, bson.M{"$set": bson.M{
+		"status":            model.StatusPartial,
+		"tp1_hit":           true,
+		"tp1_hit_at":        signal.TP1HitAt,
+		"tp1_price":         signal.TP1Price,
+		"tp1_pnl":           signal.TP1PnL,
+		"initial_stop_loss": signal.InitialStopLoss,
+		"stop_loss":         signal.StopLoss,
+	}})
+
+	monitorLog.Info("🎯 TP1 hit - 50% booked, SL moved to breakeven", "symbol", signal.Symbol,
+		"signal_id", signal.ID, "price", fill.Price, "pnl_pct", fill.PnL)
+	sm.sendTP1Alert(signal, fill.Price, fill.PnL)
+}
+
+// handleExit closes the signal with its blended PnL and sends the matching alert (once)
+func (sm *SignalMonitor) handleExit(signal *model.Signal, fill ExitFill) {
+	pnl := signal.BlendedPnL(fill.PnL)
+
+	switch fill.Reason {
+	case ReasonTPHit:
+		if !signal.TPAlertSent {
+			sm.closeSignal(signal, fill, pnl)
+			sm.sendTPAlert(signal, fill.Price, pnl)
+		}
+	case ReasonSLHit:
+		if !signal.SLAlertSent {
+			sm.closeSignal(signal, fill, pnl)
+			sm.sendSLAlert(signal, fill.Price, pnl)
+		}
+	case ReasonBreakevenStop:
+		if !signal.SLAlertSent {
+			sm.closeSignal(signal, fill, pnl)
+			sm.sendBreakevenAlert(signal, fill.Price, pnl)
+		}
+	}
+}
+
+// closeSignal updates signal status in MongoDB, with the exit fill price and the time of the candle that hit it
+func (sm *SignalMonitor) closeSignal(signal *model.Signal, fill ExitFill, pnl float64) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	now := time.Now()
+	reason := fill.Reason
 	// Set AlertSent flags to true upon close to be safe
-	update := bson.M{
-		"$set": bson.M{
-			"status":        "CLOSED",
-			"close_reason":  reason,
-			"closed_at":     now,
-			"pnl":           pnl,
-			"tp_alert_sent": reason == "TP_HIT",
-			"sl_alert_sent": reason == "SL_HIT",
-		},
-	}
+	set := bson.M{
+		"status":        model.StatusClosed,
+		"close_reason":  reason,
+		"closed_at":     now,
+		"pnl":           pnl,
+		"exit_price":    fill.Price,
+		"tp_alert_sent": reason == ReasonTPHit,
+		"sl_alert_sent": reason == ReasonSLHit || reason == ReasonBreakevenStop,
+	}
+	signal.ExitPrice = fill.Price
+	if !fill.Time.IsZero() {
+		resolvedAt := fill.Time
+		signal.ResolvedAt = &resolvedAt
+		set["resolved_at"] = resolvedAt
+	}
+	update := bson.M{"$set": set}
 
 	var updateErr error
 
@@ -247,15 +409,15 @@ func (sm *SignalMonitor) closeSignal(signal *model.Signal, reason string, exitPr
 	if updateErr != nil || signal.ID == "" {
 		_, updateErr = sm.collection.UpdateOne(
 			ctx,
-			bson.M{"symbol": signal.Symbol, "status": "ACTIVE", "timestamp": signal.Timestamp},
+			bson.M{"symbol": signal.Symbol, "status": bson.M{"$in": model.OpenStatuses}, "timestamp": signal.Timestamp},
 			update,
 		)
 	}
 
 	if updateErr != nil {
-		log.Printf("⚠️ Failed to close signal %s: %v", signal.Symbol, updateErr)
+		monitorLog.Error("⚠️  Failed to close signal", "symbol", signal.Symbol, "signal_id", signal.ID, "error", updateErr)
 	} else {
-		log.Printf("🔒 Closed %s signal: %s (PnL: %.2f%%)", signal.Symbol, reason, pnl)
+		monitorLog.Info("🔒 Signal closed", "symbol", signal.Symbol, "signal_id", signal.ID, "reason", reason, "exit", fill.Price, "pnl_pct", pnl)
 
 		// [NEW] Feedback Loop: Record outcome to Signal Tracker
 		if sm.tracker != nil {
@@ -288,7 +450,7 @@ func (sm *SignalMonitor) updateAlertStatus(signal *model.Signal, update bson.M)
 	// Fallback
 	sm.collection.UpdateOne(
 		ctx,
-		bson.M{"symbol": signal.Symbol, "status": "ACTIVE", "timestamp": signal.Timestamp},
+		bson.M{"symbol": signal.Symbol, "status": bson.M{"$in": model.OpenStatuses}, "timestamp": signal.Timestamp},
 		update,
 	)
 }
@@ -312,10 +474,7 @@ func (sm *SignalMonitor) sendTPAlert(signal *model.Signal, exitPrice, pnl float6
 <b>💵 টার্গেট:</b> %s
 
 <b>📈 প্রফিট:</b> +%.2f%%
-
-🎉 <b>অভিনন্দন!</b> আপনার ট্রেড সফল হয়েছে।
-এখন ৫০%% বিক্রি করুন এবং বাকি ৫০%% trailing stop দিয়ে রাখুন।
-`,
+%s`,
 		emoji,
 		formatID(signal.ID),
 		signal.Symbol,
@@ -325,6 +484,7 @@ func (sm *SignalMonitor) sendTPAlert(signal *model.Signal, exitPrice, pnl float6
 		formatPrice(exitPrice),
 		formatPrice(signal.TakeProfit),
 		pnl,
+		tpFooter(signal),
 	)
 
 	sm.telegram.SendMessage(message)
@@ -332,6 +492,78 @@ func (sm *SignalMonitor) sendTPAlert(signal *model.Signal, exitPrice, pnl float6
 	signal.TPAlertSent = true
 }
 
+// tpFooter explains the final TP depending on whether TP1 was booked before
+func tpFooter(signal *model.Signal) string {
+	if signal.TP1Hit {
+		return fmt.Sprintf(`<b>🎯 TP1 (৫০%%):</b> +%.2f%% @ %s
+
+🎉 <b>অভিনন্দন!</b> TP2 হিট করেছে - পুরো পজিশন ক্লোজ।
+উপরের প্রফিট দুই অংশের মিলিত (blended) হিসাব।
+`, signal.TP1PnL, formatPrice(signal.TP1Price))
+	}
+	return `
+🎉 <b>অভিনন্দন!</b> আপনার ট্রেড সফল হয়েছে।
+এখন ৫০% বিক্রি করুন এবং বাকি ৫০% trailing stop দিয়ে রাখুন।
+`
+}
+
+// sendTP1Alert sends the partial take-profit notification in Bangla
+func (sm *SignalMonitor) sendTP1Alert(signal *model.Signal, price, pnl float64) {
+	message := fmt.Sprintf(`🎯 <b>TP1 হিট! ৫০%% প্রফিট বুক করুন</b>
+%s
+
+<b>সিম্বল:</b> %s
+<b>টাইপ:</b> %s
+
+<b>💰 এন্ট্রি:</b> %s
+<b>🎯 TP1:</b> %s
+<b>📈 TP1 প্রফিট:</b> +%.2f%%
+
+🛡️ <b>স্টপ লস এন্ট্রিতে (ব্রেকইভেন) সরানো হয়েছে:</b> %s
+🚀 বাকি ৫০%% চলবে TP2 (%s) পর্যন্ত।
+`,
+		formatID(signal.ID),
+		signal.Symbol,
+		signal.Type,
+		formatPrice(signal.EntryPrice),
+		formatPrice(price),
+		pnl,
+		formatPrice(signal.StopLoss),
+		formatPrice(signal.TakeProfit2),
+	)
+
+	sm.telegram.SendMessage(message)
+}
+
+// sendBreakevenAlert sends the notification when the remainder is stopped at entry after TP1
+func (sm *SignalMonitor) sendBreakevenAlert(signal *model.Signal, exitPrice, pnl float64) {
+	message := fmt.Sprintf(`🛡️ <b>ব্রেকইভেন স্টপ হিট</b>
+%s
+
+<b>সিম্বল:</b> %s
+<b>টাইপ:</b> %s
+
+<b>💰 এন্ট্রি:</b> %s
+<b>🛑 এক্সিট:</b> %s
+<b>🎯 TP1 (৫০%%):</b> +%.2f%%
+
+<b>📊 মোট প্রফিট (blended):</b> %+.2f%%
+
+✅ TP1 বুক করার পর বাকি অংশ এন্ট্রিতে বন্ধ হয়েছে - ট্রেডটি লাভেই শেষ।
+`,
+		formatID(signal.ID),
+		signal.Symbol,
+		signal.Type,
+		formatPrice(signal.EntryPrice),
+		formatPrice(exitPrice),
+		signal.TP1PnL,
+		pnl,
+	)
+
+	sm.telegram.SendMessage(message)
+	signal.SLAlertSent = true
+}
+
 // sendSLAlert sends Stop Loss hit notification in Bangla
 func (sm *SignalMonitor) sendSLAlert(signal *model.Signal, exitPrice, pnl float64) {
 	message := fmt.Sprintf(`🛑 <b>স্টপ লস হিট!</b>
@@ -390,7 +622,7 @@ func (sm *SignalMonitor) sendReversalWarning(signal *model.Signal, currentPrice,
 	)
 
 	sm.telegram.SendMessage(message)
-	log.Printf("⚠️  Reversal warning sent for %s (%.2f%%)", signal.Symbol, movePercent)
+	monitorLog.Info("⚠️  Reversal warning sent", "symbol", signal.Symbol, "signal_id", signal.ID, "move_pct", movePercent)
 
 	// Persist alert state
 	sm.updateAlertStatus(signal, bson.M{"$set": bson.M{"reversal_alert_sent": true}})
@@ -425,7 +657,7 @@ func (sm *SignalMonitor) sendTrailingStopSuggestion(signal *model.Signal, curren
 	)
 
 	sm.telegram.SendMessage(message)
-	log.Printf("💡 Trailing stop suggestion sent for %s (+%.2f%%)", signal.Symbol, profit)
+	monitorLog.Info("💡 Trailing stop suggestion sent", "symbol", signal.Symbol, "signal_id", signal.ID, "profit_pct", profit)
 
 	// Persist alert state
 	sm.updateAlertStatus(signal, bson.M{"$set": bson.M{"trailing_alert_sent": true}})
@@ -455,7 +687,7 @@ func (sm *SignalMonitor) GetActiveSignalsCount() int {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	count, err := sm.collection.CountDocuments(ctx, bson.M{"status": "ACTIVE"})
+	count, err := sm.collection.CountDocuments(ctx, bson.M{"status": bson.M{"$in": model.OpenStatuses}})
 	if err != nil {
 		return 0
 	}
@@ -463,6 +695,48 @@ func (sm *SignalMonitor) GetActiveSignalsCount() int {
 	return int(count)
 }
 
+// ReportOpenSignals sets the open-signals gauge by tier and direction (run on every metrics scrape)
+func (sm *SignalMonitor) ReportOpenSignals() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := sm.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$in": model.OpenStatuses}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"tier": "$tier", "type": "$type"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		monitorLog.Warn("⚠️  Failed to count open signals", "error", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Tier string `bson:"tier"`
+			Type string `bson:"type"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		monitorLog.Warn("⚠️  Failed to count open signals", "error", err)
+		return
+	}
+
+	// Known combinations are always exported, as 0 when nothing is open
+	metrics.OpenSignals.Reset()
+	for _, tier := range []model.SignalTier{model.TierPremium, model.TierStandard} {
+		for _, direction := range []model.SignalType{model.SignalTypeLong, model.SignalTypeShort} {
+			metrics.OpenSignals.Set(0, string(tier), string(direction))
+		}
+	}
+	for _, group := range groups {
+		metrics.OpenSignals.Set(float64(group.Count), group.ID.Tier, group.ID.Type)
+	}
+}
+
 // GetTodayPnL calculates today's total PnL
 func (sm *SignalMonitor) GetTodayPnL() float64 {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+	"mrcrypto-go/internal/service"
+)
+
+// pagedMarket serves 1m history in pages of at most pageSize candles
+type pagedMarket struct {
+	*service.MemoryMarketData
+	klines   []model.Kline
+	pageSize int
+	pages    int
+}
+
+func (m *pagedMarket) GetKlinesRange(_, _ string, startTime int64, limit int) ([]model.Kline, error) {
+	m.pages++
+	var page []model.Kline
+	for _, k := range m.klines {
+		if k.OpenTime >= startTime && len(page) < min(limit, m.pageSize) {
+			page = append(page, k)
+		}
+	}
+	return page, nil
+}
+
+func TestFetchMinuteCandlesPagesLongGaps(t *testing.T) {
+	now := time.Date(2025, 10, 9, 14, 0, 30, 0, time.UTC)
+	from := now.Add(-30 * time.Hour).Truncate(time.Minute)
+
+	market := &pagedMarket{MemoryMarketData: service.NewMemoryMarketData(), pageSize: 100}
+	for at := from; at.Before(now); at = at.Add(time.Minute) {
+		market.klines = append(market.klines, model.Kline{OpenTime: at.UnixMilli(), CloseTime: at.Add(time.Minute).UnixMilli() - 1})
+	}
+
+	klines, err := fetchMinuteCandles(market, "ETHUSDT", from, now)
+	if err != nil {
+		t.Fatalf("fetchMinuteCandles: %v", err)
+	}
+	if len(klines) != len(market.klines) || market.pages != 19 {
+		t.Fatalf("got %d candles in %d pages, want %d in 19", len(klines), market.pages, len(market.klines))
+	}
+	for i, k := range klines {
+		if want := from.Add(time.Duration(i) * time.Minute).UnixMilli(); k.OpenTime != want {
+			t.Fatalf("candle %d opens at %d, want %d", i, k.OpenTime, want)
+		}
+	}
+}
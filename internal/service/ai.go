@@ -4,18 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 
 	"google.golang.org/genai"
 )
 
+var aiLog = logger.Component("ai")
+
+// AIService is the Gemini SignalValidator
 type AIService struct {
 	clients []*genai.Client // Use slice of clients
+	models  []string        // Batch models to try in order (GEMINI_MODELS)
 	ctx     context.Context
+	audit   AIAuditLog // Optional prompt/response audit
 }
 
 func NewAIService() *AIService {
@@ -24,7 +32,7 @@ func NewAIService() *AIService {
 
 	keys := config.AppConfig.GeminiAPIKeys
 	if len(keys) == 0 {
-		log.Printf("⚠️  No Gemini API keys found")
+		aiLog.Warn("⚠️  No Gemini API keys found")
 	}
 
 	for _, key := range keys {
@@ -36,20 +44,21 @@ func NewAIService() *AIService {
 			APIKey: key,
 		})
 		if err != nil {
-			log.Printf("⚠️  Failed to create Gemini client for key ending in ...%s: %v", key[len(key)-4:], err)
+			aiLog.Warn("⚠️  Failed to create Gemini client", "key_suffix", key[len(key)-4:], "error", err)
 			continue
 		}
 		clients = append(clients, client)
 	}
 
 	if len(clients) > 0 {
-		log.Printf("✅ Initialized %d Gemini clients for rotation", len(clients))
+		aiLog.Info("✅ Initialized Gemini clients for rotation", "clients", len(clients))
 	} else {
-		log.Printf("❌ Failed to initialize any Gemini clients")
+		aiLog.Error("❌ Failed to initialize any Gemini clients")
 	}
 
 	return &AIService{
 		clients: clients,
+		models:  config.AppConfig.GeminiModels,
 		ctx:     ctx,
 	}
 }
@@ -60,6 +69,11 @@ type AIValidationResult struct {
 	Confidence int    `json:"confidence"`
 	Tier       string `json:"tier"` // Standard or Premium
 	Reason     string `json:"reason"`
+
+	Validator   string `json:"-"` // Backend that answered (gemini, openai, rules)
+	Model       string `json:"-"` // Model name that answered, if any
+	Unvalidated bool   `json:"-"` // Validation unavailable, passed through by policy
+	Missing     bool   `json:"-"` // No valid answer for this signal, even after re-request
 }
 
 // ValidateSignal sends the signal to Gemini AI for validation with fallback models
@@ -112,16 +126,11 @@ CRITICAL: The "reason" field MUST be written in BENGALI (Bangla).
 	for _, modelName := range models {
 		// Try each client (key) for rotation
 		for cIdx, client := range s.clients {
-			result, err := client.Models.GenerateContent(
-				s.ctx,
-				modelName,
-				genai.Text(prompt),
-				nil,
-			)
+			result, err := s.generateContent(s.ctx, cIdx, client, modelName, prompt, nil)
 
 			if err != nil {
 				lastError = err
-				log.Printf("⚠️  %s - Model %s (Client %d) failed: %v", signal.Symbol, modelName, cIdx+1, err)
+				aiLog.Warn("⚠️  Model failed", "symbol", signal.Symbol, "model", modelName, "client", cIdx+1, "error", err)
 				continue
 			}
 
@@ -131,24 +140,14 @@ CRITICAL: The "reason" field MUST be written in BENGALI (Bangla).
 
 			var aiResult AIValidationResult
 			if err := json.Unmarshal([]byte(jsonText), &aiResult); err != nil {
-				log.Printf("⚠️  Failed to parse AI response for %s: %v", signal.Symbol, err)
+				aiLog.Warn("⚠️  Failed to parse AI response", "symbol", signal.Symbol, "error", err)
 				return 50, 0, "STANDARD", "AI Parse Error", nil
 			}
 
 			// Normalize Tier
-			tier := strings.ToUpper(aiResult.Tier)
-			if tier != "PREMIUM" && tier != "STANDARD" && tier != "REJECT" {
-				// Auto-correct tier based on score if missing or invalid
-				if aiResult.Score >= 90 {
-					tier = "PREMIUM"
-				} else if aiResult.Score >= 80 {
-					tier = "STANDARD"
-				} else {
-					tier = "REJECT"
-				}
-			}
+			tier := normalizeTier(aiResult.Tier, aiResult.Score)
 
-			log.Printf("✅ [AI] %s - Validated! Score: %d, Confidence: %d, Tier: %s", signal.Symbol, aiResult.Score, aiResult.Confidence, tier)
+			aiLog.Info("✅ Validated", "symbol", signal.Symbol, "score", aiResult.Score, "confidence", aiResult.Confidence, "tier", tier)
 			return aiResult.Score, aiResult.Confidence, tier, aiResult.Reason, nil
 		}
 	}
@@ -156,8 +155,13 @@ CRITICAL: The "reason" field MUST be written in BENGALI (Bangla).
 	return 0, 0, "", "", fmt.Errorf("all AI models failed: %w", lastError)
 }
 
+// Name identifies the backend in logs and on saved signals
+func (s *AIService) Name() string {
+	return "gemini"
+}
+
 // BatchValidateSignals validates multiple signals in a single AI call (OPTIMIZED)
-func (s *AIService) BatchValidateSignals(signals []*model.Signal) ([]AIValidationResult, error) {
+func (s *AIService) BatchValidateSignals(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error) {
 	if len(s.clients) == 0 {
 		return nil, fmt.Errorf("no gemini clients initialized")
 	}
@@ -166,67 +170,42 @@ func (s *AIService) BatchValidateSignals(signals []*model.Signal) ([]AIValidatio
 		return []AIValidationResult{}, nil
 	}
 
-	// Build batch prompt with comprehensive data
-	prompt := `You are a Tier-1 Crypto Trading Floor Manager with 15+ years of experience. Analyze these potential signals with extreme scrutiny. 
-Discard any setups that lack proper technical alignment or have poor risk management.
-
-STRICT CRITERIA:
-1. Multi-TF Alignment: 4H and 1H trends MUST align for high scores.
-2. Volume Confirmation: Real breakouts need >= 1.5x average volume.
-3. Key Level Integrity: Respect major Pivot and Fibonacci levels.
-4. Risk Management: If R:R < 2.0, the signal is INVALID.
-
-BENGALI ONLY REASONING:
-Explain your decision like a senior mentor teaching a junior trader. You MUST write the "reason" in BENGALI (Bangla).
-
-RESPONSE FORMAT:
-Respond only with a JSON array. 
-- "score": 0-100 (90+ = Premium, 80-89 = Standard, <80 = Reject)
-- "confidence": 0-100 (Your confidence in this analysis)
-- "tier": "PREMIUM" | "STANDARD" | "REJECT"
+	aiLog.InfoContext(ctx, "🤖 Validating batch", "validator", s.Name(), "signals", len(signals), "models", len(s.models))
 
-[
-  {"signal": 1, "score": <0-100>, "confidence": <0-100>, "tier": "PREMIUM"|"STANDARD"|"REJECT", "reason": "<Senior Analyst explanation in Bengali>"},
-  {"signal": 2, "score": <0-100>, "confidence": <0-100>, "tier": "PREMIUM"|"STANDARD"|"REJECT", "reason": "<Senior Analyst explanation in Bengali>"}
-]
+	results, err := runBatchValidation(ctx, s.Name(), signals, s.generate, s.audit)
+	if err != nil {
+		return nil, err
+	}
 
-SIGNALS TO SCRUTINIZE:
-`
+	aiLog.InfoContext(ctx, "✅ Batch validated", "validator", s.Name(), "signals", len(signals), "model", results[0].Model)
+	return results, nil
+}
 
-	for _, signal := range signals {
-		// Append each signal's details
-		prompt += generateSignalPrompt(signal)
-	}
+// SetAuditLog records every batch prompt and response
+func (s *AIService) SetAuditLog(audit AIAuditLog) {
+	s.audit = audit
+}
 
-	// List of models to try in order (fallback)
-	models := []string{
-		"gemini-3-pro-preview",
-		"gemini-3-flash-preview",
-		"gemini-2.5-flash",
-		"gemini-2.5-flash-lite",
-		"gemini-2.5-pro",
+// generate asks the models in order (rotating keys) for a JSON answer matching schema
+func (s *AIService) generate(ctx context.Context, prompt string, schema map[string]any) (string, string, error) {
+	cfg := &genai.GenerateContentConfig{
+		ResponseMIMEType:   "application/json",
+		ResponseJsonSchema: schema,
 	}
 
-	log.Printf("🤖 [AI Batch] Validating %d signals (trying %d models)...", len(signals), len(models))
-
 	var lastError error
 
 	// Try each model until one succeeds
-	for i, modelName := range models {
-		log.Printf("⏳ [AI Batch] Trying model: %s (%d/%d)...", modelName, i+1, len(models))
+	for i, modelName := range s.models {
+		aiLog.DebugContext(ctx, "⏳ Trying model", "model", modelName, "attempt", i+1, "of", len(s.models))
 
 		// Try each client (key) for rotation
 		for cIdx, client := range s.clients {
-			result, err := client.Models.GenerateContent(
-				s.ctx,
-				modelName,
-				genai.Text(prompt),
-				nil,
-			)
+			result, err := s.generateContent(ctx, cIdx, client, modelName, prompt, cfg)
 
 			if err != nil {
 				lastError = err
-				log.Printf("⚠️  Batch validation - Model %s (Client %d) failed: %v", modelName, cIdx+1, err)
+				aiLog.WarnContext(ctx, "⚠️  Model failed", "model", modelName, "client", cIdx+1, "error", err)
 
 				// If quota exceeded or key expired, try next client
 				errStr := err.Error()
@@ -235,70 +214,88 @@ SIGNALS TO SCRUTINIZE:
 					strings.Contains(errStr, "expired") ||
 					strings.Contains(errStr, "API_KEY_INVALID") ||
 					strings.Contains(errStr, "INVALID_ARGUMENT") {
-					log.Printf("🔄 Switching to next client due to error: %v", err)
+					aiLog.InfoContext(ctx, "🔄 Switching to next client", "model", modelName, "client", cIdx+1)
 					continue
 				}
 				break // Try next model on other errors
 			}
 
-			// Success! Parse response
-			responseText := result.Text()
+			return result.Text(), modelName, nil
+		}
+	}
 
-			// Extract JSON from markdown code blocks if present
-			jsonText := extractJSONFromMarkdown(responseText)
+	return "", "", fmt.Errorf("all gemini models failed: %w", lastError)
+}
 
-			// Try to parse as JSON array
-			var results []struct {
-				SignalNum  int    `json:"signal"`
-				Score      int    `json:"score"`
-				Confidence int    `json:"confidence"`
-				Tier       string `json:"tier"`
-				Reason     string `json:"reason"`
-			}
+// generateContent calls one model with one client, recording latency and failures per model and client number
+func (s *AIService) generateContent(ctx context.Context, cIdx int, client *genai.Client, modelName, prompt string, cfg *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	key := strconv.Itoa(cIdx + 1)
+	start := time.Now()
+	result, err := client.Models.GenerateContent(ctx, modelName, genai.Text(prompt), cfg)
+	metrics.GeminiDuration.Observe(time.Since(start).Seconds(), modelName, key)
+	if err != nil {
+		metrics.GeminiFailures.Inc(modelName, key)
+	}
+	return result, err
+}
 
-			if err := json.Unmarshal([]byte(jsonText), &results); err != nil {
-				log.Printf("⚠️  Failed to parse batch AI response (model: %s): %v", modelName, err)
-				log.Printf("Response preview: %s", jsonText[:min(len(jsonText), 200)])
-				// Return default scores
-				defaultResults := make([]AIValidationResult, len(signals))
-				for idx := range defaultResults {
-					defaultResults[idx] = AIValidationResult{Score: 50, Tier: "STANDARD", Reason: "AI parse error"}
-				}
-				return defaultResults, nil
-			}
+// buildBatchPrompt builds the batch validation prompt shared by the LLM backends.
+// keys are the IDs the answer must be keyed by, one per signal.
+func buildBatchPrompt(signals []*model.Signal, keys []string) string {
+	// Build batch prompt with comprehensive data
+	prompt := `You are a Tier-1 Crypto Trading Floor Manager with 15+ years of experience. Analyze these potential signals with extreme scrutiny. 
+Discard any setups that lack proper technical alignment or have poor risk management.
 
-			// Convert to AIValidationResult
-			validationResults := make([]AIValidationResult, len(signals))
-			for idx, res := range results {
-				if idx < len(validationResults) {
-					tier := strings.ToUpper(res.Tier)
-
-					// Auto-correct tier based on score if missing or invalid
-					if tier != "PREMIUM" && tier != "STANDARD" && tier != "REJECT" {
-						if res.Score >= 90 {
-							tier = "PREMIUM"
-						} else if res.Score >= 80 {
-							tier = "STANDARD"
-						} else {
-							tier = "REJECT"
-						}
-					}
-
-					validationResults[idx] = AIValidationResult{
-						Score:      res.Score,
-						Confidence: res.Confidence,
-						Tier:       tier,
-						Reason:     res.Reason,
-					}
-				}
-			}
+STRICT CRITERIA:
+1. Multi-TF Alignment: 4H and 1H trends MUST align for high scores.
+2. Volume Confirmation: Real breakouts need >= 1.5x average volume.
+3. Key Level Integrity: Respect major Pivot and Fibonacci levels.
+4. Risk Management: If R:R < 2.0, the signal is INVALID.
 
-			log.Printf("✅ [AI Batch] Successfully validated %d signals with model: %s (Client %d)", len(signals), modelName, cIdx+1)
-			return validationResults, nil
+BENGALI ONLY REASONING:
+Explain your decision like a senior mentor teaching a junior trader. You MUST write the "reason" in BENGALI (Bangla).
+
+RESPONSE FORMAT:
+Respond only with a JSON object with exactly one entry per SIGNAL ID below, keyed by that ID.
+- "score": 0-100 (90+ = Premium, 80-89 = Standard, <80 = Reject)
+- "confidence": 0-100 (Your confidence in this analysis)
+- "tier": "PREMIUM" | "STANDARD" | "REJECT"
+
+{
+`
+	for i, key := range keys {
+		separator := ","
+		if i == len(keys)-1 {
+			separator = ""
 		}
+		prompt += fmt.Sprintf(`  %q: {"score": <0-100>, "confidence": <0-100>, "tier": "PREMIUM"|"STANDARD"|"REJECT", "reason": "<Senior Analyst explanation in Bengali>"}%s
+`, key, separator)
 	}
+	prompt += `}
 
-	return nil, fmt.Errorf("unexpected error: %w", lastError)
+SIGNALS TO SCRUTINIZE:
+`
+
+	for i, signal := range signals {
+		// Append each signal's details under the ID it must be answered by
+		prompt += fmt.Sprintf("\n🆔 **SIGNAL ID:** %s\n", keys[i])
+		prompt += generateSignalPrompt(signal)
+	}
+	return prompt
+}
+
+// normalizeTier upper-cases the tier and auto-corrects it from the score if missing or invalid
+func normalizeTier(tier string, score int) string {
+	tier = strings.ToUpper(tier)
+	if tier == "PREMIUM" || tier == "STANDARD" || tier == "REJECT" {
+		return tier
+	}
+	if score >= 90 {
+		return "PREMIUM"
+	} else if score >= 80 {
+		return "STANDARD"
+	}
+	return "REJECT"
 }
 
 // validateSignalInternal helper to generate prompt text
@@ -348,6 +345,12 @@ func generateSignalPrompt(signal *model.Signal) string {
   - *Guide: >1.5x confirms breakouts/moves. <1.0x suggests weak participation.*
 - **Order Flow Delta:** %.2f
   - *Guide: Positive = Aggressive Buying, Negative = Aggressive Selling.*
+- **Order Book:** Imbalance %+.1f%% | Bands: %s | Walls: %s
+  - *Guide: Near-mid bands matter most. Only walls that stood for several polls are listed; spoofed walls are pulled before that.*
+- **Open Interest (4h):** %s | OI %+.2f%% vs price %+.2f%% | Long/Short accounts %.2f | Taker buy/sell %.2f
+  - *Guide: Rising OI with price = new positions fuel the move; falling OI with falling price = long liquidation (no bid underneath). 0 = unavailable.*
+- **Cross-Exchange:** Price spread %.3f%% | Funding spread %.4f%% | %s
+  - *Guide: A venue trading rich with much higher funding is where leveraged longs are crowded.*
 
 🔭 **MARKET STRUCTURE & LEVELS (The "Map"):**
 *Interpretation: Price reacts at these key psychological levels.*
@@ -411,6 +414,17 @@ Provide your expert verdict.
 		signal.TechnicalContext.VWAP,
 		volRatio,
 		signal.TechnicalContext.OrderFlowDelta,
+		signal.TechnicalContext.OrderBookImbalance,
+		valueOrNone(signal.TechnicalContext.OrderBookBands),
+		valueOrNone(signal.TechnicalContext.OrderBookWalls),
+		valueOrNone(signal.TechnicalContext.OIRegime),
+		signal.TechnicalContext.OIChange,
+		signal.TechnicalContext.OIPriceChange,
+		signal.TechnicalContext.LongShortRatio,
+		signal.TechnicalContext.TakerBuySellRatio,
+		signal.TechnicalContext.CrossPriceSpread,
+		signal.TechnicalContext.CrossFundingSpread,
+		valueOrNone(signal.TechnicalContext.CrossExchange),
 		FormatPrice(signal.TechnicalContext.PivotPoint),
 		FormatPrice(signal.TechnicalContext.PivotS1),
 		FormatPrice(signal.TechnicalContext.PivotR1),
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+)
+
+// completeFunc sends one prompt to an LLM backend constrained to the JSON schema.
+// Returns the raw answer and the model that produced it.
+type completeFunc func(ctx context.Context, prompt string, schema map[string]any) (string, string, error)
+
+// batchEntry is one signal's verdict in the batch answer
+type batchEntry struct {
+	Score      *int   `json:"score"`
+	Confidence *int   `json:"confidence"`
+	Tier       string `json:"tier"`
+	Reason     string `json:"reason"`
+}
+
+// batchKeys returns the ID each signal is asked and answered under.
+// Signal IDs are used as-is; empty or repeated IDs get a positional key so every key is unique.
+func batchKeys(signals []*model.Signal) []string {
+	keys := make([]string, len(signals))
+	seen := make(map[string]bool, len(signals))
+	for i, signal := range signals {
+		key := signal.ID
+		if key == "" || seen[key] {
+			key = fmt.Sprintf("%s#%d", signal.ID, i+1)
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// batchResponseSchema is the JSON schema of the answer: one object property per signal ID
+func batchResponseSchema(keys []string) map[string]any {
+	entry := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score":      map[string]any{"type": "integer", "minimum": 0, "maximum": 100},
+			"confidence": map[string]any{"type": "integer", "minimum": 0, "maximum": 100},
+			"tier":       map[string]any{"type": "string", "enum": []string{"PREMIUM", "STANDARD", "REJECT"}},
+			"reason":     map[string]any{"type": "string"},
+		},
+		"required":             []string{"score", "confidence", "tier", "reason"},
+		"additionalProperties": false,
+	}
+
+	properties := make(map[string]any, len(keys))
+	for _, key := range keys {
+		properties[key] = entry
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             keys,
+		"additionalProperties": false,
+	}
+}
+
+// parseBatchResponse matches a batch answer back to the signals by ID.
+// Entries that are missing or malformed are left out of the map and described in problems.
+func parseBatchResponse(responseText string, keys []string) (map[string]AIValidationResult, []string) {
+	jsonText := strings.TrimSpace(extractJSONFromMarkdown(strings.TrimSpace(responseText)))
+
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(jsonText), &raw); err != nil {
+		// Some models still answer with an array; accept it when every entry carries its ID
+		var list []struct {
+			ID string `json:"id"`
+		}
+		var rawList []json.RawMessage
+		if json.Unmarshal([]byte(jsonText), &list) != nil || json.Unmarshal([]byte(jsonText), &rawList) != nil {
+			return nil, []string{fmt.Sprintf("response is not a JSON object: %v", err)}
+		}
+		for i, item := range list {
+			if item.ID != "" {
+				raw[item.ID] = rawList[i]
+			}
+		}
+	}
+
+	expected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		expected[key] = true
+	}
+
+	var problems []string
+	for key := range raw {
+		if !expected[key] {
+			problems = append(problems, fmt.Sprintf("%s: unknown signal ID", key))
+		}
+	}
+
+	results := make(map[string]AIValidationResult, len(keys))
+	for _, key := range keys {
+		data, ok := raw[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: missing", key))
+			continue
+		}
+
+		var entry batchEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		if problem := entry.validate(); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, problem))
+			continue
+		}
+
+		results[key] = AIValidationResult{
+			Score:      *entry.Score,
+			Confidence: *entry.Confidence,
+			Tier:       normalizeTier(entry.Tier, *entry.Score),
+			Reason:     entry.Reason,
+		}
+	}
+	return results, problems
+}
+
+// validate describes what is wrong with an entry, or returns "" when it is usable
+func (e batchEntry) validate() string {
+	switch {
+	case e.Score == nil || *e.Score < 0 || *e.Score > 100:
+		return "score missing or outside 0-100"
+	case e.Confidence == nil || *e.Confidence < 0 || *e.Confidence > 100:
+		return "confidence missing or outside 0-100"
+	case strings.TrimSpace(e.Reason) == "":
+		return "reason missing"
+	}
+	return ""
+}
+
+// runBatchValidation validates signals in one request, then re-requests every
+// missing or malformed entry on its own. Signals still unanswered come back Missing.
+// Every exchange is written to the audit log.
+func runBatchValidation(ctx context.Context, validator string, signals []*model.Signal, complete completeFunc, audit AIAuditLog) ([]AIValidationResult, error) {
+	keys := batchKeys(signals)
+
+	parsed, modelName, err := exchange(ctx, validator, "batch", keys, buildBatchPrompt(signals, keys), complete, audit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]AIValidationResult, len(signals))
+	for i, signal := range signals {
+		result, ok := parsed[keys[i]]
+		resultModel := modelName
+		if !ok {
+			signalCtx := logger.WithSignal(logger.WithSymbol(ctx, signal.Symbol), keys[i])
+			aiLog.WarnContext(signalCtx, "🔁 Missing or malformed in batch answer, re-requesting individually", "validator", validator)
+			single := []string{keys[i]}
+			retry, retryModel, err := exchange(signalCtx, validator, "retry", single, buildBatchPrompt([]*model.Signal{signal}, single), complete, audit)
+			result, ok = retry[keys[i]]
+			resultModel = retryModel
+			if err != nil || !ok {
+				aiLog.WarnContext(signalCtx, "⚠️  No valid answer after re-request", "validator", validator)
+				result = AIValidationResult{Tier: "REJECT", Reason: "AI response missing", Missing: true}
+			}
+		}
+		result.Validator = validator
+		result.Model = resultModel
+		results[i] = result
+	}
+	return results, nil
+}
+
+// exchange sends one prompt, audits it and parses the answer
+func exchange(ctx context.Context, validator, kind string, keys []string, prompt string, complete completeFunc, audit AIAuditLog) (map[string]AIValidationResult, string, error) {
+	started := time.Now()
+	response, modelName, err := complete(ctx, prompt, batchResponseSchema(keys))
+
+	record := AIExchange{
+		Validator:  validator,
+		Model:      modelName,
+		Kind:       kind,
+		SignalIDs:  keys,
+		Prompt:     prompt,
+		Response:   response,
+		DurationMs: time.Since(started).Milliseconds(),
+		CreatedAt:  started,
+	}
+
+	var parsed map[string]AIValidationResult
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		parsed, record.Problems = parseBatchResponse(response, keys)
+		for _, problem := range record.Problems {
+			aiLog.WarnContext(ctx, "⚠️  Invalid answer", "validator", validator, "kind", kind, "model", modelName, "problem", problem)
+		}
+	}
+
+	if audit != nil {
+		if auditErr := audit.SaveExchange(record); auditErr != nil {
+			aiLog.WarnContext(ctx, "⚠️  Failed to save audit record", "validator", validator, "error", auditErr)
+		}
+	}
+	return parsed, modelName, err
+}
+
+// AIExchange is the audit record of one prompt/response round trip
+type AIExchange struct {
+	Validator  string    `bson:"validator"`
+	Model      string    `bson:"model"`
+	Kind       string    `bson:"kind"`       // batch or retry
+	SignalIDs  []string  `bson:"signal_ids"` // IDs the answer was keyed by
+	Prompt     string    `bson:"prompt"`
+	Response   string    `bson:"response"`
+	Error      string    `bson:"error,omitempty"`
+	Problems   []string  `bson:"problems,omitempty"` // Missing or malformed entries
+	DurationMs int64     `bson:"duration_ms"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// AIAuditLog persists raw AI exchanges
+type AIAuditLog interface {
+	SaveExchange(record AIExchange) error
+}
+
+// MongoAIAuditLog stores AI exchanges in the "ai_audit" collection
+type MongoAIAuditLog struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAIAuditLog creates a Mongo-backed audit log
+func NewMongoAIAuditLog(db *mongo.Database) *MongoAIAuditLog {
+	return &MongoAIAuditLog{collection: db.Collection("ai_audit")}
+}
+
+// SaveExchange inserts one audit record
+func (a *MongoAIAuditLog) SaveExchange(record AIExchange) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := a.collection.InsertOne(ctx, record); err != nil {
+		return fmt.Errorf("failed to save AI exchange: %w", err)
+	}
+	return nil
+}
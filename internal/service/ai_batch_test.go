@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+// verdict is one answer entry as a model would write it
+func verdict(score, confidence int, tier, reason string) string {
+	return fmt.Sprintf(`{"score": %d, "confidence": %d, "tier": %q, "reason": %q}`, score, confidence, tier, reason)
+}
+
+func TestParseBatchResponse(t *testing.T) {
+	a, b := verdict(85, 70, "STANDARD", "aligned"), verdict(40, 60, "REJECT", "weak volume")
+	tests := []struct {
+		name     string
+		response string
+		want     map[string]int // Key -> score of the accepted entries
+		problems []string
+	}{
+		{"reordered", `{"B": ` + b + `, "A": ` + a + `}`, map[string]int{"A": 85, "B": 40}, nil},
+		{"markdown fence", "```json\n{\"A\": " + a + ", \"B\": " + b + "}\n```", map[string]int{"A": 85, "B": 40}, nil},
+		{"missing key", `{"A": ` + a + `}`, map[string]int{"A": 85}, []string{"B: missing"}},
+		{"unknown ID", `{"A": ` + a + `, "B": ` + b + `, "C": ` + a + `}`, map[string]int{"A": 85, "B": 40},
+			[]string{"C: unknown signal ID"}},
+		{"array with ids", `[{"id": "B", "score": 40, "confidence": 60, "tier": "REJECT", "reason": "weak volume"},
+			{"id": "A", "score": 85, "confidence": 70, "tier": "STANDARD", "reason": "aligned"}]`, map[string]int{"A": 85, "B": 40}, nil},
+		{"array without ids", `[` + a + `, ` + b + `]`, map[string]int{}, []string{"A: missing", "B: missing"}},
+		{"score above 100", `{"A": ` + verdict(101, 70, "PREMIUM", "x") + `, "B": ` + b + `}`, map[string]int{"B": 40},
+			[]string{"A: score missing or outside 0-100"}},
+		{"negative score", `{"A": ` + verdict(-1, 70, "REJECT", "x") + `, "B": ` + b + `}`, map[string]int{"B": 40},
+			[]string{"A: score missing or outside 0-100"}},
+		{"confidence out of range", `{"A": ` + a + `, "B": ` + verdict(40, 101, "REJECT", "x") + `}`, map[string]int{"A": 85},
+			[]string{"B: confidence missing or outside 0-100"}},
+		{"confidence missing", `{"A": ` + a + `, "B": {"score": 40, "tier": "REJECT", "reason": "x"}}`, map[string]int{"A": 85},
+			[]string{"B: confidence missing or outside 0-100"}},
+		{"blank reason", `{"A": ` + verdict(85, 70, "STANDARD", "  ") + `, "B": ` + b + `}`, map[string]int{"B": 40},
+			[]string{"A: reason missing"}},
+		{"not JSON", `I cannot help with that`, map[string]int{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, problems := parseBatchResponse(tt.response, []string{"A", "B"})
+			if len(results) != len(tt.want) {
+				t.Errorf("results = %+v, want scores %v", results, tt.want)
+			}
+			for key, score := range tt.want {
+				if results[key].Score != score {
+					t.Errorf("%s score = %d, want %d", key, results[key].Score, score)
+				}
+			}
+			if tt.name == "not JSON" {
+				if len(problems) != 1 || !strings.HasPrefix(problems[0], "response is not a JSON object") {
+					t.Errorf("problems = %q", problems)
+				}
+				return
+			}
+			if !slices.Equal(problems, tt.problems) {
+				t.Errorf("problems = %q, want %q", problems, tt.problems)
+			}
+		})
+	}
+
+	// An unknown tier is derived from the score
+	results, _ := parseBatchResponse(`{"A": `+verdict(92, 80, "great", "x")+`}`, []string{"A"})
+	if results["A"].Tier != "PREMIUM" || results["A"].Confidence != 80 || results["A"].Reason != "x" {
+		t.Errorf("result = %+v, want PREMIUM from the score", results["A"])
+	}
+}
+
+func TestBatchKeys(t *testing.T) {
+	signals := []*model.Signal{{ID: "abc12"}, {ID: ""}, {ID: "abc12"}}
+	if keys := batchKeys(signals); !slices.Equal(keys, []string{"abc12", "#2", "abc12#3"}) {
+		t.Errorf("keys = %q, want unique keys", keys)
+	}
+}
+
+// scriptedLLM answers each call with the next scripted response and records the keys asked for
+type scriptedLLM struct {
+	responses []string
+	errs      []error
+	asked     [][]string
+}
+
+func (s *scriptedLLM) complete(_ context.Context, _ string, schema map[string]any) (string, string, error) {
+	call := len(s.asked)
+	s.asked = append(s.asked, schema["required"].([]string))
+	if call >= len(s.responses) {
+		return "", "", errors.New("no more answers")
+	}
+	var err error
+	if call < len(s.errs) {
+		err = s.errs[call]
+	}
+	return s.responses[call], fmt.Sprintf("model-%d", call), err
+}
+
+type auditCapture struct {
+	records []AIExchange
+}
+
+func (a *auditCapture) SaveExchange(record AIExchange) error {
+	a.records = append(a.records, record)
+	return nil
+}
+
+func TestRunBatchValidationRetriesMissing(t *testing.T) {
+	signals := []*model.Signal{{ID: "A", Symbol: "ETHUSDT"}, {ID: "B", Symbol: "BTCUSDT"}, {ID: "C", Symbol: "SOLUSDT"}}
+	llm := &scriptedLLM{responses: []string{
+		`{"C": ` + verdict(81, 70, "STANDARD", "ok") + `, "A": ` + verdict(150, 70, "PREMIUM", "bad score") + `}`,
+		`{"A": ` + verdict(90, 75, "PREMIUM", "retried") + `}`,  // A on its own
+		`{"X": ` + verdict(90, 75, "PREMIUM", "wrong id") + `}`, // B on its own, still unanswered
+	}}
+	audit := &auditCapture{}
+
+	results, err := runBatchValidation(context.Background(), "gemini", signals, llm.complete, audit)
+	if err != nil {
+		t.Fatalf("runBatchValidation: %v", err)
+	}
+
+	if want := [][]string{{"A", "B", "C"}, {"A"}, {"B"}}; !slices.EqualFunc(llm.asked, want, slices.Equal) {
+		t.Errorf("asked = %q, want the batch then each missing entry alone", llm.asked)
+	}
+	if r := results[0]; r.Score != 90 || r.Reason != "retried" || r.Model != "model-1" || r.Missing {
+		t.Errorf("A = %+v, want the retried answer", r)
+	}
+	if r := results[1]; !r.Missing || r.Tier != "REJECT" || r.Model != "model-2" {
+		t.Errorf("B = %+v, want Missing", r)
+	}
+	if r := results[2]; r.Score != 81 || r.Model != "model-0" || r.Validator != "gemini" || r.Missing {
+		t.Errorf("C = %+v, want the batch answer", r)
+	}
+
+	if len(audit.records) != 3 {
+		t.Fatalf("audited %d exchanges, want 3", len(audit.records))
+	}
+	kinds := []string{audit.records[0].Kind, audit.records[1].Kind, audit.records[2].Kind}
+	if !slices.Equal(kinds, []string{"batch", "retry", "retry"}) || audit.records[0].Validator != "gemini" {
+		t.Errorf("audit kinds = %q", kinds)
+	}
+	if p := audit.records[0].Problems; !slices.Equal(p, []string{"A: score missing or outside 0-100", "B: missing"}) {
+		t.Errorf("batch problems = %q", p)
+	}
+	if r := audit.records[2]; !slices.Equal(r.SignalIDs, []string{"B"}) || r.Response == "" || len(r.Problems) != 2 {
+		t.Errorf("retry record = %+v", r)
+	}
+}
+
+func TestRunBatchValidationErrors(t *testing.T) {
+	signals := []*model.Signal{{ID: "A"}, {ID: "B"}}
+
+	// The batch request failing fails the whole validation (the caller applies its policy)
+	audit := &auditCapture{}
+	llm := &scriptedLLM{responses: []string{""}, errs: []error{errors.New("quota exceeded")}}
+	if _, err := runBatchValidation(context.Background(), "openai", signals, llm.complete, audit); err == nil {
+		t.Error("a failed batch request should return an error")
+	}
+	if len(audit.records) != 1 || audit.records[0].Error != "quota exceeded" {
+		t.Errorf("audit = %+v, want the failed exchange", audit.records)
+	}
+
+	// A failed re-request leaves only that signal Missing
+	llm = &scriptedLLM{responses: []string{`{"B": ` + verdict(70, 50, "REJECT", "no") + `}`}}
+	results, err := runBatchValidation(context.Background(), "openai", signals, llm.complete, nil)
+	if err != nil {
+		t.Fatalf("runBatchValidation: %v", err)
+	}
+	if !results[0].Missing || results[1].Missing || results[1].Score != 70 {
+		t.Errorf("results = %+v, want A missing and B answered", results)
+	}
+}
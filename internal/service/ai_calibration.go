@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mrcrypto-go/internal/model"
+)
+
+// Validation outcomes recorded by the loader
+const (
+	ValidationSent       = "SENT"        // Saved and broadcast
+	ValidationLowScore   = "LOW_SCORE"   // AI or system score below the profile minimum
+	ValidationNoAnswer   = "NO_ANSWER"   // Validator returned no usable answer
+	ValidationDuplicate  = "DUPLICATE"   // Active signal already open
+	ValidationRiskGate   = "RISK_GATE"   // Rejected by portfolio limits
+	ValidationSaveFailed = "SAVE_FAILED" // Database write failed
+	ValidationPending    = "PENDING"     // Not decided (poll aborted before the signal was processed)
+)
+
+// AIValidationRecord is one signal's validation in one validator call
+type AIValidationRecord struct {
+	SignalID        string    `bson:"signal_id"`
+	Symbol          string    `bson:"symbol"`
+	Type            string    `bson:"type"`
+	Profile         string    `bson:"profile"`
+	Validator       string    `bson:"validator"`
+	Model           string    `bson:"model"`
+	AIScore         int       `bson:"ai_score"`
+	AIConfidence    int       `bson:"ai_confidence"`
+	AITier          string    `bson:"ai_tier"`
+	AIReason        string    `bson:"ai_reason"`
+	ConfluenceScore int       `bson:"confluence_score"`
+	Unvalidated     bool      `bson:"unvalidated"`
+	Outcome         string    `bson:"outcome"`    // SENT, LOW_SCORE, ...
+	CallAt          time.Time `bson:"call_at"`    // Shared by every record of the same validator call
+	CreatedAt       time.Time `bson:"created_at"` // When the outcome was decided
+}
+
+// NewAIValidationRecord captures a signal and the validator's verdict on it
+func NewAIValidationRecord(signal *model.Signal, result AIValidationResult, callAt time.Time) AIValidationRecord {
+	return AIValidationRecord{
+		SignalID:        signal.ID,
+		Symbol:          signal.Symbol,
+		Type:            string(signal.Type),
+		Profile:         signal.Profile,
+		Validator:       result.Validator,
+		Model:           result.Model,
+		AIScore:         result.Score,
+		AIConfidence:    result.Confidence,
+		AITier:          result.Tier,
+		AIReason:        result.Reason,
+		ConfluenceScore: signal.ConfluenceScore,
+		Unvalidated:     result.Unvalidated,
+		Outcome:         ValidationPending,
+		CallAt:          callAt,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// AIValidationLog persists validation records
+type AIValidationLog interface {
+	SaveValidations(records []AIValidationRecord) error
+	LoadValidations(since time.Time) ([]AIValidationRecord, error)
+}
+
+// MongoAIValidationLog stores validation records in the "ai_validations" collection
+type MongoAIValidationLog struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAIValidationLog creates a Mongo-backed validation log
+func NewMongoAIValidationLog(db *mongo.Database) *MongoAIValidationLog {
+	return &MongoAIValidationLog{collection: db.Collection("ai_validations")}
+}
+
+// SaveValidations inserts the records of one validator call
+func (v *MongoAIValidationLog) SaveValidations(records []AIValidationRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(records))
+	for i := range records {
+		docs[i] = records[i]
+	}
+	if _, err := v.collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to save AI validations: %w", err)
+	}
+	return nil
+}
+
+// LoadValidations returns the records created since the given time, oldest first
+func (v *MongoAIValidationLog) LoadValidations(since time.Time) ([]AIValidationRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "call_at", Value: 1}})
+	cursor, err := v.collection.Find(ctx, bson.M{"call_at": bson.M{"$gte": since}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AI validations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []AIValidationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode AI validations: %w", err)
+	}
+	return records, nil
+}
+
+// ScoreBucket is the realised performance of closed signals within a score range
+type ScoreBucket struct {
+	Label   string
+	Min     int // Inclusive
+	Max     int // Inclusive
+	Trades  int
+	Wins    int
+	WinRate float64 // %
+	AvgPnL  float64 // %
+}
+
+// ModelCalibration is the realised performance of the signals one model approved
+type ModelCalibration struct {
+	Model       string
+	Trades      int
+	Wins        int
+	WinRate     float64
+	AvgPnL      float64
+	AvgAIScore  float64
+	Validations int // Records in ai_validations
+	Sent        int // Of which were broadcast
+}
+
+// CalibrationReport compares how well AI score and confluence score predict outcomes
+type CalibrationReport struct {
+	Trades            int
+	ByAIScore         []ScoreBucket
+	ByConfluenceScore []ScoreBucket
+	ByModel           []ModelCalibration
+	Validations       int
+
+	// Threshold splits closed signals by AI >= threshold (rows) and confluence >= threshold (columns):
+	// [0][0] both below, [0][1] system only, [1][0] AI only, [1][1] both pass
+	Threshold     int
+	ThresholdGrid [2][2]ScoreBucket
+}
+
+// calibrationBuckets are the score ranges compared in the report
+var calibrationBuckets = []ScoreBucket{
+	{Label: "<60", Min: 0, Max: 59},
+	{Label: "60-69", Min: 60, Max: 69},
+	{Label: "70-79", Min: 70, Max: 79},
+	{Label: "80-84", Min: 80, Max: 84},
+	{Label: "85-89", Min: 85, Max: 89},
+	{Label: "90-100", Min: 90, Max: 100},
+}
+
+// BuildCalibrationReport buckets closed signals by AI and confluence score and by answering model,
+// and splits them on the dual score threshold.
+// Signals saved before the model was stored on the signal take it from their validation record.
+func BuildCalibrationReport(closed []model.Signal, validations []AIValidationRecord, threshold int) CalibrationReport {
+	report := CalibrationReport{
+		Trades:            len(closed),
+		ByAIScore:         append([]ScoreBucket(nil), calibrationBuckets...),
+		ByConfluenceScore: append([]ScoreBucket(nil), calibrationBuckets...),
+		Validations:       len(validations),
+		Threshold:         threshold,
+	}
+	report.ThresholdGrid[0][0].Label = "both below"
+	report.ThresholdGrid[0][1].Label = "system only"
+	report.ThresholdGrid[1][0].Label = "AI only"
+	report.ThresholdGrid[1][1].Label = "both pass"
+
+	modelBySignal := make(map[string]string, len(validations))
+	models := make(map[string]*ModelCalibration)
+	modelEntry := func(name string) *ModelCalibration {
+		if name == "" {
+			name = "unknown"
+		}
+		if models[name] == nil {
+			models[name] = &ModelCalibration{Model: name}
+		}
+		return models[name]
+	}
+
+	for _, record := range validations {
+		entry := modelEntry(validationModelName(record.Validator, record.Model, record.Unvalidated))
+		entry.Validations++
+		if record.Outcome == ValidationSent {
+			entry.Sent++
+			modelBySignal[record.SignalID] = entry.Model
+		}
+	}
+
+	for _, signal := range closed {
+		win := signal.PnL > 0
+		addToBucket(report.ByConfluenceScore, signal.ConfluenceScore, win, signal.PnL)
+
+		// Unvalidated signals carry the system score as AI score - keep them out of the AI comparison
+		if !signal.AIUnvalidated {
+			addToBucket(report.ByAIScore, signal.AIScore, win, signal.PnL)
+
+			cell := &report.ThresholdGrid[boolIndex(signal.AIScore >= threshold)][boolIndex(signal.ConfluenceScore >= threshold)]
+			cell.Trades++
+			cell.AvgPnL += signal.PnL
+			if win {
+				cell.Wins++
+			}
+		}
+
+		name := validationModelName(signal.AIValidator, signal.AIModel, signal.AIUnvalidated)
+		if signal.AIValidator == "" && signal.AIModel == "" && !signal.AIUnvalidated {
+			name = modelBySignal[signal.ID]
+		}
+		entry := modelEntry(name)
+		entry.Trades++
+		entry.AvgPnL += signal.PnL
+		entry.AvgAIScore += float64(signal.AIScore)
+		if win {
+			entry.Wins++
+		}
+	}
+
+	finishBuckets(report.ByAIScore)
+	finishBuckets(report.ByConfluenceScore)
+	for i := range report.ThresholdGrid {
+		finishBuckets(report.ThresholdGrid[i][:])
+	}
+
+	for _, entry := range models {
+		if entry.Trades > 0 {
+			entry.WinRate = float64(entry.Wins) / float64(entry.Trades) * 100
+			entry.AvgPnL /= float64(entry.Trades)
+			entry.AvgAIScore /= float64(entry.Trades)
+		}
+		report.ByModel = append(report.ByModel, *entry)
+	}
+	sort.Slice(report.ByModel, func(i, j int) bool {
+		if report.ByModel[i].Trades != report.ByModel[j].Trades {
+			return report.ByModel[i].Trades > report.ByModel[j].Trades
+		}
+		return report.ByModel[i].Model < report.ByModel[j].Model
+	})
+
+	return report
+}
+
+// validationModelName labels who scored a signal: the model, the rule set, or nobody
+func validationModelName(validator, modelName string, unvalidated bool) string {
+	switch {
+	case unvalidated:
+		return "unvalidated"
+	case modelName != "":
+		return modelName
+	default:
+		return validator
+	}
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func addToBucket(buckets []ScoreBucket, score int, win bool, pnl float64) {
+	for i := range buckets {
+		if score >= buckets[i].Min && score <= buckets[i].Max {
+			buckets[i].Trades++
+			buckets[i].AvgPnL += pnl
+			if win {
+				buckets[i].Wins++
+			}
+			return
+		}
+	}
+}
+
+func finishBuckets(buckets []ScoreBucket) {
+	for i := range buckets {
+		if buckets[i].Trades > 0 {
+			buckets[i].WinRate = float64(buckets[i].Wins) / float64(buckets[i].Trades) * 100
+			buckets[i].AvgPnL /= float64(buckets[i].Trades)
+		}
+	}
+}
+
+// Format renders the report as a plain-text table
+func (r CalibrationReport) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "AI calibration - %d closed signals, %d validation records\n", r.Trades, r.Validations)
+
+	writeBuckets := func(title, column string, buckets []ScoreBucket) {
+		fmt.Fprintf(&b, "\n%s\n", title)
+		fmt.Fprintf(&b, "  %-12s %7s %9s %9s\n", column, "Trades", "Win rate", "Avg PnL")
+		for _, bucket := range buckets {
+			if bucket.Trades == 0 {
+				fmt.Fprintf(&b, "  %-12s %7d %9s %9s\n", bucket.Label, 0, "-", "-")
+				continue
+			}
+			fmt.Fprintf(&b, "  %-12s %7d %8.1f%% %+8.2f%%\n", bucket.Label, bucket.Trades, bucket.WinRate, bucket.AvgPnL)
+		}
+	}
+	writeBuckets("By AI score (validated signals only)", "Score", r.ByAIScore)
+	writeBuckets("By confluence score", "Score", r.ByConfluenceScore)
+	writeBuckets(fmt.Sprintf("Dual threshold (AI / system >= %d)", r.Threshold), "Passes", []ScoreBucket{
+		r.ThresholdGrid[1][1], r.ThresholdGrid[1][0], r.ThresholdGrid[0][1], r.ThresholdGrid[0][0],
+	})
+
+	fmt.Fprintf(&b, "\nBy model\n")
+	fmt.Fprintf(&b, "  %-24s %7s %9s %9s %7s %11s %6s\n", "Model", "Trades", "Win rate", "Avg PnL", "Avg AI", "Validations", "Sent")
+	for _, m := range r.ByModel {
+		winRate, avgPnL, avgAI := "-", "-", "-"
+		if m.Trades > 0 {
+			winRate = fmt.Sprintf("%.1f%%", m.WinRate)
+			avgPnL = fmt.Sprintf("%+.2f%%", m.AvgPnL)
+			avgAI = fmt.Sprintf("%.1f", m.AvgAIScore)
+		}
+		fmt.Fprintf(&b, "  %-24s %7d %9s %9s %7s %11d %6d\n", m.Model, m.Trades, winRate, avgPnL, avgAI, m.Validations, m.Sent)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+func TestBuildCalibrationReport(t *testing.T) {
+	closed := []model.Signal{
+		{ID: "a", AIScore: 92, ConfluenceScore: 85, PnL: 4, AIValidator: "gemini", AIModel: "gemini-2.0"},
+		{ID: "b", AIScore: 91, ConfluenceScore: 82, PnL: -2, AIValidator: "gemini", AIModel: "gemini-2.0"},
+		{ID: "c", AIScore: 86, ConfluenceScore: 70, PnL: 3, AIValidator: "openai", AIModel: "llama3.1"},
+		{ID: "d", AIScore: 75, ConfluenceScore: 88, PnL: -1},                     // Legacy: model from its validation record
+		{ID: "e", AIScore: 83, ConfluenceScore: 83, PnL: 1, AIUnvalidated: true}, // AI score is the system score
+		{ID: "f", AIScore: 50, ConfluenceScore: 55, PnL: 0},                      // Legacy without a record; breakeven is no win
+	}
+	validations := []AIValidationRecord{
+		{SignalID: "d", Validator: "openai", Model: "gpt-4o", Outcome: ValidationSent},
+		{SignalID: "x", Validator: "openai", Model: "gpt-4o", Outcome: ValidationLowScore},
+		{SignalID: "a", Validator: "gemini", Model: "gemini-2.0", Outcome: ValidationSent},
+		{SignalID: "y", Validator: "rules", Outcome: ValidationSent},
+	}
+
+	report := BuildCalibrationReport(closed, validations, 80)
+	if report.Trades != 6 || report.Validations != 4 || report.Threshold != 80 {
+		t.Errorf("report totals = %d trades, %d validations, threshold %d", report.Trades, report.Validations, report.Threshold)
+	}
+
+	type bucket struct {
+		trades, wins    int
+		winRate, avgPnL float64
+	}
+	check := func(name string, got ScoreBucket, want bucket) {
+		t.Helper()
+		if got.Trades != want.trades || got.Wins != want.wins || !approx(got.WinRate, want.winRate) || !approx(got.AvgPnL, want.avgPnL) {
+			t.Errorf("%s %s = %d trades, %d wins, %.1f%%, %+.2f%%, want %+v",
+				name, got.Label, got.Trades, got.Wins, got.WinRate, got.AvgPnL, want)
+		}
+	}
+
+	// Buckets: <60, 60-69, 70-79, 80-84, 85-89, 90-100
+	wantAI := []bucket{{1, 0, 0, 0}, {}, {1, 0, 0, -1}, {}, {1, 1, 100, 3}, {2, 1, 50, 1}} // e stays out of 80-84
+	wantConfluence := []bucket{{1, 0, 0, 0}, {}, {1, 1, 100, 3}, {2, 1, 50, -0.5}, {2, 1, 50, 1.5}, {}}
+	for i := range wantAI {
+		check("AI", report.ByAIScore[i], wantAI[i])
+		check("confluence", report.ByConfluenceScore[i], wantConfluence[i])
+	}
+
+	check("grid", report.ThresholdGrid[1][1], bucket{2, 1, 50, 1})  // a, b
+	check("grid", report.ThresholdGrid[1][0], bucket{1, 1, 100, 3}) // c
+	check("grid", report.ThresholdGrid[0][1], bucket{1, 0, 0, -1})  // d
+	check("grid", report.ThresholdGrid[0][0], bucket{1, 0, 0, 0})   // f; e is unvalidated
+	if report.ThresholdGrid[0][1].Label != "system only" || report.ThresholdGrid[1][0].Label != "AI only" {
+		t.Errorf("grid labels = %q / %q", report.ThresholdGrid[0][1].Label, report.ThresholdGrid[1][0].Label)
+	}
+
+	want := []ModelCalibration{
+		{Model: "gemini-2.0", Trades: 2, Wins: 1, WinRate: 50, AvgPnL: 1, AvgAIScore: 91.5, Validations: 1, Sent: 1},
+		{Model: "gpt-4o", Trades: 1, WinRate: 0, AvgPnL: -1, AvgAIScore: 75, Validations: 2, Sent: 1},
+		{Model: "llama3.1", Trades: 1, Wins: 1, WinRate: 100, AvgPnL: 3, AvgAIScore: 86},
+		{Model: "unknown", Trades: 1, AvgAIScore: 50},
+		{Model: "unvalidated", Trades: 1, Wins: 1, WinRate: 100, AvgPnL: 1, AvgAIScore: 83},
+		{Model: "rules", Validations: 1, Sent: 1},
+	}
+	if len(report.ByModel) != len(want) {
+		t.Fatalf("models = %+v, want %+v", report.ByModel, want)
+	}
+	for i, m := range report.ByModel {
+		if m != want[i] {
+			t.Errorf("model %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+}
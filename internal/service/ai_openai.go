@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+// OpenAIValidator validates signals through an OpenAI-compatible chat completions API.
+// Works with OpenAI itself and local servers (Ollama, llama.cpp, vLLM, LM Studio).
+type OpenAIValidator struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+	audit   AIAuditLog // Optional prompt/response audit
+}
+
+// NewOpenAIValidator creates a validator for {baseURL}/chat/completions
+func NewOpenAIValidator(baseURL, apiKey, model string) *OpenAIValidator {
+	return &OpenAIValidator{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: 120 * time.Second}, // Local models can be slow
+	}
+}
+
+// Name identifies the backend in logs and on saved signals
+func (v *OpenAIValidator) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat asks for structured output matching a JSON schema
+type responseFormat struct {
+	Type       string         `json:"type"` // json_schema
+	JSONSchema jsonSchemaSpec `json:"json_schema"`
+}
+
+type jsonSchemaSpec struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+type chatCompletionResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// BatchValidateSignals sends the batch prompt as one chat completion
+func (v *OpenAIValidator) BatchValidateSignals(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error) {
+	if len(signals) == 0 {
+		return []AIValidationResult{}, nil
+	}
+
+	aiLog.InfoContext(ctx, "🤖 Validating batch", "validator", v.Name(), "signals", len(signals), "model", v.model, "base_url", v.baseURL)
+
+	results, err := runBatchValidation(ctx, v.Name(), signals, v.complete, v.audit)
+	if err != nil {
+		return nil, err
+	}
+
+	aiLog.InfoContext(ctx, "✅ Batch validated", "validator", v.Name(), "signals", len(signals), "model", results[0].Model)
+	return results, nil
+}
+
+// SetAuditLog records every batch prompt and response
+func (v *OpenAIValidator) SetAuditLog(audit AIAuditLog) {
+	v.audit = audit
+}
+
+// complete runs one chat completion and returns the answer and the model that produced it
+func (v *OpenAIValidator) complete(ctx context.Context, prompt string, schema map[string]any) (string, string, error) {
+	body, err := json.Marshal(chatCompletionRequest{
+		Model:       v.model,
+		Messages:    []chatMessage{{Role: "user", Content: prompt}},
+		Temperature: 0.2,
+		ResponseFormat: &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: jsonSchemaSpec{Name: "signal_validation", Schema: schema, Strict: true},
+		},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.apiKey)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("chat completion request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("chat completion returned %d: %s", resp.StatusCode, respBody[:min(len(respBody), 200)])
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return "", "", fmt.Errorf("failed to parse chat completion: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", "", fmt.Errorf("chat completion returned no choices")
+	}
+
+	modelName := completion.Model
+	if modelName == "" {
+		modelName = v.model
+	}
+	return completion.Choices[0].Message.Content, modelName, nil
+}
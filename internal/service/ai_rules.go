@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mrcrypto-go/internal/model"
+)
+
+// RuleBasedValidator is a deterministic, offline validator.
+// It re-checks the signal's own context with fixed rules, so the same signal always gets the same score.
+type RuleBasedValidator struct{}
+
+// NewRuleBasedValidator creates the rule-based validator
+func NewRuleBasedValidator() *RuleBasedValidator {
+	return &RuleBasedValidator{}
+}
+
+// Name identifies the backend in logs and on saved signals
+func (v *RuleBasedValidator) Name() string {
+	return "rules"
+}
+
+// BatchValidateSignals scores every signal with the fixed rule set
+func (v *RuleBasedValidator) BatchValidateSignals(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error) {
+	results := make([]AIValidationResult, len(signals))
+	for i, signal := range signals {
+		results[i] = v.validate(signal)
+	}
+	return results, nil
+}
+
+func (v *RuleBasedValidator) validate(signal *model.Signal) AIValidationResult {
+	ctx := signal.TechnicalContext
+	score := signal.ConfluenceScore
+	var notes []string
+
+	// Risk:Reward
+	switch {
+	case signal.RiskRewardRatio >= 3:
+		score += 3
+		notes = append(notes, fmt.Sprintf("ভালো R:R (%.1f)", signal.RiskRewardRatio))
+	case signal.RiskRewardRatio > 0 && signal.RiskRewardRatio < 2:
+		score -= 10
+		notes = append(notes, fmt.Sprintf("দুর্বল R:R (%.1f)", signal.RiskRewardRatio))
+	}
+
+	// Volume confirmation
+	if ctx.AvgVol > 0 {
+		volRatio := ctx.CurrentVol / ctx.AvgVol
+		switch {
+		case volRatio >= 1.5:
+			score += 3
+			notes = append(notes, fmt.Sprintf("ভলিউম শক্তিশালী (%.1fx)", volRatio))
+		case volRatio < 1:
+			score -= 5
+			notes = append(notes, fmt.Sprintf("ভলিউম গড়ের নিচে (%.1fx)", volRatio))
+		}
+	}
+
+	// BTC trend against the trade
+	if (signal.Type == model.SignalTypeLong && ctx.BTCCorrelation == "DOWN") ||
+		(signal.Type == model.SignalTypeShort && ctx.BTCCorrelation == "UP") {
+		score -= 5
+		notes = append(notes, "BTC ট্রেন্ড বিপরীতে")
+	}
+
+	// Trading with an extreme crowd
+	if (signal.Type == model.SignalTypeLong && ctx.FundingSentiment == "EXTREME_LONG") ||
+		(signal.Type == model.SignalTypeShort && ctx.FundingSentiment == "EXTREME_SHORT") {
+		score -= 5
+		notes = append(notes, "ফান্ডিং একদিকে অতিরিক্ত ভিড়")
+	}
+
+	// Strong 1h trend
+	if ctx.ADX1h > 30 {
+		score += 2
+		notes = append(notes, fmt.Sprintf("1h ট্রেন্ড শক্তিশালী (ADX %.0f)", ctx.ADX1h))
+	}
+
+	score = int(ClampFloat64(float64(score), 0, 100))
+	if len(notes) == 0 {
+		notes = append(notes, "কোনো অতিরিক্ত সতর্কতা নেই")
+	}
+
+	return AIValidationResult{
+		Score:      score,
+		Confidence: score,
+		Tier:       normalizeTier("", score),
+		Reason:     "নিয়মভিত্তিক যাচাই: " + strings.Join(notes, ", "),
+		Validator:  v.Name(),
+	}
+}
@@ -1,27 +1,162 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 )
 
+var binanceLog = logger.Component("binance")
+
+// Market selects which Binance market klines and order books come from
+const (
+	MarketSpot    = "spot"
+	MarketFutures = "futures" // USDT-M perpetuals
+)
+
+// BinanceService is the Binance implementation of MarketDataProvider
 type BinanceService struct {
-	baseURL string
-	client  *http.Client
+	baseURL    string // Spot REST API
+	futuresURL string // USDT-M futures REST API
+	market     string // MarketSpot or MarketFutures
+	client     *http.Client
+
+	clockMu       sync.Mutex
+	clockOffset   time.Duration // Server time minus local time
+	clockSyncedAt time.Time
 }
 
+// serverClockMaxAge is how long a measured clock offset is reused
+const serverClockMaxAge = 10 * time.Minute
+
+// NewBinanceService creates a client for the market configured in MARKET_TYPE
 func NewBinanceService() *BinanceService {
+	return NewBinanceServiceForMarket(config.AppConfig.MarketType)
+}
+
+// NewBinanceServiceForMarket creates a client for an explicit market (spot or futures)
+func NewBinanceServiceForMarket(market string) *BinanceService {
+	if market != MarketSpot {
+		market = MarketFutures
+	}
 	return &BinanceService{
-		baseURL: config.AppConfig.BinanceBaseURL,
-		client:  &http.Client{},
+		baseURL:    config.AppConfig.BinanceBaseURL,
+		futuresURL: config.AppConfig.BinanceFuturesURL,
+		market:     market,
+		client:     &http.Client{Timeout: 10 * time.Second, Transport: meteredTransport{http.DefaultTransport}},
+	}
+}
+
+// meteredTransport counts Binance requests per endpoint and status code and records the used request weight
+type meteredTransport struct {
+	next http.RoundTripper
+}
+
+func (t meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.Path
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		metrics.BinanceRequests.Inc(endpoint, "error")
+		return nil, err
+	}
+	metrics.BinanceRequests.Inc(endpoint, strconv.Itoa(resp.StatusCode))
+
+	// Spot and futures have separate weight limits
+	market := MarketSpot
+	if strings.HasPrefix(endpoint, "/fapi/") || strings.HasPrefix(endpoint, "/futures/") {
+		market = MarketFutures
+	}
+	if weight, err := strconv.ParseFloat(resp.Header.Get("X-MBX-USED-WEIGHT-1M"), 64); err == nil {
+		metrics.BinanceUsedWeight.Set(weight, market)
+	}
+	return resp, nil
+}
+
+// Exchange returns "binance"
+func (s *BinanceService) Exchange() string {
+	return ExchangeBinance
+}
+
+// Market returns the market klines and order books are fetched from
+func (s *BinanceService) Market() string {
+	return s.market
+}
+
+// endpoint returns the REST URL for a market data path on the selected market
+// (e.g. "klines" → /api/v3/klines on spot, /fapi/v1/klines on futures)
+func (s *BinanceService) endpoint(path string) string {
+	if s.market == MarketFutures {
+		return fmt.Sprintf("%s/fapi/v1/%s", s.futuresURL, path)
+	}
+	return fmt.Sprintf("%s/api/v3/%s", s.baseURL, path)
+}
+
+// Ping checks connectivity to the REST API of the selected market
+func (s *BinanceService) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint("ping"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ping request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("binance ping failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("binance API error: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// ServerTime returns Binance's current time, measuring the clock offset at most every serverClockMaxAge
+func (s *BinanceService) ServerTime() (time.Time, error) {
+	s.clockMu.Lock()
+	defer s.clockMu.Unlock()
+
+	if !s.clockSyncedAt.IsZero() && time.Since(s.clockSyncedAt) < serverClockMaxAge {
+		return time.Now().Add(s.clockOffset), nil
+	}
+
+	sent := time.Now()
+	resp, err := s.client.Get(s.endpoint("time"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch server time: %w", err)
+	}
+	defer resp.Body.Close()
+	received := time.Now()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return time.Time{}, fmt.Errorf("binance API error: %s - %s", resp.Status, string(body))
+	}
+
+	var result struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode server time: %w", err)
 	}
+
+	// Assume the server stamped the response halfway through the round trip
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	s.clockOffset = time.UnixMilli(result.ServerTime).Sub(midpoint)
+	s.clockSyncedAt = received
+	binanceLog.Info("🕐 Server clock offset", "offset", s.clockOffset)
+
+	return received.Add(s.clockOffset), nil
 }
 
 // KlineResponse represents Binance API response for klines
@@ -29,10 +164,25 @@ type KlineResponse []interface{}
 
 // GetKlines fetches candlestick data from Binance
 func (s *BinanceService) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
-	url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d",
-		s.baseURL, symbol, interval, limit)
+	url := fmt.Sprintf("%s?symbol=%s&interval=%s&limit=%d",
+		s.endpoint("klines"), symbol, interval, limit)
+
+	binanceLog.Debug("🌐 Fetching klines", "symbol", symbol, "interval", interval, "limit", limit)
+	return s.fetchKlines(url, symbol, interval)
+}
+
+// GetKlinesRange fetches up to limit candles opening at or after startTime (Unix ms)
+// Used to page through history (max limit 1000 per request)
+func (s *BinanceService) GetKlinesRange(symbol, interval string, startTime int64, limit int) ([]model.Kline, error) {
+	url := fmt.Sprintf("%s?symbol=%s&interval=%s&startTime=%d&limit=%d",
+		s.endpoint("klines"), symbol, interval, startTime, limit)
+
+	binanceLog.Debug("🌐 Fetching klines", "symbol", symbol, "interval", interval, "from", startTime, "limit", limit)
+	return s.fetchKlines(url, symbol, interval)
+}
 
-	log.Printf("🌐 [Binance API] Fetching %s klines (%s, limit: %d)...", symbol, interval, limit)
+// fetchKlines downloads and parses a klines response
+func (s *BinanceService) fetchKlines(url, symbol, interval string) ([]model.Kline, error) {
 	resp, err := s.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch klines: %w", err)
@@ -53,7 +203,7 @@ func (s *BinanceService) GetKlines(symbol, interval string, limit int) ([]model.
 	for idx, k := range klineData {
 		// Validate array length
 		if len(k) < 11 {
-			log.Printf("⚠️  [Binance API] Skipping invalid kline at index %d: insufficient fields (%d/11)", idx, len(k))
+			binanceLog.Warn("⚠️  Skipping invalid kline: insufficient fields", "symbol", symbol, "index", idx, "fields", len(k))
 			continue
 		}
 
@@ -75,19 +225,19 @@ func (s *BinanceService) GetKlines(symbol, interval string, limit int) ([]model.
 
 		// Check for parse errors
 		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
-			log.Printf("⚠️  [Binance API] Skipping kline at index %d: parse error", idx)
+			binanceLog.Warn("⚠️  Skipping kline: parse error", "symbol", symbol, "index", idx)
 			continue
 		}
 
 		// Validate prices are reasonable
 		if !ValidatePrice(open) || !ValidatePrice(high) || !ValidatePrice(low) || !ValidatePrice(closePrice) {
-			log.Printf("⚠️  [Binance API] Skipping kline at index %d: invalid price values", idx)
+			binanceLog.Warn("⚠️  Skipping kline: invalid price values", "symbol", symbol, "index", idx)
 			continue
 		}
 
 		// Validate OHLC logic: High >= Low, High >= Open/Close, Low <= Open/Close
 		if high < low || high < open || high < closePrice || low > open || low > closePrice {
-			log.Printf("⚠️  [Binance API] Skipping kline at index %d: invalid OHLC relationship", idx)
+			binanceLog.Warn("⚠️  Skipping kline: invalid OHLC relationship", "symbol", symbol, "index", idx)
 			continue
 		}
 
@@ -106,7 +256,7 @@ func (s *BinanceService) GetKlines(symbol, interval string, limit int) ([]model.
 		return nil, fmt.Errorf("no valid klines after parsing")
 	}
 
-	log.Printf("✅ [Binance API] Successfully fetched %d %s klines for %s", len(klines), interval, symbol)
+	binanceLog.Debug("✅ Fetched klines", "symbol", symbol, "interval", interval, "count", len(klines))
 	return klines, nil
 }
 
@@ -157,6 +307,8 @@ type OrderBookDepth struct {
 	AskVolume float64
 	Imbalance float64 // (Bid - Ask) / (Bid + Ask) * 100
 	Signal    string  // "Buy Pressure" / "Sell Pressure" / "Balanced"
+
+	Analysis *DepthAnalysis // Bands and walls when produced by the depth analyzer, nil otherwise
 }
 
 // DepthResponse represents Binance order book depth response
@@ -165,10 +317,10 @@ type DepthResponse struct {
 	Asks [][]interface{} `json:"asks"`
 }
 
-// GetOrderBookDepth fetches and analyzes order book depth
+// GetOrderBook fetches the raw order book
 // limit: 100 for detailed analysis, 500 for comprehensive (max allowed by Binance)
-func (s *BinanceService) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
-	url := fmt.Sprintf("%s/api/v3/depth?symbol=%s&limit=%d", s.baseURL, symbol, limit)
+func (s *BinanceService) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	url := fmt.Sprintf("%s?symbol=%s&limit=%d", s.endpoint("depth"), symbol, limit)
 
 	resp, err := s.client.Get(url)
 	if err != nil {
@@ -186,26 +338,42 @@ func (s *BinanceService) GetOrderBookDepth(symbol string, limit int) (*OrderBook
 		return nil, fmt.Errorf("failed to decode depth: %w", err)
 	}
 
-	// Calculate total bid and ask volume
-	bidVolume := 0.0
-	askVolume := 0.0
+	return &OrderBook{
+		Exchange: ExchangeBinance,
+		Bids:     parseLevels(depthRows(depthData.Bids), 1),
+		Asks:     parseLevels(depthRows(depthData.Asks), 1),
+	}, nil
+}
 
-	for _, bid := range depthData.Bids {
-		if len(bid) >= 2 {
-			qty := SafeTypeAssertString(bid[1], "0")
-			vol, _ := strconv.ParseFloat(qty, 64)
-			bidVolume += vol
+// depthRows converts Binance [price, qty] levels to strings
+func depthRows(levels [][]interface{}) [][]string {
+	rows := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			rows = append(rows, []string{SafeTypeAssertString(level[0], "0"), SafeTypeAssertString(level[1], "0")})
 		}
 	}
+	return rows
+}
 
-	for _, ask := range depthData.Asks {
-		if len(ask) >= 2 {
-			qty := SafeTypeAssertString(ask[1], "0")
-			vol, _ := strconv.ParseFloat(qty, 64)
-			askVolume += vol
-		}
+// GetOrderBookDepth fetches and analyzes order book depth (total volume of every level)
+func (s *BinanceService) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
+	book, err := s.GetOrderBook(symbol, limit)
+	if err != nil {
+		return nil, err
 	}
 
+	bidVolume, askVolume := book.Volumes()
+	depth := AnalyzeOrderBook(bidVolume, askVolume)
+
+	binanceLog.Debug("📚 Order book", "symbol", symbol, "bid_volume", bidVolume, "ask_volume", askVolume,
+		"imbalance_pct", depth.Imbalance, "signal", depth.Signal)
+
+	return depth, nil
+}
+
+// AnalyzeOrderBook classifies bid/ask volume into an imbalance signal
+func AnalyzeOrderBook(bidVolume, askVolume float64) *OrderBookDepth {
 	// Calculate imbalance
 	totalVolume := bidVolume + askVolume
 	imbalance := 0.0
@@ -225,15 +393,12 @@ func (s *BinanceService) GetOrderBookDepth(symbol string, limit int) (*OrderBook
 		}
 	}
 
-	log.Printf("📚 [Order Book] %s - Bid: %.2f | Ask: %.2f | Imbalance: %.1f%% (%s)",
-		symbol, bidVolume, askVolume, imbalance, signal)
-
 	return &OrderBookDepth{
 		BidVolume: bidVolume,
 		AskVolume: askVolume,
 		Imbalance: imbalance,
 		Signal:    signal,
-	}, nil
+	}
 }
 
 // ========================================
@@ -282,14 +447,75 @@ func (s *BinanceService) GetSpotPrice(symbol string) (float64, error) {
 	return price, nil
 }
 
-// GetPerpSpotDivergence calculates perpetual vs spot price divergence
-func (s *BinanceService) GetPerpSpotDivergence(perpSymbol string, perpPrice float64) (*PerpSpotDivergence, error) {
-	// Convert BTCUSDT (perp) to BTCUSDT (spot) - same symbol for Binance
-	spotPrice, err := s.GetSpotPrice(perpSymbol)
+// PremiumIndex is the USDT-M futures mark/index snapshot from /fapi/v1/premiumIndex
+type PremiumIndex struct {
+	Symbol          string
+	MarkPrice       float64
+	IndexPrice      float64 // Weighted spot price across exchanges
+	LastFundingRate float64 // In % (0.01 = 0.01%)
+	NextFundingTime time.Time
+}
+
+// premiumIndexResponse represents Binance premium index response
+type premiumIndexResponse struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	LastFundingRate string `json:"lastFundingRate"`
+	NextFundingTime int64  `json:"nextFundingTime"`
+}
+
+// GetPremiumIndex fetches mark price, index price and the current funding rate (always futures)
+func (s *BinanceService) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	url := fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", s.futuresURL, symbol)
+
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch premium index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("binance API error: %s - %s", resp.Status, string(body))
+	}
+
+	var data premiumIndexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode premium index: %w", err)
+	}
+
+	markPrice, err1 := strconv.ParseFloat(data.MarkPrice, 64)
+	indexPrice, err2 := strconv.ParseFloat(data.IndexPrice, 64)
+	fundingRate, err3 := strconv.ParseFloat(data.LastFundingRate, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("failed to parse premium index for %s", symbol)
+	}
+
+	return &PremiumIndex{
+		Symbol:          symbol,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		LastFundingRate: fundingRate * 100,
+		NextFundingTime: time.UnixMilli(data.NextFundingTime),
+	}, nil
+}
+
+// GetPerpSpotDivergence calculates the perpetual basis: futures mark price vs spot index price
+func (s *BinanceService) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	index, err := s.GetPremiumIndex(symbol)
 	if err != nil {
 		return nil, err
 	}
+	if index.IndexPrice <= 0 {
+		return nil, fmt.Errorf("invalid index price for %s", symbol)
+	}
+
+	return AnalyzePerpSpotDivergence(symbol, index.MarkPrice, index.IndexPrice), nil
+}
 
+// AnalyzePerpSpotDivergence computes the perp premium/discount and its sentiment
+func AnalyzePerpSpotDivergence(perpSymbol string, perpPrice, spotPrice float64) *PerpSpotDivergence {
 	// Calculate premium/discount
 	premium := ((perpPrice - spotPrice) / spotPrice) * 100
 	sentiment := "Neutral"
@@ -304,13 +530,13 @@ func (s *BinanceService) GetPerpSpotDivergence(perpSymbol string, perpPrice floa
 		sentiment = "Oversold Shorts"
 	}
 
-	log.Printf("💱 [Perp-Spot] %s - Perp: $%.2f | Spot: $%.2f | Premium: %.3f%% (%s)",
-		perpSymbol, perpPrice, spotPrice, premium, sentiment)
+	binanceLog.Debug("💱 Perp-spot premium", "symbol", perpSymbol, "perp", perpPrice, "spot", spotPrice,
+		"premium_pct", premium, "sentiment", sentiment)
 
 	return &PerpSpotDivergence{
 		PerpPrice: perpPrice,
 		SpotPrice: spotPrice,
 		Premium:   premium,
 		Sentiment: sentiment,
-	}, nil
+	}
 }
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+var streamLog = logger.Component("stream")
+
+// PriceTick summarizes streamed prices for one symbol since the previous flush.
+// High/Low keep wicks that happen between flushes visible to the monitor.
+type PriceTick struct {
+	Symbol string
+	Open   float64 // First price seen in the window
+	Price  float64 // Latest price
+	High   float64 // Highest price seen in the window
+	Low    float64 // Lowest price seen in the window
+	Time   time.Time
+}
+
+// StreamIntervals are the kline intervals kept live by BinanceStream
+var StreamIntervals = []string{"5m", "15m", "1h", "4h"}
+
+const (
+	streamMinBackoff  = 1 * time.Second
+	streamMaxBackoff  = 60 * time.Second
+	streamReadTimeout = 5 * time.Minute // Binance pings every 3 minutes
+)
+
+// BinanceStream keeps live candles and prices from Binance combined streams:
+// kline_<interval> and bookTicker on the traded market, markPrice on USDT-M futures.
+// Candles are merged into the KlineCache; prices are batched and pushed to OnPriceTicks;
+// mark/index prices are served to the KlineCache as a PremiumSource (perp/spot divergence).
+type BinanceStream struct {
+	marketURL  string // Traded market, e.g. wss://fstream.binance.com/stream (futures) or wss://stream.binance.com:9443/stream (spot)
+	futuresURL string // Mark price source, e.g. wss://fstream.binance.com/stream
+	cache      *KlineCache
+	dialer     *websocket.Dialer
+	flushEvery time.Duration
+	minBackoff time.Duration // First reconnect delay, doubled per failure up to streamMaxBackoff
+
+	mu       sync.Mutex
+	symbols  []string
+	changed  chan struct{} // Closed when the symbol list changes
+	onTicks  func([]PriceTick)
+	pending  map[string]*PriceTick
+	premiums map[string]streamedPremium
+}
+
+// streamedPremium is the latest markPrice event of a symbol
+type streamedPremium struct {
+	index      PremiumIndex
+	receivedAt time.Time
+}
+
+// streamPremiumMaxAge is how long a streamed mark/index price stands in for a REST request
+// (markPrice@1s updates every second while connected)
+const streamPremiumMaxAge = 10 * time.Second
+
+// NewBinanceStream creates a stream client. URLs point at the combined-stream endpoint
+// so a local websocket stand-in can be used instead of Binance.
+func NewBinanceStream(marketURL, futuresURL string, cache *KlineCache) *BinanceStream {
+	return &BinanceStream{
+		marketURL:  marketURL,
+		futuresURL: futuresURL,
+		cache:      cache,
+		dialer:     websocket.DefaultDialer,
+		flushEvery: 2 * time.Second,
+		minBackoff: streamMinBackoff,
+		changed:    make(chan struct{}),
+		pending:    make(map[string]*PriceTick),
+		premiums:   make(map[string]streamedPremium),
+	}
+}
+
+// OnPriceTicks registers the receiver of batched price ticks (e.g. SignalMonitor)
+func (s *BinanceStream) OnPriceTicks(fn func([]PriceTick)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onTicks = fn
+}
+
+// SetSymbols updates the streamed symbols; connections are re-established only on change
+func (s *BinanceStream) SetSymbols(symbols []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.Join(symbols, ",") == strings.Join(s.symbols, ",") {
+		return
+	}
+
+	s.symbols = append([]string(nil), symbols...)
+	close(s.changed)
+	s.changed = make(chan struct{})
+	streamLog.Info("📡 Symbol list updated", "symbols", len(symbols))
+}
+
+// PremiumIndex returns the latest streamed mark/index prices, if received in the last few seconds
+func (s *BinanceStream) PremiumIndex(symbol string) (*PremiumIndex, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	premium, ok := s.premiums[symbol]
+	if !ok || time.Since(premium.receivedAt) > streamPremiumMaxAge {
+		return nil, false
+	}
+	index := premium.index
+	return &index, true
+}
+
+// Run connects to the spot and futures streams and blocks until ctx is cancelled
+func (s *BinanceStream) Run(ctx context.Context) {
+	streamLog.Info("📡 Starting Binance market data streams...")
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		s.runConnection(ctx, "market", s.marketURL, s.marketStreams, s.handleMarketMessage)
+	}()
+	go func() {
+		defer wg.Done()
+		s.runConnection(ctx, "markPrice", s.futuresURL, s.markPriceStreams, s.handleMarkPriceMessage)
+	}()
+	go func() {
+		defer wg.Done()
+		s.flushLoop(ctx)
+	}()
+	wg.Wait()
+
+	streamLog.Info("📡 Stopped")
+}
+
+// marketStreams lists kline and bookTicker streams for the current symbols
+func (s *BinanceStream) marketStreams(symbols []string) []string {
+	var streams []string
+	for _, symbol := range symbols {
+		lower := strings.ToLower(symbol)
+		for _, interval := range StreamIntervals {
+			streams = append(streams, fmt.Sprintf("%s@kline_%s", lower, interval))
+		}
+		streams = append(streams, lower+"@bookTicker")
+	}
+	return streams
+}
+
+// markPriceStreams lists markPrice streams for the current symbols
+func (s *BinanceStream) markPriceStreams(symbols []string) []string {
+	var streams []string
+	for _, symbol := range symbols {
+		streams = append(streams, strings.ToLower(symbol)+"@markPrice@1s")
+	}
+	return streams
+}
+
+// runConnection keeps one combined-stream connection alive, reconnecting with exponential backoff
+func (s *BinanceStream) runConnection(ctx context.Context, name, baseURL string,
+	streamsFor func([]string) []string, handle func(streamMessage)) {
+
+	backoff := s.minBackoff
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		symbols := s.symbols
+		changed := s.changed
+		s.mu.Unlock()
+
+		streams := streamsFor(symbols)
+		if len(streams) == 0 || baseURL == "" {
+			// Nothing to subscribe yet - wait for symbols
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
+		}
+
+		url := baseURL + "?streams=" + strings.Join(streams, "/")
+		conn, _, err := s.dialer.DialContext(ctx, url, nil)
+		if err != nil {
+			streamLog.Warn("⚠️  Connect failed", "connection", name, "error", err, "retry_in", backoff)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		streamLog.Info("✅ Connected", "connection", name, "streams", len(streams))
+		connectedAt := time.Now()
+
+		// Candles may have been missed while disconnected - let the cache resync via REST
+		if name == "market" {
+			s.resync(symbols)
+		}
+
+		err = s.readLoop(ctx, conn, changed, handle)
+		conn.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			streamLog.Warn("⚠️  Disconnected", "connection", name, "error", err)
+		}
+
+		// A connection that lived for a while resets the backoff
+		if time.Since(connectedAt) > streamMaxBackoff {
+			backoff = s.minBackoff
+		}
+		select {
+		case <-changed:
+			continue // Resubscribe immediately with the new symbol list
+		default:
+		}
+		if !sleepContext(ctx, backoff) {
+			return
+		}
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// streamMessage is the combined-stream envelope: {"stream": "...", "data": {...}}
+type streamMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// readLoop reads messages until the connection fails, the symbol list changes or ctx ends
+func (s *BinanceStream) readLoop(ctx context.Context, conn *websocket.Conn, changed chan struct{}, handle func(streamMessage)) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	// Unblock ReadMessage when we need to stop
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-done:
+			return
+		}
+		conn.Close()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+	conn.SetPingHandler(func(appData string) error {
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(10*time.Second))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-changed:
+				return nil
+			default:
+				return err
+			}
+		}
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+
+		var msg streamMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Stream == "" {
+			continue // Subscription acks and unknown payloads
+		}
+		handle(msg)
+	}
+}
+
+// handleMarketMessage processes kline and bookTicker events (same payload on spot and futures)
+func (s *BinanceStream) handleMarketMessage(msg streamMessage) {
+	switch {
+	case strings.Contains(msg.Stream, "@kline_"):
+		var event struct {
+			Symbol string `json:"s"`
+			Kline  struct {
+				OpenTime  int64  `json:"t"`
+				CloseTime int64  `json:"T"`
+				Interval  string `json:"i"`
+				Open      string `json:"o"`
+				Close     string `json:"c"`
+				High      string `json:"h"`
+				Low       string `json:"l"`
+				Volume    string `json:"v"`
+			} `json:"k"`
+		}
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			return
+		}
+
+		k := event.Kline
+		values, ok := parseFloats(k.Open, k.High, k.Low, k.Close, k.Volume)
+		if !ok || !ValidatePrice(values[3]) {
+			streamLog.Debug("Skipping malformed kline", "stream", msg.Stream)
+			return // A zero-priced candle would corrupt the cache
+		}
+		open, high, low, closePrice, volume := values[0], values[1], values[2], values[3], values[4]
+
+		if s.cache != nil {
+			s.cache.ApplyKline(event.Symbol, k.Interval, model.Kline{
+				OpenTime:  k.OpenTime,
+				Open:      open,
+				High:      high,
+				Low:       low,
+				Close:     closePrice,
+				Volume:    volume,
+				CloseTime: k.CloseTime,
+			})
+		}
+		s.recordPrice(event.Symbol, closePrice)
+
+	case strings.HasSuffix(msg.Stream, "@bookTicker"):
+		var event struct {
+			Symbol string `json:"s"`
+			Bid    string `json:"b"`
+			Ask    string `json:"a"`
+		}
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			return
+		}
+		bid, _ := strconv.ParseFloat(event.Bid, 64)
+		ask, _ := strconv.ParseFloat(event.Ask, 64)
+		if bid > 0 && ask > 0 {
+			s.recordPrice(event.Symbol, (bid+ask)/2)
+		}
+	}
+}
+
+// handleMarkPriceMessage processes markPrice events
+func (s *BinanceStream) handleMarkPriceMessage(msg streamMessage) {
+	if !strings.Contains(msg.Stream, "@markPrice") {
+		return
+	}
+
+	var event struct {
+		Symbol          string `json:"s"`
+		MarkPrice       string `json:"p"`
+		IndexPrice      string `json:"i"`
+		FundingRate     string `json:"r"`
+		NextFundingTime int64  `json:"T"`
+	}
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		return
+	}
+
+	values, ok := parseFloats(event.MarkPrice, event.IndexPrice, event.FundingRate)
+	if !ok || !ValidatePrice(values[0]) || !ValidatePrice(values[1]) {
+		streamLog.Debug("Skipping malformed mark price", "symbol", event.Symbol)
+		return
+	}
+
+	s.mu.Lock()
+	s.premiums[event.Symbol] = streamedPremium{
+		index: PremiumIndex{
+			Symbol:          event.Symbol,
+			MarkPrice:       values[0],
+			IndexPrice:      values[1],
+			LastFundingRate: values[2] * 100,
+			NextFundingTime: time.UnixMilli(event.NextFundingTime),
+		},
+		receivedAt: time.Now(),
+	}
+	s.mu.Unlock()
+}
+
+// recordPrice folds a price into the pending tick for the symbol
+func (s *BinanceStream) recordPrice(symbol string, price float64) {
+	if price <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tick, ok := s.pending[symbol]
+	if !ok {
+		s.pending[symbol] = &PriceTick{Symbol: symbol, Open: price, Price: price, High: price, Low: price, Time: time.Now()}
+		return
+	}
+	tick.Price = price
+	tick.High = math.Max(tick.High, price)
+	tick.Low = math.Min(tick.Low, price)
+	tick.Time = time.Now()
+}
+
+// flushLoop hands batched ticks to the receiver every flushEvery
+func (s *BinanceStream) flushLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.flushEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush hands the ticks pending since the previous flush to the receiver
+func (s *BinanceStream) flush() {
+	s.mu.Lock()
+	fn := s.onTicks
+	ticks := make([]PriceTick, 0, len(s.pending))
+	for _, tick := range s.pending {
+		ticks = append(ticks, *tick)
+	}
+	s.pending = make(map[string]*PriceTick)
+	s.mu.Unlock()
+
+	if fn != nil && len(ticks) > 0 {
+		defer RecoverAndLog("Stream tick handler")
+		fn(ticks)
+	}
+}
+
+// resync marks streamed series stale so the next scan fills any gap via REST
+func (s *BinanceStream) resync(symbols []string) {
+	if s.cache == nil {
+		return
+	}
+	for _, symbol := range symbols {
+		for _, interval := range StreamIntervals {
+			s.cache.Resync(symbol, interval)
+		}
+	}
+}
+
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > streamMaxBackoff {
+		return streamMaxBackoff
+	}
+	return next
+}
+
+// sleepContext sleeps for d, returning false if ctx ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsStandIn is a local combined-stream endpoint; every accepted connection is handed to the test
+type wsStandIn struct {
+	*httptest.Server
+	conns chan *websocket.Conn
+	urls  chan *url.URL
+}
+
+func newWSStandIn(t *testing.T) *wsStandIn {
+	t.Helper()
+	ws := &wsStandIn{conns: make(chan *websocket.Conn, 4), urls: make(chan *url.URL, 4)}
+	upgrader := websocket.Upgrader{}
+	ws.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		ws.urls <- r.URL
+		ws.conns <- conn
+	}))
+	t.Cleanup(ws.Close)
+	return ws
+}
+
+func (ws *wsStandIn) streamURL() string {
+	return "ws" + strings.TrimPrefix(ws.URL, "http") + "/stream"
+}
+
+// receive waits for the next value of ch
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		panic("unreachable")
+	}
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// countingMarket counts the REST kline requests reaching the provider
+type countingMarket struct {
+	*MemoryMarketData
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *countingMarket) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	return m.MemoryMarketData.GetKlines(symbol, interval, limit)
+}
+
+func (m *countingMarket) klineCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// resyncPending reports whether a cached series is marked for a REST resync
+func resyncPending(cache *KlineCache, symbol, interval string) bool {
+	e := cache.entry(symbol, interval)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.checkedAt.IsZero()
+}
+
+func sendStream(t *testing.T, conn *websocket.Conn, messages ...string) {
+	t.Helper()
+	for _, msg := range messages {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+}
+
+func TestBinanceStreamReconnectResyncAndTicks(t *testing.T) {
+	forming := fixtureEnd.Truncate(5 * time.Minute)
+	market := &countingMarket{MemoryMarketData: NewMemoryMarketData()}
+	market.SetKlines("ETHUSDT", "5m", []model.Kline{
+		{OpenTime: forming.Add(-5 * time.Minute).UnixMilli(), Open: 1990, High: 2001, Low: 1985, Close: 1995, Volume: 10, CloseTime: forming.UnixMilli() - 1},
+		{OpenTime: forming.UnixMilli(), Open: 1995, High: 2000, Low: 1994, Close: 1998, Volume: 3, CloseTime: forming.Add(5*time.Minute).UnixMilli() - 1},
+	})
+	cache := NewKlineCache(market, nil, time.Hour)
+	cache.now = func() time.Time { return fixtureEnd }
+	if _, err := cache.GetKlines("ETHUSDT", "5m", 2); err != nil {
+		t.Fatalf("GetKlines: %v", err)
+	}
+
+	ws := newWSStandIn(t)
+	stream := NewBinanceStream(ws.streamURL(), "", cache) // No mark price connection
+	stream.minBackoff = 50 * time.Millisecond
+	stream.flushEvery = time.Hour // Flushed by hand below
+	ticks := make(chan []PriceTick, 1)
+	stream.OnPriceTicks(func(batch []PriceTick) { ticks <- batch })
+	stream.SetSymbols([]string{"ETHUSDT"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		receive(t, done)
+	}()
+
+	first := receive(t, ws.conns)
+	streams := receive(t, ws.urls).Query().Get("streams")
+	if streams != "ethusdt@kline_5m/ethusdt@kline_15m/ethusdt@kline_1h/ethusdt@kline_4h/ethusdt@bookTicker" {
+		t.Errorf("streams = %q", streams)
+	}
+	waitFor(t, "resync on connect", func() bool { return resyncPending(cache, "ETHUSDT", "5m") })
+	if _, err := cache.GetKlines("ETHUSDT", "5m", 2); err != nil || market.klineCalls() != 2 {
+		t.Fatalf("GetKlines after connect = %v with %d REST calls, want 2", err, market.klineCalls())
+	}
+
+	kline := func(closePrice string) string {
+		return fmt.Sprintf(`{"stream":"ethusdt@kline_5m","data":{"e":"kline","s":"ETHUSDT","k":{"t":%d,"T":%d,"i":"5m","o":"1995","c":"%s","h":"2012","l":"1994","v":"8.5"}}}`,
+			forming.UnixMilli(), forming.Add(5*time.Minute).UnixMilli()-1, closePrice)
+	}
+	sendStream(t, first,
+		`{"result":null,"id":1}`,
+		`{"stream":"ethusdt@bookTicker","data":{"s":"ETHUSDT","b":"1999","a":"2001"}}`,
+		kline("2010"),
+		kline("n/a"), // Malformed: must not reach the cache
+		`{"stream":"ethusdt@bookTicker","data":{"s":"ETHUSDT","b":"1989","a":"1991"}}`,
+		`{"stream":"ethusdt@bookTicker","data":{"s":"ETHUSDT","b":"2004","a":"2006"}}`,
+	)
+	droppedAt := time.Now()
+	first.Close()
+
+	// Reconnect after the backoff, then resync the candles missed while disconnected
+	second := receive(t, ws.conns)
+	defer second.Close()
+	if waited := time.Since(droppedAt); waited < stream.minBackoff {
+		t.Errorf("reconnected after %v, want at least the %v backoff", waited, stream.minBackoff)
+	}
+	receive(t, ws.urls)
+
+	e := cache.entry("ETHUSDT", "5m")
+	e.mu.Lock()
+	streamed := e.klines[len(e.klines)-1]
+	e.mu.Unlock()
+	if streamed.Close != 2010 || streamed.High != 2012 || streamed.Volume != 8.5 {
+		t.Errorf("forming candle = %+v, want the valid streamed update", streamed)
+	}
+
+	waitFor(t, "resync on reconnect", func() bool { return resyncPending(cache, "ETHUSDT", "5m") })
+	if _, err := cache.GetKlines("ETHUSDT", "5m", 2); err != nil || market.klineCalls() != 3 {
+		t.Errorf("GetKlines after reconnect = %v with %d REST calls, want 3", err, market.klineCalls())
+	}
+
+	// Every price since the last flush lands in one tick
+	stream.flush()
+	batch := receive(t, ticks)
+	want := PriceTick{Symbol: "ETHUSDT", Open: 2000, Price: 2005, High: 2010, Low: 1990}
+	if len(batch) != 1 {
+		t.Fatalf("ticks = %+v, want one for ETHUSDT", batch)
+	}
+	if got := batch[0]; got.Symbol != want.Symbol || got.Open != want.Open || got.Price != want.Price || got.High != want.High || got.Low != want.Low {
+		t.Errorf("tick = %+v, want %+v", got, want)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	backoff := streamMinBackoff
+	var waits []time.Duration
+	for range 8 {
+		waits = append(waits, backoff)
+		backoff = nextBackoff(backoff)
+	}
+	if waits[1] != 2*time.Second || waits[5] != 32*time.Second || waits[6] != streamMaxBackoff || waits[7] != streamMaxBackoff {
+		t.Errorf("backoffs = %v, want doubling up to %v", waits, streamMaxBackoff)
+	}
+}
+
+func TestMarkPriceFeedsPerpSpotDivergence(t *testing.T) {
+	market := NewMemoryMarketData()
+	market.SetPremiumIndex("ETHUSDT", 2000, 2000)
+	cache := NewKlineCache(market, nil, time.Hour)
+	stream := NewBinanceStream("", "", cache)
+	cache.SetPremiumSource(stream)
+
+	// Nothing streamed yet: the provider answers
+	if div, err := cache.GetPerpSpotDivergence("ETHUSDT"); err != nil || div.Premium != 0 {
+		t.Fatalf("divergence before streaming = %+v, %v, want the provider's", div, err)
+	}
+
+	mark := func(data string) streamMessage {
+		return streamMessage{Stream: "ethusdt@markPrice@1s", Data: []byte(data)}
+	}
+	stream.handleMarkPriceMessage(mark(`{"e":"markPriceUpdate","s":"ETHUSDT","p":"2012.00","i":"2000.00","r":"0.00010000","T":1759996800000}`))
+	stream.handleMarkPriceMessage(mark(`{"e":"markPriceUpdate","s":"ETHUSDT","p":"n/a","i":"2000.00","r":"0","T":0}`)) // Malformed: ignored
+
+	div, err := cache.GetPerpSpotDivergence("ETHUSDT")
+	if err != nil || !approx(div.Premium, 0.6) || div.Sentiment != "Overheated Longs" {
+		t.Errorf("divergence = %+v, %v, want +0.6%% from the stream", div, err)
+	}
+	index, err := cache.GetPremiumIndex("ETHUSDT")
+	if err != nil || index.MarkPrice != 2012 || !approx(index.LastFundingRate, 0.01) || index.NextFundingTime.UnixMilli() != 1759996800000 {
+		t.Errorf("premium index = %+v, %v", index, err)
+	}
+
+	// A stale quote falls back to the provider
+	stream.mu.Lock()
+	premium := stream.premiums["ETHUSDT"]
+	premium.receivedAt = time.Now().Add(-time.Minute)
+	stream.premiums["ETHUSDT"] = premium
+	stream.mu.Unlock()
+	if div, err := cache.GetPerpSpotDivergence("ETHUSDT"); err != nil || div.Premium != 0 {
+		t.Errorf("divergence with a stale quote = %+v, %v, want the provider's", div, err)
+	}
+}
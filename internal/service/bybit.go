@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+// bybitIntervals maps Binance interval names to Bybit v5 kline intervals
+var bybitIntervals = map[string]string{
+	"1m": "1", "3m": "3", "5m": "5", "15m": "15", "30m": "30",
+	"1h": "60", "2h": "120", "4h": "240", "6h": "360", "12h": "720", "1d": "D",
+}
+
+// BybitService is the Bybit (v5 public API) implementation of MarketDataProvider.
+// Futures means USDT linear perpetuals; symbols use the same names as Binance (BTCUSDT).
+type BybitService struct {
+	baseURL string
+	market  string // MarketSpot or MarketFutures
+	client  *http.Client
+}
+
+var _ KlineRangeProvider = (*BybitService)(nil)
+
+// NewBybitService creates a Bybit client for a market (spot or futures)
+func NewBybitService(baseURL, market string) *BybitService {
+	if market != MarketSpot {
+		market = MarketFutures
+	}
+	return &BybitService{baseURL: baseURL, market: market, client: newExchangeClient(ExchangeBybit)}
+}
+
+// Exchange returns "bybit"
+func (s *BybitService) Exchange() string {
+	return ExchangeBybit
+}
+
+// category is the v5 product category of the selected market
+func (s *BybitService) category() string {
+	if s.market == MarketSpot {
+		return "spot"
+	}
+	return "linear"
+}
+
+// bybitResponse is the v5 response envelope
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// get calls a v5 endpoint and decodes its result into v
+func (s *BybitService) get(path string, params url.Values, v any) error {
+	return s.getContext(context.Background(), path, params, v)
+}
+
+func (s *BybitService) getContext(ctx context.Context, path string, params url.Values, v any) error {
+	var resp bybitResponse
+	if err := getJSON(ctx, s.client, ExchangeBybit, s.baseURL+path+"?"+params.Encode(), &resp); err != nil {
+		return err
+	}
+	if resp.RetCode != 0 {
+		return fmt.Errorf("bybit API error %d: %s", resp.RetCode, resp.RetMsg)
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		return fmt.Errorf("failed to decode bybit %s: %w", path, err)
+	}
+	return nil
+}
+
+// Ping checks connectivity to the REST API
+func (s *BybitService) Ping(ctx context.Context) error {
+	var result struct {
+		TimeSecond string `json:"timeSecond"`
+	}
+	return s.getContext(ctx, "/v5/market/time", url.Values{}, &result)
+}
+
+// GetKlines fetches the latest candles (max 1000)
+func (s *BybitService) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(min(limit, 1000)))
+	return s.fetchKlines(symbol, interval, params)
+}
+
+// GetKlinesRange fetches up to limit candles opening at or after startTime (Unix ms)
+func (s *BybitService) GetKlinesRange(symbol, interval string, startTime int64, limit int) ([]model.Kline, error) {
+	limit = min(limit, 1000)
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(startTime, 10))
+	params.Set("end", strconv.FormatInt(startTime+int64(limit)*IntervalDuration(interval).Milliseconds()-1, 10))
+	params.Set("limit", strconv.Itoa(limit))
+	return s.fetchKlines(symbol, interval, params)
+}
+
+func (s *BybitService) fetchKlines(symbol, interval string, params url.Values) ([]model.Kline, error) {
+	bybitInterval, ok := bybitIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("interval %s is not available on bybit", interval)
+	}
+	params.Set("category", s.category())
+	params.Set("symbol", symbol)
+	params.Set("interval", bybitInterval)
+
+	// Rows: [startTime, open, high, low, close, volume, turnover], newest first
+	var result struct {
+		List [][]string `json:"list"`
+	}
+	if err := s.get("/v5/market/kline", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch klines: %w", err)
+	}
+	return parseKlineRows(ExchangeBybit, symbol, interval, result.List, 5)
+}
+
+// GetOrderBook fetches the raw order book (max 500 levels on linear, 200 on spot)
+func (s *BybitService) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	maxLimit := 500
+	if s.market == MarketSpot {
+		maxLimit = 200
+	}
+	params := url.Values{}
+	params.Set("category", s.category())
+	params.Set("symbol", symbol)
+	params.Set("limit", strconv.Itoa(min(limit, maxLimit)))
+
+	var result struct {
+		Bids [][]string `json:"b"`
+		Asks [][]string `json:"a"`
+	}
+	if err := s.get("/v5/market/orderbook", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch depth: %w", err)
+	}
+
+	return &OrderBook{Exchange: ExchangeBybit, Bids: parseLevels(result.Bids, 1), Asks: parseLevels(result.Asks, 1)}, nil
+}
+
+// GetOrderBookDepth fetches and analyzes order book depth
+func (s *BybitService) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
+	book, err := s.GetOrderBook(symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeOrderBook(book.Volumes()), nil
+}
+
+// bybitTicker is one entry of /v5/market/tickers (perpetual fields are empty on spot)
+type bybitTicker struct {
+	Symbol          string `json:"symbol"`
+	LastPrice       string `json:"lastPrice"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingTime string `json:"nextFundingTime"`
+}
+
+func (s *BybitService) ticker(category, symbol string) (*bybitTicker, error) {
+	params := url.Values{}
+	params.Set("category", category)
+	params.Set("symbol", symbol)
+
+	var result struct {
+		List []bybitTicker `json:"list"`
+	}
+	if err := s.get("/v5/market/tickers", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch ticker: %w", err)
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("no %s ticker for %s", category, symbol)
+	}
+	return &result.List[0], nil
+}
+
+// GetSpotPrice fetches the current spot price
+func (s *BybitService) GetSpotPrice(symbol string) (float64, error) {
+	ticker, err := s.ticker("spot", symbol)
+	if err != nil {
+		return 0, err
+	}
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse spot price: %w", err)
+	}
+	return price, nil
+}
+
+// GetPremiumIndex fetches mark price, index price and the current funding rate (always linear)
+func (s *BybitService) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	ticker, err := s.ticker("linear", symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	markPrice, err1 := strconv.ParseFloat(ticker.MarkPrice, 64)
+	indexPrice, err2 := strconv.ParseFloat(ticker.IndexPrice, 64)
+	fundingRate, err3 := strconv.ParseFloat(ticker.FundingRate, 64)
+	nextFunding, err4 := strconv.ParseInt(ticker.NextFundingTime, 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return nil, fmt.Errorf("failed to parse premium index for %s", symbol)
+	}
+
+	return &PremiumIndex{
+		Symbol:          symbol,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		LastFundingRate: fundingRate * 100,
+		NextFundingTime: time.UnixMilli(nextFunding),
+	}, nil
+}
+
+// GetFundingRate returns the funding rate of the running interval (Binance reports the last settled one)
+func (s *BybitService) GetFundingRate(symbol string) (*FundingRateInfo, error) {
+	index, err := s.GetPremiumIndex(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return NewFundingRateInfo(symbol, index.LastFundingRate, index.NextFundingTime), nil
+}
+
+// GetPerpSpotDivergence calculates the perpetual basis: mark price vs spot index price
+func (s *BybitService) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	index, err := s.GetPremiumIndex(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if index.IndexPrice <= 0 {
+		return nil, fmt.Errorf("invalid index price for %s", symbol)
+	}
+	return AnalyzePerpSpotDivergence(symbol, index.MarkPrice, index.IndexPrice), nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBybitKlines(t *testing.T) {
+	server := newFixtureServer(t, "bybit")
+	bybit := NewBybitService(server.URL, MarketFutures)
+
+	klines, err := bybit.GetKlines("ETHUSDT", "5m", 3)
+	if err != nil {
+		t.Fatalf("GetKlines: %v", err)
+	}
+	assertParams(t, server.query(t, "/v5/market/kline", 0), map[string]string{
+		"category": "linear", "symbol": "ETHUSDT", "interval": "5", "limit": "3",
+	})
+
+	if len(klines) != 3 {
+		t.Fatalf("got %d klines, want 3", len(klines))
+	}
+	first, last := klines[0], klines[2]
+	if first.OpenTime != 1759996800000 || last.OpenTime != 1759997400000 {
+		t.Fatalf("klines not oldest first: %d .. %d", first.OpenTime, last.OpenTime)
+	}
+	if first.Open != 4512.64 || first.High != 4519.8 || first.Low != 4511.3 || first.Close != 4517.9 || first.Volume != 1954.66 {
+		t.Errorf("first kline = %+v", first)
+	}
+	if first.CloseTime != first.OpenTime+5*60*1000-1 {
+		t.Errorf("CloseTime = %d, want open + 5m - 1ms", first.CloseTime)
+	}
+}
+
+func TestBybitKlinesRange(t *testing.T) {
+	server := newFixtureServer(t, "bybit")
+	bybit := NewBybitService(server.URL, MarketSpot)
+
+	if _, err := bybit.GetKlinesRange("ETHUSDT", "1h", 1759996800000, 5000); err != nil {
+		t.Fatalf("GetKlinesRange: %v", err)
+	}
+	assertParams(t, server.query(t, "/v5/market/kline", 0), map[string]string{
+		"category": "spot", "interval": "60", "limit": "1000",
+		"start": "1759996800000", "end": "1763596799999", // start + 1000h - 1ms
+	})
+
+	if _, err := bybit.GetKlines("ETHUSDT", "8h", 10); err == nil {
+		t.Error("an interval Bybit does not offer should fail")
+	}
+}
+
+func TestBybitOrderBook(t *testing.T) {
+	server := newFixtureServer(t, "bybit")
+	bybit := NewBybitService(server.URL, MarketFutures)
+
+	depth, err := bybit.GetOrderBookDepth("ETHUSDT", 1000)
+	if err != nil {
+		t.Fatalf("GetOrderBookDepth: %v", err)
+	}
+	assertParams(t, server.query(t, "/v5/market/orderbook", 0), map[string]string{"category": "linear", "limit": "500"})
+
+	if !approx(depth.BidVolume, 100) || !approx(depth.AskVolume, 50) {
+		t.Errorf("volumes = %v / %v, want 100 / 50", depth.BidVolume, depth.AskVolume)
+	}
+	if depth.Signal != "Strong Buy Pressure" {
+		t.Errorf("Signal = %s", depth.Signal)
+	}
+}
+
+func TestBybitPremiumAndFunding(t *testing.T) {
+	server := newFixtureServer(t, "bybit")
+	bybit := NewBybitService(server.URL, MarketSpot)
+
+	// Perpetual data always comes from the linear category, even on a spot client
+	index, err := bybit.GetPremiumIndex("ETHUSDT")
+	if err != nil {
+		t.Fatalf("GetPremiumIndex: %v", err)
+	}
+	assertParams(t, server.query(t, "/v5/market/tickers", 0), map[string]string{"category": "linear", "symbol": "ETHUSDT"})
+	if index.MarkPrice != 4524.9 || index.IndexPrice != 4522.61 {
+		t.Errorf("mark/index = %v / %v", index.MarkPrice, index.IndexPrice)
+	}
+	if !approx(index.LastFundingRate, 0.0125) {
+		t.Errorf("LastFundingRate = %v, want 0.0125 (percent)", index.LastFundingRate)
+	}
+	if !index.NextFundingTime.Equal(time.UnixMilli(1760025600000)) {
+		t.Errorf("NextFundingTime = %v", index.NextFundingTime)
+	}
+
+	funding, err := bybit.GetFundingRate("ETHUSDT")
+	if err != nil || !approx(funding.FundingRate, 0.0125) {
+		t.Errorf("GetFundingRate = %+v, %v", funding, err)
+	}
+
+	price, err := bybit.GetSpotPrice("ETHUSDT")
+	if err != nil || price != 4524.88 {
+		t.Errorf("GetSpotPrice = %v, %v", price, err)
+	}
+	assertParams(t, server.query(t, "/v5/market/tickers", 2), map[string]string{"category": "spot"})
+
+	if err := bybit.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestBybitAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"retCode":10001,"retMsg":"params error: symbol invalid","result":{},"retExtInfo":{},"time":1759997512345}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewBybitService(server.URL, MarketFutures).GetKlines("NOPEUSDT", "5m", 10); err == nil {
+		t.Fatal("a non-zero retCode should fail")
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+)
+
+var candidateLog = logger.Component("candidates")
+
+// CandidateJournal persists setups rejected before they became signals
+type CandidateJournal interface {
+	RecordCandidate(ctx context.Context, candidate *model.Candidate) error
+}
+
+// CandidateStore is a journal whose open candidates can be tracked forward
+type CandidateStore interface {
+	CandidateJournal
+	OpenCandidates() ([]model.Candidate, error)
+	UpdateCandidate(candidate *model.Candidate) error
+}
+
+// MongoCandidateJournal stores near-miss candidates in the "candidates" collection
+type MongoCandidateJournal struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCandidateJournal creates a Mongo-backed candidate journal
+func NewMongoCandidateJournal(db *mongo.Database) *MongoCandidateJournal {
+	return &MongoCandidateJournal{collection: db.Collection("candidates")}
+}
+
+// RecordCandidate inserts a rejected setup for forward tracking.
+// While the same setup (profile, symbol, direction, stage) is still being tracked, repeats are not recorded.
+func (j *MongoCandidateJournal) RecordCandidate(ctx context.Context, candidate *model.Candidate) error {
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"profile": profileFilter(candidate.Profile),
+		"symbol":  candidate.Symbol,
+		"type":    candidate.Type,
+		"stage":   candidate.Stage,
+		"status":  bson.M{"$in": model.OpenStatuses},
+	}
+	count, err := j.collection.CountDocuments(queryCtx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to check open candidates: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	candidate.CreatedAt = time.Now()
+	if _, err := j.collection.InsertOne(queryCtx, candidate); err != nil {
+		return fmt.Errorf("failed to save candidate: %w", err)
+	}
+
+	candidateLog.InfoContext(ctx, "📓 Candidate recorded", "type", candidate.Type, "stage", candidate.Stage,
+		"score", candidate.ConfluenceScore, "reason", candidate.Reason)
+	return nil
+}
+
+// OpenCandidates returns the candidates still being tracked
+func (j *MongoCandidateJournal) OpenCandidates() ([]model.Candidate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := j.collection.Find(ctx, bson.M{"status": bson.M{"$in": model.OpenStatuses}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open candidates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []model.Candidate
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, fmt.Errorf("failed to decode open candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// ClosedCandidates returns candidates whose tracking finished since the given time (zero = all), oldest first
+func (j *MongoCandidateJournal) ClosedCandidates(since time.Time) ([]model.Candidate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": model.StatusClosed}
+	if !since.IsZero() {
+		filter["closed_at"] = bson.M{"$gte": since}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "closed_at", Value: 1}})
+
+	cursor, err := j.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch closed candidates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []model.Candidate
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, fmt.Errorf("failed to decode closed candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// UpdateCandidate saves the tracking state of a candidate
+func (j *MongoCandidateJournal) UpdateCandidate(candidate *model.Candidate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := j.collection.ReplaceOne(ctx, bson.M{"_id": candidate.ObjectID}, candidate); err != nil {
+		return fmt.Errorf("failed to update candidate %s: %w", candidate.Symbol, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"mrcrypto-go/internal/model"
+)
+
+// candidateStages lists rejection stages in pipeline order
+var candidateStages = []string{
+	model.StageDeadZone, model.StageChoppy, model.StageNoDirection, model.StageLowScore,
+	model.StageKeyLevel, model.StageRiskReward, model.StagePatternDisabled, model.StageCooldown,
+	model.StageAINoAnswer, model.StageAIRejected, model.StageDuplicate, model.StageRiskGate,
+}
+
+// StageOutcome is what the candidates rejected at one stage would have done
+type StageOutcome struct {
+	Stage    string
+	Trades   int
+	Wins     int
+	Expired  int     // Hit neither TP nor SL within the tracking window
+	WinRate  float64 // %
+	AvgPnL   float64 // %
+	TotalPnL float64 // %
+}
+
+// CandidateReport compares rejected candidates per stage with the signals that were sent
+type CandidateReport struct {
+	Sent    StageOutcome // Baseline: resolved signals
+	ByStage []StageOutcome
+}
+
+// BuildCandidateReport groups closed candidates by rejection stage. A win is PnL > 0.
+func BuildCandidateReport(candidates []model.Candidate, sent []model.Signal) CandidateReport {
+	report := CandidateReport{Sent: StageOutcome{Stage: "SENT"}}
+	for _, signal := range sent {
+		report.Sent.add(signal.PnL, false)
+	}
+	report.Sent.finish()
+
+	byStage := make(map[string]*StageOutcome)
+	for _, candidate := range candidates {
+		if candidate.Status != model.StatusClosed {
+			continue
+		}
+		outcome, ok := byStage[candidate.Stage]
+		if !ok {
+			outcome = &StageOutcome{Stage: candidate.Stage}
+			byStage[candidate.Stage] = outcome
+		}
+		outcome.add(candidate.PnL, candidate.CloseReason == model.CloseReasonExpired)
+	}
+
+	for _, stage := range candidateStages {
+		if outcome, ok := byStage[stage]; ok {
+			outcome.finish()
+			report.ByStage = append(report.ByStage, *outcome)
+			delete(byStage, stage)
+		}
+	}
+	// Stages not known to this build (e.g. from newer records) go last
+	for _, outcome := range byStage {
+		outcome.finish()
+		report.ByStage = append(report.ByStage, *outcome)
+	}
+	return report
+}
+
+func (o *StageOutcome) add(pnl float64, expired bool) {
+	o.Trades++
+	o.TotalPnL += pnl
+	if pnl > 0 {
+		o.Wins++
+	}
+	if expired {
+		o.Expired++
+	}
+}
+
+func (o *StageOutcome) finish() {
+	if o.Trades > 0 {
+		o.WinRate = float64(o.Wins) / float64(o.Trades) * 100
+		o.AvgPnL = o.TotalPnL / float64(o.Trades)
+	}
+}
+
+// Format renders the report as a plain-text table.
+// A stage whose candidates would have made money is costing winners; one whose candidates lose is saving us.
+func (r CandidateReport) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Near-miss candidates - what rejected setups would have done\n\n")
+	fmt.Fprintf(&b, "  %-18s %7s %9s %9s %10s %8s  %s\n", "Stage", "Trades", "Win rate", "Avg PnL", "Total PnL", "Expired", "Filter")
+
+	write := func(o StageOutcome, verdict string) {
+		if o.Trades == 0 {
+			fmt.Fprintf(&b, "  %-18s %7d %9s %9s %10s %8s  %s\n", o.Stage, 0, "-", "-", "-", "-", verdict)
+			return
+		}
+		fmt.Fprintf(&b, "  %-18s %7d %8.1f%% %+8.2f%% %+9.2f%% %8d  %s\n",
+			o.Stage, o.Trades, o.WinRate, o.AvgPnL, o.TotalPnL, o.Expired, verdict)
+	}
+
+	write(r.Sent, "(baseline)")
+	for _, o := range r.ByStage {
+		verdict := "saving losers"
+		if o.AvgPnL > 0 {
+			verdict = "costing winners"
+		}
+		write(o, verdict)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+// nearMiss is a candidate rejected at stage that ended with pnl
+func nearMiss(stage, status, closeReason string, pnl float64) model.Candidate {
+	return model.Candidate{
+		Signal: model.Signal{Status: status, CloseReason: closeReason, PnL: pnl},
+		Stage:  stage,
+	}
+}
+
+func TestBuildCandidateReport(t *testing.T) {
+	closed := model.StatusClosed
+	tests := []struct {
+		name       string
+		candidates []model.Candidate
+		sent       []float64 // PnL of the resolved signals
+		wantSent   StageOutcome
+		wantStages []StageOutcome
+	}{
+		{
+			name:     "nothing resolved",
+			wantSent: StageOutcome{Stage: "SENT"},
+		},
+		{
+			name: "stages in pipeline order",
+			candidates: []model.Candidate{
+				nearMiss(model.StageLowScore, closed, "TP_HIT", 2),
+				nearMiss(model.StageCooldown, closed, "SL_HIT", -2),
+				nearMiss(model.StageLowScore, closed, "SL_HIT", -1),
+				nearMiss("FUTURE_STAGE", closed, "TP_HIT", 3), // Unknown stages go last
+				nearMiss(model.StageLowScore, closed, model.CloseReasonExpired, 0.5),
+				nearMiss(model.StageNoDirection, closed, model.CloseReasonExpired, -0.3),
+				nearMiss(model.StageCooldown, model.StatusActive, "", 5), // Still tracked: left out
+			},
+			sent:     []float64{4, -2, 0},
+			wantSent: StageOutcome{Stage: "SENT", Trades: 3, Wins: 1, WinRate: 100.0 / 3, AvgPnL: 2.0 / 3, TotalPnL: 2},
+			wantStages: []StageOutcome{
+				{Stage: model.StageNoDirection, Trades: 1, Expired: 1, AvgPnL: -0.3, TotalPnL: -0.3},
+				{Stage: model.StageLowScore, Trades: 3, Wins: 2, Expired: 1, WinRate: 200.0 / 3, AvgPnL: 0.5, TotalPnL: 1.5},
+				{Stage: model.StageCooldown, Trades: 1, AvgPnL: -2, TotalPnL: -2},
+				{Stage: "FUTURE_STAGE", Trades: 1, Wins: 1, WinRate: 100, AvgPnL: 3, TotalPnL: 3},
+			},
+		},
+		{
+			name:       "breakeven is no win",
+			candidates: []model.Candidate{nearMiss(model.StageRiskGate, closed, "BREAKEVEN_STOP", 0)},
+			wantSent:   StageOutcome{Stage: "SENT"},
+			wantStages: []StageOutcome{{Stage: model.StageRiskGate, Trades: 1}},
+		},
+	}
+
+	sameOutcome := func(a, b StageOutcome) bool {
+		return a.Stage == b.Stage && a.Trades == b.Trades && a.Wins == b.Wins && a.Expired == b.Expired &&
+			approx(a.WinRate, b.WinRate) && approx(a.AvgPnL, b.AvgPnL) && approx(a.TotalPnL, b.TotalPnL)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := make([]model.Signal, len(tt.sent))
+			for i, pnl := range tt.sent {
+				sent[i] = model.Signal{Status: closed, PnL: pnl}
+			}
+
+			report := BuildCandidateReport(tt.candidates, sent)
+			if !sameOutcome(report.Sent, tt.wantSent) {
+				t.Errorf("sent = %+v, want %+v", report.Sent, tt.wantSent)
+			}
+			if len(report.ByStage) != len(tt.wantStages) {
+				t.Fatalf("stages = %+v, want %+v", report.ByStage, tt.wantStages)
+			}
+			for i, want := range tt.wantStages {
+				if !sameOutcome(report.ByStage[i], want) {
+					t.Errorf("stage %d = %+v, want %+v", i, report.ByStage[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCandidateReportVerdicts(t *testing.T) {
+	report := BuildCandidateReport([]model.Candidate{
+		nearMiss(model.StageLowScore, model.StatusClosed, "TP_HIT", 2),
+		nearMiss(model.StageChoppy, model.StatusClosed, "SL_HIT", -1),
+	}, nil)
+
+	verdicts := map[string]string{"SENT": "(baseline)", model.StageChoppy: "saving losers", model.StageLowScore: "costing winners"}
+	for _, line := range strings.Split(report.Format(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if want, ok := verdicts[fields[0]]; ok {
+			if !strings.HasSuffix(line, want) {
+				t.Errorf("%s row = %q, want %q", fields[0], line, want)
+			}
+			delete(verdicts, fields[0])
+		}
+	}
+	if len(verdicts) != 0 {
+		t.Errorf("rows missing: %v", verdicts)
+	}
+}
@@ -3,11 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"time"
 
 	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
 	"mrcrypto-go/internal/model"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -15,6 +15,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var dbLog = logger.Component("database")
+
 type DatabaseService struct {
 	client     *mongo.Client
 	collection *mongo.Collection
@@ -36,7 +38,7 @@ func NewDatabaseService() (*DatabaseService, error) {
 
 	collection := client.Database("mrcrypto").Collection("signals")
 
-	log.Println("✅ MongoDB connected successfully")
+	dbLog.Info("✅ MongoDB connected successfully")
 
 	return &DatabaseService{
 		client:     client,
@@ -44,29 +46,38 @@ func NewDatabaseService() (*DatabaseService, error) {
 	}, nil
 }
 
-// SaveSignal saves a trading signal to the database
-func (s *DatabaseService) SaveSignal(signal *model.Signal) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// SaveSignal saves a trading signal to the database. The write is not cut short by a cancelled ctx (shutdown).
+func (s *DatabaseService) SaveSignal(ctx context.Context, signal *model.Signal) error {
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer cancel()
 
-	log.Printf("💾 [Database] Saving signal for %s...", signal.Symbol)
+	dbLog.DebugContext(ctx, "💾 Saving signal")
 	signal.CreatedAt = time.Now()
 
-	_, err := s.collection.InsertOne(ctx, signal)
+	_, err := s.collection.InsertOne(writeCtx, signal)
 	if err != nil {
 		return fmt.Errorf("failed to save signal: %w", err)
 	}
 
-	log.Printf("💾 Signal saved to database: %s %s", signal.Symbol, signal.Type)
+	dbLog.InfoContext(ctx, "💾 Signal saved", "type", signal.Type)
 	return nil
 }
 
-// GetLastSignalTime retrieves the timestamp of the last signal for a symbol
-func (s *DatabaseService) GetLastSignalTime(symbol string) (time.Time, error) {
+// profileFilter matches signals of a strategy profile.
+// Signals saved before profiles existed count as the default profile.
+func profileFilter(profile string) interface{} {
+	if profile == "" || profile == config.DefaultProfileName {
+		return bson.M{"$in": bson.A{config.DefaultProfileName, "", nil}}
+	}
+	return profile
+}
+
+// GetLastSignalTime retrieves the timestamp of the last signal for a symbol within a strategy profile
+func (s *DatabaseService) GetLastSignalTime(profile, symbol string) (time.Time, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"symbol": symbol}
+	filter := bson.M{"symbol": symbol, "profile": profileFilter(profile)}
 	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	var result model.Signal
@@ -81,12 +92,12 @@ func (s *DatabaseService) GetLastSignalTime(symbol string) (time.Time, error) {
 	return result.CreatedAt, nil
 }
 
-// CheckCooldown checks if enough time has passed since the last signal
-func (s *DatabaseService) CheckCooldown(symbol string, duration time.Duration) bool {
-	log.Printf("⏳ [Database] Checking cooldown for %s...", symbol)
-	lastTime, err := s.GetLastSignalTime(symbol)
+// CheckCooldown checks if enough time has passed since the profile's last signal for a symbol
+func (s *DatabaseService) CheckCooldown(ctx context.Context, profile, symbol string, duration time.Duration) bool {
+	dbLog.DebugContext(ctx, "⏳ Checking cooldown", "profile", profile)
+	lastTime, err := s.GetLastSignalTime(profile, symbol)
 	if err != nil {
-		log.Printf("⚠️  Error checking cooldown for %s: %v", symbol, err)
+		dbLog.WarnContext(ctx, "⚠️  Error checking cooldown", "error", err)
 		return false
 	}
 
@@ -96,53 +107,61 @@ func (s *DatabaseService) CheckCooldown(symbol string, duration time.Duration) b
 
 	timeSince := time.Since(lastTime)
 	if timeSince < duration {
-		log.Printf("⏱️  Cooldown active for %s: %.1f minutes remaining",
-			symbol, (duration - timeSince).Minutes())
+		dbLog.DebugContext(ctx, "⏱️  Cooldown active", "remaining_min", math.Round((duration-timeSince).Minutes()*10)/10)
 		return true
 	}
 
 	return false
 }
 
-// CheckDuplicateActiveSignal checks if an active signal already exists for symbol+type
-// Returns TRUE if duplicate (should skip), FALSE if allowed (e.g. price difference > 1.5%)
-func (s *DatabaseService) CheckDuplicateActiveSignal(symbol string, signalType model.SignalType, newEntryPrice float64) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// CheckDuplicateActiveSignal checks if an active signal already exists for profile+symbol+type
+// Returns TRUE if duplicate (should skip), FALSE if allowed (price difference > scalingInPercent)
+func (s *DatabaseService) CheckDuplicateActiveSignal(ctx context.Context, profile, symbol string, signalType model.SignalType, newEntryPrice, scalingInPercent float64) bool {
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer cancel()
 
 	// Find the latest active signal for this symbol and type
 	filter := bson.M{
-		"symbol": symbol,
-		"type":   signalType,
-		"status": "ACTIVE",
+		"symbol":  symbol,
+		"type":    signalType,
+		"status":  bson.M{"$in": model.OpenStatuses},
+		"profile": profileFilter(profile),
 	}
 	// Sort by newest first to compare with latest entry
 	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	var existingSignal model.Signal
-	err := s.collection.FindOne(ctx, filter, opts).Decode(&existingSignal)
+	err := s.collection.FindOne(queryCtx, filter, opts).Decode(&existingSignal)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return false // No active signal found, safe to proceed
 		}
-		log.Printf("⚠️  Error checking duplicate for %s %s: %v", symbol, signalType, err)
+		dbLog.WarnContext(ctx, "⚠️  Error checking duplicate", "type", signalType, "error", err)
 		return false // Assume safe on error to avoid blocking valid signals
 	}
 
 	// Active signal exists. Check price difference.
-	// Logic: If price has moved significantly (> 1.5%), allow "Scaling In"
+	// Logic: If price has moved significantly (> 1.5% by default), allow "Scaling In"
 	priceDiff := math.Abs(existingSignal.EntryPrice - newEntryPrice)
 	percentDiff := (priceDiff / existingSignal.EntryPrice) * 100
 
-	if percentDiff > 1.5 {
-		log.Printf("✅ %s %s - Scaling In Allowed (Price diff: %.2f%% from prev entry)", symbol, signalType, percentDiff)
+	if percentDiff > scalingInPercent {
+		dbLog.InfoContext(ctx, "✅ Scaling in allowed", "type", signalType, "price_diff_pct", percentDiff)
 		return false // Not a duplicate (conceptually), allow new signal
 	}
 
-	log.Printf("⏭️  %s %s - Active signal exists at similar price (%.2f%% diff) - Duplicate prevented", symbol, signalType, percentDiff)
+	dbLog.InfoContext(ctx, "⏭️  Duplicate prevented - active signal at similar price", "type", signalType, "price_diff_pct", percentDiff)
 	return true
 }
 
+// Ping checks the MongoDB connection
+func (s *DatabaseService) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (s *DatabaseService) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -152,7 +171,7 @@ func (s *DatabaseService) Close() error {
 		return fmt.Errorf("failed to disconnect from MongoDB: %w", err)
 	}
 
-	log.Println("🔌 MongoDB connection closed")
+	dbLog.Info("🔌 MongoDB connection closed")
 	return nil
 }
 
@@ -161,12 +180,13 @@ func (s *DatabaseService) CloseAllActiveSignals(reason string) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"status": "ACTIVE"}
+	now := time.Now()
+	filter := bson.M{"status": model.StatusActive}
 	update := bson.M{
 		"$set": bson.M{
-			"status":       "CLOSED",
+			"status":       model.StatusClosed,
 			"close_reason": reason,
-			"closed_at":    time.Now(),
+			"closed_at":    now,
 		},
 	}
 
@@ -175,13 +195,142 @@ func (s *DatabaseService) CloseAllActiveSignals(reason string) (int64, error) {
 		return 0, fmt.Errorf("failed to close active signals: %w", err)
 	}
 
-	if result.ModifiedCount > 0 {
-		log.Printf("🧹 [Database] Closed %d active signals (Reason: %s)", result.ModifiedCount, reason)
+	// Partial positions keep the booked TP1 half; the remainder sits at breakeven
+	partialUpdate := bson.A{bson.M{"$set": bson.M{
+		"status":       model.StatusClosed,
+		"close_reason": reason,
+		"closed_at":    now,
+		"pnl":          bson.M{"$multiply": bson.A{"$tp1_pnl", model.TP1CloseFraction}},
+	}}}
+	partialResult, err := s.collection.UpdateMany(ctx, bson.M{"status": model.StatusPartial}, partialUpdate)
+	if err != nil {
+		return result.ModifiedCount, fmt.Errorf("failed to close partial signals: %w", err)
+	}
+
+	closed := result.ModifiedCount + partialResult.ModifiedCount
+	if closed > 0 {
+		dbLog.Info("🧹 Closed active signals", "count", closed, "reason", reason)
 	}
-	return result.ModifiedCount, nil
+	return closed, nil
+}
+
+// GetOpenSignals returns all ACTIVE and PARTIAL signals
+func (s *DatabaseService) GetOpenSignals() ([]*model.Signal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{"status": bson.M{"$in": model.OpenStatuses}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open signals: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var signals []*model.Signal
+	if err := cursor.All(ctx, &signals); err != nil {
+		return nil, fmt.Errorf("failed to decode open signals: %w", err)
+	}
+	return signals, nil
+}
+
+// GetResolvedSignals returns signals closed by TP/SL (not manual or cleanup closes), oldest close first
+func (s *DatabaseService) GetResolvedSignals() ([]model.Signal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":       model.StatusClosed,
+		"close_reason": bson.M{"$in": []string{"TP_HIT", "SL_HIT", "BREAKEVEN_STOP"}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "closed_at", Value: 1}})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch closed signals: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var signals []model.Signal
+	if err := cursor.All(ctx, &signals); err != nil {
+		return nil, fmt.Errorf("failed to decode closed signals: %w", err)
+	}
+	return signals, nil
 }
 
 // GetDB returns the MongoDB database instance
 func (s *DatabaseService) GetDB() *mongo.Database {
 	return s.client.Database("mrcrypto")
 }
+
+// SignalFilter selects signals for FindSignals; zero fields match everything
+type SignalFilter struct {
+	Statuses []string // e.g. model.OpenStatuses or StatusClosed
+	Symbol   string
+	Profile  string
+	Type     model.SignalType
+	Tier     model.SignalTier
+	Since    time.Time // Signal time (inclusive)
+	Until    time.Time // Signal time (exclusive)
+	Limit    int64
+}
+
+// FindSignals returns the signals matching the filter, newest first
+func (s *DatabaseService) FindSignals(filter SignalFilter) ([]model.Signal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.Symbol != "" {
+		query["symbol"] = filter.Symbol
+	}
+	if filter.Profile != "" {
+		query["profile"] = profileFilter(filter.Profile)
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.Tier != "" {
+		query["tier"] = filter.Tier
+	}
+	if !filter.Since.IsZero() || !filter.Until.IsZero() {
+		window := bson.M{}
+		if !filter.Since.IsZero() {
+			window["$gte"] = filter.Since
+		}
+		if !filter.Until.IsZero() {
+			window["$lt"] = filter.Until
+		}
+		query["timestamp"] = window
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signals: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	signals := []model.Signal{}
+	if err := cursor.All(ctx, &signals); err != nil {
+		return nil, fmt.Errorf("failed to decode signals: %w", err)
+	}
+	return signals, nil
+}
+
+// GetSignalByID returns the signal with the given 5-char ID (mongo.ErrNoDocuments when missing)
+func (s *DatabaseService) GetSignalByID(id string) (*model.Signal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var signal model.Signal
+	if err := s.collection.FindOne(ctx, bson.M{"id": id}).Decode(&signal); err != nil {
+		return nil, fmt.Errorf("failed to fetch signal %s: %w", id, err)
+	}
+	return &signal, nil
+}
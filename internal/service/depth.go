@@ -0,0 +1,327 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ========================================
+// ORDER BOOK SNAPSHOTS
+// ========================================
+
+// OrderBookLevel is one price level; Quantity is in the base coin
+type OrderBookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook is a raw order book snapshot, best levels first
+type OrderBook struct {
+	Exchange string
+	Bids     []OrderBookLevel
+	Asks     []OrderBookLevel
+}
+
+// Mid is the midpoint of the best bid and ask (0 when either side is empty)
+func (b *OrderBook) Mid() float64 {
+	if len(b.Bids) == 0 || len(b.Asks) == 0 {
+		return 0
+	}
+	return (b.Bids[0].Price + b.Asks[0].Price) / 2
+}
+
+// Volumes sums the quantity of every level on each side
+func (b *OrderBook) Volumes() (bidVolume, askVolume float64) {
+	for _, level := range b.Bids {
+		bidVolume += level.Quantity
+	}
+	for _, level := range b.Asks {
+		askVolume += level.Quantity
+	}
+	return bidVolume, askVolume
+}
+
+// parseLevels converts [price, quantity, ...] rows of strings, scaling quantities (e.g. contracts to coins)
+func parseLevels(rows [][]string, scale float64) []OrderBookLevel {
+	levels := make([]OrderBookLevel, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		price, err1 := strconv.ParseFloat(row[0], 64)
+		qty, err2 := strconv.ParseFloat(row[1], 64)
+		if err1 != nil || err2 != nil || !ValidatePrice(price) || qty <= 0 {
+			continue
+		}
+		levels = append(levels, OrderBookLevel{Price: price, Quantity: qty * scale})
+	}
+	return levels
+}
+
+// ========================================
+// DEPTH ANALYSIS
+// ========================================
+
+// DepthConfig tunes the depth analyzer
+type DepthConfig struct {
+	Bands        []float64     // Imbalance bands in % from mid (e.g. 0.5, 1, 2)
+	HalfLife     float64       // % from mid at which a level counts half in the weighted imbalance
+	WallMultiple float64       // A level this many times the median level size (within the widest band) is a wall
+	WallMinAge   time.Duration // How long a wall must stand before it is trusted (pulled spoofs never get there)
+}
+
+// DefaultDepthConfig returns 0.5/1/2% bands, a 1% half-life and 5x walls trusted after 3 minutes
+func DefaultDepthConfig() DepthConfig {
+	return DepthConfig{Bands: []float64{0.5, 1, 2}, HalfLife: 1, WallMultiple: 5, WallMinAge: 3 * time.Minute}
+}
+
+// wallTolerance is how far (% of price) a wall may move between polls and still be the same wall
+const wallTolerance = 0.05
+
+// DepthAnalyzer weighs order book levels by distance from mid, splits the imbalance into bands and
+// tracks large walls across polls. One analyzer is shared by every strategy profile.
+type DepthAnalyzer struct {
+	cfg DepthConfig
+
+	mu    sync.Mutex
+	walls map[string][]Wall // Symbol -> walls of the last analysis
+}
+
+// NewDepthAnalyzer creates an analyzer; bands are sorted and non-positive values dropped
+func NewDepthAnalyzer(cfg DepthConfig) *DepthAnalyzer {
+	bands := make([]float64, 0, len(cfg.Bands))
+	for _, band := range cfg.Bands {
+		if band > 0 {
+			bands = append(bands, band)
+		}
+	}
+	sort.Float64s(bands)
+	if len(bands) == 0 {
+		bands = DefaultDepthConfig().Bands
+	}
+	cfg.Bands = slices.Compact(bands)
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = DefaultDepthConfig().HalfLife
+	}
+	return &DepthAnalyzer{cfg: cfg, walls: make(map[string][]Wall)}
+}
+
+// BandImbalance is the bid/ask volume within Percent of mid
+type BandImbalance struct {
+	Percent   float64
+	BidVolume float64
+	AskVolume float64
+	Imbalance float64 // (Bid - Ask) / (Bid + Ask) * 100
+}
+
+// Wall is an outsized resting order
+type Wall struct {
+	Exchange   string
+	Side       string // "bid" or "ask"
+	Price      float64
+	Quantity   float64
+	Distance   float64 // % from mid
+	Multiple   float64 // Quantity / median level quantity
+	FirstSeen  time.Time
+	Age        time.Duration
+	Persistent bool // Standing for at least WallMinAge
+}
+
+// DepthAnalysis is the result of one analysis, across one or more exchanges
+type DepthAnalysis struct {
+	Exchanges         []string
+	Mid               float64 // Mid of the first book (the symbol's own exchange)
+	WeightedBid       float64
+	WeightedAsk       float64
+	WeightedImbalance float64 // Imbalance of the distance-weighted volumes
+	Bands             []BandImbalance
+	Walls             []Wall // Largest first
+}
+
+// Analyze combines the books of one symbol (the first one is the symbol's own exchange). Books without
+// both sides are ignored; returns nil when none is usable. now drives wall persistence.
+func (a *DepthAnalyzer) Analyze(symbol string, now time.Time, books ...*OrderBook) *DepthAnalysis {
+	maxBand := a.cfg.Bands[len(a.cfg.Bands)-1]
+	analysis := &DepthAnalysis{Bands: make([]BandImbalance, len(a.cfg.Bands))}
+	for i, band := range a.cfg.Bands {
+		analysis.Bands[i].Percent = band
+	}
+
+	var walls []Wall
+	for _, book := range books {
+		if book == nil || book.Mid() <= 0 {
+			continue
+		}
+		mid := book.Mid()
+		if analysis.Mid == 0 {
+			analysis.Mid = mid
+		}
+		analysis.Exchanges = append(analysis.Exchanges, book.Exchange)
+
+		for _, side := range []struct {
+			name   string
+			levels []OrderBookLevel
+		}{{"bid", book.Bids}, {"ask", book.Asks}} {
+			var near []OrderBookLevel
+			for _, level := range side.levels {
+				distance := math.Abs(level.Price-mid) / mid * 100
+				weighted := level.Quantity * math.Pow(0.5, distance/a.cfg.HalfLife)
+				if side.name == "bid" {
+					analysis.WeightedBid += weighted
+				} else {
+					analysis.WeightedAsk += weighted
+				}
+				for i := range analysis.Bands {
+					if distance <= analysis.Bands[i].Percent {
+						if side.name == "bid" {
+							analysis.Bands[i].BidVolume += level.Quantity
+						} else {
+							analysis.Bands[i].AskVolume += level.Quantity
+						}
+					}
+				}
+				if distance <= maxBand {
+					near = append(near, level)
+				}
+			}
+			walls = append(walls, a.findWalls(book.Exchange, side.name, mid, near)...)
+		}
+	}
+	if analysis.Mid == 0 {
+		return nil
+	}
+
+	analysis.WeightedImbalance = imbalance(analysis.WeightedBid, analysis.WeightedAsk)
+	for i := range analysis.Bands {
+		analysis.Bands[i].Imbalance = imbalance(analysis.Bands[i].BidVolume, analysis.Bands[i].AskVolume)
+	}
+	analysis.Walls = a.track(symbol, now, walls)
+	return analysis
+}
+
+// findWalls returns the levels at least WallMultiple times the median size of the side
+func (a *DepthAnalyzer) findWalls(exchange, side string, mid float64, levels []OrderBookLevel) []Wall {
+	if len(levels) < 3 || a.cfg.WallMultiple <= 0 {
+		return nil
+	}
+	quantities := make([]float64, len(levels))
+	for i, level := range levels {
+		quantities[i] = level.Quantity
+	}
+	sort.Float64s(quantities)
+	median := quantities[len(quantities)/2]
+	if len(quantities)%2 == 0 {
+		median = (quantities[len(quantities)/2-1] + median) / 2
+	}
+
+	var walls []Wall
+	for _, level := range levels {
+		if level.Quantity >= a.cfg.WallMultiple*median {
+			walls = append(walls, Wall{
+				Exchange: exchange,
+				Side:     side,
+				Price:    level.Price,
+				Quantity: level.Quantity,
+				Distance: math.Abs(level.Price-mid) / mid * 100,
+				Multiple: level.Quantity / median,
+			})
+		}
+	}
+	return walls
+}
+
+// track carries FirstSeen over from walls of the previous analysis at (nearly) the same price.
+// A wall missing from a book is forgotten, so a pulled and re-placed wall starts over.
+func (a *DepthAnalyzer) track(symbol string, now time.Time, walls []Wall) []Wall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := a.walls[symbol]
+	for i := range walls {
+		w := &walls[i]
+		w.FirstSeen = now
+		for _, p := range previous {
+			if p.Exchange == w.Exchange && p.Side == w.Side && math.Abs(p.Price-w.Price)/p.Price*100 <= wallTolerance {
+				w.FirstSeen = p.FirstSeen
+				break
+			}
+		}
+		w.Age = now.Sub(w.FirstSeen)
+		w.Persistent = w.Age >= a.cfg.WallMinAge
+	}
+	sort.SliceStable(walls, func(i, j int) bool { return walls[i].Multiple > walls[j].Multiple })
+
+	if len(walls) == 0 {
+		delete(a.walls, symbol)
+	} else {
+		a.walls[symbol] = walls
+	}
+	return walls
+}
+
+// imbalance is (bid - ask) / (bid + ask) * 100, 0 without volume
+func imbalance(bidVolume, askVolume float64) float64 {
+	if bidVolume+askVolume <= 0 {
+		return 0
+	}
+	return (bidVolume - askVolume) / (bidVolume + askVolume) * 100
+}
+
+// NearestWall returns the closest persistent wall on a side ("bid" or "ask"), nil if none
+func (d *DepthAnalysis) NearestWall(side string) *Wall {
+	var nearest *Wall
+	for i := range d.Walls {
+		w := &d.Walls[i]
+		if w.Side == side && w.Persistent && (nearest == nil || w.Distance < nearest.Distance) {
+			nearest = w
+		}
+	}
+	return nearest
+}
+
+// BandSummary lists the band imbalances, e.g. "0.5%: +32.1 | 1%: +18.0 | 2%: -4.2"
+func (d *DepthAnalysis) BandSummary() string {
+	parts := make([]string, len(d.Bands))
+	for i, band := range d.Bands {
+		parts[i] = fmt.Sprintf("%g%%: %+.1f", band.Percent, band.Imbalance)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// WallSummary describes the nearest persistent wall on each side, e.g. "bid 2450.1 (-0.42%, 6.3x, 5m)"
+func (d *DepthAnalysis) WallSummary() string {
+	var parts []string
+	for _, side := range []string{"bid", "ask"} {
+		w := d.NearestWall(side)
+		if w == nil {
+			continue
+		}
+		sign := "+"
+		if side == "bid" {
+			sign = "-"
+		}
+		part := fmt.Sprintf("%s %s (%s%.2f%%, %.1fx, %.0fm)", side, FormatPrice(w.Price), sign, w.Distance, w.Multiple, w.Age.Minutes())
+		if len(d.Exchanges) > 1 {
+			part += " on " + w.Exchange
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Depth turns the analysis into the OrderBookDepth used for scoring: the imbalance is the weighted
+// one, and the signal names the persistent walls
+func (d *DepthAnalysis) Depth() *OrderBookDepth {
+	depth := AnalyzeOrderBook(d.WeightedBid, d.WeightedAsk)
+	if walls := d.WallSummary(); walls != "" {
+		depth.Signal += " | walls: " + walls
+	}
+	depth.Analysis = d
+	return depth
+}
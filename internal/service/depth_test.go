@@ -0,0 +1,136 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ladder builds a book around mid 100 with one level per 0.1% step on each side
+func ladder(exchange string, bid, ask func(step int) float64) *OrderBook {
+	book := &OrderBook{Exchange: exchange}
+	for step := 1; step <= 30; step++ {
+		offset := float64(step) * 0.1
+		book.Bids = append(book.Bids, OrderBookLevel{Price: 100 - offset, Quantity: bid(step)})
+		book.Asks = append(book.Asks, OrderBookLevel{Price: 100 + offset, Quantity: ask(step)})
+	}
+	return book
+}
+
+func flatSize(size float64) func(int) float64 {
+	return func(int) float64 { return size }
+}
+
+// withWall puts size at one step and 10 elsewhere
+func withWall(wallStep int, size float64) func(int) float64 {
+	return func(step int) float64 {
+		if step == wallStep {
+			return size
+		}
+		return 10
+	}
+}
+
+func TestDepthWeightsByDistance(t *testing.T) {
+	// Bids near mid, asks far away: equal totals, but the weighted imbalance favors bids
+	book := ladder(ExchangeBinance,
+		func(step int) float64 { return float64(31 - step) },
+		func(step int) float64 { return float64(step) },
+	)
+	bid, ask := book.Volumes()
+	if bid != ask {
+		t.Fatalf("fixture volumes %v / %v should be equal", bid, ask)
+	}
+
+	analysis := NewDepthAnalyzer(DefaultDepthConfig()).Analyze("ETHUSDT", time.Now(), book)
+	if analysis.Mid != 100 {
+		t.Errorf("Mid = %v, want 100", analysis.Mid)
+	}
+	if analysis.WeightedImbalance < 20 {
+		t.Errorf("WeightedImbalance = %v, want strongly positive", analysis.WeightedImbalance)
+	}
+
+	// 0.5% band holds steps 1-5: bids 30..26, asks 1..5
+	band := analysis.Bands[0]
+	if band.Percent != 0.5 || !approx(band.BidVolume, 140) || !approx(band.AskVolume, 15) {
+		t.Errorf("0.5%% band = %+v, want 140 / 15", band)
+	}
+	if !approx(band.Imbalance, (140.0-15)/155*100) {
+		t.Errorf("0.5%% band imbalance = %v", band.Imbalance)
+	}
+	if got := analysis.BandSummary(); !strings.HasPrefix(got, "0.5%: +80.6 | 1%: ") {
+		t.Errorf("BandSummary = %q", got)
+	}
+}
+
+func TestDepthBandsConfig(t *testing.T) {
+	analyzer := NewDepthAnalyzer(DepthConfig{Bands: []float64{2, 0.25, -1, 2}})
+	analysis := analyzer.Analyze("ETHUSDT", time.Now(), ladder(ExchangeBinance, flatSize(1), flatSize(1)))
+	if len(analysis.Bands) != 2 || analysis.Bands[0].Percent != 0.25 || analysis.Bands[1].Percent != 2 {
+		t.Errorf("bands = %+v, want 0.25%% and 2%%", analysis.Bands)
+	}
+
+	if analyzer.Analyze("ETHUSDT", time.Now(), &OrderBook{Bids: []OrderBookLevel{{Price: 99, Quantity: 1}}}) != nil {
+		t.Error("a one-sided book should give no analysis")
+	}
+}
+
+func TestDepthWallPersistence(t *testing.T) {
+	analyzer := NewDepthAnalyzer(DefaultDepthConfig())
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	book := ladder(ExchangeBinance, withWall(4, 200), flatSize(10)) // Bid wall at 99.6, 20x
+
+	first := analyzer.Analyze("ETHUSDT", start, book)
+	if len(first.Walls) != 1 {
+		t.Fatalf("walls = %+v, want the bid wall", first.Walls)
+	}
+	wall := first.Walls[0]
+	if wall.Side != "bid" || wall.Price != 99.6 || !approx(wall.Multiple, 20) || !approx(wall.Distance, 0.4) {
+		t.Errorf("wall = %+v", wall)
+	}
+	if wall.Persistent || first.NearestWall("bid") != nil {
+		t.Error("a new wall must not be trusted yet")
+	}
+
+	// Still there three minutes later: trusted
+	later := analyzer.Analyze("ETHUSDT", start.Add(3*time.Minute), book)
+	if w := later.NearestWall("bid"); w == nil || w.Age != 3*time.Minute || !w.FirstSeen.Equal(start) {
+		t.Fatalf("walls = %+v, want the bid wall persistent for 3m", later.Walls)
+	}
+	if got := later.WallSummary(); got != "bid 99.60 (-0.40%, 20.0x, 3m)" {
+		t.Errorf("WallSummary = %q", got)
+	}
+	if depth := later.Depth(); depth.Analysis != later || !strings.Contains(depth.Signal, " | walls: bid 99.60") {
+		t.Errorf("Depth = %+v", depth)
+	}
+
+	// Pulled, then placed again: the clock starts over
+	analyzer.Analyze("ETHUSDT", start.Add(4*time.Minute), ladder(ExchangeBinance, flatSize(10), flatSize(10)))
+	again := analyzer.Analyze("ETHUSDT", start.Add(5*time.Minute), book)
+	if again.Walls[0].Persistent || !again.Walls[0].FirstSeen.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("re-placed wall = %+v, want a fresh wall", again.Walls[0])
+	}
+}
+
+func TestDepthAggregatesExchanges(t *testing.T) {
+	analyzer := NewDepthAnalyzer(DefaultDepthConfig())
+	binance := ladder(ExchangeBinance, flatSize(10), flatSize(10))
+	bybit := ladder(ExchangeBybit, flatSize(10), withWall(2, 300))
+
+	now := time.Now()
+	analyzer.Analyze("ETHUSDT", now.Add(-5*time.Minute), binance, bybit)
+	analysis := analyzer.Analyze("ETHUSDT", now, binance, nil, bybit)
+
+	if strings.Join(analysis.Exchanges, ",") != "binance,bybit" {
+		t.Errorf("Exchanges = %v", analysis.Exchanges)
+	}
+	if band := analysis.Bands[0]; !approx(band.BidVolume, 100) || !approx(band.AskVolume, 390) {
+		t.Errorf("0.5%% band = %+v, want both books summed", band)
+	}
+	if analysis.WeightedImbalance >= 0 {
+		t.Errorf("WeightedImbalance = %v, want negative", analysis.WeightedImbalance)
+	}
+	if got := analysis.WallSummary(); got != "ask 100.20 (+0.20%, 30.0x, 5m) on bybit" {
+		t.Errorf("WallSummary = %q", got)
+	}
+}
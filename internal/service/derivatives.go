@@ -0,0 +1,270 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ========================================
+// FUTURES POSITIONING DATA
+// ========================================
+
+// OpenInterestPoint is the open interest at the end of one period
+type OpenInterestPoint struct {
+	Time              int64   `json:"time"`              // Unix ms
+	OpenInterest      float64 `json:"openInterest"`      // In the base coin
+	OpenInterestValue float64 `json:"openInterestValue"` // In USDT
+}
+
+// Price is the notional price implied by the point (value / contracts)
+func (p OpenInterestPoint) Price() float64 {
+	if p.OpenInterest <= 0 {
+		return 0
+	}
+	return p.OpenInterestValue / p.OpenInterest
+}
+
+// LongShortRatio is the share of accounts net long vs net short
+type LongShortRatio struct {
+	Time         int64   `json:"time"`
+	Ratio        float64 `json:"ratio"`       // LongAccount / ShortAccount
+	LongAccount  float64 `json:"longAccount"` // Fraction of accounts (0.66 = 66%)
+	ShortAccount float64 `json:"shortAccount"`
+}
+
+// TakerVolume is the market buy vs market sell volume of one period
+type TakerVolume struct {
+	Time         int64   `json:"time"`
+	BuySellRatio float64 `json:"buySellRatio"`
+	BuyVolume    float64 `json:"buyVolume"`
+	SellVolume   float64 `json:"sellVolume"`
+}
+
+// DerivativesProvider is implemented by providers with futures positioning data.
+// Periods are 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h or 1d; series are oldest first.
+type DerivativesProvider interface {
+	GetOpenInterestHist(symbol, period string, limit int) ([]OpenInterestPoint, error)
+	GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error)
+	GetTakerVolume(symbol, period string, limit int) ([]TakerVolume, error)
+}
+
+var _ DerivativesProvider = (*BinanceService)(nil)
+var _ DerivativesProvider = (*MemoryMarketData)(nil)
+var _ DerivativesProvider = (*KlineCache)(nil)
+var _ DerivativesProvider = (*ExchangeRouter)(nil)
+
+// getFuturesData fetches a /futures/data endpoint (always USDT-M futures, whatever MARKET_TYPE is)
+func (s *BinanceService) getFuturesData(path, symbol, period string, limit int, v any) error {
+	url := fmt.Sprintf("%s/futures/data/%s?symbol=%s&period=%s&limit=%d", s.futuresURL, path, symbol, period, min(limit, 500))
+
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("binance API error: %s - %s", resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// parseFloats parses every string or returns false
+func parseFloats(values ...string) ([]float64, bool) {
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, false
+		}
+		parsed[i] = v
+	}
+	return parsed, true
+}
+
+// GetOpenInterestHist fetches open interest history (max 500 periods, last 30 days)
+func (s *BinanceService) GetOpenInterestHist(symbol, period string, limit int) ([]OpenInterestPoint, error) {
+	var rows []struct {
+		SumOpenInterest      string      `json:"sumOpenInterest"`
+		SumOpenInterestValue string      `json:"sumOpenInterestValue"`
+		Timestamp            json.Number `json:"timestamp"`
+	}
+	if err := s.getFuturesData("openInterestHist", symbol, period, limit, &rows); err != nil {
+		return nil, err
+	}
+
+	points := make([]OpenInterestPoint, 0, len(rows))
+	for _, row := range rows {
+		values, ok := parseFloats(row.SumOpenInterest, row.SumOpenInterestValue)
+		timestamp, err := row.Timestamp.Int64()
+		if !ok || err != nil {
+			binanceLog.Warn("⚠️  Skipping open interest point", "symbol", symbol, "timestamp", row.Timestamp)
+			continue
+		}
+		points = append(points, OpenInterestPoint{Time: timestamp, OpenInterest: values[0], OpenInterestValue: values[1]})
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no open interest history for %s", symbol)
+	}
+	return points, nil
+}
+
+// GetLongShortRatio fetches the global long/short account ratio
+func (s *BinanceService) GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error) {
+	var rows []struct {
+		LongShortRatio string      `json:"longShortRatio"`
+		LongAccount    string      `json:"longAccount"`
+		ShortAccount   string      `json:"shortAccount"`
+		Timestamp      json.Number `json:"timestamp"`
+	}
+	if err := s.getFuturesData("globalLongShortAccountRatio", symbol, period, limit, &rows); err != nil {
+		return nil, err
+	}
+
+	ratios := make([]LongShortRatio, 0, len(rows))
+	for _, row := range rows {
+		values, ok := parseFloats(row.LongShortRatio, row.LongAccount, row.ShortAccount)
+		timestamp, err := row.Timestamp.Int64()
+		if !ok || err != nil {
+			binanceLog.Warn("⚠️  Skipping long/short ratio", "symbol", symbol, "timestamp", row.Timestamp)
+			continue
+		}
+		ratios = append(ratios, LongShortRatio{Time: timestamp, Ratio: values[0], LongAccount: values[1], ShortAccount: values[2]})
+	}
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("no long/short ratio for %s", symbol)
+	}
+	return ratios, nil
+}
+
+// GetTakerVolume fetches taker buy/sell volume
+func (s *BinanceService) GetTakerVolume(symbol, period string, limit int) ([]TakerVolume, error) {
+	var rows []struct {
+		BuySellRatio string      `json:"buySellRatio"`
+		BuyVol       string      `json:"buyVol"`
+		SellVol      string      `json:"sellVol"`
+		Timestamp    json.Number `json:"timestamp"`
+	}
+	if err := s.getFuturesData("takerlongshortRatio", symbol, period, limit, &rows); err != nil {
+		return nil, err
+	}
+
+	volumes := make([]TakerVolume, 0, len(rows))
+	for _, row := range rows {
+		values, ok := parseFloats(row.BuySellRatio, row.BuyVol, row.SellVol)
+		timestamp, err := row.Timestamp.Int64()
+		if !ok || err != nil {
+			binanceLog.Warn("⚠️  Skipping taker volume", "symbol", symbol, "timestamp", row.Timestamp)
+			continue
+		}
+		volumes = append(volumes, TakerVolume{Time: timestamp, BuySellRatio: values[0], BuyVolume: values[1], SellVolume: values[2]})
+	}
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no taker volume for %s", symbol)
+	}
+	return volumes, nil
+}
+
+// ========================================
+// OPEN INTEREST REGIMES
+// ========================================
+
+// Open interest vs price regimes
+const (
+	OIRegimeNewLongs        = "NEW_LONGS"        // OI up, price up: fresh longs drive the move
+	OIRegimeNewShorts       = "NEW_SHORTS"       // OI up, price down: fresh shorts drive the move
+	OIRegimeShortCovering   = "SHORT_COVERING"   // OI down, price up: shorts closing, little new money
+	OIRegimeLongLiquidation = "LONG_LIQUIDATION" // OI down, price down: longs closing or liquidated
+	OIRegimeNeutral         = "NEUTRAL"          // OI or price roughly flat
+)
+
+// Regime thresholds over the analysis window (in %)
+const (
+	oiChangeThreshold    = 1.0
+	oiPriceThreshold     = 0.5
+	derivativesPeriod    = "1h"
+	derivativesLookback  = 4 // Periods compared: OI and price change over the last 4 hours
+	derivativesSeriesLen = derivativesLookback + 1
+)
+
+// DerivativesContext summarizes positioning for one evaluation
+type DerivativesContext struct {
+	OIChange          float64 // % change of open interest over the window
+	PriceChange       float64 // % change of the OI-implied price over the same window
+	Regime            string  // OIRegime*
+	LongShortRatio    float64 // Latest global long/short account ratio, 0 = unavailable
+	TakerBuySellRatio float64 // Latest taker buy/sell volume ratio, 0 = unavailable
+}
+
+// ClassifyOIRegime names the regime for an open interest change and a price change (in %)
+func ClassifyOIRegime(oiChange, priceChange float64) string {
+	oiUp, oiDown := oiChange >= oiChangeThreshold, oiChange <= -oiChangeThreshold
+	priceUp, priceDown := priceChange >= oiPriceThreshold, priceChange <= -oiPriceThreshold
+	switch {
+	case oiUp && priceUp:
+		return OIRegimeNewLongs
+	case oiUp && priceDown:
+		return OIRegimeNewShorts
+	case oiDown && priceUp:
+		return OIRegimeShortCovering
+	case oiDown && priceDown:
+		return OIRegimeLongLiquidation
+	default:
+		return OIRegimeNeutral
+	}
+}
+
+// AnalyzeDerivatives compares the first and last open interest points (price implied by their value)
+// and attaches the latest ratios. Returns nil with fewer than two valid points.
+func AnalyzeDerivatives(openInterest []OpenInterestPoint, longShort []LongShortRatio, taker []TakerVolume) *DerivativesContext {
+	if len(openInterest) < 2 {
+		return nil
+	}
+	first, last := openInterest[0], openInterest[len(openInterest)-1]
+	if first.OpenInterest <= 0 || first.Price() <= 0 || last.Price() <= 0 {
+		return nil
+	}
+
+	ctx := &DerivativesContext{
+		OIChange:    (last.OpenInterest - first.OpenInterest) / first.OpenInterest * 100,
+		PriceChange: (last.Price() - first.Price()) / first.Price() * 100,
+	}
+	ctx.Regime = ClassifyOIRegime(ctx.OIChange, ctx.PriceChange)
+	if len(longShort) > 0 {
+		ctx.LongShortRatio = longShort[len(longShort)-1].Ratio
+	}
+	if len(taker) > 0 {
+		ctx.TakerBuySellRatio = taker[len(taker)-1].BuySellRatio
+	}
+	return ctx
+}
+
+// FetchDerivatives fetches the positioning series for a symbol and analyzes them.
+// Open interest is required; the ratios are optional.
+func FetchDerivatives(provider DerivativesProvider, symbol string) (*DerivativesContext, error) {
+	openInterest, err := provider.GetOpenInterestHist(symbol, derivativesPeriod, derivativesSeriesLen)
+	if err != nil {
+		return nil, err
+	}
+	longShort, err := provider.GetLongShortRatio(symbol, derivativesPeriod, 1)
+	if err != nil {
+		strategyLog.Debug("No long/short ratio", "symbol", symbol, "error", err)
+	}
+	taker, err := provider.GetTakerVolume(symbol, derivativesPeriod, 1)
+	if err != nil {
+		strategyLog.Debug("No taker volume", "symbol", symbol, "error", err)
+	}
+
+	ctx := AnalyzeDerivatives(openInterest, longShort, taker)
+	if ctx == nil {
+		return nil, fmt.Errorf("not enough open interest history for %s", symbol)
+	}
+	return ctx, nil
+}
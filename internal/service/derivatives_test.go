@@ -0,0 +1,191 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	internalmath "mrcrypto-go/internal/math"
+	"mrcrypto-go/internal/model"
+)
+
+func TestBinancePositioningEndpoints(t *testing.T) {
+	server := newFixtureServer(t, "binance")
+	// Positioning data always comes from the futures API, even for spot signals
+	binance := &BinanceService{futuresURL: server.URL, market: MarketSpot, client: server.Client()}
+
+	oi, err := binance.GetOpenInterestHist("ETHUSDT", "1h", 5)
+	if err != nil {
+		t.Fatalf("GetOpenInterestHist: %v", err)
+	}
+	assertParams(t, server.query(t, "/futures/data/openInterestHist", 0), map[string]string{
+		"symbol": "ETHUSDT", "period": "1h", "limit": "5",
+	})
+	if len(oi) != 5 || oi[0].Time != 1759982400000 || oi[4].OpenInterest != 1887000 || !approx(oi[4].Price(), 4545) {
+		t.Errorf("open interest = %+v", oi)
+	}
+
+	// Timestamps come as strings here
+	ls, err := binance.GetLongShortRatio("ETHUSDT", "1h", 1000)
+	if err != nil {
+		t.Fatalf("GetLongShortRatio: %v", err)
+	}
+	assertParams(t, server.query(t, "/futures/data/globalLongShortAccountRatio", 0), map[string]string{"limit": "500"})
+	if len(ls) != 1 || ls[0] != (LongShortRatio{Time: 1759996800000, Ratio: 2.1837, LongAccount: 0.6859, ShortAccount: 0.3141}) {
+		t.Errorf("long/short = %+v", ls)
+	}
+
+	taker, err := binance.GetTakerVolume("ETHUSDT", "1h", 1)
+	if err != nil {
+		t.Fatalf("GetTakerVolume: %v", err)
+	}
+	if len(taker) != 1 || taker[0].BuySellRatio != 1.152 || taker[0].BuyVolume != 10342.551 || taker[0].SellVolume != 8977.874 {
+		t.Errorf("taker = %+v", taker)
+	}
+
+	ctx, err := FetchDerivatives(binance, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("FetchDerivatives: %v", err)
+	}
+	if ctx.Regime != OIRegimeNewLongs || !approx(ctx.OIChange, 2) || !approx(ctx.PriceChange, 1) {
+		t.Errorf("context = %+v, want new longs at +2%% OI / +1%% price", ctx)
+	}
+	if ctx.LongShortRatio != 2.1837 || ctx.TakerBuySellRatio != 1.152 {
+		t.Errorf("ratios = %v / %v", ctx.LongShortRatio, ctx.TakerBuySellRatio)
+	}
+}
+
+func TestClassifyOIRegime(t *testing.T) {
+	tests := []struct {
+		oi, price float64
+		want      string
+	}{
+		{2, 1, OIRegimeNewLongs},
+		{2, -1, OIRegimeNewShorts},
+		{-2, 1, OIRegimeShortCovering},
+		{-2, -1, OIRegimeLongLiquidation},
+		{1, 0.5, OIRegimeNewLongs}, // Thresholds are inclusive
+		{0.9, 3, OIRegimeNeutral},
+		{5, 0.4, OIRegimeNeutral},
+	}
+	for _, tt := range tests {
+		if got := ClassifyOIRegime(tt.oi, tt.price); got != tt.want {
+			t.Errorf("ClassifyOIRegime(%v, %v) = %s, want %s", tt.oi, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeDerivatives(t *testing.T) {
+	oi := []OpenInterestPoint{
+		{Time: 0, OpenInterest: 1000, OpenInterestValue: 100000}, // Price 100
+		{Time: 1, OpenInterest: 990, OpenInterestValue: 100980},  // Price 102
+		{Time: 2, OpenInterest: 970, OpenInterestValue: 101850},  // Price 105, OI -3%
+	}
+	ctx := AnalyzeDerivatives(oi, nil, nil)
+	if ctx == nil || ctx.Regime != OIRegimeShortCovering || !approx(ctx.OIChange, -3) || !approx(ctx.PriceChange, 5) {
+		t.Fatalf("context = %+v, want short covering", ctx)
+	}
+	if ctx.LongShortRatio != 0 || ctx.TakerBuySellRatio != 0 {
+		t.Errorf("missing ratios should stay 0: %+v", ctx)
+	}
+
+	if AnalyzeDerivatives(oi[:1], nil, nil) != nil {
+		t.Error("one point should give no context")
+	}
+	if AnalyzeDerivatives([]OpenInterestPoint{{}, oi[2]}, nil, nil) != nil {
+		t.Error("a zero first point should give no context")
+	}
+}
+
+func TestFetchDerivativesFromFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "market.json")
+	fixture := `{"symbols": {"ETHUSDT": {
+		"openInterest": [
+			{"time": 0, "openInterest": 1000, "openInterestValue": 100000},
+			{"time": 1, "openInterest": 1010, "openInterestValue": 100990},
+			{"time": 2, "openInterest": 1020, "openInterestValue": 101000},
+			{"time": 3, "openInterest": 1030, "openInterestValue": 101970},
+			{"time": 4, "openInterest": 1040, "openInterestValue": 102960},
+			{"time": 5, "openInterest": 1050, "openInterestValue": 102900}
+		],
+		"takerVolume": [{"time": 5, "buySellRatio": 0.8, "buyVolume": 400, "sellVolume": 500}]
+	}}}`
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	market, err := LoadMarketFixture(path)
+	if err != nil {
+		t.Fatalf("LoadMarketFixture: %v", err)
+	}
+
+	// Only the last five points count: OI 1010 -> 1050, price 99.99 -> 98
+	ctx, err := FetchDerivatives(market, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("FetchDerivatives: %v", err)
+	}
+	if ctx.Regime != OIRegimeNewShorts || !approx(ctx.OIChange, 40.0/1010*100) {
+		t.Errorf("context = %+v, want new shorts", ctx)
+	}
+	if ctx.LongShortRatio != 0 || ctx.TakerBuySellRatio != 0.8 {
+		t.Errorf("ratios = %v / %v, want no long/short ratio", ctx.LongShortRatio, ctx.TakerBuySellRatio)
+	}
+
+	if _, err := FetchDerivatives(market, "BTCUSDT"); err == nil {
+		t.Error("a symbol without open interest should fail")
+	}
+}
+
+// positioningFactors scores neutral indicators with the given positioning and returns its rows
+func positioningFactors(direction string, derivatives *DerivativesContext) map[string]model.ScoreFactor {
+	_, factors := calculateConfluenceScore(
+		direction, model.RegimeTrendingUp,
+		50, 50, 50, 20, 20, 20, 0, 1, 0,
+		100, internalmath.PivotPoints{}, internalmath.FibonacciLevels{},
+		"NEUTRAL", false, "", false, "", 5,
+		"", "", 50, 50, "", "",
+		derivatives,
+	)
+	rows := make(map[string]model.ScoreFactor)
+	for _, f := range factors {
+		switch f.Name {
+		case "OI Regime", "Taker Buy/Sell", "Long/Short Ratio":
+			rows[f.Name] = f
+		}
+	}
+	return rows
+}
+
+func TestConfluencePositioningRows(t *testing.T) {
+	if rows := positioningFactors("LONG", nil); len(rows) != 0 {
+		t.Fatalf("rows without positioning data = %+v", rows)
+	}
+
+	tests := []struct {
+		direction string
+		ctx       DerivativesContext
+		want      map[string]int
+	}{
+		{"LONG", DerivativesContext{Regime: OIRegimeNewLongs, TakerBuySellRatio: 1.2, LongShortRatio: 1.5},
+			map[string]int{"OI Regime": 10, "Taker Buy/Sell": 5, "Long/Short Ratio": 0}},
+		{"LONG", DerivativesContext{Regime: OIRegimeLongLiquidation, LongShortRatio: 3},
+			map[string]int{"OI Regime": -10, "Long/Short Ratio": -5}},
+		{"SHORT", DerivativesContext{Regime: OIRegimeNewShorts, TakerBuySellRatio: 0.85, LongShortRatio: 0.5},
+			map[string]int{"OI Regime": 10, "Taker Buy/Sell": 5, "Long/Short Ratio": -5}},
+		{"SHORT", DerivativesContext{Regime: OIRegimeShortCovering, TakerBuySellRatio: 1.2},
+			map[string]int{"OI Regime": -10, "Taker Buy/Sell": 0}},
+		{"SHORT", DerivativesContext{Regime: OIRegimeNeutral},
+			map[string]int{"OI Regime": 0}},
+	}
+	for _, tt := range tests {
+		rows := positioningFactors(tt.direction, &tt.ctx)
+		if len(rows) != len(tt.want) {
+			t.Errorf("%s %s: rows = %+v, want %v", tt.direction, tt.ctx.Regime, rows, tt.want)
+			continue
+		}
+		for name, points := range tt.want {
+			if rows[name].Points != points {
+				t.Errorf("%s %s: %s = %d, want %d", tt.direction, tt.ctx.Regime, name, rows[name].Points, points)
+			}
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
+	"mrcrypto-go/internal/model"
+)
+
+var exchangeLog = logger.Component("exchange")
+
+// Supported exchanges; a watchlist entry without one uses the first of EXCHANGES
+const (
+	ExchangeBinance = "binance"
+	ExchangeBybit   = "bybit"
+	ExchangeOKX     = "okx"
+)
+
+// KnownExchanges lists every exchange with an adapter
+var KnownExchanges = []string{ExchangeBinance, ExchangeBybit, ExchangeOKX}
+
+// ExchangeAdapter is a MarketDataProvider for one exchange
+type ExchangeAdapter interface {
+	MarketDataProvider
+	Exchange() string
+}
+
+var _ ExchangeAdapter = (*BinanceService)(nil)
+var _ ExchangeAdapter = (*BybitService)(nil)
+var _ ExchangeAdapter = (*OKXService)(nil)
+var _ OrderBookProvider = (*BinanceService)(nil)
+var _ OrderBookProvider = (*BybitService)(nil)
+var _ OrderBookProvider = (*OKXService)(nil)
+
+// NewExchangeAdapter creates the adapter for an exchange on the market configured in MARKET_TYPE
+func NewExchangeAdapter(name string) (ExchangeAdapter, error) {
+	switch name {
+	case ExchangeBinance:
+		return NewBinanceService(), nil
+	case ExchangeBybit:
+		return NewBybitService(config.AppConfig.BybitBaseURL, config.AppConfig.MarketType), nil
+	case ExchangeOKX:
+		return NewOKXService(config.AppConfig.OKXBaseURL, config.AppConfig.MarketType), nil
+	default:
+		return nil, fmt.Errorf("unknown exchange %q (use %s)", name, strings.Join(KnownExchanges, ", "))
+	}
+}
+
+// NewExchangeAdapters creates the adapters for the enabled exchanges (EXCHANGES); the first one is the default
+func NewExchangeAdapters(names []string) (map[string]MarketDataProvider, string, error) {
+	adapters := make(map[string]MarketDataProvider, len(names))
+	defaultExchange := ""
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := adapters[name]; ok {
+			return nil, "", fmt.Errorf("exchange %s listed twice", name)
+		}
+		adapter, err := NewExchangeAdapter(name)
+		if err != nil {
+			return nil, "", err
+		}
+		adapters[name] = adapter
+		if defaultExchange == "" {
+			defaultExchange = name
+		}
+	}
+	if defaultExchange == "" {
+		return nil, "", fmt.Errorf("no exchange enabled")
+	}
+	return adapters, defaultExchange, nil
+}
+
+// ExchangeResolver returns the exchange selected for a symbol ("" = default)
+type ExchangeResolver interface {
+	ExchangeFor(symbol string) string
+}
+
+// ExchangeRouter is a MarketDataProvider that sends every request to the exchange selected
+// for its symbol, so strategy, monitors and bot commands stay exchange-agnostic
+type ExchangeRouter struct {
+	providers map[string]MarketDataProvider
+	fallback  string           // Exchange for symbols without a selection
+	resolver  ExchangeResolver // nil = everything goes to fallback
+}
+
+var _ MarketDataProvider = (*ExchangeRouter)(nil)
+var _ KlineRangeProvider = (*ExchangeRouter)(nil)
+var _ ServerClock = (*ExchangeRouter)(nil)
+var _ CrossExchangeProvider = (*ExchangeRouter)(nil)
+var _ OrderBookProvider = (*ExchangeRouter)(nil)
+var _ VenueBookProvider = (*ExchangeRouter)(nil)
+var _ ExchangeResolver = (*ExchangeRouter)(nil)
+var _ ExchangeResolver = (*SymbolManager)(nil)
+
+// NewExchangeRouter routes symbols between providers (keyed by exchange name); fallback must be one of them
+func NewExchangeRouter(providers map[string]MarketDataProvider, fallback string, resolver ExchangeResolver) *ExchangeRouter {
+	return &ExchangeRouter{providers: providers, fallback: fallback, resolver: resolver}
+}
+
+// ExchangeFor returns the exchange a symbol's requests go to
+func (r *ExchangeRouter) ExchangeFor(symbol string) string {
+	if r.resolver != nil {
+		if name := r.resolver.ExchangeFor(symbol); name != "" {
+			if _, ok := r.providers[name]; ok {
+				return name
+			}
+			exchangeLog.Debug("Exchange not enabled, using default", "symbol", symbol, "exchange", name, "default", r.fallback)
+		}
+	}
+	return r.fallback
+}
+
+func (r *ExchangeRouter) provider(symbol string) MarketDataProvider {
+	return r.providers[r.ExchangeFor(symbol)]
+}
+
+func (r *ExchangeRouter) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	return r.provider(symbol).GetKlines(symbol, interval, limit)
+}
+
+func (r *ExchangeRouter) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
+	return r.provider(symbol).GetOrderBookDepth(symbol, limit)
+}
+
+func (r *ExchangeRouter) GetSpotPrice(symbol string) (float64, error) {
+	return r.provider(symbol).GetSpotPrice(symbol)
+}
+
+func (r *ExchangeRouter) GetFundingRate(symbol string) (*FundingRateInfo, error) {
+	return r.provider(symbol).GetFundingRate(symbol)
+}
+
+func (r *ExchangeRouter) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	return r.provider(symbol).GetPremiumIndex(symbol)
+}
+
+func (r *ExchangeRouter) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	return r.provider(symbol).GetPerpSpotDivergence(symbol)
+}
+
+// GetKlinesRange pages history on the symbol's exchange when its adapter supports it
+func (r *ExchangeRouter) GetKlinesRange(symbol, interval string, startTime int64, limit int) ([]model.Kline, error) {
+	ranged, ok := r.provider(symbol).(KlineRangeProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot page kline history", r.ExchangeFor(symbol))
+	}
+	return ranged.GetKlinesRange(symbol, interval, startTime, limit)
+}
+
+// GetOrderBook fetches the raw book on the symbol's exchange when its adapter supports it
+func (r *ExchangeRouter) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	books, ok := r.provider(symbol).(OrderBookProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s has no raw order book", r.ExchangeFor(symbol))
+	}
+	return books.GetOrderBook(symbol, limit)
+}
+
+// GetOrderBooks fetches the symbol's book on every enabled exchange, its own exchange first.
+// Exchanges that do not list the symbol are skipped.
+func (r *ExchangeRouter) GetOrderBooks(symbol string, limit int) ([]*OrderBook, error) {
+	own := r.ExchangeFor(symbol)
+	names := []string{own}
+	for _, name := range r.names() {
+		if name != own {
+			names = append(names, name)
+		}
+	}
+
+	var books []*OrderBook
+	for _, name := range names {
+		provider, ok := r.providers[name].(OrderBookProvider)
+		if !ok {
+			continue
+		}
+		book, err := provider.GetOrderBook(symbol, limit)
+		if err != nil {
+			exchangeLog.Debug("No order book", "symbol", symbol, "exchange", name, "error", err)
+			continue
+		}
+		books = append(books, book)
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("no order book for %s on any exchange", symbol)
+	}
+	return books, nil
+}
+
+// names returns the enabled exchanges in sorted order
+func (r *ExchangeRouter) names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// derivatives returns the symbol's exchange when its adapter has positioning data
+func (r *ExchangeRouter) derivatives(symbol string) (DerivativesProvider, error) {
+	provider, ok := r.provider(symbol).(DerivativesProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s has no positioning data", r.ExchangeFor(symbol))
+	}
+	return provider, nil
+}
+
+func (r *ExchangeRouter) GetOpenInterestHist(symbol, period string, limit int) ([]OpenInterestPoint, error) {
+	provider, err := r.derivatives(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetOpenInterestHist(symbol, period, limit)
+}
+
+func (r *ExchangeRouter) GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error) {
+	provider, err := r.derivatives(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetLongShortRatio(symbol, period, limit)
+}
+
+func (r *ExchangeRouter) GetTakerVolume(symbol, period string, limit int) ([]TakerVolume, error) {
+	provider, err := r.derivatives(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetTakerVolume(symbol, period, limit)
+}
+
+// ServerTime is the default exchange's clock
+func (r *ExchangeRouter) ServerTime() (time.Time, error) {
+	clock, ok := r.providers[r.fallback].(ServerClock)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s has no server clock", r.fallback)
+	}
+	return clock.ServerTime()
+}
+
+// GetCrossExchangeSpread compares the symbol's perpetual on every enabled exchange.
+// Returns nil without error when fewer than two exchanges are enabled.
+func (r *ExchangeRouter) GetCrossExchangeSpread(symbol string) (*CrossExchangeSpread, error) {
+	if len(r.providers) < 2 {
+		return nil, nil
+	}
+
+	var quotes []VenueQuote
+	for _, name := range r.names() {
+		index, err := r.providers[name].GetPremiumIndex(symbol)
+		if err != nil {
+			// Not every symbol is listed everywhere
+			exchangeLog.Debug("No premium index", "symbol", symbol, "exchange", name, "error", err)
+			continue
+		}
+		if !ValidatePrice(index.MarkPrice) {
+			continue
+		}
+		quotes = append(quotes, VenueQuote{Exchange: name, MarkPrice: index.MarkPrice, FundingRate: index.LastFundingRate})
+	}
+
+	spread := AnalyzeCrossExchange(quotes)
+	if spread == nil {
+		return nil, fmt.Errorf("%s is quoted on %d exchange(s), need 2", symbol, len(quotes))
+	}
+	return spread, nil
+}
+
+// ========================================
+// CROSS-EXCHANGE SPREADS
+// ========================================
+
+// VenueQuote is one exchange's perpetual mark price and funding rate (in %)
+type VenueQuote struct {
+	Exchange    string
+	MarkPrice   float64
+	FundingRate float64
+}
+
+// CrossExchangeSpread compares a perpetual's price and funding across exchanges
+type CrossExchangeSpread struct {
+	Quotes         []VenueQuote
+	PriceSpread    float64 // (Highest - lowest mark price) / lowest * 100
+	FundingSpread  float64 // Highest minus lowest funding rate, in percentage points
+	Cheapest       string  // Exchange with the lowest mark price
+	Richest        string  // Exchange with the highest mark price
+	LowestFunding  string
+	HighestFunding string
+}
+
+// AnalyzeCrossExchange computes price and funding spreads (nil for fewer than two quotes)
+func AnalyzeCrossExchange(quotes []VenueQuote) *CrossExchangeSpread {
+	if len(quotes) < 2 {
+		return nil
+	}
+
+	spread := &CrossExchangeSpread{Quotes: quotes}
+	low, high := quotes[0], quotes[0]
+	lowFunding, highFunding := quotes[0], quotes[0]
+	for _, q := range quotes[1:] {
+		if q.MarkPrice < low.MarkPrice {
+			low = q
+		}
+		if q.MarkPrice > high.MarkPrice {
+			high = q
+		}
+		if q.FundingRate < lowFunding.FundingRate {
+			lowFunding = q
+		}
+		if q.FundingRate > highFunding.FundingRate {
+			highFunding = q
+		}
+	}
+
+	spread.PriceSpread = (high.MarkPrice - low.MarkPrice) / low.MarkPrice * 100
+	spread.FundingSpread = highFunding.FundingRate - lowFunding.FundingRate
+	spread.Cheapest, spread.Richest = low.Exchange, high.Exchange
+	spread.LowestFunding, spread.HighestFunding = lowFunding.Exchange, highFunding.Exchange
+	return spread
+}
+
+// Summary lists every quote, e.g. "binance 2500.10 (0.0100%) | bybit 2501.35 (0.0125%)"
+func (c *CrossExchangeSpread) Summary() string {
+	parts := make([]string, len(c.Quotes))
+	for i, q := range c.Quotes {
+		parts[i] = fmt.Sprintf("%s %s (%.4f%%)", q.Exchange, FormatPrice(q.MarkPrice), q.FundingRate)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ========================================
+// SHARED ADAPTER HELPERS
+// ========================================
+
+// exchangeTransport counts Bybit and OKX requests per endpoint and status code
+type exchangeTransport struct {
+	exchange string
+	next     http.RoundTripper
+}
+
+func (t exchangeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		metrics.ExchangeRequests.Inc(t.exchange, req.URL.Path, "error")
+		return nil, err
+	}
+	metrics.ExchangeRequests.Inc(t.exchange, req.URL.Path, strconv.Itoa(resp.StatusCode))
+	return resp, nil
+}
+
+func newExchangeClient(exchange string) *http.Client {
+	return &http.Client{Timeout: 10 * time.Second, Transport: exchangeTransport{exchange, http.DefaultTransport}}
+}
+
+// getJSON fetches a URL and decodes a 200 response into v
+func getJSON(ctx context.Context, client *http.Client, exchange, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", exchange, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", exchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s API error: %s - %s", exchange, resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", exchange, err)
+	}
+	return nil
+}
+
+// parseKlineRow converts a [openTime, open, high, low, close, ...] row of strings (Bybit, OKX)
+// into a kline, rejecting the same malformed candles the Binance parser skips
+func parseKlineRow(row []string, volumeIndex int, duration time.Duration) (model.Kline, error) {
+	if len(row) <= volumeIndex || len(row) < 5 {
+		return model.Kline{}, fmt.Errorf("insufficient fields: %d", len(row))
+	}
+
+	var values [6]float64
+	for i, cell := range []string{row[0], row[1], row[2], row[3], row[4], row[volumeIndex]} {
+		v, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return model.Kline{}, fmt.Errorf("parse error: %w", err)
+		}
+		values[i] = v
+	}
+	openTime, open, high, low, closePrice, volume := int64(values[0]), values[1], values[2], values[3], values[4], values[5]
+
+	if !ValidatePrice(open) || !ValidatePrice(high) || !ValidatePrice(low) || !ValidatePrice(closePrice) {
+		return model.Kline{}, fmt.Errorf("invalid price values")
+	}
+	if high < low || high < open || high < closePrice || low > open || low > closePrice {
+		return model.Kline{}, fmt.Errorf("invalid OHLC relationship")
+	}
+
+	return model.Kline{
+		OpenTime:  openTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Volume:    volume,
+		CloseTime: openTime + duration.Milliseconds() - 1,
+	}, nil
+}
+
+// parseKlineRows parses newest-first rows into oldest-first klines, skipping malformed ones
+func parseKlineRows(exchange, symbol, interval string, rows [][]string, volumeIndex int) ([]model.Kline, error) {
+	duration := IntervalDuration(interval)
+	klines := make([]model.Kline, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		k, err := parseKlineRow(rows[i], volumeIndex, duration)
+		if err != nil {
+			exchangeLog.Warn("⚠️  Skipping kline", "exchange", exchange, "symbol", symbol, "index", i, "error", err)
+			continue
+		}
+		klines = append(klines, k)
+	}
+
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no valid klines after parsing")
+	}
+	return klines, nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fixtureServer serves recorded API responses from testdata/<dir>, one file per endpoint
+// (/v5/market/kline -> v5_market_kline.json), and records the query of every request
+type fixtureServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	queries map[string][]url.Values
+}
+
+func newFixtureServer(t *testing.T, dir string) *fixtureServer {
+	t.Helper()
+	fs := &fixtureServer{queries: make(map[string][]url.Values)}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		fs.queries[r.URL.Path] = append(fs.queries[r.URL.Path], r.URL.Query())
+		fs.mu.Unlock()
+
+		name := strings.ReplaceAll(strings.TrimPrefix(r.URL.Path, "/"), "/", "_") + ".json"
+		body, err := os.ReadFile(filepath.Join("testdata", dir, name))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+// query returns the query of the n-th request to path
+func (fs *fixtureServer) query(t *testing.T, path string, n int) url.Values {
+	t.Helper()
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if len(fs.queries[path]) <= n {
+		t.Fatalf("%s requested %d time(s), want more than %d", path, len(fs.queries[path]), n)
+	}
+	return fs.queries[path][n]
+}
+
+func assertParams(t *testing.T, got url.Values, want map[string]string) {
+	t.Helper()
+	for key, value := range want {
+		if got.Get(key) != value {
+			t.Errorf("param %s = %q, want %q", key, got.Get(key), value)
+		}
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+// staticResolver assigns exchanges from a map
+type staticResolver map[string]string
+
+func (r staticResolver) ExchangeFor(symbol string) string {
+	return r[symbol]
+}
+
+func TestExchangeRouterRouting(t *testing.T) {
+	binance, bybit := NewMemoryMarketData(), NewMemoryMarketData()
+	binance.SetSpotPrice("BTCUSDT", 100)
+	binance.SetSpotPrice("SOLUSDT", 10)
+	binance.SetSpotPrice("XRPUSDT", 1)
+	bybit.SetSpotPrice("BTCUSDT", 101)
+
+	router := NewExchangeRouter(
+		map[string]MarketDataProvider{ExchangeBinance: binance, ExchangeBybit: bybit},
+		ExchangeBinance,
+		staticResolver{"BTCUSDT": ExchangeBybit, "XRPUSDT": ExchangeOKX},
+	)
+
+	tests := []struct {
+		symbol   string
+		exchange string
+		price    float64
+	}{
+		{"BTCUSDT", ExchangeBybit, 101},
+		{"SOLUSDT", ExchangeBinance, 10}, // No selection
+		{"XRPUSDT", ExchangeBinance, 1},  // Selected exchange not enabled
+	}
+	for _, tt := range tests {
+		if got := router.ExchangeFor(tt.symbol); got != tt.exchange {
+			t.Errorf("ExchangeFor(%s) = %s, want %s", tt.symbol, got, tt.exchange)
+		}
+		price, err := router.GetSpotPrice(tt.symbol)
+		if err != nil || price != tt.price {
+			t.Errorf("GetSpotPrice(%s) = %v, %v, want %v", tt.symbol, price, err, tt.price)
+		}
+	}
+
+	// Memory providers cannot page history
+	if _, err := router.GetKlinesRange("BTCUSDT", "5m", 0, 10); err == nil {
+		t.Error("GetKlinesRange on a provider without ranges should fail")
+	}
+}
+
+func TestExchangeRouterCrossExchangeSpread(t *testing.T) {
+	binance, bybit, okx := NewMemoryMarketData(), NewMemoryMarketData(), NewMemoryMarketData()
+	binance.SetPremiumIndex("ETHUSDT", 2500, 2499)
+	bybit.SetPremiumIndex("ETHUSDT", 2505, 2499)
+	okx.SetPremiumIndex("BTCUSDT", 60000, 60000) // ETHUSDT not listed
+
+	single := NewExchangeRouter(map[string]MarketDataProvider{ExchangeBinance: binance}, ExchangeBinance, nil)
+	if spread, err := single.GetCrossExchangeSpread("ETHUSDT"); spread != nil || err != nil {
+		t.Fatalf("single exchange = %v, %v, want nil, nil", spread, err)
+	}
+
+	router := NewExchangeRouter(
+		map[string]MarketDataProvider{ExchangeBinance: binance, ExchangeBybit: bybit, ExchangeOKX: okx},
+		ExchangeBinance, nil,
+	)
+	spread, err := router.GetCrossExchangeSpread("ETHUSDT")
+	if err != nil {
+		t.Fatalf("GetCrossExchangeSpread: %v", err)
+	}
+	if len(spread.Quotes) != 2 || spread.Quotes[0].Exchange != ExchangeBinance || spread.Quotes[1].Exchange != ExchangeBybit {
+		t.Fatalf("quotes = %+v, want binance then bybit", spread.Quotes)
+	}
+	if !approx(spread.PriceSpread, 0.2) || spread.Cheapest != ExchangeBinance || spread.Richest != ExchangeBybit {
+		t.Errorf("spread = %+v, want 0.2%% from binance to bybit", spread)
+	}
+
+	if _, err := router.GetCrossExchangeSpread("SOLUSDT"); err == nil {
+		t.Error("a symbol quoted nowhere should fail")
+	}
+}
+
+func TestAnalyzeCrossExchange(t *testing.T) {
+	if AnalyzeCrossExchange([]VenueQuote{{Exchange: ExchangeBinance, MarkPrice: 100}}) != nil {
+		t.Error("one quote should give no spread")
+	}
+
+	spread := AnalyzeCrossExchange([]VenueQuote{
+		{Exchange: ExchangeBinance, MarkPrice: 2500, FundingRate: 0.01},
+		{Exchange: ExchangeBybit, MarkPrice: 2510, FundingRate: 0.0125},
+		{Exchange: ExchangeOKX, MarkPrice: 2495, FundingRate: -0.005},
+	})
+	if !approx(spread.PriceSpread, 15.0/2495*100) {
+		t.Errorf("PriceSpread = %v", spread.PriceSpread)
+	}
+	if !approx(spread.FundingSpread, 0.0175) {
+		t.Errorf("FundingSpread = %v, want 0.0175", spread.FundingSpread)
+	}
+	if spread.Cheapest != ExchangeOKX || spread.Richest != ExchangeBybit ||
+		spread.LowestFunding != ExchangeOKX || spread.HighestFunding != ExchangeBybit {
+		t.Errorf("extremes = %+v", spread)
+	}
+	if got := spread.Summary(); !strings.HasPrefix(got, "binance ") || strings.Count(got, " | ") != 2 {
+		t.Errorf("Summary = %q", got)
+	}
+}
+
+func TestParseKlineRowsSkipsMalformed(t *testing.T) {
+	rows := [][]string{
+		{"1759997400000", "10", "12", "9", "11", "5"},
+		{"1759997100000", "10", "9", "11", "10", "5"}, // High below low
+		{"1759996800000", "x", "12", "9", "11", "5"},
+		{"1759996500000", "10", "12", "9"},
+		{"1759996200000", "10", "11", "9", "10.5", "7"},
+	}
+	klines, err := parseKlineRows(ExchangeBybit, "ETHUSDT", "5m", rows, 5)
+	if err != nil {
+		t.Fatalf("parseKlineRows: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1759996200000 || klines[1].OpenTime != 1759997400000 {
+		t.Fatalf("klines = %+v, want the two valid rows oldest first", klines)
+	}
+	if klines[0].Volume != 7 || klines[0].CloseTime != 1759996200000+300000-1 {
+		t.Errorf("first kline = %+v", klines[0])
+	}
+
+	if _, err := parseKlineRows(ExchangeBybit, "ETHUSDT", "5m", rows[1:4], 5); err == nil {
+		t.Error("only malformed rows should fail")
+	}
+}
+
+func TestExchangeRouterOrderBooks(t *testing.T) {
+	binance, bybit, okx := NewMemoryMarketData(), NewMemoryMarketData(), NewMemoryMarketData()
+	binance.SetOrderBookLevels("ETHUSDT", ladder(ExchangeBinance, flatSize(1), flatSize(1)))
+	bybit.SetOrderBookLevels("ETHUSDT", ladder(ExchangeBybit, flatSize(2), flatSize(2)))
+
+	router := NewExchangeRouter(
+		map[string]MarketDataProvider{ExchangeBinance: binance, ExchangeBybit: bybit, ExchangeOKX: okx},
+		ExchangeBinance,
+		staticResolver{"ETHUSDT": ExchangeBybit},
+	)
+
+	book, err := router.GetOrderBook("ETHUSDT", 500)
+	if err != nil || book.Exchange != ExchangeBybit {
+		t.Fatalf("GetOrderBook = %+v, %v, want the bybit book", book, err)
+	}
+
+	// The symbol's own exchange first; OKX does not list it
+	books, err := router.GetOrderBooks("ETHUSDT", 500)
+	if err != nil {
+		t.Fatalf("GetOrderBooks: %v", err)
+	}
+	if len(books) != 2 || books[0].Exchange != ExchangeBybit || books[1].Exchange != ExchangeBinance {
+		t.Errorf("books from %d exchanges, want bybit then binance", len(books))
+	}
+
+	if _, err := router.GetOrderBooks("SOLUSDT", 500); err == nil {
+		t.Error("a symbol without books should fail")
+	}
+}
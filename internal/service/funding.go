@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
 )
 
+var fundingLog = logger.Component("funding")
+
 // FundingRateInfo contains funding rate data
 type FundingRateInfo struct {
 	Symbol      string
@@ -28,11 +32,10 @@ type BinanceFundingResponse struct {
 }
 
 // GetFundingRate fetches current funding rate from Binance Futures API (FREE, no API key needed)
-func GetFundingRate(symbol string) (*FundingRateInfo, error) {
-	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/fundingRate?symbol=%s&limit=1", symbol)
+func (s *BinanceService) GetFundingRate(symbol string) (*FundingRateInfo, error) {
+	url := fmt.Sprintf("%s/fapi/v1/fundingRate?symbol=%s&limit=1", s.futuresURL, symbol)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := s.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch funding rate: %w", err)
 	}
@@ -64,16 +67,21 @@ func GetFundingRate(symbol string) (*FundingRateInfo, error) {
 	// Convert to percentage
 	ratePercent := rate * 100
 
+	return NewFundingRateInfo(symbol, ratePercent, time.UnixMilli(fundingData[0].FundingTime)), nil
+}
+
+// NewFundingRateInfo builds funding info (rate in %) with sentiment and risk filled in
+func NewFundingRateInfo(symbol string, ratePercent float64, nextFunding time.Time) *FundingRateInfo {
 	info := &FundingRateInfo{
 		Symbol:      symbol,
 		FundingRate: ratePercent,
-		NextFunding: time.UnixMilli(fundingData[0].FundingTime),
+		NextFunding: nextFunding,
 	}
 
 	// Analyze funding rate sentiment
 	analyzeFundingRate(info)
 
-	return info, nil
+	return info
 }
 
 // analyzeFundingRate determines sentiment and risk from funding rate
@@ -111,7 +119,7 @@ func analyzeFundingRate(info *FundingRateInfo) {
 
 // CalculateFundingScore calculates score adjustment using already-fetched funding info
 // This avoids duplicate API calls
-func CalculateFundingScore(info *FundingRateInfo, direction string) int {
+func CalculateFundingScore(info *FundingRateInfo, direction string, weights config.FundingWeights) int {
 	if info == nil {
 		return 0 // No penalty if funding info not available
 	}
@@ -119,17 +127,17 @@ func CalculateFundingScore(info *FundingRateInfo, direction string) int {
 	// Score adjustment based on funding vs trade direction
 	switch {
 	case direction == "LONG" && info.Sentiment == "EXTREME_LONG":
-		return -15 // Heavy penalty - going long when everyone is long
+		return -weights.ExtremeCrowdedPenalty // Heavy penalty - going long when everyone is long
 	case direction == "SHORT" && info.Sentiment == "EXTREME_SHORT":
-		return -15 // Heavy penalty - going short when everyone is short
+		return -weights.ExtremeCrowdedPenalty // Heavy penalty - going short when everyone is short
 	case direction == "LONG" && info.Sentiment == "EXTREME_SHORT":
-		return 10 // Bonus - contrarian long in extreme short
+		return weights.ContrarianBonus // Bonus - contrarian long in extreme short
 	case direction == "SHORT" && info.Sentiment == "EXTREME_LONG":
-		return 10 // Bonus - contrarian short in extreme long
+		return weights.ContrarianBonus // Bonus - contrarian short in extreme long
 	case direction == "LONG" && info.Sentiment == "BULLISH":
-		return -5 // Slight penalty
+		return -weights.CrowdedPenalty // Slight penalty
 	case direction == "SHORT" && info.Sentiment == "BEARISH":
-		return -5 // Slight penalty
+		return -weights.CrowdedPenalty // Slight penalty
 	default:
 		return 0 // Neutral
 	}
@@ -138,20 +146,20 @@ func CalculateFundingScore(info *FundingRateInfo, direction string) int {
 // GetFundingScore returns confluence score adjustment based on funding
 // direction: "LONG" or "SHORT"
 // NOTE: This makes an API call - use CalculateFundingScore if you already have funding info
-func GetFundingScore(symbol, direction string) int {
-	info, err := GetFundingRate(symbol)
+func GetFundingScore(market MarketDataProvider, symbol, direction string) int {
+	info, err := market.GetFundingRate(symbol)
 	if err != nil {
-		log.Printf("⚠️ Failed to fetch funding for %s: %v", symbol, err)
+		fundingLog.Warn("⚠️  Failed to fetch funding", "symbol", symbol, "error", err)
 		return 0 // No penalty if API fails
 	}
 
-	log.Printf("📊 [Funding] %s: %.4f%% (%s)", symbol, info.FundingRate, info.Sentiment)
-	return CalculateFundingScore(info, direction)
+	fundingLog.Debug("📊 Funding rate", "symbol", symbol, "rate_pct", info.FundingRate, "sentiment", info.Sentiment)
+	return CalculateFundingScore(info, direction, config.DefaultStrategyProfile().Funding)
 }
 
 // IsFundingRisky checks if trade is risky based on funding
-func IsFundingRisky(symbol, direction string) (bool, string) {
-	info, err := GetFundingRate(symbol)
+func IsFundingRisky(market MarketDataProvider, symbol, direction string) (bool, string) {
+	info, err := market.GetFundingRate(symbol)
 	if err != nil {
 		return false, ""
 	}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var cacheLog = logger.Component("kline_cache")
+
+// intervalDurations maps Binance interval names to candle length
+var intervalDurations = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// IntervalDuration returns the candle length of a Binance interval (0 if unknown)
+func IntervalDuration(interval string) time.Duration {
+	return intervalDurations[interval]
+}
+
+// KlineStore persists cached klines between restarts
+type KlineStore interface {
+	Load(symbol, interval string) ([]model.Kline, error)
+	Save(symbol, interval string, klines []model.Kline) error
+}
+
+// KlineCache is a MarketDataProvider that keeps klines in memory per symbol+interval
+// and only asks the underlying provider for candles newer than the last stored OpenTime.
+// Everything other than klines is passed straight through.
+type KlineCache struct {
+	MarketDataProvider // Underlying provider (order book, funding, spot price, ...)
+
+	store    KlineStore    // Optional persistence, nil for memory only
+	premiums PremiumSource // Optional live mark/index prices, nil to always ask the provider
+	maxAge   time.Duration // How long a series is served without asking the provider
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*klineEntry
+}
+
+type klineEntry struct {
+	mu        sync.Mutex
+	klines    []model.Kline // Oldest first, last one may still be forming
+	size      int           // Largest limit requested so far (retention)
+	checkedAt time.Time     // Last time the provider was asked for updates
+	loaded    bool          // Store already consulted
+}
+
+// PremiumSource serves recent mark/index prices without a REST request (e.g. BinanceStream)
+type PremiumSource interface {
+	PremiumIndex(symbol string) (*PremiumIndex, bool)
+}
+
+// NewKlineCache wraps a provider with an incremental kline cache.
+// maxAge lets several scans in the same cycle (e.g. BTCUSDT 4h for every symbol) share one fetch.
+func NewKlineCache(provider MarketDataProvider, store KlineStore, maxAge time.Duration) *KlineCache {
+	return &KlineCache{
+		MarketDataProvider: provider,
+		store:              store,
+		maxAge:             maxAge,
+		now:                time.Now,
+		entries:            make(map[string]*klineEntry),
+	}
+}
+
+func (c *KlineCache) entry(symbol, interval string) *klineEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := klineKey(symbol, interval)
+	e, ok := c.entries[key]
+	if !ok {
+		e = &klineEntry{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+// GetKlines serves the latest `limit` candles, fetching only what is missing
+func (c *KlineCache) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	e := c.entry(symbol, interval)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if limit > e.size {
+		e.size = limit
+	}
+
+	if !e.loaded {
+		e.loaded = true
+		if c.store != nil {
+			stored, err := c.store.Load(symbol, interval)
+			if err != nil {
+				cacheLog.Warn("⚠️  Failed to load from store", "symbol", symbol, "interval", interval, "error", err)
+			} else {
+				e.klines = stored
+			}
+		}
+	}
+
+	now := c.now()
+	if len(e.klines) < limit || now.Sub(e.checkedAt) >= c.maxAge || lastCandleClosedSinceCheck(e, now) {
+		if err := c.refresh(e, symbol, interval, now); err != nil {
+			// Serve stale data rather than failing the scan if we have enough of it
+			if len(e.klines) < limit {
+				return nil, err
+			}
+			cacheLog.Warn("⚠️  Refresh failed, serving cached candles", "symbol", symbol, "interval", interval, "error", err)
+		}
+	}
+
+	klines := e.klines
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	out := make([]model.Kline, len(klines))
+	copy(out, klines)
+	return out, nil
+}
+
+// closeGrace covers local vs exchange clock skew: a candle fetched this soon after its close may still have been forming
+const closeGrace = 5 * time.Second
+
+// lastCandleClosedSinceCheck reports whether the newest cached candle may have been fetched
+// while still forming and has closed since, so its cached values may not be final
+func lastCandleClosedSinceCheck(e *klineEntry, now time.Time) bool {
+	if len(e.klines) == 0 {
+		return false
+	}
+	closeAt := time.UnixMilli(e.klines[len(e.klines)-1].CloseTime + 1)
+	return e.checkedAt.Before(closeAt.Add(closeGrace)) && !now.Before(closeAt)
+}
+
+// ServerTime passes through to the underlying provider's clock, or the local clock without one
+func (c *KlineCache) ServerTime() (time.Time, error) {
+	if clock, ok := c.MarketDataProvider.(ServerClock); ok {
+		return clock.ServerTime()
+	}
+	return c.now(), nil
+}
+
+// GetOrderBook passes through to the underlying provider's raw order book
+func (c *KlineCache) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	books, ok := c.MarketDataProvider.(OrderBookProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider has no raw order book")
+	}
+	return books.GetOrderBook(symbol, limit)
+}
+
+// SetPremiumSource serves mark/index prices from src while it has them (call before scanning starts)
+func (c *KlineCache) SetPremiumSource(src PremiumSource) {
+	c.premiums = src
+}
+
+// GetPremiumIndex serves the streamed mark/index prices when fresh, otherwise asks the provider
+func (c *KlineCache) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	if c.premiums != nil {
+		if index, ok := c.premiums.PremiumIndex(symbol); ok {
+			return index, nil
+		}
+	}
+	return c.MarketDataProvider.GetPremiumIndex(symbol)
+}
+
+// GetPerpSpotDivergence computes the basis from streamed mark/index prices when fresh, otherwise asks the provider
+func (c *KlineCache) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	if c.premiums != nil {
+		if index, ok := c.premiums.PremiumIndex(symbol); ok {
+			return AnalyzePerpSpotDivergence(symbol, index.MarkPrice, index.IndexPrice), nil
+		}
+	}
+	return c.MarketDataProvider.GetPerpSpotDivergence(symbol)
+}
+
+// derivatives returns the underlying provider's positioning data source
+func (c *KlineCache) derivatives() (DerivativesProvider, error) {
+	provider, ok := c.MarketDataProvider.(DerivativesProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider has no positioning data")
+	}
+	return provider, nil
+}
+
+// GetOpenInterestHist passes through to the underlying provider (not cached)
+func (c *KlineCache) GetOpenInterestHist(symbol, period string, limit int) ([]OpenInterestPoint, error) {
+	provider, err := c.derivatives()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetOpenInterestHist(symbol, period, limit)
+}
+
+// GetLongShortRatio passes through to the underlying provider (not cached)
+func (c *KlineCache) GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error) {
+	provider, err := c.derivatives()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetLongShortRatio(symbol, period, limit)
+}
+
+// GetTakerVolume passes through to the underlying provider (not cached)
+func (c *KlineCache) GetTakerVolume(symbol, period string, limit int) ([]TakerVolume, error) {
+	provider, err := c.derivatives()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetTakerVolume(symbol, period, limit)
+}
+
+// refresh fetches new candles from the provider and merges them into the entry
+func (c *KlineCache) refresh(e *klineEntry, symbol, interval string, now time.Time) error {
+	intervalDur := IntervalDuration(interval)
+
+	fetchLimit := e.size
+	incremental := false
+	if len(e.klines) >= e.size && intervalDur > 0 {
+		// Re-fetch the last stored candle (it may have been forming) plus everything after it
+		last := e.klines[len(e.klines)-1]
+		missing := int(now.Sub(time.UnixMilli(last.OpenTime))/intervalDur) + 2
+		if missing < e.size {
+			fetchLimit = missing
+			incremental = true
+		}
+	}
+
+	fresh, err := c.MarketDataProvider.GetKlines(symbol, interval, fetchLimit)
+	if err != nil {
+		return err
+	}
+	if len(fresh) == 0 {
+		return fmt.Errorf("no %s klines returned for %s", interval, symbol)
+	}
+
+	previousLast := int64(0)
+	if len(e.klines) > 0 {
+		previousLast = e.klines[len(e.klines)-1].OpenTime
+	}
+
+	if incremental {
+		merged, ok := mergeKlines(e.klines, fresh)
+		if !ok {
+			// Gap between cache and fetched batch - start over with a full window
+			fresh, err = c.MarketDataProvider.GetKlines(symbol, interval, e.size)
+			if err != nil {
+				return err
+			}
+			merged = fresh
+		}
+		e.klines = merged
+	} else {
+		e.klines = fresh
+	}
+
+	if len(e.klines) > e.size {
+		e.klines = e.klines[len(e.klines)-e.size:]
+	}
+	e.checkedAt = now
+
+	// Persist only when a new candle opened, not on every forming-candle tick
+	if c.store != nil && e.klines[len(e.klines)-1].OpenTime != previousLast {
+		if err := c.store.Save(symbol, interval, e.klines); err != nil {
+			cacheLog.Warn("⚠️  Failed to persist", "symbol", symbol, "interval", interval, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// ApplyKline merges a streamed candle into a cached series.
+// Contiguous updates keep the series fresh without REST calls; a gap marks it for resync.
+func (c *KlineCache) ApplyKline(symbol, interval string, k model.Kline) {
+	c.mu.Lock()
+	e, ok := c.entries[klineKey(symbol, interval)]
+	c.mu.Unlock()
+	if !ok {
+		return // Nobody has asked for this series yet
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.klines) == 0 {
+		return
+	}
+
+	last := e.klines[len(e.klines)-1]
+	switch {
+	case k.OpenTime == last.OpenTime:
+		e.klines[len(e.klines)-1] = k
+	case k.OpenTime == last.OpenTime+IntervalDuration(interval).Milliseconds():
+		e.klines = append(e.klines, k)
+		if len(e.klines) > e.size {
+			e.klines = e.klines[len(e.klines)-e.size:]
+		}
+	case k.OpenTime < last.OpenTime:
+		return // Stale update
+	default:
+		// Missed candles - let the next GetKlines fetch them via REST
+		e.checkedAt = time.Time{}
+		return
+	}
+	e.checkedAt = c.now()
+}
+
+// Resync forces the next GetKlines for a series to check the provider (e.g. after a stream reconnect)
+func (c *KlineCache) Resync(symbol, interval string) {
+	c.mu.Lock()
+	e, ok := c.entries[klineKey(symbol, interval)]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	e.checkedAt = time.Time{}
+	e.mu.Unlock()
+}
+
+// mergeKlines overlays `fresh` onto `cached` by OpenTime.
+// Returns false when fresh does not connect to the cached series.
+func mergeKlines(cached, fresh []model.Kline) ([]model.Kline, bool) {
+	if len(cached) == 0 {
+		return fresh, true
+	}
+
+	// Find where the fresh batch starts inside the cached series
+	start := len(cached)
+	for i := len(cached) - 1; i >= 0; i-- {
+		if cached[i].OpenTime < fresh[0].OpenTime {
+			break
+		}
+		start = i
+	}
+	if start == len(cached) {
+		return nil, false // Gap: last cached candle is not part of the fresh batch
+	}
+
+	merged := make([]model.Kline, 0, start+len(fresh))
+	merged = append(merged, cached[:start]...)
+	merged = append(merged, fresh...)
+	return merged, true
+}
+
+// MongoKlineStore persists kline series in the "kline_cache" collection.
+// Series are keyed by exchange and market too, so spot and futures candles never mix.
+type MongoKlineStore struct {
+	collection *mongo.Collection
+	exchange   string
+	market     string
+}
+
+type klineCacheDoc struct {
+	ID        string        `bson:"_id"` // exchange|market|SYMBOL|interval
+	Exchange  string        `bson:"exchange"`
+	Market    string        `bson:"market"`
+	Symbol    string        `bson:"symbol"`
+	Interval  string        `bson:"interval"`
+	Klines    []model.Kline `bson:"klines"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+// NewMongoKlineStore creates a Mongo-backed kline store for one exchange and market (spot or futures).
+// Series stored under another exchange or market, or by older versions without one, are never loaded.
+func NewMongoKlineStore(db *mongo.Database, exchange, market string) *MongoKlineStore {
+	if market != MarketSpot {
+		market = MarketFutures
+	}
+	return &MongoKlineStore{collection: db.Collection("kline_cache"), exchange: exchange, market: market}
+}
+
+func (s *MongoKlineStore) key(symbol, interval string) string {
+	return s.exchange + "|" + s.market + "|" + klineKey(symbol, interval)
+}
+
+// Load returns the stored series (nil when nothing is stored yet)
+func (s *MongoKlineStore) Load(symbol, interval string) ([]model.Kline, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc klineCacheDoc
+	err := s.collection.FindOne(ctx, bson.M{"_id": s.key(symbol, interval)}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load klines: %w", err)
+	}
+	return doc.Klines, nil
+}
+
+// Save replaces the stored series
+func (s *MongoKlineStore) Save(symbol, interval string, klines []model.Kline) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doc := klineCacheDoc{
+		ID:        s.key(symbol, interval),
+		Exchange:  s.exchange,
+		Market:    s.market,
+		Symbol:    symbol,
+		Interval:  interval,
+		Klines:    klines,
+		UpdatedAt: time.Now(),
+	}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save klines: %w", err)
+	}
+	return nil
+}
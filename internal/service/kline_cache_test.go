@@ -0,0 +1,160 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+var cacheStart = time.Date(2025, 10, 9, 12, 0, 0, 0, time.UTC)
+
+// bar is the i-th 5m candle from cacheStart closing at close
+func bar(i int, close float64) model.Kline {
+	openTime := cacheStart.Add(time.Duration(i) * 5 * time.Minute)
+	return model.Kline{
+		OpenTime: openTime.UnixMilli(), Open: close, High: close, Low: close, Close: close,
+		CloseTime: openTime.Add(5*time.Minute).UnixMilli() - 1,
+	}
+}
+
+func bars(from, to int) []model.Kline {
+	var klines []model.Kline
+	for i := from; i <= to; i++ {
+		klines = append(klines, bar(i, 100+float64(i)))
+	}
+	return klines
+}
+
+func TestMergeKlines(t *testing.T) {
+	forming := bar(4, 103.5) // Candle 4 fetched mid-way
+	tests := []struct {
+		name          string
+		cached, fresh []model.Kline
+		want          []model.Kline
+		ok            bool
+	}{
+		{"empty cache", nil, bars(0, 2), bars(0, 2), true},
+		{"overlap", bars(0, 4), bars(3, 6), bars(0, 6), true},
+		{"forming candle replaced", append(bars(0, 3), forming), bars(4, 4), bars(0, 4), true},
+		{"forming candle replaced and new ones added", append(bars(0, 3), forming), bars(4, 5), bars(0, 5), true},
+		{"fresh batch covers the cache", bars(2, 4), bars(0, 5), bars(0, 5), true},
+		{"gap", bars(0, 4), bars(6, 7), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeKlines(tt.cached, tt.fresh)
+			if ok != tt.ok || !slices.Equal(got, tt.want) {
+				t.Errorf("mergeKlines = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// limitMarket records the limit of every kline request
+type limitMarket struct {
+	*MemoryMarketData
+	limits []int
+}
+
+func (m *limitMarket) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	m.limits = append(m.limits, limit)
+	return m.MemoryMarketData.GetKlines(symbol, interval, limit)
+}
+
+// savingStore records every persisted series
+type savingStore struct {
+	mu    sync.Mutex
+	saved [][]model.Kline
+}
+
+func (s *savingStore) Load(string, string) ([]model.Kline, error) { return nil, nil }
+
+func (s *savingStore) Save(_, _ string, klines []model.Kline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, append([]model.Kline(nil), klines...))
+	return nil
+}
+
+func TestKlineCacheIncrementalRefresh(t *testing.T) {
+	market := &limitMarket{MemoryMarketData: NewMemoryMarketData()}
+	store := &savingStore{}
+	cache := NewKlineCache(market, store, time.Minute)
+	now := cacheStart.Add(9*5*time.Minute + 2*time.Minute) // Candle 9 forming
+	cache.now = func() time.Time { return now }
+
+	get := func(limit int) []model.Kline {
+		t.Helper()
+		klines, err := cache.GetKlines("ETHUSDT", "5m", limit)
+		if err != nil {
+			t.Fatalf("GetKlines: %v", err)
+		}
+		return klines
+	}
+	formingNine := bar(9, 109.2)
+	market.SetKlines("ETHUSDT", "5m", append(bars(0, 8), formingNine))
+
+	// First request: a full window, persisted
+	if got := get(5); !slices.Equal(got, append(bars(5, 8), formingNine)) || !slices.Equal(market.limits, []int{5}) {
+		t.Fatalf("first fetch = %v with limits %v", got, market.limits)
+	}
+	if len(store.saved) != 1 {
+		t.Fatalf("saved %d times, want once", len(store.saved))
+	}
+
+	// Within maxAge and no candle closed: served from memory
+	now = now.Add(30 * time.Second)
+	market.SetKlines("ETHUSDT", "5m", append(bars(0, 8), bar(9, 109.4)))
+	if got := get(5); got[4] != formingNine || len(market.limits) != 1 {
+		t.Errorf("cached read = %v with limits %v, want no request", got, market.limits)
+	}
+
+	// maxAge passed: only the forming candle (and anything after it) is fetched; nothing new to persist
+	now = now.Add(time.Minute)
+	if got := get(5); got[4] != bar(9, 109.4) || !slices.Equal(market.limits, []int{5, 2}) {
+		t.Errorf("refresh = %v with limits %v, want the forming candle replaced by a 2-candle request", got, market.limits)
+	}
+	if len(store.saved) != 1 {
+		t.Errorf("saved %d times, want no save for a forming-candle update", len(store.saved))
+	}
+
+	// Candle 9 closes and 10 opens: the series is refreshed right away, trimmed to the limit and persisted
+	now = cacheStart.Add(10*5*time.Minute + 10*time.Second)
+	market.SetKlines("ETHUSDT", "5m", append(bars(0, 9), bar(10, 110)))
+	want := append(bars(6, 9), bar(10, 110))
+	if got := get(5); !slices.Equal(got, want) || !slices.Equal(market.limits, []int{5, 2, 3}) {
+		t.Errorf("after close = %v with limits %v, want %v", got, market.limits, want)
+	}
+	if len(store.saved) != 2 || !slices.Equal(store.saved[1], want) {
+		t.Errorf("saves = %v, want the trimmed series persisted once more", store.saved)
+	}
+
+	// A larger limit needs a full window
+	if got := get(8); !slices.Equal(got, append(bars(3, 9), bar(10, 110))) || market.limits[len(market.limits)-1] != 8 {
+		t.Errorf("larger window = %v with limits %v", got, market.limits)
+	}
+}
+
+func TestKlineCacheServesStaleOnError(t *testing.T) {
+	market := NewMemoryMarketData()
+	market.SetKlines("ETHUSDT", "5m", bars(0, 4))
+	cache := NewKlineCache(market, nil, time.Minute)
+	now := cacheStart.Add(4*5*time.Minute + time.Minute)
+	cache.now = func() time.Time { return now }
+	if _, err := cache.GetKlines("ETHUSDT", "5m", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider loses the series: the cached window is still served, a larger one fails
+	market.SetKlines("ETHUSDT", "5m", nil)
+	now = now.Add(2 * time.Minute)
+	if got, err := cache.GetKlines("ETHUSDT", "5m", 5); err != nil || !slices.Equal(got, bars(0, 4)) {
+		t.Errorf("stale read = %v, %v, want the cached candles", got, err)
+	}
+	if _, err := cache.GetKlines("ETHUSDT", "5m", 6); err == nil {
+		t.Error("a window larger than the cache should fail without the provider")
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+// MarketDataProvider is the market data source used by strategy, monitors and bot commands.
+// BinanceService is the live implementation; MemoryMarketData serves fixed data without network.
+type MarketDataProvider interface {
+	GetKlines(symbol, interval string, limit int) ([]model.Kline, error)
+	GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error)
+	GetSpotPrice(symbol string) (float64, error)
+	GetFundingRate(symbol string) (*FundingRateInfo, error)
+	GetPremiumIndex(symbol string) (*PremiumIndex, error)
+	GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error)
+}
+
+// KlineRangeProvider is implemented by providers that can page candles from a start time
+type KlineRangeProvider interface {
+	GetKlinesRange(symbol, interval string, startTime int64, limit int) ([]model.Kline, error)
+}
+
+// CrossExchangeProvider is implemented by providers that can quote a symbol on several exchanges
+type CrossExchangeProvider interface {
+	GetCrossExchangeSpread(symbol string) (*CrossExchangeSpread, error)
+}
+
+// OrderBookProvider is implemented by providers that return the raw order book levels
+type OrderBookProvider interface {
+	GetOrderBook(symbol string, limit int) (*OrderBook, error)
+}
+
+// VenueBookProvider is implemented by providers that can fetch a symbol's book on several exchanges
+type VenueBookProvider interface {
+	GetOrderBooks(symbol string, limit int) ([]*OrderBook, error)
+}
+
+// ServerClock is implemented by providers that know the exchange's clock.
+// Closed-candle evaluation compares candle CloseTimes against it instead of the local clock.
+type ServerClock interface {
+	ServerTime() (time.Time, error)
+}
+
+var _ MarketDataProvider = (*BinanceService)(nil)
+var _ KlineRangeProvider = (*BinanceService)(nil)
+var _ ServerClock = (*BinanceService)(nil)
+var _ ServerClock = (*KlineCache)(nil)
+var _ OrderBookProvider = (*KlineCache)(nil)
+var _ MarketDataProvider = (*MemoryMarketData)(nil)
+var _ OrderBookProvider = (*MemoryMarketData)(nil)
+
+// MemoryMarketData is an in-memory MarketDataProvider for tests, replays and offline runs
+type MemoryMarketData struct {
+	mu         sync.RWMutex
+	klines     map[string][]model.Kline // key: SYMBOL|interval
+	orderBooks map[string]*OrderBookDepth
+	rawBooks   map[string]*OrderBook
+	spotPrices map[string]float64
+	funding    map[string]*FundingRateInfo
+	premiums   map[string]*PremiumIndex
+
+	openInterest map[string][]OpenInterestPoint
+	longShort    map[string][]LongShortRatio
+	taker        map[string][]TakerVolume
+}
+
+// NewMemoryMarketData creates an empty in-memory provider
+func NewMemoryMarketData() *MemoryMarketData {
+	return &MemoryMarketData{
+		klines:     make(map[string][]model.Kline),
+		orderBooks: make(map[string]*OrderBookDepth),
+		rawBooks:   make(map[string]*OrderBook),
+		spotPrices: make(map[string]float64),
+		funding:    make(map[string]*FundingRateInfo),
+		premiums:   make(map[string]*PremiumIndex),
+
+		openInterest: make(map[string][]OpenInterestPoint),
+		longShort:    make(map[string][]LongShortRatio),
+		taker:        make(map[string][]TakerVolume),
+	}
+}
+
+func klineKey(symbol, interval string) string {
+	return symbol + "|" + interval
+}
+
+// SetKlines stores candles (oldest first) for a symbol/interval
+func (m *MemoryMarketData) SetKlines(symbol, interval string, klines []model.Kline) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.klines[klineKey(symbol, interval)] = klines
+}
+
+// SetOrderBook stores bid/ask volume for a symbol; the imbalance is derived like the live feed
+func (m *MemoryMarketData) SetOrderBook(symbol string, bidVolume, askVolume float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orderBooks[symbol] = AnalyzeOrderBook(bidVolume, askVolume)
+}
+
+// SetOrderBookLevels stores a raw order book; its total volumes also serve GetOrderBookDepth
+func (m *MemoryMarketData) SetOrderBookLevels(symbol string, book *OrderBook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rawBooks[symbol] = book
+	m.orderBooks[symbol] = AnalyzeOrderBook(book.Volumes())
+}
+
+// SetSpotPrice stores the spot ticker price
+func (m *MemoryMarketData) SetSpotPrice(symbol string, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spotPrices[symbol] = price
+}
+
+// SetFundingRate stores the funding rate (in %) for a symbol
+func (m *MemoryMarketData) SetFundingRate(symbol string, ratePercent float64, nextFunding time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funding[symbol] = NewFundingRateInfo(symbol, ratePercent, nextFunding)
+}
+
+// SetPremiumIndex stores the futures mark and index price for a symbol
+func (m *MemoryMarketData) SetPremiumIndex(symbol string, markPrice, indexPrice float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.premiums[symbol] = &PremiumIndex{Symbol: symbol, MarkPrice: markPrice, IndexPrice: indexPrice}
+}
+
+// SetDerivatives stores open interest, long/short ratio and taker volume series (oldest first; nil keeps none)
+func (m *MemoryMarketData) SetDerivatives(symbol string, openInterest []OpenInterestPoint, longShort []LongShortRatio, taker []TakerVolume) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.openInterest[symbol] = openInterest
+	m.longShort[symbol] = longShort
+	m.taker[symbol] = taker
+}
+
+// GetKlines returns the latest `limit` stored candles
+func (m *MemoryMarketData) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	klines, ok := m.klines[klineKey(symbol, interval)]
+	if !ok || len(klines) == 0 {
+		return nil, fmt.Errorf("no %s klines for %s", interval, symbol)
+	}
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+
+	out := make([]model.Kline, len(klines))
+	copy(out, klines)
+	return out, nil
+}
+
+// GetOrderBookDepth returns the stored order book analysis
+func (m *MemoryMarketData) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	depth, ok := m.orderBooks[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no order book for %s", symbol)
+	}
+	copied := *depth
+	return &copied, nil
+}
+
+// GetOrderBook returns the stored raw order book
+func (m *MemoryMarketData) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	book, ok := m.rawBooks[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no order book levels for %s", symbol)
+	}
+	return book, nil
+}
+
+// GetSpotPrice returns the stored spot price
+func (m *MemoryMarketData) GetSpotPrice(symbol string) (float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	price, ok := m.spotPrices[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no spot price for %s", symbol)
+	}
+	return price, nil
+}
+
+// GetFundingRate returns the stored funding rate
+func (m *MemoryMarketData) GetFundingRate(symbol string) (*FundingRateInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	info, ok := m.funding[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no funding rate for %s", symbol)
+	}
+	copied := *info
+	return &copied, nil
+}
+
+// GetPremiumIndex returns the stored mark/index prices
+func (m *MemoryMarketData) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	index, ok := m.premiums[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no premium index for %s", symbol)
+	}
+	copied := *index
+	return &copied, nil
+}
+
+// GetPerpSpotDivergence compares the stored mark price against the stored index price
+func (m *MemoryMarketData) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	index, err := m.GetPremiumIndex(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzePerpSpotDivergence(symbol, index.MarkPrice, index.IndexPrice), nil
+}
+
+// latest returns the last limit entries of a stored series (the period is ignored)
+func latest[T any](series []T, limit int) []T {
+	if limit > 0 && len(series) > limit {
+		series = series[len(series)-limit:]
+	}
+	return slices.Clone(series)
+}
+
+// GetOpenInterestHist returns the latest stored open interest points
+func (m *MemoryMarketData) GetOpenInterestHist(symbol, period string, limit int) ([]OpenInterestPoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.openInterest[symbol]) == 0 {
+		return nil, fmt.Errorf("no open interest history for %s", symbol)
+	}
+	return latest(m.openInterest[symbol], limit), nil
+}
+
+// GetLongShortRatio returns the latest stored long/short ratios
+func (m *MemoryMarketData) GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.longShort[symbol]) == 0 {
+		return nil, fmt.Errorf("no long/short ratio for %s", symbol)
+	}
+	return latest(m.longShort[symbol], limit), nil
+}
+
+// GetTakerVolume returns the latest stored taker volumes
+func (m *MemoryMarketData) GetTakerVolume(symbol, period string, limit int) ([]TakerVolume, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.taker[symbol]) == 0 {
+		return nil, fmt.Errorf("no taker volume for %s", symbol)
+	}
+	return latest(m.taker[symbol], limit), nil
+}
+
+// MarketFixture is the JSON layout accepted by LoadMarketFixture
+//
+//	{
+//	  "symbols": {
+//	    "ETHUSDT": {
+//	      "klines": {"5m": [{"openTime": 0, "open": 1, "high": 1, "low": 1, "close": 1, "volume": 1, "closeTime": 0}]},
+//	      "orderBook": {"bidVolume": 1200, "askVolume": 800},
+//	      "spotPrice": 2500.5,
+//	      "markPrice": 2502.1,
+//	      "indexPrice": 2500.9,
+//	      "fundingRate": 0.01,
+//	      "openInterest": [{"time": 0, "openInterest": 1000, "openInterestValue": 2500000}],
+//	      "longShortRatio": [{"time": 0, "ratio": 1.8, "longAccount": 0.64, "shortAccount": 0.36}],
+//	      "takerVolume": [{"time": 0, "buySellRatio": 1.1, "buyVolume": 550, "sellVolume": 500}]
+//	    }
+//	  }
+//	}
+type MarketFixture struct {
+	Symbols map[string]SymbolFixture `json:"symbols"`
+}
+
+// SymbolFixture holds the fixture data for one symbol
+type SymbolFixture struct {
+	Klines    map[string][]FixtureKline `json:"klines"`
+	OrderBook *struct {
+		BidVolume float64 `json:"bidVolume"`
+		AskVolume float64 `json:"askVolume"`
+	} `json:"orderBook,omitempty"`
+	SpotPrice   *float64   `json:"spotPrice,omitempty"`
+	MarkPrice   *float64   `json:"markPrice,omitempty"`   // Futures mark price
+	IndexPrice  *float64   `json:"indexPrice,omitempty"`  // Spot index price
+	FundingRate *float64   `json:"fundingRate,omitempty"` // In %
+	NextFunding *time.Time `json:"nextFunding,omitempty"`
+
+	// Futures positioning series, oldest first
+	OpenInterest   []OpenInterestPoint `json:"openInterest,omitempty"`
+	LongShortRatio []LongShortRatio    `json:"longShortRatio,omitempty"`
+	TakerVolume    []TakerVolume       `json:"takerVolume,omitempty"`
+}
+
+// FixtureKline is a JSON-tagged kline
+type FixtureKline struct {
+	OpenTime  int64   `json:"openTime"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	CloseTime int64   `json:"closeTime"`
+}
+
+// LoadMarketFixture builds a MemoryMarketData from a JSON fixture file
+func LoadMarketFixture(path string) (*MemoryMarketData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture MarketFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+
+	m := NewMemoryMarketData()
+	for symbol, sf := range fixture.Symbols {
+		for interval, rows := range sf.Klines {
+			klines := make([]model.Kline, len(rows))
+			for i, k := range rows {
+				klines[i] = model.Kline(k)
+			}
+			m.SetKlines(symbol, interval, klines)
+		}
+		if sf.OrderBook != nil {
+			m.SetOrderBook(symbol, sf.OrderBook.BidVolume, sf.OrderBook.AskVolume)
+		}
+		if sf.SpotPrice != nil {
+			m.SetSpotPrice(symbol, *sf.SpotPrice)
+		}
+		if sf.MarkPrice != nil && sf.IndexPrice != nil {
+			m.SetPremiumIndex(symbol, *sf.MarkPrice, *sf.IndexPrice)
+		}
+		if sf.FundingRate != nil {
+			next := time.Time{}
+			if sf.NextFunding != nil {
+				next = *sf.NextFunding
+			}
+			m.SetFundingRate(symbol, *sf.FundingRate, next)
+		}
+		if len(sf.OpenInterest) > 0 || len(sf.LongShortRatio) > 0 || len(sf.TakerVolume) > 0 {
+			m.SetDerivatives(symbol, sf.OpenInterest, sf.LongShortRatio, sf.TakerVolume)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+// okxBars maps Binance interval names to OKX bars (UTC-aligned from 6h up, like Binance)
+var okxBars = map[string]string{
+	"1m": "1m", "3m": "3m", "5m": "5m", "15m": "15m", "30m": "30m",
+	"1h": "1H", "2h": "2H", "4h": "4H", "6h": "6Hutc", "12h": "12Hutc", "1d": "1Dutc",
+}
+
+// OKX page sizes
+const (
+	okxCandlesLimit = 300 // /market/candles (recent)
+	okxHistoryLimit = 100 // /market/history-candles
+	okxBooksLimit   = 400
+)
+
+// OKXService is the OKX (v5 public API) implementation of MarketDataProvider.
+// Symbols use Binance names (BTCUSDT) and map to BTC-USDT-SWAP on futures and BTC-USDT on spot.
+// Swap volumes are reported in contracts by OKX and converted to the base coin here.
+type OKXService struct {
+	baseURL string
+	market  string // MarketSpot or MarketFutures
+	client  *http.Client
+
+	contractMu    sync.Mutex
+	contractSizes map[string]float64 // Swap instId -> base coin per contract
+}
+
+var _ KlineRangeProvider = (*OKXService)(nil)
+
+// NewOKXService creates an OKX client for a market (spot or futures)
+func NewOKXService(baseURL, market string) *OKXService {
+	if market != MarketSpot {
+		market = MarketFutures
+	}
+	return &OKXService{
+		baseURL:       baseURL,
+		market:        market,
+		client:        newExchangeClient(ExchangeOKX),
+		contractSizes: make(map[string]float64),
+	}
+}
+
+// Exchange returns "okx"
+func (s *OKXService) Exchange() string {
+	return ExchangeOKX
+}
+
+// spotInstID converts BTCUSDT to BTC-USDT
+func spotInstID(symbol string) (string, error) {
+	base, ok := strings.CutSuffix(symbol, "USDT")
+	if !ok || base == "" {
+		return "", fmt.Errorf("okx adapter only supports USDT pairs, got %s", symbol)
+	}
+	return base + "-USDT", nil
+}
+
+// swapInstID converts BTCUSDT to BTC-USDT-SWAP
+func swapInstID(symbol string) (string, error) {
+	inst, err := spotInstID(symbol)
+	if err != nil {
+		return "", err
+	}
+	return inst + "-SWAP", nil
+}
+
+// instID is the instrument of the selected market
+func (s *OKXService) instID(symbol string) (string, error) {
+	if s.market == MarketSpot {
+		return spotInstID(symbol)
+	}
+	return swapInstID(symbol)
+}
+
+// okxResponse is the v5 response envelope
+type okxResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// get calls a v5 endpoint and decodes its data into v
+func (s *OKXService) get(path string, params url.Values, v any) error {
+	return s.getContext(context.Background(), path, params, v)
+}
+
+func (s *OKXService) getContext(ctx context.Context, path string, params url.Values, v any) error {
+	var resp okxResponse
+	if err := getJSON(ctx, s.client, ExchangeOKX, s.baseURL+path+"?"+params.Encode(), &resp); err != nil {
+		return err
+	}
+	if resp.Code != "0" {
+		return fmt.Errorf("okx API error %s: %s", resp.Code, resp.Msg)
+	}
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		return fmt.Errorf("failed to decode okx %s: %w", path, err)
+	}
+	return nil
+}
+
+// Ping checks connectivity to the REST API
+func (s *OKXService) Ping(ctx context.Context) error {
+	var data []struct {
+		Ts string `json:"ts"`
+	}
+	return s.getContext(ctx, "/api/v5/public/time", url.Values{}, &data)
+}
+
+// GetKlines fetches the latest candles; more than 300 are paged from the history endpoint
+func (s *OKXService) GetKlines(symbol, interval string, limit int) ([]model.Kline, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(min(limit, okxCandlesLimit)))
+	klines, err := s.fetchKlines("/api/v5/market/candles", symbol, interval, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Page backwards: "after" returns candles older than the given open time
+	for len(klines) < limit {
+		params := url.Values{}
+		params.Set("after", strconv.FormatInt(klines[0].OpenTime, 10))
+		params.Set("limit", strconv.Itoa(min(limit-len(klines), okxHistoryLimit)))
+		older, err := s.fetchKlines("/api/v5/market/history-candles", symbol, interval, params)
+		if err != nil {
+			break // Listing start or history unavailable: serve what we have
+		}
+		klines = append(older, klines...)
+	}
+	return klines, nil
+}
+
+// GetKlinesRange fetches up to 100 candles opening at or after startTime (Unix ms)
+func (s *OKXService) GetKlinesRange(symbol, interval string, startTime int64, limit int) ([]model.Kline, error) {
+	limit = min(limit, okxHistoryLimit)
+	end := startTime + int64(limit)*IntervalDuration(interval).Milliseconds()
+
+	params := url.Values{}
+	params.Set("after", strconv.FormatInt(end, 10))
+	params.Set("limit", strconv.Itoa(limit))
+	klines, err := s.fetchKlines("/api/v5/market/history-candles", symbol, interval, params)
+	if err != nil {
+		return nil, err
+	}
+
+	inRange := klines[:0]
+	for _, k := range klines {
+		if k.OpenTime >= startTime {
+			inRange = append(inRange, k)
+		}
+	}
+	if len(inRange) == 0 {
+		return nil, fmt.Errorf("no klines after %d", startTime)
+	}
+	return inRange, nil
+}
+
+func (s *OKXService) fetchKlines(path, symbol, interval string, params url.Values) ([]model.Kline, error) {
+	bar, ok := okxBars[interval]
+	if !ok {
+		return nil, fmt.Errorf("interval %s is not available on okx", interval)
+	}
+	inst, err := s.instID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("instId", inst)
+	params.Set("bar", bar)
+
+	// Rows: [ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm], newest first.
+	// vol is in contracts on swaps and in the base coin on spot; volCcy is always the base coin.
+	var rows [][]string
+	if err := s.get(path, params, &rows); err != nil {
+		return nil, fmt.Errorf("failed to fetch klines: %w", err)
+	}
+	volumeIndex := 5
+	if s.market == MarketFutures {
+		volumeIndex = 6
+	}
+	return parseKlineRows(ExchangeOKX, symbol, interval, rows, volumeIndex)
+}
+
+// contractSize returns the base coin amount of one swap contract (cached per instrument)
+func (s *OKXService) contractSize(inst string) (float64, error) {
+	s.contractMu.Lock()
+	defer s.contractMu.Unlock()
+	if size, ok := s.contractSizes[inst]; ok {
+		return size, nil
+	}
+
+	params := url.Values{}
+	params.Set("instType", "SWAP")
+	params.Set("instId", inst)
+	var data []struct {
+		CtVal string `json:"ctVal"`
+	}
+	if err := s.get("/api/v5/public/instruments", params, &data); err != nil {
+		return 0, fmt.Errorf("failed to fetch instrument: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("unknown instrument %s", inst)
+	}
+	size, err := strconv.ParseFloat(data[0].CtVal, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid contract size for %s", inst)
+	}
+
+	s.contractSizes[inst] = size
+	return size, nil
+}
+
+// GetOrderBook fetches the raw order book (max 400 levels), in base coin
+func (s *OKXService) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	inst, err := s.instID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("instId", inst)
+	params.Set("sz", strconv.Itoa(min(limit, okxBooksLimit)))
+
+	var data []struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := s.get("/api/v5/market/books", params, &data); err != nil {
+		return nil, fmt.Errorf("failed to fetch depth: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty order book for %s", inst)
+	}
+
+	size := 1.0
+	if s.market == MarketFutures {
+		if size, err = s.contractSize(inst); err != nil {
+			return nil, err
+		}
+	}
+	return &OrderBook{Exchange: ExchangeOKX, Bids: parseLevels(data[0].Bids, size), Asks: parseLevels(data[0].Asks, size)}, nil
+}
+
+// GetOrderBookDepth fetches and analyzes order book depth, in base coin
+func (s *OKXService) GetOrderBookDepth(symbol string, limit int) (*OrderBookDepth, error) {
+	book, err := s.GetOrderBook(symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeOrderBook(book.Volumes()), nil
+}
+
+// GetSpotPrice fetches the current spot price
+func (s *OKXService) GetSpotPrice(symbol string) (float64, error) {
+	inst, err := spotInstID(symbol)
+	if err != nil {
+		return 0, err
+	}
+	params := url.Values{}
+	params.Set("instId", inst)
+
+	var data []struct {
+		Last string `json:"last"`
+	}
+	if err := s.get("/api/v5/market/ticker", params, &data); err != nil {
+		return 0, fmt.Errorf("failed to fetch spot price: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no spot ticker for %s", inst)
+	}
+	price, err := strconv.ParseFloat(data[0].Last, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse spot price: %w", err)
+	}
+	return price, nil
+}
+
+// fundingRate returns the swap's funding rate (fraction) and its settlement time
+func (s *OKXService) fundingRate(symbol string) (float64, time.Time, error) {
+	inst, err := swapInstID(symbol)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	params := url.Values{}
+	params.Set("instId", inst)
+
+	var data []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime string `json:"fundingTime"`
+	}
+	if err := s.get("/api/v5/public/funding-rate", params, &data); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to fetch funding rate: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, time.Time{}, fmt.Errorf("no funding data returned")
+	}
+
+	rate, err1 := strconv.ParseFloat(data[0].FundingRate, 64)
+	fundingTime, err2 := strconv.ParseInt(data[0].FundingTime, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to parse funding rate for %s", inst)
+	}
+	return rate, time.UnixMilli(fundingTime), nil
+}
+
+// GetFundingRate returns the funding rate of the running interval (Binance reports the last settled one)
+func (s *OKXService) GetFundingRate(symbol string) (*FundingRateInfo, error) {
+	rate, fundingTime, err := s.fundingRate(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return NewFundingRateInfo(symbol, rate*100, fundingTime), nil
+}
+
+// GetPremiumIndex combines the swap mark price, the spot index and the funding rate (always swap)
+func (s *OKXService) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
+	swap, err := swapInstID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	spot, _ := spotInstID(symbol)
+
+	markParams := url.Values{}
+	markParams.Set("instType", "SWAP")
+	markParams.Set("instId", swap)
+	var mark []struct {
+		MarkPx string `json:"markPx"`
+	}
+	if err := s.get("/api/v5/public/mark-price", markParams, &mark); err != nil {
+		return nil, fmt.Errorf("failed to fetch mark price: %w", err)
+	}
+
+	indexParams := url.Values{}
+	indexParams.Set("instId", spot)
+	var index []struct {
+		IdxPx string `json:"idxPx"`
+	}
+	if err := s.get("/api/v5/market/index-tickers", indexParams, &index); err != nil {
+		return nil, fmt.Errorf("failed to fetch index price: %w", err)
+	}
+	if len(mark) == 0 || len(index) == 0 {
+		return nil, fmt.Errorf("no mark or index price for %s", swap)
+	}
+
+	markPrice, err1 := strconv.ParseFloat(mark[0].MarkPx, 64)
+	indexPrice, err2 := strconv.ParseFloat(index[0].IdxPx, 64)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("failed to parse premium index for %s", symbol)
+	}
+
+	rate, fundingTime, err := s.fundingRate(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PremiumIndex{
+		Symbol:          symbol,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		LastFundingRate: rate * 100,
+		NextFundingTime: fundingTime,
+	}, nil
+}
+
+// GetPerpSpotDivergence calculates the perpetual basis: swap mark price vs spot index price
+func (s *OKXService) GetPerpSpotDivergence(symbol string) (*PerpSpotDivergence, error) {
+	index, err := s.GetPremiumIndex(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if index.IndexPrice <= 0 {
+		return nil, fmt.Errorf("invalid index price for %s", symbol)
+	}
+	return AnalyzePerpSpotDivergence(symbol, index.MarkPrice, index.IndexPrice), nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOKXInstIDs(t *testing.T) {
+	if inst, err := swapInstID("BTCUSDT"); err != nil || inst != "BTC-USDT-SWAP" {
+		t.Errorf("swapInstID = %q, %v", inst, err)
+	}
+	if inst, err := spotInstID("1000PEPEUSDT"); err != nil || inst != "1000PEPE-USDT" {
+		t.Errorf("spotInstID = %q, %v", inst, err)
+	}
+	for _, symbol := range []string{"ETHBTC", "USDT"} {
+		if _, err := spotInstID(symbol); err == nil {
+			t.Errorf("spotInstID(%s) should fail", symbol)
+		}
+	}
+}
+
+func TestOKXKlinesPagesHistory(t *testing.T) {
+	server := newFixtureServer(t, "okx")
+	okx := NewOKXService(server.URL, MarketFutures)
+
+	klines, err := okx.GetKlines("ETHUSDT", "5m", 5)
+	if err != nil {
+		t.Fatalf("GetKlines: %v", err)
+	}
+	assertParams(t, server.query(t, "/api/v5/market/candles", 0), map[string]string{
+		"instId": "ETH-USDT-SWAP", "bar": "5m", "limit": "5",
+	})
+	// The second page continues before the oldest candle of the first
+	assertParams(t, server.query(t, "/api/v5/market/history-candles", 0), map[string]string{
+		"instId": "ETH-USDT-SWAP", "after": "1759996800000", "limit": "2",
+	})
+
+	if len(klines) != 5 {
+		t.Fatalf("got %d klines, want 5", len(klines))
+	}
+	for i := 1; i < len(klines); i++ {
+		if klines[i].OpenTime != klines[i-1].OpenTime+300000 {
+			t.Fatalf("klines not contiguous oldest first at %d: %d after %d", i, klines[i].OpenTime, klines[i-1].OpenTime)
+		}
+	}
+	// Swap volume is volCcy (ETH), not the contract count
+	if last := klines[4]; last.Close != 4524.95 || last.Volume != 1841.05 {
+		t.Errorf("last kline = %+v", last)
+	}
+}
+
+func TestOKXSpotKlines(t *testing.T) {
+	server := newFixtureServer(t, "okx")
+	okx := NewOKXService(server.URL, MarketSpot)
+
+	klines, err := okx.GetKlines("ETHUSDT", "4h", 3)
+	if err != nil {
+		t.Fatalf("GetKlines: %v", err)
+	}
+	assertParams(t, server.query(t, "/api/v5/market/candles", 0), map[string]string{"instId": "ETH-USDT", "bar": "4H"})
+	if klines[2].Volume != 18410.5 {
+		t.Errorf("spot volume = %v, want the vol column", klines[2].Volume)
+	}
+}
+
+func TestOKXKlinesRange(t *testing.T) {
+	server := newFixtureServer(t, "okx")
+	okx := NewOKXService(server.URL, MarketFutures)
+
+	klines, err := okx.GetKlinesRange("ETHUSDT", "5m", 1759996500000, 500)
+	if err != nil {
+		t.Fatalf("GetKlinesRange: %v", err)
+	}
+	assertParams(t, server.query(t, "/api/v5/market/history-candles", 0), map[string]string{
+		"after": "1760026500000", "limit": "100", // start + 100 candles
+	})
+	if len(klines) != 1 || klines[0].OpenTime != 1759996500000 {
+		t.Errorf("klines = %+v, want only the candle at the start time", klines)
+	}
+}
+
+func TestOKXOrderBookInBaseCoin(t *testing.T) {
+	server := newFixtureServer(t, "okx")
+	okx := NewOKXService(server.URL, MarketFutures)
+
+	for range 2 {
+		depth, err := okx.GetOrderBookDepth("ETHUSDT", 500)
+		if err != nil {
+			t.Fatalf("GetOrderBookDepth: %v", err)
+		}
+		// 750 / 250 contracts of 0.1 ETH
+		if !approx(depth.BidVolume, 75) || !approx(depth.AskVolume, 25) || !approx(depth.Imbalance, 50) {
+			t.Errorf("depth = %+v, want 75 / 25 ETH", depth)
+		}
+	}
+	assertParams(t, server.query(t, "/api/v5/market/books", 0), map[string]string{"instId": "ETH-USDT-SWAP", "sz": "400"})
+	assertParams(t, server.query(t, "/api/v5/public/instruments", 0), map[string]string{"instType": "SWAP", "instId": "ETH-USDT-SWAP"})
+	if n := len(server.queries["/api/v5/public/instruments"]); n != 1 {
+		t.Errorf("instruments requested %d times, want the contract size cached", n)
+	}
+
+	book, err := okx.GetOrderBook("ETHUSDT", 500)
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if book.Exchange != ExchangeOKX || book.Bids[0] != (OrderBookLevel{Price: 4524.95, Quantity: 30.5}) || book.Mid() != 4524.955 {
+		t.Errorf("book = %+v, want levels in ETH", book)
+	}
+}
+
+func TestOKXPremiumAndFunding(t *testing.T) {
+	server := newFixtureServer(t, "okx")
+	okx := NewOKXService(server.URL, MarketSpot)
+
+	index, err := okx.GetPremiumIndex("ETHUSDT")
+	if err != nil {
+		t.Fatalf("GetPremiumIndex: %v", err)
+	}
+	assertParams(t, server.query(t, "/api/v5/public/mark-price", 0), map[string]string{"instType": "SWAP", "instId": "ETH-USDT-SWAP"})
+	assertParams(t, server.query(t, "/api/v5/market/index-tickers", 0), map[string]string{"instId": "ETH-USDT"})
+	if index.MarkPrice != 4525.12 || index.IndexPrice != 4522.8 {
+		t.Errorf("mark/index = %v / %v", index.MarkPrice, index.IndexPrice)
+	}
+	if !approx(index.LastFundingRate, 0.00832) || !index.NextFundingTime.Equal(time.UnixMilli(1760025600000)) {
+		t.Errorf("funding = %v at %v", index.LastFundingRate, index.NextFundingTime)
+	}
+
+	divergence, err := okx.GetPerpSpotDivergence("ETHUSDT")
+	if err != nil || !approx(divergence.Premium, (4525.12-4522.8)/4522.8*100) {
+		t.Errorf("GetPerpSpotDivergence = %+v, %v", divergence, err)
+	}
+
+	price, err := okx.GetSpotPrice("ETHUSDT")
+	if err != nil || price != 4523.01 {
+		t.Errorf("GetSpotPrice = %v, %v", price, err)
+	}
+
+	if err := okx.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestOKXAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"51001","msg":"Instrument ID does not exist","data":[]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewOKXService(server.URL, MarketFutures).GetFundingRate("NOPEUSDT"); err == nil {
+		t.Fatal("a non-zero code should fail")
+	}
+}
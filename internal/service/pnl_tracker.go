@@ -1,13 +1,15 @@
 package service
 
 import (
-	"log"
 	"time"
 
+	"mrcrypto-go/internal/logger"
 	internalmath "mrcrypto-go/internal/math"
 	"mrcrypto-go/internal/model"
 )
 
+var pnlLog = logger.Component("pnl")
+
 // PnLTracker tracks and calculates PnL for completed trades
 type PnLTracker struct {
 	trades []internalmath.TradeResult
@@ -35,8 +37,8 @@ func (p *PnLTracker) RecordClosedTrade(signal *model.Signal, exitPrice float64,
 	// Store for stats calculation
 	p.trades = append(p.trades, result)
 
-	log.Printf("📊 [PnL] %s closed - Direction: %s, Entry: %.4f, Exit: %.4f, PnL: %.2f (%.2f%%)",
-		signal.Symbol, direction, signal.EntryPrice, exitPrice, result.PnL, result.PnLPercent)
+	pnlLog.Info("📊 Trade closed", "symbol", signal.Symbol, "signal_id", signal.ID, "direction", direction,
+		"entry", signal.EntryPrice, "exit", exitPrice, "pnl", result.PnL, "pnl_pct", result.PnLPercent)
 
 	return result
 }
@@ -67,22 +69,19 @@ func (p *PnLTracker) GetMaxDrawdown(initialEquity float64) float64 {
 func (p *PnLTracker) GetPerformanceSummary() {
 	stats := p.GetStats()
 
-	log.Println("==========================================")
-	log.Println("📈 TRADING PERFORMANCE SUMMARY")
-	log.Println("==========================================")
-	log.Printf("Total Trades: %d", stats.TotalTrades)
-	log.Printf("Winning: %d | Losing: %d", stats.WinningTrades, stats.LosingTrades)
-	log.Printf("Win Rate: %.2f%%", stats.WinRate)
-	log.Printf("Total PnL: $%.2f", stats.TotalPnL)
-	log.Printf("Avg Win: $%.2f | Avg Loss: $%.2f", stats.AvgWin, stats.AvgLoss)
-	log.Printf("Largest Win: $%.2f | Largest Loss: $%.2f", stats.LargestWin, stats.LargestLoss)
-	log.Printf("Profit Factor: %.2f", stats.ProfitFactor)
-	log.Printf("Expected Value: $%.2f", stats.ExpectedValue)
-	log.Println("------------------------------------------")
-	log.Println("📐 PROBABILITY METRICS")
-	log.Println("------------------------------------------")
-	log.Printf("Sharpe Ratio: %.2f", stats.SharpeRatio)
-	log.Printf("Risk of Ruin: %.2f%%", stats.RiskOfRuin*100)
-	log.Printf("Optimal Position Size (Kelly): %.2f%%", stats.OptimalF)
-	log.Println("==========================================")
+	pnlLog.Info("📈 Trading performance summary",
+		"trades", stats.TotalTrades,
+		"wins", stats.WinningTrades,
+		"losses", stats.LosingTrades,
+		"win_rate", stats.WinRate,
+		"total_pnl", stats.TotalPnL,
+		"avg_win", stats.AvgWin,
+		"avg_loss", stats.AvgLoss,
+		"largest_win", stats.LargestWin,
+		"largest_loss", stats.LargestLoss,
+		"profit_factor", stats.ProfitFactor,
+		"expected_value", stats.ExpectedValue,
+		"sharpe", stats.SharpeRatio,
+		"risk_of_ruin_pct", stats.RiskOfRuin*100,
+		"kelly_pct", stats.OptimalF)
 }
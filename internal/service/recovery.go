@@ -2,7 +2,7 @@ package service
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"runtime/debug"
 )
@@ -10,7 +10,7 @@ import (
 // RecoverAndLog recovers from panic and logs it with context
 func RecoverAndLog(context string) {
 	if r := recover(); r != nil {
-		log.Printf("❌ [PANIC RECOVERED] %s: %v\n%s", context, r, string(debug.Stack()))
+		slog.Error("❌ Panic recovered", "where", context, "panic", r, "stack", string(debug.Stack()))
 	}
 }
 
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"math"
 	"time"
 
@@ -10,9 +10,12 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"mrcrypto-go/internal/logger"
 	"mrcrypto-go/internal/model"
 )
 
+var riskLog = logger.Component("risk")
+
 // RiskManager handles dynamic position sizing based on recent performance
 type RiskManager struct {
 	collection *mongo.Collection
@@ -114,8 +117,8 @@ func (rm *RiskManager) CalculateDynamicPositionSize(tier model.SignalTier) *Posi
 	// Ensure bounds
 	info.RecommendedSize = math.Max(0.5, math.Min(adjustedSize, 3.0))
 
-	log.Printf("📊 [RiskManager] Win Streak: %d, Lose Streak: %d, Win Rate: %.1f%%, Size: %.2f%%",
-		info.WinStreak, info.LoseStreak, info.RecentWinRate, info.RecommendedSize)
+	riskLog.Debug("📊 Position size", "win_streak", info.WinStreak, "lose_streak", info.LoseStreak,
+		"win_rate", info.RecentWinRate, "size_pct", info.RecommendedSize)
 
 	return info
 }
@@ -135,22 +138,23 @@ func (rm *RiskManager) getRecentClosedTrades(limit int) []model.Signal {
 
 	cursor, err := rm.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Printf("⚠️ [RiskManager] Failed to fetch recent trades: %v", err)
+		riskLog.Warn("⚠️  Failed to fetch recent trades", "error", err)
 		return nil
 	}
 	defer cursor.Close(ctx)
 
 	var signals []model.Signal
 	if err := cursor.All(ctx, &signals); err != nil {
-		log.Printf("⚠️ [RiskManager] Failed to decode trades: %v", err)
+		riskLog.Warn("⚠️  Failed to decode trades", "error", err)
 		return nil
 	}
 
 	return signals
 }
 
-// GetTodayStats returns today's trading statistics
-func (rm *RiskManager) GetTodayStats() (wins, losses int, totalPnL float64) {
+// GetTodayStats returns today's trading statistics. totalPnL is in % of account (see AccountPnL);
+// an error means today's result is unknown.
+func (rm *RiskManager) GetTodayStats() (wins, losses int, totalPnL float64, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -162,21 +166,46 @@ func (rm *RiskManager) GetTodayStats() (wins, losses int, totalPnL float64) {
 
 	cursor, err := rm.collection.Find(ctx, filter)
 	if err != nil {
-		return 0, 0, 0
+		return 0, 0, 0, fmt.Errorf("failed to fetch today's trades: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	for cursor.Next(ctx) {
-		var signal model.Signal
-		if err := cursor.Decode(&signal); err == nil {
-			totalPnL += signal.PnL
-			if signal.PnL > 0 {
-				wins++
-			} else {
-				losses++
-			}
+	var signals []model.Signal
+	if err := cursor.All(ctx, &signals); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to decode today's trades: %w", err)
+	}
+
+	for i := range signals {
+		totalPnL += AccountPnL(&signals[i])
+		if signals[i].PnL > 0 {
+			wins++
+		} else {
+			losses++
 		}
 	}
 
-	return wins, losses, math.Round(totalPnL*100) / 100
+	return wins, losses, math.Round(totalPnL*100) / 100, nil
+}
+
+// defaultPositionSize is assumed for signals saved without a position size (% of account)
+const defaultPositionSize = 1.0
+
+// AccountPnL converts a closed signal's PnL (% price move) into % of account.
+// RecommendedSize is the % of account lost at the original stop, so the PnL is scaled by it.
+func AccountPnL(signal *model.Signal) float64 {
+	size := signal.RecommendedSize
+	if size <= 0 {
+		size = defaultPositionSize
+	}
+
+	stop := signal.StopLoss
+	if signal.InitialStopLoss > 0 {
+		stop = signal.InitialStopLoss // The stop moved to breakeven after TP1
+	}
+	if signal.EntryPrice <= 0 || stop == signal.EntryPrice {
+		return signal.PnL * size / 100 // No stop distance: weight by size only
+	}
+
+	stopDistance := math.Abs(signal.EntryPrice-stop) / signal.EntryPrice * 100
+	return signal.PnL / stopDistance * size
 }
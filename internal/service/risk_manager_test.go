@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"mrcrypto-go/internal/model"
+)
+
+func TestAccountPnL(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal model.Signal
+		want   float64
+	}{
+		// 2% stop risking 1.5% of the account: a full stop-out costs 1.5%
+		{"stopped out", model.Signal{EntryPrice: 100, StopLoss: 98, RecommendedSize: 1.5, PnL: -2}, -1.5},
+		{"TP2 at 2R", model.Signal{EntryPrice: 100, StopLoss: 98, RecommendedSize: 1.5, PnL: 4}, 3},
+		// After TP1 the stop sits at entry; the original distance still sets the size
+		{"breakeven after TP1", model.Signal{EntryPrice: 100, StopLoss: 100, InitialStopLoss: 102, RecommendedSize: 2, PnL: 1}, 1},
+		{"no size", model.Signal{EntryPrice: 100, StopLoss: 95, PnL: -5}, -defaultPositionSize},
+		{"no stop", model.Signal{EntryPrice: 100, StopLoss: 100, RecommendedSize: 2, PnL: 3}, 0.06},
+	}
+	for _, tt := range tests {
+		if got := AccountPnL(&tt.signal); !approx(got, tt.want) {
+			t.Errorf("%s: AccountPnL = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
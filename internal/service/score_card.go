@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+
+	"mrcrypto-go/internal/model"
+)
+
+// scoreCard accumulates a score together with the factors that produced it
+type scoreCard struct {
+	total   int
+	factors []model.ScoreFactor
+}
+
+// add records a factor and adds its points, including factors worth 0 points
+func (c *scoreCard) add(name, value string, points int) {
+	c.factors = append(c.factors, model.ScoreFactor{Name: name, Value: value, Points: points})
+	c.total += points
+}
+
+// clamp bounds the total to 0-100, recording the adjustment so the factors still sum to the total
+func (c *scoreCard) clamp(name string) {
+	switch {
+	case c.total < 0:
+		c.add(name, fmt.Sprintf("%d → 0", c.total), -c.total)
+	case c.total > 100:
+		c.add(name, fmt.Sprintf("%d → 100", c.total), 100-c.total)
+	}
+}
+
+// valueOrNone shows empty indicator readings as "None"
+func valueOrNone(value string) string {
+	if value == "" {
+		return "None"
+	}
+	return value
+}
+
+// zoneValue shows an OB/FVG type only when price is inside the zone
+func zoneValue(inZone bool, zoneType string) string {
+	if !inZone {
+		return "None"
+	}
+	return valueOrNone(zoneType)
+}
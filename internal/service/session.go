@@ -33,7 +33,12 @@ type SessionInfo struct {
 // - London-NY Overlap: 13:00 - 16:00 UTC (7:00 PM - 10:00 PM BD) - BEST TIME
 // - Dead Zone: 21:00 - 00:00 UTC (3:00 AM - 6:00 AM BD)
 func GetCurrentSession() SessionInfo {
-	now := time.Now().UTC()
+	return GetSessionAt(time.Now())
+}
+
+// GetSessionAt returns the trading session active at the given time
+func GetSessionAt(t time.Time) SessionInfo {
+	now := t.UTC()
 	hour := now.Hour()
 
 	// Bangladesh time for display
@@ -102,7 +107,12 @@ func IsGoodTimeToTrade() bool {
 // GetSessionScore returns a score bonus/penalty based on session
 // Used in confluence scoring
 func GetSessionScore() int {
-	session := GetCurrentSession()
+	return GetSessionScoreAt(time.Now())
+}
+
+// GetSessionScoreAt returns the session score bonus/penalty at the given time
+func GetSessionScoreAt(t time.Time) int {
+	session := GetSessionAt(t)
 
 	switch session.Session {
 	case SessionOverlap:
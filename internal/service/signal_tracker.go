@@ -1,28 +1,45 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"mrcrypto-go/internal/logger"
 	"mrcrypto-go/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var trackerLog = logger.Component("tracker")
+
 // PatternStats tracks performance of specific indicator patterns
 type PatternStats struct {
-	Pattern      string
-	WinCount     int
-	LossCount    int
-	TotalCount   int
-	WinRate      float64
-	IsEnabled    bool
-	LastOutcomes []bool // Last 10 outcomes for recent performance
+	Pattern      string    `json:"pattern" bson:"_id"`
+	WinCount     int       `json:"win_count" bson:"win_count"`
+	LossCount    int       `json:"loss_count" bson:"loss_count"`
+	TotalCount   int       `json:"total_count" bson:"total_count"`
+	WinRate      float64   `json:"win_rate" bson:"win_rate"`
+	IsEnabled    bool      `json:"is_enabled" bson:"is_enabled"`
+	LastOutcomes []bool    `json:"last_outcomes" bson:"last_outcomes"` // Last 10 outcomes for recent performance
+	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// PatternStore persists pattern statistics between restarts
+type PatternStore interface {
+	LoadAll() ([]PatternStats, error)
+	Save(stats PatternStats) error
 }
 
 // SignalTracker tracks and learns from signal outcomes
 type SignalTracker struct {
 	patterns map[string]*PatternStats
+	store    PatternStore // Optional write-through persistence, nil for memory only
 	mu       sync.RWMutex
 }
 
@@ -98,13 +115,82 @@ func GeneratePatternFingerprint(signal *model.Signal) string {
 	return strings.Join(components, "+")
 }
 
+// AttachStore loads persisted statistics and writes every later outcome through to the store.
+// Returns the number of patterns loaded; 0 means the store is empty (see Rebuild).
+func (st *SignalTracker) AttachStore(store PatternStore) (int, error) {
+	stored, err := store.LoadAll()
+	if err != nil {
+		return 0, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.store = store
+	for i := range stored {
+		stats := stored[i]
+		st.patterns[stats.Pattern] = &stats
+	}
+
+	trackerLog.Info("📊 Loaded pattern stats from store", "patterns", len(stored))
+	return len(stored), nil
+}
+
+// Rebuild replays closed signals (oldest first) into the tracker and persists the result
+func (st *SignalTracker) Rebuild(closed []model.Signal) {
+	st.mu.Lock()
+	touched := make(map[string]bool)
+	for i := range closed {
+		pattern := GeneratePatternFingerprint(&closed[i])
+		st.record(pattern, closed[i].PnL > 0)
+		touched[pattern] = true
+	}
+
+	snapshots := make([]PatternStats, 0, len(touched))
+	for pattern := range touched {
+		snapshots = append(snapshots, st.snapshot(pattern))
+	}
+	store := st.store
+	st.mu.Unlock()
+
+	if store != nil {
+		for _, stats := range snapshots {
+			if err := store.Save(stats); err != nil {
+				trackerLog.Warn("⚠️  Failed to persist pattern", "pattern", stats.Pattern, "error", err)
+			}
+		}
+	}
+
+	trackerLog.Info("📊 Rebuilt patterns from closed signals", "patterns", len(snapshots), "signals", len(closed))
+}
+
 // RecordSignalOutcome records whether a signal won or lost
 func (st *SignalTracker) RecordSignalOutcome(signal *model.Signal, won bool) {
 	pattern := GeneratePatternFingerprint(signal)
 
 	st.mu.Lock()
-	defer st.mu.Unlock()
+	stats := st.record(pattern, won)
+	snapshot := st.snapshot(pattern)
+	store := st.store
+	st.mu.Unlock()
+
+	outcome := "LOSS"
+	if won {
+		outcome = "WIN"
+	}
+	trackerLog.Info("📊 Outcome recorded", "symbol", signal.Symbol, "signal_id", signal.ID, "outcome", outcome,
+		"pattern", pattern, "win_rate", stats.WinRate, "wins", stats.WinCount, "trades", stats.TotalCount)
+
+	// Write through so a restart keeps learned win rates and disabled patterns
+	if store != nil {
+		if err := store.Save(snapshot); err != nil {
+			trackerLog.Warn("⚠️  Failed to persist pattern", "pattern", pattern, "error", err)
+		}
+	}
+}
 
+// record updates a pattern's stats. Caller must hold st.mu.
+func (st *SignalTracker) record(pattern string, won bool) PatternStats {
 	// Get or create pattern stats
 	stats, exists := st.patterns[pattern]
 	if !exists {
@@ -136,23 +222,24 @@ func (st *SignalTracker) RecordSignalOutcome(signal *model.Signal, won bool) {
 	}
 
 	// Auto-disable if performing poorly (after minimum sample size)
-	if stats.TotalCount >= 10 && stats.WinRate < 40 {
+	if stats.TotalCount >= 10 && stats.WinRate < 40 && stats.IsEnabled {
 		stats.IsEnabled = false
-		log.Printf("🚫 [Signal Tracker] Pattern DISABLED: %s (Win Rate: %.1f%% after %d trades)",
-			pattern, stats.WinRate, stats.TotalCount)
+		trackerLog.Warn("🚫 Pattern disabled", "pattern", pattern, "win_rate", stats.WinRate, "trades", stats.TotalCount)
 	} else if stats.TotalCount >= 10 && stats.WinRate >= 50 && !stats.IsEnabled {
 		// Re-enable if it recovers
 		stats.IsEnabled = true
-		log.Printf("✅ [Signal Tracker] Pattern RE-ENABLED: %s (Win Rate: %.1f%% after %d trades)",
-			pattern, stats.WinRate, stats.TotalCount)
+		trackerLog.Info("✅ Pattern re-enabled", "pattern", pattern, "win_rate", stats.WinRate, "trades", stats.TotalCount)
 	}
 
-	outcome := "LOSS"
-	if won {
-		outcome = "WIN"
-	}
-	log.Printf("📊 [Signal Tracker] Recorded %s - Pattern: %s | Win Rate: %.1f%% (%d/%d)",
-		outcome, pattern, stats.WinRate, stats.WinCount, stats.TotalCount)
+	stats.UpdatedAt = time.Now()
+	return *stats
+}
+
+// snapshot returns a copy of a pattern's stats safe to use outside the lock. Caller must hold st.mu.
+func (st *SignalTracker) snapshot(pattern string) PatternStats {
+	stats := *st.patterns[pattern]
+	stats.LastOutcomes = append([]bool(nil), stats.LastOutcomes...)
+	return stats
 }
 
 // IsPatternEnabled checks if a pattern is enabled
@@ -193,15 +280,32 @@ func (st *SignalTracker) GetAllPatternStats() map[string]*PatternStats {
 	return statsCopy
 }
 
+// RankPatterns returns patterns with at least minTrades outcomes, best win rate first.
+// Ties are broken by sample size so well-tested patterns rank above lucky ones.
+func (st *SignalTracker) RankPatterns(minTrades int) []PatternStats {
+	st.mu.RLock()
+	ranked := make([]PatternStats, 0, len(st.patterns))
+	for pattern, stats := range st.patterns {
+		if stats.TotalCount >= minTrades {
+			ranked = append(ranked, st.snapshot(pattern))
+		}
+	}
+	st.mu.RUnlock()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].WinRate != ranked[j].WinRate {
+			return ranked[i].WinRate > ranked[j].WinRate
+		}
+		return ranked[i].TotalCount > ranked[j].TotalCount
+	})
+	return ranked
+}
+
 // LogPerformanceSummary logs a summary of all patterns
 func (st *SignalTracker) LogPerformanceSummary() {
 	st.mu.RLock()
 	defer st.mu.RUnlock()
 
-	log.Println("==========================================")
-	log.Println("📈 SIGNAL PATTERN PERFORMANCE SUMMARY")
-	log.Println("==========================================")
-
 	totalPatterns := len(st.patterns)
 	enabledPatterns := 0
 	disabledPatterns := 0
@@ -213,17 +317,50 @@ func (st *SignalTracker) LogPerformanceSummary() {
 			disabledPatterns++
 		}
 
-		status := "✅ ENABLED"
-		if !stats.IsEnabled {
-			status = "🚫 DISABLED"
-		}
+		trackerLog.Info("📈 Pattern", "pattern", stats.Pattern, "enabled", stats.IsEnabled,
+			"win_rate", stats.WinRate, "wins", stats.WinCount, "trades", stats.TotalCount)
+	}
+
+	trackerLog.Info("📈 Signal pattern performance summary",
+		"patterns", totalPatterns, "enabled", enabledPatterns, "disabled", disabledPatterns)
+}
+
+// MongoPatternStore persists pattern statistics in the "pattern_stats" collection
+type MongoPatternStore struct {
+	collection *mongo.Collection
+}
 
-		log.Printf("%s | %s | Win Rate: %.1f%% (%d/%d trades)",
-			status, stats.Pattern, stats.WinRate, stats.WinCount, stats.TotalCount)
+// NewMongoPatternStore creates a Mongo-backed pattern store
+func NewMongoPatternStore(db *mongo.Database) *MongoPatternStore {
+	return &MongoPatternStore{collection: db.Collection("pattern_stats")}
+}
+
+// LoadAll returns every stored pattern
+func (s *MongoPatternStore) LoadAll() ([]PatternStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pattern stats: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	log.Println("------------------------------------------")
-	log.Printf("Total Patterns: %d | Enabled: %d | Disabled: %d",
-		totalPatterns, enabledPatterns, disabledPatterns)
-	log.Println("==========================================")
+	var stats []PatternStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode pattern stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Save replaces the stored stats of one pattern
+func (s *MongoPatternStore) Save(stats PatternStats) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": stats.Pattern}, stats, opts); err != nil {
+		return fmt.Errorf("failed to save pattern stats: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,120 @@
+package service
+
+import "mrcrypto-go/internal/model"
+
+// PnLSummary is the realised PnL overview (today, last 7 days, TP1 booked on open positions)
+type PnLSummary struct {
+	TodayPnL         float64 `json:"today_pnl"`
+	TodayTrades      int     `json:"today_trades"`
+	TodayWins        int     `json:"today_wins"`
+	TodayLosses      int     `json:"today_losses"`
+	TodayWinRate     float64 `json:"today_win_rate"`
+	WeekPnL          float64 `json:"week_pnl"`
+	WeekTrades       int     `json:"week_trades"`
+	BookedPnL        float64 `json:"booked_pnl"`        // TP1 half already realised on PARTIAL signals
+	PartialPositions int     `json:"partial_positions"` // Open signals that booked TP1
+}
+
+// SummarizePnL builds the PnL overview from today's and this week's closed signals and the PARTIAL ones
+func SummarizePnL(today, week, partial []model.Signal) PnLSummary {
+	summary := PnLSummary{
+		TodayTrades:      len(today),
+		WeekTrades:       len(week),
+		PartialPositions: len(partial),
+	}
+
+	for _, sig := range today {
+		summary.TodayPnL += sig.PnL
+		if sig.PnL > 0 {
+			summary.TodayWins++
+		} else {
+			summary.TodayLosses++
+		}
+	}
+	if summary.TodayTrades > 0 {
+		summary.TodayWinRate = (float64(summary.TodayWins) / float64(summary.TodayTrades)) * 100
+	}
+
+	for _, sig := range week {
+		summary.WeekPnL += sig.PnL
+	}
+
+	for _, sig := range partial {
+		summary.BookedPnL += sig.TP1PnL * model.TP1CloseFraction
+	}
+	return summary
+}
+
+// TradeRef identifies a closed signal in the statistics
+type TradeRef struct {
+	ID     string  `json:"id"`
+	Symbol string  `json:"symbol"`
+	PnL    float64 `json:"pnl"`
+}
+
+// PerformanceStats are the all-time statistics of closed signals
+type PerformanceStats struct {
+	TotalTrades  int      `json:"total_trades"`
+	Wins         int      `json:"wins"`
+	Losses       int      `json:"losses"`
+	WinRate      float64  `json:"win_rate"`
+	ProfitFactor float64  `json:"profit_factor"`
+	AvgWin       float64  `json:"avg_win"`
+	AvgLoss      float64  `json:"avg_loss"`
+	TP1Hits      int      `json:"tp1_hits"`
+	TP1HitRate   float64  `json:"tp1_hit_rate"`
+	BestTrade    TradeRef `json:"best_trade"`
+	WorstTrade   TradeRef `json:"worst_trade"`
+}
+
+// CalculatePerformanceStats computes win rate, profit factor and best/worst trade. A win is PnL > 0.
+func CalculatePerformanceStats(closed []model.Signal) PerformanceStats {
+	var stats PerformanceStats
+	if len(closed) == 0 {
+		return stats
+	}
+
+	totalWinPnL, totalLossPnL := 0.0, 0.0
+	stats.BestTrade.PnL, stats.WorstTrade.PnL = -999, 999
+
+	for _, sig := range closed {
+		if sig.TP1Hit {
+			stats.TP1Hits++
+		}
+		if sig.PnL > 0 {
+			stats.Wins++
+			totalWinPnL += sig.PnL
+			if sig.PnL > stats.BestTrade.PnL {
+				stats.BestTrade = TradeRef{ID: sig.ID, Symbol: sig.Symbol, PnL: sig.PnL}
+			}
+		} else {
+			stats.Losses++
+			totalLossPnL += sig.PnL
+			if sig.PnL < stats.WorstTrade.PnL {
+				stats.WorstTrade = TradeRef{ID: sig.ID, Symbol: sig.Symbol, PnL: sig.PnL}
+			}
+		}
+	}
+
+	// No win (or no loss) at all leaves nothing to reference
+	if stats.Wins == 0 {
+		stats.BestTrade = TradeRef{}
+	}
+	if stats.Losses == 0 {
+		stats.WorstTrade = TradeRef{}
+	}
+
+	stats.TotalTrades = stats.Wins + stats.Losses
+	stats.WinRate = (float64(stats.Wins) / float64(stats.TotalTrades)) * 100
+	stats.TP1HitRate = float64(stats.TP1Hits) / float64(stats.TotalTrades) * 100
+	if stats.Wins > 0 {
+		stats.AvgWin = totalWinPnL / float64(stats.Wins)
+	}
+	if stats.Losses > 0 {
+		stats.AvgLoss = totalLossPnL / float64(stats.Losses)
+	}
+	if totalLossPnL != 0 {
+		stats.ProfitFactor = -totalWinPnL / totalLossPnL
+	}
+	return stats
+}
@@ -1,26 +1,95 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"math"
+	"sync"
 	"time"
 
+	"mrcrypto-go/internal/config"
 	"mrcrypto-go/internal/indicator"
+	"mrcrypto-go/internal/logger"
 	internalmath "mrcrypto-go/internal/math"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 )
 
+var strategyLog = logger.Component("strategy")
+
+// Evaluation modes
+const (
+	EvaluationLive   = "live"   // The last candle of each timeframe may still be forming (values repaint)
+	EvaluationClosed = "closed" // Closed candles only; each 5m close is evaluated once per symbol
+)
+
 type StrategyService struct {
-	binance *BinanceService
+	market  MarketDataProvider
 	tracker *SignalTracker
+	profile config.StrategyProfile
+	now     func() time.Time
+	mode    string
+	journal CandidateJournal // Optional near-miss journal
+
+	depth          *DepthAnalyzer // nil = plain bid/ask volume sums
+	depthAggregate bool           // Analyze the books of every enabled exchange together
+
+	evaluatedMu sync.Mutex
+	evaluated   map[string]int64 // Closed mode: last 5m OpenTime evaluated per symbol
 }
 
-func NewStrategyService(binance *BinanceService, tracker *SignalTracker) *StrategyService {
+func NewStrategyService(market MarketDataProvider, tracker *SignalTracker) *StrategyService {
 	return &StrategyService{
-		binance: binance,
-		tracker: tracker,
+		market:    market,
+		tracker:   tracker,
+		profile:   config.DefaultStrategyProfile(),
+		now:       time.Now,
+		mode:      EvaluationLive,
+		evaluated: make(map[string]int64),
+	}
+}
+
+// SetEvaluationMode selects live (default) or closed-candle evaluation
+func (s *StrategyService) SetEvaluationMode(mode string) error {
+	if mode != EvaluationLive && mode != EvaluationClosed {
+		return fmt.Errorf("unknown evaluation mode %q (use live or closed)", mode)
 	}
+	s.mode = mode
+	return nil
+}
+
+// SetCandidateJournal records every rejected setup as a near-miss candidate
+func (s *StrategyService) SetCandidateJournal(journal CandidateJournal) {
+	s.journal = journal
+}
+
+// SetDepthAnalyzer scores the order book by distance-weighted imbalance and persistent walls.
+// With aggregate, the books of every enabled exchange are combined (when the provider can fetch them).
+func (s *StrategyService) SetDepthAnalyzer(analyzer *DepthAnalyzer, aggregate bool) {
+	s.depth = analyzer
+	s.depthAggregate = aggregate
+}
+
+// SetProfile replaces the strategy thresholds (default: config.DefaultStrategyProfile)
+func (s *StrategyService) SetProfile(profile config.StrategyProfile) {
+	s.profile = profile
+}
+
+// Profile returns the active strategy profile
+func (s *StrategyService) Profile() config.StrategyProfile {
+	return s.profile
+}
+
+// SetMinScore overrides the minimum confluence score of the active profile.
+// Used by the backtester to compare thresholds.
+func (s *StrategyService) SetMinScore(score int) {
+	s.profile.MinScore = score
+}
+
+// SetClock overrides the evaluation clock (default time.Now).
+// Lets fixture-driven runs of EvaluateSymbol be deterministic.
+func (s *StrategyService) SetClock(now func() time.Time) {
+	s.now = now
 }
 
 // ========================================
@@ -28,82 +97,341 @@ func NewStrategyService(binance *BinanceService, tracker *SignalTracker) *Strate
 // Proper Order: Context → Key Levels → Regime → Confluence → Entry → Risk
 // ========================================
 
-// EvaluateSymbol analyzes a symbol using professional multi-factor confluence approach
-func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64, error) {
-	log.Printf("🔄 [Strategy] Evaluating %s...", symbol)
+// MarketSnapshot holds every market input needed for a single evaluation.
+// Live scans fill it from Binance; the backtester fills it from stored klines.
+type MarketSnapshot struct {
+	Symbol string
+	Time   time.Time // Evaluation time (drives session detection and signal timestamp)
+
+	Klines1d  []model.Kline
+	Klines4h  []model.Kline
+	Klines1h  []model.Kline
+	Klines15m []model.Kline
+	Klines5m  []model.Kline
+
+	BTCKlines4h []model.Kline // Empty for BTCUSDT itself or when unavailable
+
+	Funding   *FundingRateInfo    // nil when unavailable
+	OrderBook *OrderBookDepth     // nil when unavailable
+	PerpSpot  *PerpSpotDivergence // nil when unavailable
+
+	CrossExchange *CrossExchangeSpread // nil unless several exchanges are enabled
+	Derivatives   *DerivativesContext  // Open interest regime and positioning, nil when unavailable
+}
+
+// ClosedOnly returns a copy of the snapshot without the candles still forming at snapshot.Time
+func (m *MarketSnapshot) ClosedOnly() *MarketSnapshot {
+	cutoff := m.Time.UnixMilli()
+	closed := *m
+	closed.Klines1d = dropUnclosed(m.Klines1d, cutoff)
+	closed.Klines4h = dropUnclosed(m.Klines4h, cutoff)
+	closed.Klines1h = dropUnclosed(m.Klines1h, cutoff)
+	closed.Klines15m = dropUnclosed(m.Klines15m, cutoff)
+	closed.Klines5m = dropUnclosed(m.Klines5m, cutoff)
+	closed.BTCKlines4h = dropUnclosed(m.BTCKlines4h, cutoff)
+	return &closed
+}
+
+// dropUnclosed trims trailing candles whose CloseTime has not passed at cutoff (Unix ms)
+func dropUnclosed(klines []model.Kline, cutoff int64) []model.Kline {
+	n := len(klines)
+	for n > 0 && klines[n-1].CloseTime >= cutoff {
+		n--
+	}
+	return klines[:n]
+}
+
+// CandleOpenTimes returns the OpenTime of the last candle of every series, keyed by interval
+// (BTC context as "BTCUSDT_4h"). Together with the symbol this pins down the exact candles evaluated.
+func (m *MarketSnapshot) CandleOpenTimes() map[string]int64 {
+	openTimes := make(map[string]int64)
+	for key, klines := range map[string][]model.Kline{
+		"1d":         m.Klines1d,
+		"4h":         m.Klines4h,
+		"1h":         m.Klines1h,
+		"15m":        m.Klines15m,
+		"5m":         m.Klines5m,
+		"BTCUSDT_4h": m.BTCKlines4h,
+	} {
+		if len(klines) > 0 {
+			openTimes[key] = klines[len(klines)-1].OpenTime
+		}
+	}
+	return openTimes
+}
+
+// EvaluateSymbol analyzes a symbol using professional multi-factor confluence approach.
+// A cancelled ctx stops the evaluation between market data requests.
+func (s *StrategyService) EvaluateSymbol(ctx context.Context, symbol string) (*model.Signal, float64, error) {
+	ctx = logger.WithSymbol(ctx, symbol)
+	strategyLog.DebugContext(ctx, "🔄 Evaluating", "profile", s.profile.Name)
+
+	// Skip dead zone before spending any request weight (unless the journal should see the setup)
+	if s.journal == nil && GetSessionAt(s.now()).Session == SessionDeadZone {
+		strategyLog.DebugContext(ctx, "⏭️  Skipped (Dead Zone - low volatility period)")
+		metrics.SignalsRejected.Inc(s.profile.Name, model.StageDeadZone)
+		return nil, 0, nil
+	}
+
+	snapshot, err := s.fetchSnapshot(ctx, symbol)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if s.mode == EvaluationClosed {
+		snapshot = snapshot.ClosedOnly()
+		if !s.markEvaluated(symbol, snapshot.Klines5m) {
+			strategyLog.DebugContext(ctx, "⏭️  Skipped (5m candle already evaluated on close)")
+			return nil, 0, nil
+		}
+	}
+
+	signal, price, err := s.evaluate(ctx, snapshot, s.journal, true)
+	if signal != nil {
+		metrics.SignalsGenerated.Inc(s.profile.Name)
+	}
+	return signal, price, err
+}
+
+// markEvaluated records the last closed 5m candle of a symbol.
+// Returns false when that candle was already evaluated.
+func (s *StrategyService) markEvaluated(symbol string, klines5m []model.Kline) bool {
+	if len(klines5m) == 0 {
+		return true // Let EvaluateSnapshot report the missing data
+	}
+	openTime := klines5m[len(klines5m)-1].OpenTime
+
+	s.evaluatedMu.Lock()
+	defer s.evaluatedMu.Unlock()
+	if s.evaluated[symbol] == openTime {
+		return false
+	}
+	s.evaluated[symbol] = openTime
+	return true
+}
+
+// evaluationTime is the snapshot time: the exchange clock in closed mode (when the provider has one)
+func (s *StrategyService) evaluationTime() time.Time {
+	if s.mode != EvaluationClosed {
+		return s.now()
+	}
+	clock, ok := s.market.(ServerClock)
+	if !ok {
+		return s.now()
+	}
+	serverNow, err := clock.ServerTime()
+	if err != nil {
+		strategyLog.Warn("⚠️  Failed to read server time, using local clock", "error", err)
+		return s.now()
+	}
+	return serverNow
+}
 
+// fetchSnapshot collects all live market data for a symbol, checking ctx before each request
+func (s *StrategyService) fetchSnapshot(ctx context.Context, symbol string) (*MarketSnapshot, error) {
 	// ========================================
 	// STEP 1: DATA COLLECTION (Higher TF First)
 	// ========================================
+	snapshot := &MarketSnapshot{
+		Symbol: symbol,
+		Time:   s.evaluationTime(),
+	}
+
+	var err error
+	getKlines := func(symbol, interval string, limit int) ([]model.Kline, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return s.market.GetKlines(symbol, interval, limit)
+	}
 
 	// Daily for pivot points (100 candles = ~3 months context)
-	klines1d, err := s.binance.GetKlines(symbol, "1d", 100)
+	snapshot.Klines1d, err = getKlines(symbol, "1d", 100)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch 1d klines: %w", err)
+		return nil, fmt.Errorf("failed to fetch 1d klines: %w", err)
 	}
 
 	// 4H for trend direction and key levels (500 candles = ~83 days)
-	klines4h, err := s.binance.GetKlines(symbol, "4h", 500)
+	snapshot.Klines4h, err = getKlines(symbol, "4h", 500)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch 4h klines: %w", err)
+		return nil, fmt.Errorf("failed to fetch 4h klines: %w", err)
 	}
 
 	// 1H for confirmation (500 candles = ~20 days)
-	klines1h, err := s.binance.GetKlines(symbol, "1h", 500)
+	snapshot.Klines1h, err = getKlines(symbol, "1h", 500)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch 1h klines: %w", err)
+		return nil, fmt.Errorf("failed to fetch 1h klines: %w", err)
 	}
 
 	// 15m for alignment (500 candles = ~5 days)
-	klines15m, err := s.binance.GetKlines(symbol, "15m", 500)
+	snapshot.Klines15m, err = getKlines(symbol, "15m", 500)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch 15m klines: %w", err)
+		return nil, fmt.Errorf("failed to fetch 15m klines: %w", err)
 	}
 
 	// 5m for entry timing (500 candles = ~1.7 days)
-	klines5m, err := s.binance.GetKlines(symbol, "5m", 500)
+	snapshot.Klines5m, err = getKlines(symbol, "5m", 500)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch 5m klines: %w", err)
+		return nil, fmt.Errorf("failed to fetch 5m klines: %w", err)
 	}
 
 	// ========================================
 	// STEP 1.1: FETCH BTC CONTEXT (Correlation)
 	// ========================================
-	var btcTrend string
 	if symbol != "BTCUSDT" {
-		btcKlines4h, err := s.binance.GetKlines("BTCUSDT", "4h", 500)
+		btcKlines4h, err := getKlines("BTCUSDT", "4h", 500)
 		if err == nil {
-			btcCloses, _, _, _ := extractSeries(btcKlines4h)
-			btcEma50 := indicator.CalculateEMA(btcCloses, 50)
-			if len(btcEma50) > 0 {
-				lastBtcPrice := btcCloses[len(btcCloses)-1]
-				lastBtcEma := btcEma50[len(btcEma50)-1]
-				if lastBtcPrice > lastBtcEma {
-					btcTrend = "UP"
-				} else {
-					btcTrend = "DOWN"
-				}
-			}
+			snapshot.BTCKlines4h = btcKlines4h
 		} else {
-			log.Printf("⚠️  [Strategy] Failed to fetch BTC klines: %v", err)
+			strategyLog.WarnContext(ctx, "⚠️  Failed to fetch BTC klines", "error", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Fetch funding rate
+	snapshot.Funding, _ = s.market.GetFundingRate(symbol)
+
+	// Order Book Depth Analysis (limit 500 for comprehensive data)
+	snapshot.OrderBook, err = s.fetchOrderBook(symbol, snapshot.Time)
+	if err != nil {
+		strategyLog.WarnContext(ctx, "⚠️  Failed to fetch order book", "error", err)
+	}
+
+	// Perp vs Spot basis (futures mark price vs spot index price)
+	snapshot.PerpSpot, err = s.market.GetPerpSpotDivergence(symbol)
+	if err != nil {
+		strategyLog.WarnContext(ctx, "⚠️  Failed to fetch perp-spot divergence", "error", err)
+	}
+
+	// Price and funding spreads across the enabled exchanges
+	if cross, ok := s.market.(CrossExchangeProvider); ok {
+		snapshot.CrossExchange, err = cross.GetCrossExchangeSpread(symbol)
+		if err != nil {
+			strategyLog.WarnContext(ctx, "⚠️  Failed to compare exchanges", "error", err)
+		}
+	}
+
+	// Open interest, long/short ratio and taker volume (not every exchange has them)
+	if derivatives, ok := s.market.(DerivativesProvider); ok {
+		snapshot.Derivatives, err = FetchDerivatives(derivatives, symbol)
+		if err != nil {
+			strategyLog.DebugContext(ctx, "No positioning data", "error", err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// fetchOrderBook runs the depth analyzer on the raw book(s), or sums the book without one
+func (s *StrategyService) fetchOrderBook(symbol string, now time.Time) (*OrderBookDepth, error) {
+	provider, ok := s.market.(OrderBookProvider)
+	if s.depth == nil || !ok {
+		return s.market.GetOrderBookDepth(symbol, 500)
+	}
+
+	var books []*OrderBook
+	var err error
+	if venues, ok := s.market.(VenueBookProvider); ok && s.depthAggregate {
+		books, err = venues.GetOrderBooks(symbol, 500)
+	} else {
+		var book *OrderBook
+		book, err = provider.GetOrderBook(symbol, 500)
+		books = []*OrderBook{book}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := s.depth.Analyze(symbol, now, books...)
+	if analysis == nil {
+		return nil, fmt.Errorf("empty order book for %s", symbol)
+	}
+	strategyLog.Debug("📚 Depth", "symbol", symbol, "exchanges", analysis.Exchanges,
+		"weighted_imbalance", analysis.WeightedImbalance, "bands", analysis.BandSummary(), "walls", analysis.WallSummary())
+	return analysis.Depth(), nil
+}
+
+// Preview evaluates a symbol on demand. Nothing is journaled and, in closed mode, the candle is not
+// marked as evaluated, so the scanner is unaffected. A rejected setup comes back as a candidate
+// (signal and candidate are both nil when market data was insufficient).
+func (s *StrategyService) Preview(ctx context.Context, symbol string) (*model.Signal, *model.Candidate, error) {
+	ctx = logger.WithSymbol(ctx, symbol)
+	snapshot, err := s.fetchSnapshot(ctx, symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	capture := &candidateCapture{}
+	signal, _, err := s.evaluate(ctx, snapshot, capture, false)
+	return signal, capture.candidate, err
+}
+
+// candidateCapture keeps the rejected setup of a preview instead of journaling it
+type candidateCapture struct {
+	candidate *model.Candidate
+}
+
+func (c *candidateCapture) RecordCandidate(_ context.Context, candidate *model.Candidate) error {
+	c.candidate = candidate
+	return nil
+}
+
+// EvaluateSnapshot runs the full scoring pipeline on already-collected market data.
+// It performs no network calls, so it is safe to drive from stored history.
+func (s *StrategyService) EvaluateSnapshot(snapshot *MarketSnapshot) (*model.Signal, float64, error) {
+	return s.evaluate(logger.WithSymbol(context.Background(), snapshot.Symbol), snapshot, s.journal, false)
+}
+
+// evaluate runs the pipeline, recording rejected setups to journal (nil = none).
+// Rejections of scheduled scans are counted in the metrics; previews and replays are not.
+func (s *StrategyService) evaluate(ctx context.Context, snapshot *MarketSnapshot, journal CandidateJournal, scan bool) (*model.Signal, float64, error) {
+	if s.mode == EvaluationClosed {
+		snapshot = snapshot.ClosedOnly()
+	}
+	symbol := snapshot.Symbol
+	klines1d := snapshot.Klines1d
+	klines4h := snapshot.Klines4h
+	klines1h := snapshot.Klines1h
+	klines15m := snapshot.Klines15m
+	klines5m := snapshot.Klines5m
+
+	var btcTrend string
+	if len(snapshot.BTCKlines4h) > 0 {
+		btcCloses, _, _, _ := extractSeries(snapshot.BTCKlines4h)
+		btcEma50 := indicator.CalculateEMA(btcCloses, 50)
+		if len(btcEma50) > 0 {
+			lastBtcPrice := btcCloses[len(btcCloses)-1]
+			lastBtcEma := btcEma50[len(btcEma50)-1]
+			if lastBtcPrice > lastBtcEma {
+				btcTrend = "UP"
+			} else {
+				btcTrend = "DOWN"
+			}
 		}
 	}
 
 	// ========================================
 	// STEP 1.2: SESSION & FUNDING CHECK (NEW)
 	// ========================================
-	sessionInfo := GetCurrentSession()
-	log.Printf("🕐 [Strategy] %s - Session: %s (%s volatility)", symbol, sessionInfo.Name, sessionInfo.Volatility)
+	sessionInfo := GetSessionAt(snapshot.Time)
+	strategyLog.DebugContext(ctx, "🕐 Session", "session", sessionInfo.Name, "volatility", sessionInfo.Volatility)
 
 	// Skip dead zone signals with penalty
-	sessionScore := GetSessionScore()
-	if sessionInfo.Session == SessionDeadZone {
-		log.Printf("⏭️  [Strategy] %s - Skipped (Dead Zone - low volatility period)", symbol)
+	// With a candidate journal the setup is still analyzed and rejected after regime detection
+	sessionScore := GetSessionScoreAt(snapshot.Time)
+	deadZone := sessionInfo.Session == SessionDeadZone
+	if deadZone && journal == nil {
+		strategyLog.DebugContext(ctx, "⏭️  Skipped (Dead Zone - low volatility period)")
+		if scan {
+			metrics.SignalsRejected.Inc(s.profile.Name, model.StageDeadZone)
+		}
 		return nil, 0, nil
 	}
 
-	// Fetch funding rate
-	fundingInfo, _ := GetFundingRate(symbol)
+	fundingInfo := snapshot.Funding
 	var fundingRate float64
 	var fundingSentiment string
 	var fundingWarning string
@@ -111,7 +439,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 		fundingRate = fundingInfo.FundingRate
 		fundingSentiment = fundingInfo.Sentiment
 		fundingWarning = fundingInfo.Warning
-		log.Printf("📊 [Strategy] %s - Funding: %.4f%% (%s)", symbol, fundingRate, fundingSentiment)
+		strategyLog.DebugContext(ctx, "📊 Funding", "rate_pct", fundingRate, "sentiment", fundingSentiment)
 	}
 
 	// Extract price arrays
@@ -122,13 +450,13 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 
 	// Validate we have sufficient data
 	if len(closes5m) == 0 || len(closes4h) == 0 || len(closes1h) == 0 || len(closes15m) == 0 {
-		log.Printf("⚠️  [Strategy] %s - Insufficient price data after extraction", symbol)
+		strategyLog.WarnContext(ctx, "⚠️  Insufficient price data after extraction")
 		return nil, 0, nil
 	}
 
 	currentPrice := closes5m[len(closes5m)-1]
 	if !ValidatePrice(currentPrice) {
-		log.Printf("⚠️  [Strategy] %s - Invalid current price: %v", symbol, currentPrice)
+		strategyLog.WarnContext(ctx, "⚠️  Invalid current price", "price", currentPrice)
 		return nil, 0, nil
 	}
 
@@ -136,7 +464,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// STEP 1.3: MARKET STRUCTURE ANALYSIS (NEW)
 	// ========================================
 	structureInfo := indicator.AnalyzeMarketStructure(klines1h, 30)
-	log.Printf("📐 [Strategy] %s - Structure: %s", symbol, structureInfo.Structure)
+	strategyLog.DebugContext(ctx, "📐 Market structure", "structure", structureInfo.Structure)
 
 	// ========================================
 	// STEP 1.4: ADVANCED FEATURES
@@ -144,27 +472,41 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// CVD - Cumulative Volume Delta (using 15m for entry timing)
 	cvdValue, cvdTrend := indicator.GetLastCVDTrend(klines15m, 20)
 	cvdDivergence := indicator.GetCVDDivergence(klines15m, 30)
-	log.Printf("📊 [CVD] %s - Value: %.2f | Trend: %.2f | Divergence: %s",
-		symbol, cvdValue, cvdTrend, cvdDivergence)
+	strategyLog.DebugContext(ctx, "📊 CVD", "value", cvdValue, "trend", cvdTrend, "divergence", cvdDivergence)
 
-	// Order Book Depth Analysis (limit 500 for comprehensive data)
-	orderBookDepth, err := s.binance.GetOrderBookDepth(symbol, 500)
-	if err != nil {
-		log.Printf("⚠️  [Strategy] %s - Failed to fetch order book: %v", symbol, err)
+	// Order Book Depth Analysis
+	orderBookDepth := snapshot.OrderBook
+	if orderBookDepth == nil {
 		orderBookDepth = &OrderBookDepth{Signal: "Unknown", Imbalance: 0}
 	}
 
 	// Perp vs Spot Divergence
-	perpSpotDiv, err := s.binance.GetPerpSpotDivergence(symbol, currentPrice)
-	if err != nil {
-		log.Printf("⚠️  [Strategy] %s - Failed to fetch perp-spot divergence: %v", symbol, err)
+	perpSpotDiv := snapshot.PerpSpot
+	if perpSpotDiv == nil {
 		perpSpotDiv = &PerpSpotDivergence{Sentiment: "Unknown", Premium: 0}
 	}
 
+	// Order book depth bands and walls
+	orderBookBands, orderBookWalls := "", ""
+	if orderBookDepth.Analysis != nil {
+		orderBookBands, orderBookWalls = orderBookDepth.Analysis.BandSummary(), orderBookDepth.Analysis.WallSummary()
+	}
+
+	derivatives := snapshot.Derivatives
+	if derivatives == nil {
+		derivatives = &DerivativesContext{}
+	}
+
+	// Cross-exchange price and funding spreads
+	crossExchange := snapshot.CrossExchange
+	if crossExchange == nil {
+		crossExchange = &CrossExchangeSpread{}
+	}
+
 	// ========================================
 	// STEP 2: KEY LEVELS (Before anything else)
 	// ========================================
-	log.Printf("⏳ [Strategy] %s - Calculating key levels...", symbol)
+	strategyLog.DebugContext(ctx, "⏳ Calculating key levels")
 
 	// Daily Pivot Points
 	var pivotPoints internalmath.PivotPoints
@@ -186,7 +528,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	}
 	ema50Value := ema50_4h[len(ema50_4h)-1]
 	if !ValidateFloat64(ema50Value) {
-		log.Printf("⚠️  [Strategy] %s - Invalid EMA50 value", symbol)
+		strategyLog.WarnContext(ctx, "⚠️  Invalid EMA50 value")
 		return nil, currentPrice, nil
 	}
 
@@ -204,10 +546,10 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// ========================================
 	// STEP 3: INDICATOR CALCULATION
 	// ========================================
-	log.Printf("⏳ [Strategy] %s - Calculating indicators...", symbol)
+	strategyLog.DebugContext(ctx, "⏳ Calculating indicators")
 
 	// RSI - Multi-timeframe
-	log.Printf("Debug: Calculating RSI for %s...", symbol)
+	strategyLog.DebugContext(ctx, "Calculating RSI")
 	rsi4h := indicator.GetLastRSI(closes4h, 14)
 	rsi1h := indicator.GetLastRSI(closes1h, 14)
 	rsi15mVec := indicator.CalculateRSI(closes15m, 14) // Vector for advanced analysis
@@ -219,19 +561,19 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 
 	// Validate RSI values
 	if !ValidateFloat64(rsi4h) || !ValidateFloat64(rsi1h) {
-		log.Printf("⚠️  [Strategy] %s - Invalid RSI values", symbol)
+		strategyLog.WarnContext(ctx, "⚠️  Invalid RSI values")
 		return nil, currentPrice, nil
 	}
 
 	// ADX - Trend strength
-	log.Printf("Debug: Calculating ADX for %s...", symbol)
+	strategyLog.DebugContext(ctx, "Calculating ADX")
 	adx4h := indicator.GetLastADX(highs4h, lows4h, closes4h, 14)
 	adx1h := indicator.GetLastADX(highs1h, lows1h, closes1h, 14)
 	adx15m := indicator.GetLastADX(highs15m, lows15m, closes15m, 14)
 
 	// Validate data
 	if rsi4h == 0 || rsi1h == 0 || adx4h == 0 {
-		log.Printf("⚠️  [Strategy] %s - Insufficient data", symbol)
+		strategyLog.WarnContext(ctx, "⚠️  Insufficient data for ADX")
 		return nil, currentPrice, nil
 	}
 
@@ -242,7 +584,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// Volume
 	avgVol := calculateAverage(volumes5m)
 	if len(volumes5m) == 0 {
-		log.Printf("⚠️  [Strategy] %s - No volume data", symbol)
+		strategyLog.WarnContext(ctx, "⚠️  No volume data")
 		return nil, currentPrice, nil
 	}
 	currentVol := volumes5m[len(volumes5m)-1]
@@ -252,14 +594,14 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	}
 
 	// Order Flow
-	log.Printf("Debug: Calculating Order Flow for %s...", symbol)
+	strategyLog.DebugContext(ctx, "Calculating order flow")
 	orderFlowDelta := calculateOrderFlowDelta(klines5m)
 
 	// ========================================
 	// STEP 3.1: SMC & VOLUME PROFILE
 	// ========================================
 	// SMC (Smart Money Concepts) - Use 1H for reliability
-	log.Printf("Debug: Calculating SMC for %s...", symbol)
+	strategyLog.DebugContext(ctx, "Calculating SMC")
 	fvgs := indicator.FindFVGs(klines1h)
 	obs := indicator.FindOrderBlocks(klines1h)
 
@@ -267,7 +609,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	inOB, obType := indicator.IsPriceInOB(currentPrice, obs)
 
 	// Volume Profile - Use 4H for major levels
-	log.Printf("Debug: Calculating Volume Profile for %s...", symbol)
+	strategyLog.DebugContext(ctx, "Calculating volume profile")
 	vp := indicator.CalculateVolumeProfile(klines4h, 100)
 	pocDist := indicator.GetPOCDistance(currentPrice, vp.POC)
 
@@ -280,38 +622,73 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	liquiditySweep := indicator.FindLiquiditySweeps(klines1h)
 	trendState, _, _ := indicator.CheckTrendState(closes4h, 50, 200)
 
-	log.Printf("📊 [Strategy] %s - Advanced: %s | Div: %s | Sweep: %s | Trend: %s | Stoch: %.1f/%.1f",
-		symbol, candlestick, divergence, liquiditySweep, trendState, stochK, stochD)
+	strategyLog.DebugContext(ctx, "📊 Advanced indicators",
+		"candlestick", candlestick, "divergence", divergence, "sweep", liquiditySweep,
+		"trend", trendState, "stoch_k", stochK, "stoch_d", stochD)
 
 	// ========================================
 	// STEP 4: REGIME DETECTION
 	// ========================================
 	// Use 1H and 15m for faster regime detection
-	regime := detectRegimePro(adx1h, adx15m, currentPrice, ema50Value)
+	regime := detectRegimePro(adx1h, adx15m, currentPrice, ema50Value, s.profile.Regime)
 
-	log.Printf("ℹ️  [Strategy] %s - Regime: %s (ADX1h: %.1f, ADX15m: %.1f)",
-		symbol, regime, adx1h, adx15m)
+	strategyLog.DebugContext(ctx, "ℹ️  Regime", "regime", regime, "adx_1h", adx1h, "adx_15m", adx15m)
+
+	signalDir := determineSignalDirection(regime, currentPrice, ema50Value, rsi4h)
+
+	// reject ends the evaluation, journaling the setup as a near-miss candidate.
+	// Setups without a direction of their own take the side price leans to (vs 4H EMA50).
+	var card *scoreCard
+	reject := func(stage, reason string) (*model.Signal, float64, error) {
+		if scan {
+			metrics.SignalsRejected.Inc(s.profile.Name, stage)
+		}
+		if journal == nil {
+			return nil, currentPrice, nil
+		}
+		direction := signalDir
+		if direction == "" {
+			direction = "LONG"
+			if fibTrend == "DOWN" {
+				direction = "SHORT"
+			}
+		}
+		candidate := s.newCandidate(snapshot, direction, currentPrice, atr1h, pivotPoints)
+		candidate.Regime = string(regime)
+		candidate.Stage = stage
+		candidate.Reason = reason
+		candidate.Lean = signalDir == ""
+		if card != nil {
+			candidate.ConfluenceScore = card.total
+			candidate.ScoreBreakdown = card.factors
+		}
+		if err := journal.RecordCandidate(ctx, candidate); err != nil {
+			strategyLog.WarnContext(ctx, "⚠️  Failed to journal candidate", "error", err)
+		}
+		return nil, currentPrice, nil
+	}
+
+	if deadZone {
+		strategyLog.DebugContext(ctx, "⏭️  Skipped (Dead Zone - low volatility period)")
+		return reject(model.StageDeadZone, "Dead Zone session")
+	}
 
 	// Skip choppy markets early
 	if regime == model.RegimeChoppy {
-		log.Printf("⏭️  [Strategy] %s - Skipped (choppy ADX < 20)", symbol)
-		return nil, currentPrice, nil
+		strategyLog.InfoContext(ctx, "⏭️  Skipped (choppy)", "choppy_adx", s.profile.Regime.ChoppyADX)
+		return reject(model.StageChoppy, fmt.Sprintf("Choppy regime (ADX 1h %.1f / 15m %.1f)", adx1h, adx15m))
 	}
 
-	// ========================================
-	// STEP 5: CONFLUENCE SCORING (0-100)
-	// ========================================
 	// ========================================
 	// STEP 5: CONFLUENCE SCORING (Strict 0-100)
 	// ========================================
-	signalDir := determineSignalDirection(regime, currentPrice, ema50Value, rsi4h)
 	if signalDir == "" {
-		log.Printf("⏭️  [Strategy] %s - No clear direction", symbol)
-		return nil, currentPrice, nil
+		strategyLog.InfoContext(ctx, "⏭️  No clear direction")
+		return reject(model.StageNoDirection, fmt.Sprintf("No clear direction (%s, RSI 4h %.1f)", regime, rsi4h))
 	}
 
 	// Calculate Score (Max 100)
-	score := calculateConfluenceScore(
+	score, factors := calculateConfluenceScore(
 		signalDir, regime,
 		rsi4h, rsi1h, rsi15m,
 		adx4h, adx1h, adx15m,
@@ -319,86 +696,108 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 		currentPrice, pivotPoints, fibLevels,
 		btcTrend, inFVG, fvgType, inOB, obType, pocDist,
 		candlestick, divergence, stochK, stochD, liquiditySweep, string(trendState), // New params
+		snapshot.Derivatives,
 	)
+	card = &scoreCard{total: score, factors: factors}
 
 	// Add NEW bonuses/penalties from session, funding, structure
-	score += sessionScore // Session bonus/penalty
+	card.add("Session", sessionInfo.Name, sessionScore) // Session bonus/penalty
 
 	// Funding rate adjustment - use already-fetched fundingInfo (NO duplicate API call)
-	fundingScore := CalculateFundingScore(fundingInfo, signalDir)
-	score += fundingScore
+	fundingScore := CalculateFundingScore(fundingInfo, signalDir, s.profile.Funding)
+	card.add("Funding", fmt.Sprintf("%.4f%% (%s)", fundingRate, valueOrNone(fundingSentiment)), fundingScore)
 
 	// Market structure alignment
 	structureScore := indicator.GetStructureScore(structureInfo, signalDir)
-	score += structureScore
+	card.add("Market Structure", string(structureInfo.Structure), structureScore)
 
 	// Advanced Features Scoring
-	advancedScore := 0
+	advancedStart := card.total
 
 	// CVD Alignment (+10 for trend match, +15 for divergence)
+	points := 0
 	if signalDir == "LONG" && cvdTrend > 0 {
-		advancedScore += 10
+		points = 10
 	} else if signalDir == "SHORT" && cvdTrend < 0 {
-		advancedScore += 10
+		points = 10
 	}
+	card.add("CVD Trend", fmt.Sprintf("%.2f", cvdTrend), points)
+
+	points = 0
 	if (signalDir == "LONG" && cvdDivergence == "Bullish CVD Divergence") ||
 		(signalDir == "SHORT" && cvdDivergence == "Bearish CVD Divergence") {
-		advancedScore += 15
+		points = 15
 	}
+	card.add("CVD Divergence", valueOrNone(cvdDivergence), points)
 
-	// Order Book Imbalance (+12 for strong alignment, -15 for opposite)
+	// Order Book Imbalance (default +12 for strong alignment, -15 for opposite)
+	ob := s.profile.OrderBook
+	points = 0
 	if signalDir == "LONG" {
-		if orderBookDepth.Imbalance > 30 {
-			advancedScore += 12
-		} else if orderBookDepth.Imbalance > 15 {
-			advancedScore += 6
-		} else if orderBookDepth.Imbalance < -15 {
-			advancedScore -= 15 // Strong sell pressure on LONG = bad
+		if orderBookDepth.Imbalance > ob.StrongImbalance {
+			points = ob.StrongBonus
+		} else if orderBookDepth.Imbalance > ob.ModerateImbalance {
+			points = ob.ModerateBonus
+		} else if orderBookDepth.Imbalance < -ob.ModerateImbalance {
+			points = -ob.OpposingPenalty // Strong sell pressure on LONG = bad
 		}
 	} else { // SHORT
-		if orderBookDepth.Imbalance < -30 {
-			advancedScore += 12
-		} else if orderBookDepth.Imbalance < -15 {
-			advancedScore += 6
-		} else if orderBookDepth.Imbalance > 15 {
-			advancedScore -= 15 // Strong buy pressure on SHORT = bad
+		if orderBookDepth.Imbalance < -ob.StrongImbalance {
+			points = ob.StrongBonus
+		} else if orderBookDepth.Imbalance < -ob.ModerateImbalance {
+			points = ob.ModerateBonus
+		} else if orderBookDepth.Imbalance > ob.ModerateImbalance {
+			points = -ob.OpposingPenalty // Strong buy pressure on SHORT = bad
+		}
+	}
+	card.add("Order Book Imbalance", fmt.Sprintf("%+.1f%%", orderBookDepth.Imbalance), points)
+
+	// Persistent walls (depth analyzer only): support under a trade helps, a wall in its way hurts
+	if depth := orderBookDepth.Analysis; depth != nil {
+		support, blocking := depth.NearestWall("bid"), depth.NearestWall("ask")
+		if signalDir == "SHORT" {
+			support, blocking = blocking, support
 		}
+		points = 0
+		if support != nil {
+			points += ob.WallBonus
+		}
+		if blocking != nil {
+			points -= ob.WallPenalty
+		}
+		card.add("Order Book Walls", valueOrNone(depth.WallSummary()), points)
 	}
 
 	// Perp-Spot Divergence (+5 for neutral, -12 for overheated)
+	points = 0
 	if signalDir == "LONG" {
 		if perpSpotDiv.Premium > 0.5 {
-			advancedScore -= 12 // Overheated longs
+			points = -12 // Overheated longs
 		} else if perpSpotDiv.Premium < 0.2 && perpSpotDiv.Premium > -0.2 {
-			advancedScore += 5 // Neutral = good
+			points = 5 // Neutral = good
 		}
 	} else { // SHORT
 		if perpSpotDiv.Premium < -0.5 {
-			advancedScore -= 12 // Oversold shorts
+			points = -12 // Oversold shorts
 		} else if perpSpotDiv.Premium > -0.2 && perpSpotDiv.Premium < 0.2 {
-			advancedScore += 5 // Neutral = good
+			points = 5 // Neutral = good
 		}
 	}
+	card.add("Perp Premium", fmt.Sprintf("%+.3f%%", perpSpotDiv.Premium), points)
 
-	score += advancedScore
-
-	log.Printf("📊 [Advanced Scoring] %s - Total Advanced: %+d", symbol, advancedScore)
+	strategyLog.DebugContext(ctx, "📊 Advanced scoring", "points", card.total-advancedStart)
 
 	// Clamp score to 0-100
-	if score < 0 {
-		score = 0
-	}
-	if score > 100 {
-		score = 100
-	}
+	card.clamp("Final Clamp (0-100)")
+	score = card.total
 
-	log.Printf("📊 [Strategy] %s - Final Score: %d/100 (Session: %+d, Funding: %+d, Structure: %+d)",
-		symbol, score, sessionScore, fundingScore, structureScore)
+	strategyLog.DebugContext(ctx, "📊 Final score",
+		"score", score, "session", sessionScore, "funding", fundingScore, "structure", structureScore)
 
-	// Minimum score threshold (Strict 80)
-	if score < 80 {
-		log.Printf("⏭️  [Strategy] %s - Score too low (%d < 80)", symbol, score)
-		return nil, currentPrice, nil
+	// Minimum score threshold (Strict 80 by default)
+	if score < s.profile.MinScore {
+		strategyLog.InfoContext(ctx, "⏭️  Score too low", "score", score, "min_score", s.profile.MinScore)
+		return reject(model.StageLowScore, fmt.Sprintf("Score %d < %d", score, s.profile.MinScore))
 	}
 
 	// ========================================
@@ -421,20 +820,19 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	}
 
 	// Must be within 2% of a key level for entry
-	// EXCEPTION: If score is Premium (>= 90), we allow slightly wider entry
+	// EXCEPTION: If score is Premium (>= 90 by default), we allow slightly wider entry
 	nearKeyLevel := pivotProximity <= 2.0 || fibProximity <= 2.0
-	if !nearKeyLevel && score < 90 {
-		log.Printf("⏭️  [Strategy] %s - Not near key level (Pivot: %.2f%%, Fib: %.2f%%)",
-			symbol, pivotProximity, fibProximity)
-		return nil, currentPrice, nil
+	if !nearKeyLevel && score < s.profile.PremiumScore {
+		strategyLog.InfoContext(ctx, "⏭️  Not near key level", "pivot_pct", pivotProximity, "fib_pct", fibProximity)
+		return reject(model.StageKeyLevel, fmt.Sprintf("Not near key level (Pivot %.2f%%, Fib %.2f%%)", pivotProximity, fibProximity))
 	}
 
 	// ========================================
 	// STEP 7: DETERMINE TIER
 	// ========================================
-	// Tier: 80-89 = STANDARD, 90-100 = PREMIUM
+	// Tier (default): 80-89 = STANDARD, 90-100 = PREMIUM
 	tier := model.TierStandard
-	if score >= 90 {
+	if score >= s.profile.PremiumScore {
 		tier = model.TierPremium
 	}
 
@@ -442,67 +840,7 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// ========================================
 	// STEP 8: CALCULATE SL/TP WITH PROPER R:R
 	// ========================================
-	var stopLoss, takeProfit1, takeProfit2 float64
-
-	// Use percentage-based SL/TP for consistent R:R
-	// UPDATED: Wider SL to reduce premature SL hits
-	// SL: 3% (was 2%)
-	// TP1: 4.5% (1:1.5 R:R) -> Book 50%
-	// TP2: 9% (1:3 R:R) -> Book 50%
-	slPercent := 3.0 / 100.0
-	tp1Percent := 4.5 / 100.0
-	tp2Percent := 9.0 / 100.0
-
-	// Adjust based on ATR volatility
-	atrPercent := 0.0
-	if currentPrice > 0 && ValidateFloat64(atr1h) {
-		atrPercent = (atr1h / currentPrice) * 100
-		if !ValidateFloat64(atrPercent) {
-			atrPercent = 2.0 // Default moderate volatility
-		}
-	} else {
-		atrPercent = 2.0 // Default moderate volatility
-	}
-
-	if atrPercent > 3.0 {
-		// High volatility - use even wider stops/targets
-		slPercent = 4.5 / 100.0
-		tp1Percent = 6.75 / 100.0
-		tp2Percent = 13.5 / 100.0
-	} else if atrPercent < 1.0 {
-		// Low volatility - slightly tighter but still safe
-		slPercent = 2.5 / 100.0
-		tp1Percent = 3.75 / 100.0
-		tp2Percent = 7.5 / 100.0
-	}
-
-	if signalDir == "LONG" {
-		// LONG calculation
-		stopLoss = currentPrice * (1 - slPercent)
-		takeProfit1 = currentPrice * (1 + tp1Percent)
-		takeProfit2 = currentPrice * (1 + tp2Percent)
-
-		// Optional: Adjust TP2 to resistance if meaningful
-		tpPivot := getNextResistance(currentPrice, pivotPoints)
-		if tpPivot > takeProfit2 && tpPivot < currentPrice*1.15 {
-			takeProfit2 = tpPivot
-			// Recalculate TP2 percent if adjusted
-			tp2Percent = (takeProfit2 - currentPrice) / currentPrice
-		}
-	} else {
-		// SHORT calculation
-		stopLoss = currentPrice * (1 + slPercent)
-		takeProfit1 = currentPrice * (1 - tp1Percent)
-		takeProfit2 = currentPrice * (1 - tp2Percent)
-
-		// Optional: Adjust TP2 to support if meaningful
-		tpPivot := getNextSupport(currentPrice, pivotPoints)
-		if tpPivot < takeProfit2 && tpPivot > currentPrice*0.85 {
-			takeProfit2 = tpPivot
-			// Recalculate TP2 percent if adjusted
-			tp2Percent = (currentPrice - takeProfit2) / currentPrice // absolute % change
-		}
-	}
+	stopLoss, takeProfit1, takeProfit2 := s.tradeLevels(signalDir, currentPrice, atr1h, pivotPoints)
 
 	// ========================================
 	// STEP 9: RISK MANAGEMENT & PROBABILITY
@@ -512,8 +850,8 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 
 	// Minimum 2:1 R:R required (based on final target)
 	if rrResult.Ratio < 2.0 {
-		log.Printf("⏭️  [Strategy] %s - R:R too low (%.2f < 2.0)", symbol, rrResult.Ratio)
-		return nil, currentPrice, nil
+		strategyLog.InfoContext(ctx, "⏭️  R:R too low", "rr", rrResult.Ratio)
+		return reject(model.StageRiskReward, fmt.Sprintf("R:R %.2f < 2.0", rrResult.Ratio))
 	}
 
 	// Calculate probability metrics
@@ -621,8 +959,20 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 		CVDDivergence:      cvdDivergence,
 		OrderBookSignal:    orderBookDepth.Signal,
 		OrderBookImbalance: orderBookDepth.Imbalance,
+		OrderBookBands:     orderBookBands,
+		OrderBookWalls:     orderBookWalls,
 		PerpSpotPremium:    perpSpotDiv.Premium,
 		PerpSpotSentiment:  perpSpotDiv.Sentiment,
+		// Open Interest & Positioning
+		OIRegime:          derivatives.Regime,
+		OIChange:          derivatives.OIChange,
+		OIPriceChange:     derivatives.PriceChange,
+		LongShortRatio:    derivatives.LongShortRatio,
+		TakerBuySellRatio: derivatives.TakerBuySellRatio,
+		// Cross-Exchange
+		CrossPriceSpread:   crossExchange.PriceSpread,
+		CrossFundingSpread: crossExchange.FundingSpread,
+		CrossExchange:      crossExchange.Summary(),
 	}
 
 	signalType := model.SignalTypeLong
@@ -640,8 +990,8 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 	// [NEW] Signal Performance Tracker Check
 	// If pattern has poor historical performance (<40% win rate), disable data
 	if s.tracker != nil && !s.tracker.IsPatternEnabled(tempSignalForCheck) {
-		log.Printf("🚫 [Strategy] %s - Pattern DISABLED (poor historical performance)", symbol)
-		return nil, currentPrice, nil
+		strategyLog.InfoContext(ctx, "🚫 Pattern disabled (poor historical performance)")
+		return reject(model.StagePatternDisabled, "Pattern disabled: "+GeneratePatternFingerprint(tempSignalForCheck))
 	}
 
 	signal := &model.Signal{
@@ -667,18 +1017,103 @@ func (s *StrategyService) EvaluateSymbol(symbol string) (*model.Signal, float64,
 		TP2Percent:       rewardPercent, // Same as RewardPercent
 		NearestLevelDist: nearestLevelDist,
 		// Status
-		Status:    "ACTIVE",
-		Timestamp: time.Now(),
+		Profile:   s.profile.Name,
+		Status:    model.StatusActive,
+		Timestamp: snapshot.Time,
 		ID:        generateSignalID(), // Generate unique simple ID
+		// Reproducibility
+		EvaluationMode:  s.mode,
+		CandleOpenTimes: snapshot.CandleOpenTimes(),
+		ScoreBreakdown:  card.factors,
 	}
 
-	log.Printf("✨ [Strategy] %s - %s signal! ID: %s, Score: %d (%.0f%% prob), Tier: %s, R:R: %.2f, Entry: %s, SL: %s (%.2f%%)",
-		symbol, signalDir, signal.ID, score, signalProbability*100, tier, rrResult.Ratio,
-		FormatPrice(currentPrice), FormatPrice(stopLoss), riskPercent)
+	strategyLog.InfoContext(ctx, "✨ Signal found",
+		"signal_id", signal.ID, "type", signalDir, "score", score, "probability_pct", math.Round(signalProbability*100),
+		"tier", tier, "rr", rrResult.Ratio, "entry", FormatPrice(currentPrice),
+		"stop_loss", FormatPrice(stopLoss), "risk_pct", riskPercent)
 
 	return signal, currentPrice, nil
 }
 
+// tradeLevels calculates SL, TP1 and TP2 for an entry at price
+func (s *StrategyService) tradeLevels(direction string, price, atr1h float64, pivots internalmath.PivotPoints) (stopLoss, takeProfit1, takeProfit2 float64) {
+	// Use percentage-based SL/TP for consistent R:R (profile defaults):
+	// SL: 3%
+	// TP1: 4.5% (1:1.5 R:R) -> Book 50%
+	// TP2: 9% (1:3 R:R) -> Book 50%
+	// Wider levels when 1h ATR > 3%, slightly tighter when < 1%
+
+	// Adjust based on ATR volatility
+	atrPercent := 0.0
+	if price > 0 && ValidateFloat64(atr1h) {
+		atrPercent = (atr1h / price) * 100
+		if !ValidateFloat64(atrPercent) {
+			atrPercent = 2.0 // Default moderate volatility
+		}
+	} else {
+		atrPercent = 2.0 // Default moderate volatility
+	}
+
+	levels := s.profile.Risk.LevelsForATR(atrPercent)
+	slPercent := levels.StopLoss / 100.0
+	tp1Percent := levels.TakeProfit1 / 100.0
+	tp2Percent := levels.TakeProfit2 / 100.0
+
+	if direction == "LONG" {
+		// LONG calculation
+		stopLoss = price * (1 - slPercent)
+		takeProfit1 = price * (1 + tp1Percent)
+		takeProfit2 = price * (1 + tp2Percent)
+
+		// Optional: Adjust TP2 to resistance if meaningful
+		tpPivot := getNextResistance(price, pivots)
+		if tpPivot > takeProfit2 && tpPivot < price*1.15 {
+			takeProfit2 = tpPivot
+		}
+	} else {
+		// SHORT calculation
+		stopLoss = price * (1 + slPercent)
+		takeProfit1 = price * (1 - tp1Percent)
+		takeProfit2 = price * (1 - tp2Percent)
+
+		// Optional: Adjust TP2 to support if meaningful
+		tpPivot := getNextSupport(price, pivots)
+		if tpPivot < takeProfit2 && tpPivot > price*0.85 {
+			takeProfit2 = tpPivot
+		}
+	}
+	return stopLoss, takeProfit1, takeProfit2
+}
+
+// newCandidate builds a near-miss candidate with the trade plan a signal at price would have had
+func (s *StrategyService) newCandidate(snapshot *MarketSnapshot, direction string, price, atr1h float64, pivots internalmath.PivotPoints) *model.Candidate {
+	stopLoss, takeProfit1, takeProfit2 := s.tradeLevels(direction, price, atr1h, pivots)
+
+	signalType := model.SignalTypeLong
+	if direction == "SHORT" {
+		signalType = model.SignalTypeShort
+	}
+
+	return &model.Candidate{
+		Signal: model.Signal{
+			Symbol:          snapshot.Symbol,
+			Type:            signalType,
+			EntryPrice:      price,
+			StopLoss:        stopLoss,
+			TakeProfit:      takeProfit2,
+			TakeProfit1:     takeProfit1,
+			TakeProfit2:     takeProfit2,
+			RiskRewardRatio: internalmath.CalculateRiskReward(price, stopLoss, takeProfit2).Ratio,
+			Profile:         s.profile.Name,
+			Status:          model.StatusActive,
+			Timestamp:       snapshot.Time,
+			ID:              generateSignalID(),
+			EvaluationMode:  s.mode,
+			CandleOpenTimes: snapshot.CandleOpenTimes(),
+		},
+	}
+}
+
 // generateSignalID generates a short 5-character alphanumeric ID
 func generateSignalID() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -698,14 +1133,14 @@ func generateSignalID() string {
 }
 
 // detectRegimePro uses multi-timeframe ADX for better regime detection
-func detectRegimePro(adx1h, adx15m, price, ema50 float64) model.MarketRegime {
+func detectRegimePro(adx1h, adx15m, price, ema50 float64, cutoffs config.RegimeProfile) model.MarketRegime {
 	avgADX := (adx1h + adx15m) / 2
 
-	if avgADX < 20 {
+	if avgADX < cutoffs.ChoppyADX {
 		return model.RegimeChoppy
 	}
 
-	if avgADX < 25 {
+	if avgADX < cutoffs.TrendADX {
 		return model.RegimeRanging
 	}
 
@@ -739,6 +1174,8 @@ func determineSignalDirection(regime model.MarketRegime, price, ema50, rsi4h flo
 // 7. SMC (OB/FVG): 		10
 // 8. Volume Profile (POC): 5
 // 9. BTC Correlation: 		5
+// Extras: candlestick, divergence, sweep, MA cross, StochRSI and, when reported, futures positioning
+// (OI regime, taker flow, long/short crowding)
 func calculateConfluenceScore(
 	direction string, regime model.MarketRegime,
 	rsi4h, rsi1h, rsi15m float64,
@@ -747,91 +1184,112 @@ func calculateConfluenceScore(
 	price float64, pivots internalmath.PivotPoints, fibs internalmath.FibonacciLevels,
 	btcTrend string, inFVG bool, fvgType string, inOB bool, obType string, pocDist float64,
 	candlestick, divergence string, stochK, stochD float64, liquiditySweep string, trendState string,
-) int {
-	score := 0
+	derivatives *DerivativesContext,
+) (int, []model.ScoreFactor) {
+	card := &scoreCard{}
 
 	// 1. Trend Alignment (Max 20)
 	// Strong trend in both 1H and 15m
+	points := 0
 	if adx1h > 25 && adx15m > 25 {
-		score += 20
+		points = 20
 	} else if adx1h > 25 || adx15m > 25 {
-		score += 10
+		points = 10
 	}
+	card.add("Trend Alignment", fmt.Sprintf("ADX 1h %.1f / 15m %.1f", adx1h, adx15m), points)
 
 	// 2. RSI Momentum (Max 25) - 15m Priority for entries
+	points = 0
 	if direction == "LONG" {
 		// Ideal entry: 15m RSI oversold (pullback) in uptrend
 		if rsi15m < 45 && rsi15m > 30 {
-			score += 25 // Perfect pullback entry
+			points = 25 // Perfect pullback entry
 		} else if rsi15m < 60 && rsi1h < 70 {
-			score += 15 // Good continuation
+			points = 15 // Good continuation
 		}
 	} else {
 		// Ideal entry: 15m RSI overbought (pullback) in downtrend
 		if rsi15m > 55 && rsi15m < 70 {
-			score += 25 // Perfect pullback entry
+			points = 25 // Perfect pullback entry
 		} else if rsi15m > 40 && rsi1h > 30 {
-			score += 15 // Good continuation
+			points = 15 // Good continuation
 		}
 	}
+	card.add("RSI Momentum", fmt.Sprintf("RSI 15m %.1f / 1h %.1f", rsi15m, rsi1h), points)
 
 	// 3. Key Level Proximity (Max 15)
 	pivotDist := getPivotDistance(price, pivots)
 	fibDist := getFibDistance(price, fibs)
 
+	points = 0
 	if pivotDist <= 1.5 || fibDist <= 1.5 {
-		score += 15
+		points = 15
 	} else if pivotDist <= 2.5 || fibDist <= 2.5 {
-		score += 8
+		points = 8
 	}
+	card.add("Key Level", fmt.Sprintf("Pivot %.2f%% / Fib %.2f%%", pivotDist, fibDist), points)
 
 	// 4. Volume (Max 10)
+	points = 0
 	if volRatio >= 1.5 {
-		score += 10
+		points = 10
 	} else if volRatio >= 1.2 {
-		score += 5
+		points = 5
 	}
+	card.add("Volume", fmt.Sprintf("%.2fx avg", volRatio), points)
 
 	// 5. Order Flow (Max 5)
+	points = 0
 	if (direction == "LONG" && orderFlow > 0) || (direction == "SHORT" && orderFlow < 0) {
-		score += 5
+		points = 5
 	}
+	card.add("Order Flow", fmt.Sprintf("%.2f", orderFlow), points)
 
 	// 6. MACD (Max 5)
+	points = 0
 	if (direction == "LONG" && histogram > 0) || (direction == "SHORT" && histogram < 0) {
-		score += 5
+		points = 5
 	}
+	card.add("MACD", fmt.Sprintf("Hist %.6f", histogram), points)
 
 	// 7. SMC (OB/FVG) (Max 10)
-	smcScore := 0
+	points = 0
 	if inOB {
 		if (direction == "LONG" && obType == "BULLISH") || (direction == "SHORT" && obType == "BEARISH") {
-			smcScore += 5
+			points = 5
 		}
 	}
+	card.add("Order Block", zoneValue(inOB, obType), points)
+
+	points = 0
 	if inFVG {
 		if (direction == "LONG" && fvgType == "BULLISH") || (direction == "SHORT" && fvgType == "BEARISH") {
-			smcScore += 5
+			points = 5
 		}
 	}
-	score += smcScore
+	card.add("Fair Value Gap", zoneValue(inFVG, fvgType), points)
 
 	// 8. Volume Profile / POC (Max 5)
+	points = 0
 	if pocDist <= 2.0 {
-		score += 5
+		points = 5
 	}
+	card.add("POC Proximity", fmt.Sprintf("%.2f%%", pocDist), points)
 
 	// 9. BTC Correlation (Max 5)
+	points = 0
 	if btcTrend != "" {
 		if (direction == "LONG" && btcTrend == "UP") || (direction == "SHORT" && btcTrend == "DOWN") {
-			score += 5
+			points = 5
 		} else {
 			// Penalty for fighting BTC
-			score -= 10
+			points = -10
 		}
 	}
+	card.add("BTC Correlation", valueOrNone(btcTrend), points)
 
 	// 10. Candlestick Patterns (Max 5)
+	points = 0
 	if candlestick != "" {
 		// Bullish patterns
 		isBullishPattern := (candlestick == "Hammer" || candlestick == "Morning Star" || candlestick == "Bullish Engulfing")
@@ -839,50 +1297,103 @@ func calculateConfluenceScore(
 		isBearishPattern := (candlestick == "Shooting Star" || candlestick == "Evening Star" || candlestick == "Bearish Engulfing")
 
 		if direction == "LONG" && isBullishPattern {
-			score += 5
+			points = 5
 		} else if direction == "SHORT" && isBearishPattern {
-			score += 5
+			points = 5
 		}
 	}
+	card.add("Candlestick", valueOrNone(candlestick), points)
 
 	// 11. Divergence (Max 10)
+	points = 0
 	if (direction == "LONG" && divergence == "Bullish") || (direction == "SHORT" && divergence == "Bearish") {
-		score += 10
+		points = 10
 	}
+	card.add("RSI Divergence", valueOrNone(divergence), points)
 
 	// 12. Liquidity Sweep (Max 10)
+	points = 0
 	if (direction == "LONG" && liquiditySweep == "Bullish Sweep") || (direction == "SHORT" && liquiditySweep == "Bearish Sweep") {
-		score += 10
+		points = 10
 	}
+	card.add("Liquidity Sweep", valueOrNone(liquiditySweep), points)
 
 	// 13. Trend State (MA Cross) (Max 5)
+	points = 0
 	if (direction == "LONG" && trendState == "Golden Cross") || (direction == "SHORT" && trendState == "Death Cross") {
-		score += 5
+		points = 5
 	}
+	card.add("MA Cross", valueOrNone(trendState), points)
 
 	// 14. Stochastic RSI (Max 5)
 	// Long: Oversold (< 20) and curving up? Just check extreme.
 	// Short: Overbought (> 80)
+	points = 0
 	if direction == "LONG" && stochK < 20 {
-		score += 5
+		points = 5
 	} else if direction == "SHORT" && stochK > 80 {
-		score += 5
+		points = 5
+	}
+	card.add("Stoch RSI", fmt.Sprintf("K %.1f / D %.1f", stochK, stochD), points)
+
+	// 15. Futures Positioning (Max 15, only scored when the exchange reports it)
+	if derivatives != nil {
+		// OI vs price: new positions on the trade's side confirm it (+10), the other side closing
+		// is a weaker move (+3); new positions against it (-5) or its own side unwinding (-10) hurt
+		points = 0
+		if direction == "LONG" {
+			switch derivatives.Regime {
+			case OIRegimeNewLongs:
+				points = 10
+			case OIRegimeShortCovering:
+				points = 3
+			case OIRegimeNewShorts:
+				points = -5
+			case OIRegimeLongLiquidation:
+				points = -10 // Never buy into a liquidation cascade
+			}
+		} else {
+			switch derivatives.Regime {
+			case OIRegimeNewShorts:
+				points = 10
+			case OIRegimeLongLiquidation:
+				points = 3
+			case OIRegimeNewLongs:
+				points = -5
+			case OIRegimeShortCovering:
+				points = -10 // Shorting into a squeeze
+			}
+		}
+		card.add("OI Regime", fmt.Sprintf("%s (OI %+.2f%% / price %+.2f%%)", derivatives.Regime, derivatives.OIChange, derivatives.PriceChange), points)
+
+		// Taker flow in the trade direction
+		if derivatives.TakerBuySellRatio > 0 {
+			points = 0
+			if (direction == "LONG" && derivatives.TakerBuySellRatio >= 1.1) || (direction == "SHORT" && derivatives.TakerBuySellRatio <= 0.9) {
+				points = 5
+			}
+			card.add("Taker Buy/Sell", fmt.Sprintf("%.2f", derivatives.TakerBuySellRatio), points)
+		}
+
+		// Crowded accounts on the trade's side
+		if derivatives.LongShortRatio > 0 {
+			points = 0
+			if (direction == "LONG" && derivatives.LongShortRatio >= 2.5) || (direction == "SHORT" && derivatives.LongShortRatio <= 0.6) {
+				points = -5
+			}
+			card.add("Long/Short Ratio", fmt.Sprintf("%.2f", derivatives.LongShortRatio), points)
+		}
 	}
 
 	// Penalties
 	if volRatio < 0.8 {
-		score -= 10 // Low volume penalty
+		card.add("Low Volume Penalty", fmt.Sprintf("%.2fx avg", volRatio), -10)
 	}
 
 	// Boundary Check
-	if score < 0 {
-		return 0
-	}
-	if score > 100 {
-		return 100
-	}
+	card.clamp("Base Clamp (0-100)")
 
-	return score
+	return card.total, card.factors
 }
 
 // findSwingHighLow finds swing high and low from recent candles
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+// fixtureEnd is the evaluation time of the fixture tests (London / NY overlap)
+var fixtureEnd = time.Date(2025, 10, 9, 14, 2, 0, 0, time.UTC)
+
+// wavePrice is the fixture price hours before or after fixtureEnd: an exponential drift (per hour)
+// with a sine wave (period ~19h) on top
+func wavePrice(hours, base, drift, amplitude float64) float64 {
+	return base*math.Exp(drift*hours) + amplitude*math.Sin(hours/3)
+}
+
+// waveKlines builds n candles of one interval ending at fixtureEnd, all sampled from wavePrice
+func waveKlines(interval time.Duration, n int, base, drift, amplitude float64) []FixtureKline {
+	first := fixtureEnd.Truncate(interval).Add(-time.Duration(n-1) * interval)
+	wick := amplitude / 4 * math.Sqrt(interval.Hours())
+
+	klines := make([]FixtureKline, n)
+	for i := range klines {
+		openTime := first.Add(time.Duration(i) * interval)
+		closeTime := openTime.Add(interval)
+		if closeTime.After(fixtureEnd) {
+			closeTime = fixtureEnd // The last candle is still forming
+		}
+		open := wavePrice(openTime.Sub(fixtureEnd).Hours(), base, drift, amplitude)
+		close := wavePrice(closeTime.Sub(fixtureEnd).Hours(), base, drift, amplitude)
+		klines[i] = FixtureKline{
+			OpenTime:  openTime.UnixMilli(),
+			Open:      open,
+			High:      math.Max(open, close) + wick,
+			Low:       math.Min(open, close) - wick,
+			Close:     close,
+			Volume:    (100 + 50*math.Abs(math.Sin(float64(i)/2))) * interval.Hours(),
+			CloseTime: openTime.Add(interval).UnixMilli() - 1,
+		}
+	}
+	return klines
+}
+
+// writeMarketFixture writes a MarketFixture file for the symbols and loads it
+func writeMarketFixture(t *testing.T, symbols map[string]SymbolFixture) *MemoryMarketData {
+	t.Helper()
+	data, err := json.Marshal(MarketFixture{Symbols: symbols})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "market.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	market, err := LoadMarketFixture(path)
+	if err != nil {
+		t.Fatalf("LoadMarketFixture: %v", err)
+	}
+	return market
+}
+
+// waveSymbol builds every timeframe the strategy reads from the same drift and wave
+func waveSymbol(base, drift, amplitude float64) SymbolFixture {
+	return SymbolFixture{Klines: map[string][]FixtureKline{
+		"1d":  waveKlines(24*time.Hour, 100, base, drift, amplitude),
+		"4h":  waveKlines(4*time.Hour, 300, base, drift, amplitude),
+		"1h":  waveKlines(time.Hour, 300, base, drift, amplitude),
+		"15m": waveKlines(15*time.Minute, 300, base, drift, amplitude),
+		"5m":  waveKlines(5*time.Minute, 300, base, drift, amplitude),
+	}}
+}
+
+// journalCapture records rejected setups
+type journalCapture struct {
+	candidates []*model.Candidate
+}
+
+func (j *journalCapture) RecordCandidate(_ context.Context, candidate *model.Candidate) error {
+	j.candidates = append(j.candidates, candidate)
+	return nil
+}
+
+// newFixtureStrategy evaluates the fixture at fixtureEnd, journaling rejections
+func newFixtureStrategy(market MarketDataProvider) (*StrategyService, *journalCapture) {
+	s := NewStrategyService(market, nil)
+	s.SetClock(func() time.Time { return fixtureEnd })
+	journal := &journalCapture{}
+	s.SetCandidateJournal(journal)
+	return s, journal
+}
+
+func TestEvaluateSymbolFromFixture(t *testing.T) {
+	// A steady downtrend with swings around it
+	market := writeMarketFixture(t, map[string]SymbolFixture{"ETHUSDT": waveSymbol(2000, -0.001, 30)})
+	s, journal := newFixtureStrategy(market)
+
+	signal, price, err := s.EvaluateSymbol(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("EvaluateSymbol: %v", err)
+	}
+	if signal == nil {
+		t.Fatalf("no signal, rejected as %+v", journal.candidates)
+	}
+	if price != 2000 || signal.EntryPrice != 2000 || signal.Type != model.SignalTypeShort {
+		t.Errorf("signal = %s at %v (price %v), want SHORT at 2000", signal.Type, signal.EntryPrice, price)
+	}
+	if signal.ConfluenceScore != 85 || signal.Tier != model.TierStandard || signal.StopLoss != 2060 || signal.TakeProfit2 != 1820 {
+		t.Errorf("score %d, tier %s, SL %v, TP2 %v, want 85 STANDARD 2060 / 1820",
+			signal.ConfluenceScore, signal.Tier, signal.StopLoss, signal.TakeProfit2)
+	}
+	if !signal.Timestamp.Equal(fixtureEnd) || signal.Profile != s.Profile().Name || len(signal.ScoreBreakdown) == 0 {
+		t.Errorf("signal = %+v", signal)
+	}
+	if got := signal.CandleOpenTimes["5m"]; got != fixtureEnd.Truncate(5*time.Minute).UnixMilli() {
+		t.Errorf("5m candle = %d, want the forming one", got)
+	}
+	if len(journal.candidates) != 0 {
+		t.Errorf("journaled %d candidates for a signal", len(journal.candidates))
+	}
+}
+
+func TestEvaluateSymbolRejectionStages(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  SymbolFixture
+		minScore int
+		stage    string
+	}{
+		{"overbought uptrend", waveSymbol(2000, 0.0005, 5), 0, model.StageNoDirection},
+		{"score below minimum", waveSymbol(2000, 0.0005, 20), 0, model.StageLowScore},
+		{"raised minimum", waveSymbol(2000, -0.001, 30), 90, model.StageLowScore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, journal := newFixtureStrategy(writeMarketFixture(t, map[string]SymbolFixture{"ETHUSDT": tt.fixture}))
+			if tt.minScore > 0 {
+				s.SetMinScore(tt.minScore)
+			}
+
+			signal, _, err := s.EvaluateSymbol(context.Background(), "ETHUSDT")
+			if err != nil || signal != nil {
+				t.Fatalf("EvaluateSymbol = %+v, %v, want a rejection", signal, err)
+			}
+			if len(journal.candidates) != 1 || journal.candidates[0].Stage != tt.stage {
+				t.Fatalf("candidates = %+v, want one at %s", journal.candidates, tt.stage)
+			}
+			if c := journal.candidates[0]; c.Symbol != "ETHUSDT" || c.EntryPrice != 2000 {
+				t.Errorf("candidate = %+v", c)
+			}
+		})
+	}
+}
+
+func TestEvaluateSymbolClosedMode(t *testing.T) {
+	market := writeMarketFixture(t, map[string]SymbolFixture{"ETHUSDT": waveSymbol(2000, -0.001, 30)})
+	s, journal := newFixtureStrategy(market)
+	if err := s.SetEvaluationMode(EvaluationClosed); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the forming candles the 4h RSI leaves the short zone: the live signal is not repeated
+	signal, price, err := s.EvaluateSymbol(context.Background(), "ETHUSDT")
+	if err != nil || signal != nil {
+		t.Fatalf("EvaluateSymbol = %+v, %v, want a rejection", signal, err)
+	}
+	if want := wavePrice(-2.0/60, 2000, -0.001, 30); !approx(price, want) {
+		t.Errorf("price = %v, want the last closed 5m close %v", price, want)
+	}
+	if len(journal.candidates) != 1 || journal.candidates[0].Stage != model.StageNoDirection {
+		t.Fatalf("candidates = %+v, want one at %s", journal.candidates, model.StageNoDirection)
+	}
+	if got := journal.candidates[0].CandleOpenTimes["5m"]; got != fixtureEnd.Truncate(5*time.Minute).Add(-5*time.Minute).UnixMilli() {
+		t.Errorf("5m candle = %d, want the last closed one", got)
+	}
+
+	// The same closed candle is evaluated once
+	if signal, price, err := s.EvaluateSymbol(context.Background(), "ETHUSDT"); signal != nil || price != 0 || err != nil {
+		t.Errorf("second evaluation = %+v, %v, %v, want skipped", signal, price, err)
+	}
+	if len(journal.candidates) != 1 {
+		t.Errorf("journaled %d candidates, want the skipped evaluation not recorded", len(journal.candidates))
+	}
+}
@@ -3,23 +3,33 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mrcrypto-go/internal/logger"
 )
 
+var symbolLog = logger.Component("watchlist")
+
 type SymbolManager struct {
 	collection *mongo.Collection
+	exchanges  []string // Exchanges a symbol may be assigned to, nil = any known exchange
+
+	mu       sync.RWMutex
+	assigned map[string]string // Symbol -> exchange, refreshed on every watchlist read and write
 }
 
 type WatchedSymbol struct {
-	Symbol   string    `bson:"symbol"`
-	AddedAt  time.Time `bson:"added_at"`
-	IsActive bool      `bson:"is_active"`
+	Symbol   string    `bson:"symbol" json:"symbol"`
+	Exchange string    `bson:"exchange,omitempty" json:"exchange,omitempty"` // Empty = default exchange
+	AddedAt  time.Time `bson:"added_at" json:"added_at"`
+	IsActive bool      `bson:"is_active" json:"is_active"`
 }
 
 func NewSymbolManager(db *mongo.Database) *SymbolManager {
@@ -27,11 +37,17 @@ func NewSymbolManager(db *mongo.Database) *SymbolManager {
 
 	sm := &SymbolManager{
 		collection: collection,
+		assigned:   make(map[string]string),
 	}
 
 	// Seed initialization if empty
 	sm.initializeDefaults()
 
+	// Load exchange assignments before the first poll
+	if _, err := sm.GetWatchlist(); err != nil {
+		symbolLog.Warn("⚠️  Failed to load watchlist", "error", err)
+	}
+
 	return sm
 }
 
@@ -42,12 +58,12 @@ func (sm *SymbolManager) initializeDefaults() {
 
 	count, err := sm.collection.CountDocuments(ctx, bson.M{})
 	if err != nil {
-		log.Printf("⚠️ Failed to check watchlist count: %v", err)
+		symbolLog.Warn("⚠️  Failed to check watchlist count", "error", err)
 		return
 	}
 
 	if count == 0 {
-		log.Println("🌱 Seeding default watchlist...")
+		symbolLog.Info("🌱 Seeding default watchlist...")
 		defaults := []string{
 			"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT", "XRPUSDT",
 			"DOGEUSDT", "ADAUSDT", "AVAXUSDT", "TRXUSDT", "LINKUSDT",
@@ -55,13 +71,19 @@ func (sm *SymbolManager) initializeDefaults() {
 		}
 
 		for _, s := range defaults {
-			sm.AddSymbol(s)
+			sm.AddSymbol(s, "")
 		}
 	}
 }
 
-// AddSymbol adds a symbol to the watchlist
-func (sm *SymbolManager) AddSymbol(symbol string) error {
+// SetExchanges restricts the exchanges a symbol can be assigned to (the enabled ones)
+func (sm *SymbolManager) SetExchanges(exchanges []string) {
+	sm.exchanges = exchanges
+}
+
+// AddSymbol adds a symbol to the watchlist, or moves it to another exchange.
+// An empty exchange means the default exchange.
+func (sm *SymbolManager) AddSymbol(symbol, exchange string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -70,10 +92,20 @@ func (sm *SymbolManager) AddSymbol(symbol string) error {
 		return fmt.Errorf("symbol must end with USDT")
 	}
 
+	exchange = strings.ToLower(strings.TrimSpace(exchange))
+	allowed := sm.exchanges
+	if allowed == nil {
+		allowed = KnownExchanges
+	}
+	if exchange != "" && !slices.Contains(allowed, exchange) {
+		return fmt.Errorf("exchange must be one of %s", strings.Join(allowed, ", "))
+	}
+
 	filter := bson.M{"symbol": symbol}
 	update := bson.M{
 		"$set": bson.M{
 			"symbol":    symbol,
+			"exchange":  exchange,
 			"is_active": true,
 		},
 		"$setOnInsert": bson.M{
@@ -87,7 +119,11 @@ func (sm *SymbolManager) AddSymbol(symbol string) error {
 		return fmt.Errorf("failed to add symbol: %w", err)
 	}
 
-	log.Printf("✅ Added %s to watchlist", symbol)
+	sm.mu.Lock()
+	sm.assigned[symbol] = exchange
+	sm.mu.Unlock()
+
+	symbolLog.Info("✅ Added to watchlist", "symbol", symbol, "exchange", exchange)
 	return nil
 }
 
@@ -104,12 +140,31 @@ func (sm *SymbolManager) RemoveSymbol(symbol string) error {
 		return fmt.Errorf("failed to remove symbol: %w", err)
 	}
 
-	log.Printf("🗑️ Removed %s from watchlist", symbol)
+	sm.mu.Lock()
+	delete(sm.assigned, symbol)
+	sm.mu.Unlock()
+
+	symbolLog.Info("🗑️ Removed from watchlist", "symbol", symbol)
 	return nil
 }
 
 // GetWatchlist returns all active symbols
 func (sm *SymbolManager) GetWatchlist() ([]string, error) {
+	entries, err := sm.GetWatchlistEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []string
+	for _, s := range entries {
+		symbols = append(symbols, s.Symbol)
+	}
+
+	return symbols, nil
+}
+
+// GetWatchlistEntries returns all watchlist entries with their exchange
+func (sm *SymbolManager) GetWatchlistEntries() ([]WatchedSymbol, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -124,10 +179,20 @@ func (sm *SymbolManager) GetWatchlist() ([]string, error) {
 		return nil, err
 	}
 
-	var symbols []string
+	assigned := make(map[string]string, len(results))
 	for _, s := range results {
-		symbols = append(symbols, s.Symbol)
+		assigned[s.Symbol] = s.Exchange
 	}
+	sm.mu.Lock()
+	sm.assigned = assigned
+	sm.mu.Unlock()
 
-	return symbols, nil
+	return results, nil
+}
+
+// ExchangeFor returns the exchange assigned to a watchlist symbol ("" = default)
+func (sm *SymbolManager) ExchangeFor(symbol string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.assigned[symbol]
 }
@@ -3,11 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
 	"mrcrypto-go/internal/model"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -16,21 +16,24 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var telegramLog = logger.Component("telegram")
+
 type TelegramService struct {
 	bot           *tgbotapi.BotAPI
 	chatID        int64
 	collection    *mongo.Collection
-	binance       *BinanceService
+	market        MarketDataProvider
 	symbolManager *SymbolManager
+	tracker       *SignalTracker // Optional, enables /patterns
 }
 
-func NewTelegramService(binanceService *BinanceService, symbolManager *SymbolManager) (*TelegramService, error) {
+func NewTelegramService(market MarketDataProvider, symbolManager *SymbolManager) (*TelegramService, error) {
 	bot, err := tgbotapi.NewBotAPI(config.AppConfig.TelegramBotToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
 	}
 
-	log.Printf("✅ Telegram bot authorized: %s", bot.Self.UserName)
+	telegramLog.Info("✅ Telegram bot authorized", "bot", bot.Self.UserName)
 
 	// MongoDB connection for /today command
 	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(config.AppConfig.MongoURI))
@@ -44,17 +47,36 @@ func NewTelegramService(binanceService *BinanceService, symbolManager *SymbolMan
 		bot:           bot,
 		chatID:        parseChatID(config.AppConfig.TelegramChatID),
 		collection:    collection,
-		binance:       binanceService,
+		market:        market,
 		symbolManager: symbolManager,
 	}
 
 	// Start command handler in background
 	go service.handleCommands()
-	log.Println("✅ Telegram command handler started")
+	telegramLog.Info("✅ Telegram command handler started")
 
 	return service, nil
 }
 
+// Ping checks that the bot token is still accepted by Telegram
+func (s *TelegramService) Ping() error {
+	if _, err := s.bot.GetMe(); err != nil {
+		return fmt.Errorf("telegram getMe failed: %w", err)
+	}
+	return nil
+}
+
+// Stop stops receiving commands; sending keeps working until the process exits
+func (s *TelegramService) Stop() {
+	s.bot.StopReceivingUpdates()
+	telegramLog.Info("🔌 Telegram command handler stopped")
+}
+
+// SetSignalTracker enables the /patterns command
+func (s *TelegramService) SetSignalTracker(tracker *SignalTracker) {
+	s.tracker = tracker
+}
+
 // handleCommands listens for and processes Telegram commands
 func (s *TelegramService) handleCommands() {
 	u := tgbotapi.NewUpdate(0)
@@ -73,46 +95,41 @@ func (s *TelegramService) handleCommands() {
 
 		command := update.Message.Command()
 		chatID := update.Message.Chat.ID
+		telegramLog.Info("📱 Command received", "command", command, "chat_id", chatID)
 
 		switch command {
 		case "start":
-			log.Println("📱 /start command executed")
 			s.handleStart(chatID)
 		case "status":
-			log.Println("📱 /status command executed")
 			s.handleStatusCheck(update.Message)
 		case "today":
-			log.Println("📱 /today command executed")
 			s.handleToday(chatID)
 		case "help":
-			log.Println("📱 /help command executed")
 			s.handleHelp(chatID)
 		case "active":
-			log.Println("📱 /active command executed")
 			s.handleActive(update.Message)
 		case "pnl":
-			log.Println("📱 /pnl command executed")
 			s.handlePnL(update.Message)
 		case "stats":
-			log.Println("📱 /stats command executed")
 			s.handleStats(update.Message)
 		case "closed":
-			log.Println("📱 /closed command executed")
 			s.handleClosed(update.Message)
+		case "patterns":
+			s.handlePatterns(update.Message)
 		case "price":
-			log.Println("📱 /price command executed")
 			s.handlePrice(update.Message)
 		case "reset":
-			log.Println("📱 /reset command executed")
 			s.handleReset(update.Message)
 		case "symbol":
-			log.Println("📱 /symbol command executed")
 			s.handleSymbol(update.Message)
+		case "why":
+			s.handleWhy(update.Message)
 		default:
 			// Handle dynamic commands like /status_A1B2C
 			if strings.HasPrefix(command, "status_") {
-				log.Printf("📱 %s command executed", command)
 				s.handleStatusCheck(update.Message)
+			} else if strings.HasPrefix(command, "why_") {
+				s.handleWhy(update.Message)
 			} else {
 				msg := tgbotapi.NewMessage(chatID, "Unknown command. Use /help to see available commands.")
 				s.bot.Send(msg)
@@ -128,6 +145,7 @@ func (s *TelegramService) handleSymbol(msg *tgbotapi.Message) {
 		s.sendMessage(msg.Chat.ID, `💡 <b>Symbol Management</b>
 Usage:
 • <code>/symbol add BTCUSDT</code> (Add to watchlist)
+• <code>/symbol add BTCUSDT bybit</code> (Add on a specific exchange)
 • <code>/symbol del BTCUSDT</code> (Remove from watchlist)
 • <code>/symbol list</code> (Show watchlist)`)
 		return
@@ -142,8 +160,14 @@ Usage:
 			return
 		}
 		symbol := strings.ToUpper(parts[2])
-		if err := s.symbolManager.AddSymbol(symbol); err != nil {
+		exchange := ""
+		if len(parts) > 3 {
+			exchange = strings.ToLower(parts[3])
+		}
+		if err := s.symbolManager.AddSymbol(symbol, exchange); err != nil {
 			s.sendMessage(msg.Chat.ID, fmt.Sprintf("❌ Failed to add symbol: %v", err))
+		} else if exchange != "" {
+			s.sendMessage(msg.Chat.ID, fmt.Sprintf("✅ <b>%s</b> added to watchlist (%s).", symbol, exchange))
 		} else {
 			s.sendMessage(msg.Chat.ID, fmt.Sprintf("✅ <b>%s</b> added to watchlist.", symbol))
 		}
@@ -161,17 +185,25 @@ Usage:
 		}
 
 	case "list":
-		symbols, err := s.symbolManager.GetWatchlist()
+		entries, err := s.symbolManager.GetWatchlistEntries()
 		if err != nil {
 			s.sendMessage(msg.Chat.ID, fmt.Sprintf("❌ Failed to fetch list: %v", err))
 			return
 		}
 
-		if len(symbols) == 0 {
+		if len(entries) == 0 {
 			s.sendMessage(msg.Chat.ID, "📭 Watchlist is empty.")
 			return
 		}
 
+		// Symbols on a non-default exchange show it in brackets
+		symbols := make([]string, len(entries))
+		for i, e := range entries {
+			symbols[i] = e.Symbol
+			if e.Exchange != "" {
+				symbols[i] += " (" + e.Exchange + ")"
+			}
+		}
 		message := fmt.Sprintf("📋 <b>Watchlist (%d)</b>\n\n", len(symbols))
 		message += strings.Join(symbols, ", ")
 		s.sendMessage(msg.Chat.ID, message)
@@ -203,7 +235,39 @@ func (s *TelegramService) handleReset(msg *tgbotapi.Message) {
 🔄 Monitoring will start fresh.`, result.DeletedCount)
 
 	s.sendMessage(msg.Chat.ID, confirmation)
-	log.Printf("🗑️ [Telegram] System reset triggered by user. Deleted %d signals.", result.DeletedCount)
+	telegramLog.Warn("🗑️ System reset triggered by user", "deleted_signals", result.DeletedCount)
+}
+
+// handleWhy shows the per-factor score breakdown of a signal (/why ID or /why_ID)
+func (s *TelegramService) handleWhy(msg *tgbotapi.Message) {
+	var signalID string
+	command := msg.Command()
+	if strings.HasPrefix(command, "why_") {
+		signalID = strings.TrimPrefix(command, "why_")
+	} else {
+		signalID = msg.CommandArguments()
+	}
+	signalID = strings.ToUpper(strings.TrimSpace(signalID))
+
+	if signalID == "" {
+		s.sendMessage(msg.Chat.ID, `💡 <b>Usage:</b>
+• <code>/why {ID}</code>
+• or click <code>/why_ID</code>
+
+Example: /why A1B2C`)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var signal model.Signal
+	if err := s.collection.FindOne(ctx, bson.M{"id": signalID}).Decode(&signal); err != nil {
+		s.sendMessage(msg.Chat.ID, fmt.Sprintf("❌ Signal ID <b>%s</b> not found.", escapeHTML(signalID)))
+		return
+	}
+
+	s.sendMessage(msg.Chat.ID, formatScoreBreakdown(&signal))
 }
 
 // handleStart sends welcome message
@@ -285,6 +349,8 @@ func (s *TelegramService) handleToday(chatID int64) {
 			} else {
 				statusEmoji = "❌"
 			}
+		} else if sig.Status == model.StatusPartial {
+			statusEmoji = "🎯"
 		} else if sig.PnL < 0 {
 			statusEmoji = "🔻"
 		}
@@ -355,19 +421,20 @@ Example: /status A1B2C`)
 
 	// Fetch current price for live update
 	currentPrice := 0.0
-	klines, err := s.binance.GetKlines(signal.Symbol, "1m", 1)
+	klines, err := s.market.GetKlines(signal.Symbol, "1m", 1)
 	if err == nil && len(klines) > 0 {
 		currentPrice = klines[0].Close
 	}
 
-	// Calculate live PnL if active
+	// Calculate live PnL if open (blended with the TP1 half once booked)
 	livePnL := signal.PnL
-	if signal.Status == "ACTIVE" && currentPrice > 0 {
+	if signal.IsOpen() && currentPrice > 0 {
 		if signal.Type == model.SignalTypeLong {
 			livePnL = ((currentPrice - signal.EntryPrice) / signal.EntryPrice) * 100
 		} else {
 			livePnL = ((signal.EntryPrice - currentPrice) / signal.EntryPrice) * 100
 		}
+		livePnL = signal.BlendedPnL(livePnL)
 	}
 
 	// Base Message (Original Signal Format)
@@ -402,8 +469,14 @@ Example: /status A1B2C`)
 		time.Now().Format("15:04:05, 02 Jan"),
 	)
 
+	if signal.TP1Hit {
+		statusSection += fmt.Sprintf("<b>TP1 (50%%):</b> +%.2f%% @ %s | SL → Breakeven\n", signal.TP1PnL, FormatPrice(signal.TP1Price))
+	}
 	if signal.Status == "CLOSED" {
 		statusSection += fmt.Sprintf("<b>Closed Reason:</b> %s\n", signal.CloseReason)
+		if exit := formatExit(&signal); exit != "" {
+			statusSection += "<b>Exit:</b> " + exit + "\n"
+		}
 	}
 
 	finalMessage := baseMessage + statusSection
@@ -421,8 +494,10 @@ func (s *TelegramService) handleHelp(chatID int64) {
 /closed - Recently closed signals
 /pnl - Profit &amp; Loss summary
 /stats - Performance statistics
+/patterns - সেরা ও দুর্বল signal patterns
 /price SYMBOL - Current price check
 /today - আজকের signals
+/why ID - Signal এর স্কোর কোথা থেকে এলো
 
 <b>⚙️ Config Commands:</b>
 /symbol add SYMBOL - Watchlist এ coin add করুন (e.g. /symbol add BTCUSDT)
@@ -452,7 +527,7 @@ func (s *TelegramService) handleActive(msg *tgbotapi.Message) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := s.collection.Find(ctx, bson.M{"status": "ACTIVE"})
+	cursor, err := s.collection.Find(ctx, bson.M{"status": bson.M{"$in": model.OpenStatuses}})
 	if err != nil {
 		s.sendMessage(msg.Chat.ID, "❌ Failed to fetch active signals")
 		return
@@ -481,13 +556,17 @@ func (s *TelegramService) handleActive(msg *tgbotapi.Message) {
 		if signal.Type == model.SignalTypeShort {
 			emoji = "🔴"
 		}
+		partial := ""
+		if signal.TP1Hit {
+			partial = fmt.Sprintf(" | 🎯 TP1 +%.2f%%", signal.TP1PnL)
+		}
 
-		message += fmt.Sprintf(`%s <b>%s - %s</b>
+		message += fmt.Sprintf(`%s <b>%s - %s</b>%s
 Entry: %s
 TP: %s | SL: %s
 ⏰ %s
 
-`, emoji, signal.Symbol, signal.Type,
+`, emoji, signal.Symbol, signal.Type, partial,
 			FormatPrice(signal.EntryPrice),
 			FormatPrice(signal.TakeProfit),
 			FormatPrice(signal.StopLoss),
@@ -509,47 +588,35 @@ func (s *TelegramService) handlePnL(msg *tgbotapi.Message) {
 
 	// Today's PnL
 	today := time.Now().Truncate(24 * time.Hour)
-	todayCursor, _ := s.collection.Find(ctx, bson.M{
+	todaySignals, err := s.findSignals(ctx, bson.M{
 		"status":     "CLOSED",
 		"created_at": bson.M{"$gte": today},
 	})
-	defer todayCursor.Close(ctx)
-
-	var todaySignals []model.Signal
-	todayCursor.All(ctx, &todaySignals)
-
-	todayPnL, todayWins, todayLosses := 0.0, 0, 0
-	for _, sig := range todaySignals {
-		todayPnL += sig.PnL
-		if sig.PnL > 0 {
-			todayWins++
-		} else {
-			todayLosses++
-		}
+	if err != nil {
+		s.sendMessage(msg.Chat.ID, "❌ Failed to fetch today's signals")
+		return
 	}
 
 	// This week
 	weekStart := time.Now().AddDate(0, 0, -7)
-	weekCursor, _ := s.collection.Find(ctx, bson.M{
+	weekSignals, err := s.findSignals(ctx, bson.M{
 		"status":     "CLOSED",
 		"created_at": bson.M{"$gte": weekStart},
 	})
-	defer weekCursor.Close(ctx)
-
-	var weekSignals []model.Signal
-	weekCursor.All(ctx, &weekSignals)
-
-	weekPnL := 0.0
-	for _, sig := range weekSignals {
-		weekPnL += sig.PnL
+	if err != nil {
+		s.sendMessage(msg.Chat.ID, "❌ Failed to fetch this week's signals")
+		return
 	}
 
-	winRate := 0.0
-	totalTrades := todayWins + todayLosses
-	if totalTrades > 0 {
-		winRate = (float64(todayWins) / float64(totalTrades)) * 100
+	// Open positions that already booked TP1 (half of the position is realized)
+	partialSignals, err := s.findSignals(ctx, bson.M{"status": model.StatusPartial})
+	if err != nil {
+		s.sendMessage(msg.Chat.ID, "❌ Failed to fetch partial signals")
+		return
 	}
 
+	summary := SummarizePnL(todaySignals, weekSignals, partialSignals)
+
 	message := fmt.Sprintf(`💰 <b>Profit &amp; Loss Summary</b>
 
 📅 <b>Today:</b> %s%.2f%% (%d trades)
@@ -559,66 +626,51 @@ func (s *TelegramService) handlePnL(msg *tgbotapi.Message) {
 
 📅 <b>This Week:</b> %s%.2f%% (%d trades)
 
+🎯 <b>TP1 Booked (open):</b> +%.2f%% (%d positions)
+
+ℹ️ PnL = TP1 (50%%) + বাকি অংশের মিলিত (blended) হিসাব
 💡 আপনার পারফরম্যান্স দেখতে /stats ব্যবহার করুন
 `,
-		getPnLEmoji(todayPnL), todayPnL, len(todaySignals),
-		todayWins, todayLosses, winRate,
-		getPnLEmoji(weekPnL), weekPnL, len(weekSignals))
+		getPnLEmoji(summary.TodayPnL), summary.TodayPnL, summary.TodayTrades,
+		summary.TodayWins, summary.TodayLosses, summary.TodayWinRate,
+		getPnLEmoji(summary.WeekPnL), summary.WeekPnL, summary.WeekTrades,
+		summary.BookedPnL, summary.PartialPositions)
 
 	s.sendMessage(msg.Chat.ID, message)
 }
 
+// findSignals loads every signal matching filter
+func (s *TelegramService) findSignals(ctx context.Context, filter bson.M) ([]model.Signal, error) {
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signals: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var signals []model.Signal
+	if err := cursor.All(ctx, &signals); err != nil {
+		return nil, fmt.Errorf("failed to decode signals: %w", err)
+	}
+	return signals, nil
+}
+
 // handleStats shows performance statistics
 func (s *TelegramService) handleStats(msg *tgbotapi.Message) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, _ := s.collection.Find(ctx, bson.M{"status": "CLOSED"})
-	defer cursor.Close(ctx)
-
-	var allSignals []model.Signal
-	cursor.All(ctx, &allSignals)
+	allSignals, err := s.findSignals(ctx, bson.M{"status": "CLOSED"})
+	if err != nil {
+		s.sendMessage(msg.Chat.ID, "❌ Failed to fetch closed signals")
+		return
+	}
 
 	if len(allSignals) == 0 {
 		s.sendMessage(msg.Chat.ID, "📊 এখনো কোন closed signal নেই।")
 		return
 	}
 
-	wins, losses := 0, 0
-	totalWinPnL, totalLossPnL := 0.0, 0.0
-	bestTrade, worstTrade := model.Signal{PnL: -999}, model.Signal{PnL: 999}
-
-	for _, sig := range allSignals {
-		if sig.PnL > 0 {
-			wins++
-			totalWinPnL += sig.PnL
-			if sig.PnL > bestTrade.PnL {
-				bestTrade = sig
-			}
-		} else {
-			losses++
-			totalLossPnL += sig.PnL
-			if sig.PnL < worstTrade.PnL {
-				worstTrade = sig
-			}
-		}
-	}
-
-	totalTrades := wins + losses
-	winRate := (float64(wins) / float64(totalTrades)) * 100
-	avgWin := 0.0
-	avgLoss := 0.0
-	if wins > 0 {
-		avgWin = totalWinPnL / float64(wins)
-	}
-	if losses > 0 {
-		avgLoss = totalLossPnL / float64(losses)
-	}
-
-	profitFactor := 0.0
-	if totalLossPnL != 0 {
-		profitFactor = -totalWinPnL / totalLossPnL
-	}
+	stats := CalculatePerformanceStats(allSignals)
 
 	message := fmt.Sprintf(`📊 <b>Performance Statistics</b>
 
@@ -630,18 +682,73 @@ func (s *TelegramService) handleStats(msg *tgbotapi.Message) {
 🏆 <b>Best Trade:</b> +%.2f%% (%s)
 💀 <b>Worst Trade:</b> %.2f%% (%s)
 
+🎯 <b>TP1 Hit Rate:</b> %.1f%% (%d/%d)
+
 📊 <b>Total Trades:</b> %d
 ✅ <b>Wins:</b> %d
 ❌ <b>Losses:</b> %d
+
+ℹ️ PnL = TP1 (50%%) + বাকি অংশের মিলিত (blended) হিসাব
 `,
-		winRate, wins, totalTrades,
-		profitFactor,
-		avgWin,
-		avgLoss,
-		bestTrade.PnL, bestTrade.Symbol,
-		worstTrade.PnL, worstTrade.Symbol,
-		totalTrades, wins, losses)
+		stats.WinRate, stats.Wins, stats.TotalTrades,
+		stats.ProfitFactor,
+		stats.AvgWin,
+		stats.AvgLoss,
+		stats.BestTrade.PnL, stats.BestTrade.Symbol,
+		stats.WorstTrade.PnL, stats.WorstTrade.Symbol,
+		stats.TP1HitRate, stats.TP1Hits, stats.TotalTrades,
+		stats.TotalTrades, stats.Wins, stats.Losses)
+
+	s.sendMessage(msg.Chat.ID, message)
+}
+
+// handlePatterns lists the best and worst pattern fingerprints tracked by SignalTracker
+func (s *TelegramService) handlePatterns(msg *tgbotapi.Message) {
+	if s.tracker == nil {
+		s.sendMessage(msg.Chat.ID, "⚠️ Pattern tracking চালু নেই।")
+		return
+	}
+
+	const minTrades = 3
+	const listSize = 5
+
+	ranked := s.tracker.RankPatterns(minTrades)
+	if len(ranked) == 0 {
+		s.sendMessage(msg.Chat.ID, fmt.Sprintf("📊 এখনো কোন pattern এ %d টি closed trade হয়নি।", minTrades))
+		return
+	}
+
+	formatPattern := func(stats PatternStats) string {
+		status := ""
+		if !stats.IsEnabled {
+			status = " 🚫"
+		}
+		return fmt.Sprintf("• <code>%s</code>\n  %.1f%% (%d/%d)%s\n", stats.Pattern, stats.WinRate, stats.WinCount, stats.TotalCount, status)
+	}
+
+	best := ranked
+	if len(best) > listSize {
+		best = best[:listSize]
+	}
+
+	message := "📊 <b>Pattern Performance</b>\n\n🏆 <b>Best Patterns:</b>\n"
+	for _, stats := range best {
+		message += formatPattern(stats)
+	}
+
+	// Worst list skips patterns already shown as best when there are only a few
+	worstCount := len(ranked) - len(best)
+	if worstCount > listSize {
+		worstCount = listSize
+	}
+	if worstCount > 0 {
+		message += "\n💀 <b>Worst Patterns:</b>\n"
+		for i := len(ranked) - 1; i >= len(ranked)-worstCount; i-- {
+			message += formatPattern(ranked[i])
+		}
+	}
 
+	message += fmt.Sprintf("\nℹ️ কমপক্ষে %d টি trade হওয়া patterns | 🚫 = auto-disabled", minTrades)
 	s.sendMessage(msg.Chat.ID, message)
 }
 
@@ -687,6 +794,8 @@ func (s *TelegramService) handleClosed(msg *tgbotapi.Message) {
 			reasonEmoji = "🎯"
 		case "SL_HIT":
 			reasonEmoji = "🛑"
+		case "BREAKEVEN_STOP":
+			reasonEmoji = "🛡️"
 		}
 
 		closedTime := time.Now()
@@ -720,7 +829,7 @@ Example: /price ETHUSDT`)
 	symbol := strings.ToUpper(parts[1])
 
 	// Fetch current 1m kline data
-	klines, err := s.binance.GetKlines(symbol, "1m", 1)
+	klines, err := s.market.GetKlines(symbol, "1m", 1)
 	if err != nil {
 		s.sendMessage(msg.Chat.ID, fmt.Sprintf(`❌ <b>Error</b>
 
@@ -743,7 +852,7 @@ Symbol টি সঠিক আছে কিনা চেক করুন।`, sy
 	volume := klines[0].Volume
 
 	// Fetch 24h kline to calculate 24h change
-	klines24h, err := s.binance.GetKlines(symbol, "1d", 1)
+	klines24h, err := s.market.GetKlines(symbol, "1d", 1)
 	change24h := 0.0
 	if err == nil && len(klines24h) > 0 {
 		price24hAgo := klines24h[0].Open
@@ -791,8 +900,8 @@ func (s *TelegramService) sendMessage(chatID int64, message string) {
 }
 
 // SendSignal sends a trading signal notification to Telegram
-func (s *TelegramService) SendSignal(signal *model.Signal) error {
-	log.Printf("📤 [Telegram] Sending signal notification for %s...", signal.Symbol)
+func (s *TelegramService) SendSignal(ctx context.Context, signal *model.Signal) error {
+	telegramLog.DebugContext(ctx, "📤 Sending signal notification")
 	message := formatSignalMessage(signal)
 
 	msg := tgbotapi.NewMessage(s.chatID, message)
@@ -803,7 +912,7 @@ func (s *TelegramService) SendSignal(signal *model.Signal) error {
 		return fmt.Errorf("failed to send telegram message: %w", err)
 	}
 
-	log.Printf("📲 Telegram notification sent for %s", signal.Symbol)
+	telegramLog.InfoContext(ctx, "📲 Telegram notification sent")
 	return nil
 }
 
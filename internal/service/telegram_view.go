@@ -30,6 +30,13 @@ func formatSignalMessage(signal *model.Signal) string {
 	if aiScore == 0 {
 		aiScore = systemScore // Fallback if AI score not yet distinct
 	}
+	aiScoreLine := fmt.Sprintf("🤖 <b>AI Score:</b> %d/100", aiScore)
+	switch {
+	case signal.AIUnvalidated:
+		aiScoreLine = "🤖 <b>AI Score:</b> ⚠️ যাচাই হয়নি (AI unavailable)"
+	case signal.AIValidator == "rules":
+		aiScoreLine += " (নিয়মভিত্তিক)"
+	}
 
 	// Tier Display
 	systemTier := string(signal.Tier)
@@ -77,12 +84,14 @@ func formatSignalMessage(signal *model.Signal) string {
 
 🚀 <b>ENTRY:</b> <code>%s</code>
 🛑 <b>SL:</b> <code>%s</code> (%.2f%%)
+💼 <b>রিস্ক:</b> অ্যাকাউন্টের %.2f%%
 
 🎯 <b>TP 1:</b> <code>%s</code> (%.2f%%)
 🏆 <b>TP 2:</b> <code>%s</code> (%.2f%%)
 
-🤖 <b>AI Score:</b> %d/100
+%s
 ⚙️ <b>System Score:</b> %d/100
+🔍 /why_%s - স্কোরের ব্যাখ্যা
 
 ━━━━━━━━━━━━━━━━━━━
 📊 <b>মার্কেট কন্টেক্সট</b>
@@ -117,12 +126,14 @@ func formatSignalMessage(signal *model.Signal) string {
 		FormatPrice(signal.EntryPrice),
 		FormatPrice(signal.StopLoss),
 		signal.RiskPercent,
+		signal.RecommendedSize,
 		FormatPrice(signal.TakeProfit1),
 		signal.TP1Percent,
 		FormatPrice(signal.TakeProfit2),
 		signal.TP2Percent,
-		aiScore,
+		aiScoreLine,
 		systemScore,
+		signal.ID,
 		// Market Context
 		sessionEmoji, signal.TechnicalContext.TradingSession, signal.TechnicalContext.SessionVolatility,
 		fundingEmoji, signal.TechnicalContext.FundingRate, signal.TechnicalContext.FundingSentiment,
@@ -139,6 +150,62 @@ func formatSignalMessage(signal *model.Signal) string {
 	return message
 }
 
+// formatScoreBreakdown lists every factor behind a signal's confluence score
+func formatScoreBreakdown(signal *model.Signal) string {
+	message := fmt.Sprintf("🔍 <b>স্কোর ব্যাখ্যা — %s %s</b>\n🆔 <b>ID:</b> %s\n\n", signal.Symbol, signal.Type, signal.ID)
+
+	if len(signal.ScoreBreakdown) == 0 {
+		return message + "⚠️ এই signal এর স্কোর ব্যাখ্যা সংরক্ষিত নেই (পুরনো signal)।"
+	}
+
+	var gained, lost []string
+	for _, factor := range signal.ScoreBreakdown {
+		line := fmt.Sprintf("<b>%+d</b> %s <i>(%s)</i>", factor.Points, escapeHTML(factor.Name), escapeHTML(factor.Value))
+		switch {
+		case factor.Points > 0:
+			gained = append(gained, "✅ "+line)
+		case factor.Points < 0:
+			lost = append(lost, "❌ "+line)
+		}
+	}
+
+	if len(gained) > 0 {
+		message += "<b>যা স্কোর বাড়িয়েছে:</b>\n" + strings.Join(gained, "\n") + "\n\n"
+	}
+	if len(lost) > 0 {
+		message += "<b>যা স্কোর কমিয়েছে:</b>\n" + strings.Join(lost, "\n") + "\n\n"
+	}
+
+	var idle []string
+	for _, factor := range signal.ScoreBreakdown {
+		if factor.Points == 0 {
+			idle = append(idle, escapeHTML(factor.Name))
+		}
+	}
+	if len(idle) > 0 {
+		message += "<b>অবদান নেই:</b> " + strings.Join(idle, ", ") + "\n\n"
+	}
+
+	message += fmt.Sprintf("⚙️ <b>মোট System Score:</b> %d/100", signal.ConfluenceScore)
+	if exit := formatExit(signal); exit != "" {
+		message += "\n🏁 <b>ফলাফল:</b> " + exit
+	}
+	return message
+}
+
+// formatExit describes where a closed signal exited, e.g. "TP_HIT @ 2450.10 (+3.20%) · 14:05, 02 Jan".
+// Empty for open signals and for signals closed before exit prices were recorded.
+func formatExit(signal *model.Signal) string {
+	if signal.Status != model.StatusClosed || signal.ExitPrice <= 0 {
+		return ""
+	}
+	exit := fmt.Sprintf("%s @ %s (%s%.2f%%)", signal.CloseReason, FormatPrice(signal.ExitPrice), getPnLSign(signal.PnL), signal.PnL)
+	if signal.ResolvedAt != nil {
+		exit += " · " + signal.ResolvedAt.Format("15:04, 02 Jan")
+	}
+	return exit
+}
+
 // escapeHTML escapes HTML special characters for Telegram
 func escapeHTML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
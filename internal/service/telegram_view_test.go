@@ -0,0 +1,41 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mrcrypto-go/internal/model"
+)
+
+func TestFormatExit(t *testing.T) {
+	resolvedAt := time.Date(2025, 10, 9, 14, 5, 0, 0, time.UTC)
+	signal := &model.Signal{
+		ID:             "A1B2C",
+		Symbol:         "ETHUSDT",
+		Type:           model.SignalTypeShort,
+		Status:         model.StatusClosed,
+		CloseReason:    "TP_HIT",
+		ExitPrice:      1820,
+		ResolvedAt:     &resolvedAt,
+		PnL:            9,
+		ScoreBreakdown: []model.ScoreFactor{{Name: "Trend Alignment", Value: "ADX 30", Points: 20}},
+	}
+	if got := formatExit(signal); got != "TP_HIT @ 1820.00 (+9.00%) · 14:05, 09 Oct" {
+		t.Errorf("formatExit = %q", got)
+	}
+	if !strings.HasSuffix(formatScoreBreakdown(signal), "🏁 <b>ফলাফল:</b> TP_HIT @ 1820.00 (+9.00%) · 14:05, 09 Oct") {
+		t.Error("/why should end with the exit")
+	}
+
+	// Closed before exit prices were recorded, or still open
+	legacy := *signal
+	legacy.ExitPrice, legacy.ResolvedAt = 0, nil
+	open := *signal
+	open.Status = model.StatusPartial
+	for _, s := range []*model.Signal{&legacy, &open} {
+		if got := formatExit(s); got != "" {
+			t.Errorf("formatExit(%s) = %q, want empty", s.Status, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"mrcrypto-go/internal/config"
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/model"
+)
+
+// SignalValidator scores a batch of strategy signals before they are sent.
+// Results are returned in the same order as the signals.
+type SignalValidator interface {
+	Name() string
+	BatchValidateSignals(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error)
+}
+
+var _ SignalValidator = (*AIService)(nil)
+var _ SignalValidator = (*OpenAIValidator)(nil)
+var _ SignalValidator = (*RuleBasedValidator)(nil)
+var _ SignalValidator = (*PolicyValidator)(nil)
+
+// UnavailablePolicy decides what happens to a batch when its validator fails
+type UnavailablePolicy string
+
+const (
+	PolicySkip        UnavailablePolicy = "skip"     // Drop the batch (old behaviour)
+	PolicyPassThrough UnavailablePolicy = "pass"     // Keep signals, flagged as unvalidated
+	PolicyFallback    UnavailablePolicy = "fallback" // Score with the rule-based validator
+)
+
+// PolicyValidator wraps a primary validator and applies the unavailability policy
+type PolicyValidator struct {
+	primary  SignalValidator
+	fallback SignalValidator
+	policy   UnavailablePolicy
+}
+
+// NewPolicyValidator creates a validator that falls back according to policy
+func NewPolicyValidator(primary SignalValidator, policy UnavailablePolicy) *PolicyValidator {
+	return &PolicyValidator{
+		primary:  primary,
+		fallback: NewRuleBasedValidator(),
+		policy:   policy,
+	}
+}
+
+// Name returns the primary backend name
+func (v *PolicyValidator) Name() string {
+	return v.primary.Name()
+}
+
+// BatchValidateSignals validates with the primary backend, applying the policy if it fails
+// as a whole or leaves individual signals without an answer
+func (v *PolicyValidator) BatchValidateSignals(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error) {
+	results, err := v.primary.BatchValidateSignals(ctx, signals)
+	if err != nil {
+		if v.policy == PolicySkip || ctx.Err() != nil {
+			return nil, err
+		}
+		aiLog.WarnContext(ctx, "⚠️  Validator unavailable - applying policy",
+			"validator", v.primary.Name(), "policy", v.policy, "signals", len(signals), "error", err)
+		return v.unavailable(ctx, signals)
+	}
+
+	if v.policy == PolicySkip {
+		return results, nil
+	}
+
+	// Signals the backend never answered properly get the same treatment, one by one
+	for i, result := range results {
+		if !result.Missing {
+			continue
+		}
+		aiLog.WarnContext(logger.WithSymbol(ctx, signals[i].Symbol), "⚠️  No answer - applying policy",
+			"validator", v.primary.Name(), "policy", v.policy)
+		replacement, err := v.unavailable(ctx, signals[i:i+1])
+		if err != nil {
+			return nil, err
+		}
+		results[i] = replacement[0]
+	}
+	return results, nil
+}
+
+// unavailable scores signals the primary backend could not validate
+func (v *PolicyValidator) unavailable(ctx context.Context, signals []*model.Signal) ([]AIValidationResult, error) {
+	if v.policy == PolicyFallback {
+		return v.fallback.BatchValidateSignals(ctx, signals)
+	}
+
+	results := make([]AIValidationResult, len(signals))
+	for i, signal := range signals {
+		results[i] = AIValidationResult{
+			Score:       signal.ConfluenceScore,
+			Tier:        string(signal.Tier),
+			Reason:      "AI যাচাই করা যায়নি - শুধু সিস্টেম স্কোরের ভিত্তিতে পাঠানো হয়েছে",
+			Validator:   v.primary.Name(),
+			Unvalidated: true,
+		}
+	}
+	return results, nil
+}
+
+// NewSignalValidator builds the validator selected by AI_VALIDATOR wrapped in AI_UNAVAILABLE_POLICY.
+// LLM backends write every prompt and response to audit when it is not nil.
+func NewSignalValidator(audit AIAuditLog) (SignalValidator, error) {
+	var primary SignalValidator
+	switch config.AppConfig.AIValidator {
+	case "gemini", "":
+		gemini := NewAIService()
+		gemini.SetAuditLog(audit)
+		primary = gemini
+	case "openai":
+		openai := NewOpenAIValidator(config.AppConfig.OpenAIBaseURL, config.AppConfig.OpenAIAPIKey, config.AppConfig.OpenAIModel)
+		openai.SetAuditLog(audit)
+		primary = openai
+	case "rules":
+		primary = NewRuleBasedValidator()
+	default:
+		return nil, fmt.Errorf("unknown AI_VALIDATOR %q (use gemini, openai or rules)", config.AppConfig.AIValidator)
+	}
+
+	policy := UnavailablePolicy(config.AppConfig.AIUnavailablePolicy)
+	switch policy {
+	case PolicySkip, PolicyPassThrough, PolicyFallback:
+	default:
+		return nil, fmt.Errorf("unknown AI_UNAVAILABLE_POLICY %q (use skip, pass or fallback)", policy)
+	}
+
+	aiLog.Info("✅ AI validator configured", "validator", primary.Name(), "when_unavailable", policy)
+	return NewPolicyValidator(primary, policy), nil
+}
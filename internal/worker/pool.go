@@ -1,14 +1,19 @@
 package worker
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"sync"
+	"time"
 
+	"mrcrypto-go/internal/logger"
+	"mrcrypto-go/internal/metrics"
 	"mrcrypto-go/internal/model"
 	"mrcrypto-go/internal/service"
 )
 
+var poolLog = logger.Component("worker")
+
 type ScanResult struct {
 	Signal *model.Signal
 	Symbol string
@@ -21,6 +26,7 @@ type WorkerPool struct {
 	results  chan ScanResult
 	wg       sync.WaitGroup
 	strategy *service.StrategyService
+	ctx      context.Context // Poll context, for log correlation in Wait
 }
 
 // NewPool creates a new worker pool
@@ -33,33 +39,45 @@ func NewPool(workers int, strategy *service.StrategyService) *WorkerPool {
 	}
 }
 
-// Start launches the worker goroutines
-func (p *WorkerPool) Start() {
-	log.Printf("🔄 [Worker Pool] Starting %d workers...", p.workers)
+// Start launches the worker goroutines. Once ctx is cancelled, queued symbols are skipped
+// and running evaluations stop at their next market data request.
+func (p *WorkerPool) Start(ctx context.Context) {
+	p.ctx = ctx
 	for i := 0; i < p.workers; i++ {
 		p.wg.Add(1)
-		go p.worker(i)
+		go p.worker(ctx, i)
 	}
-	log.Printf("✅ [Worker Pool] All %d workers started", p.workers)
+	poolLog.DebugContext(ctx, "✅ Workers started", "workers", p.workers, "profile", p.strategy.Profile().Name)
 }
 
 // worker processes jobs from the jobs channel
-func (p *WorkerPool) worker(id int) {
+func (p *WorkerPool) worker(ctx context.Context, id int) {
 	// Critical: Add panic recovery to prevent entire pool crash
 	defer service.RecoverAndLog(fmt.Sprintf("Worker %d", id))
 	defer p.wg.Done()
 
 	for symbol := range p.jobs {
-		log.Printf("⏳ [Worker %d] Processing %s...", id, symbol)
+		if ctx.Err() != nil {
+			continue // Drain the queue without evaluating
+		}
+		symbolCtx := logger.WithSymbol(ctx, symbol)
+		poolLog.DebugContext(symbolCtx, "⏳ Processing", "worker", id)
 
 		// Add individual job panic recovery
 		func() {
 			defer service.RecoverAndLog(fmt.Sprintf("Worker %d processing %s", id, symbol))
 
-			signal, price, err := p.strategy.EvaluateSymbol(symbol)
+			profile := p.strategy.Profile().Name
+			start := time.Now()
+			signal, price, err := p.strategy.EvaluateSymbol(ctx, symbol)
+			if ctx.Err() != nil {
+				return // Interrupted by shutdown, not an evaluation failure
+			}
+			metrics.EvaluationDuration.Observe(time.Since(start).Seconds(), profile, symbol)
 
 			if err != nil {
-				log.Printf("⚠️  [Worker %d] Error evaluating %s: %v", id, symbol, err)
+				poolLog.WarnContext(symbolCtx, "⚠️  Error evaluating", "worker", id, "profile", profile, "error", err)
+				metrics.EvaluationErrors.Inc(profile, symbol)
 				return
 			}
 
@@ -71,11 +89,11 @@ func (p *WorkerPool) worker(id int) {
 			}
 
 			if signal != nil {
-				log.Printf("📈 [Worker %d] Signal found for %s!", id, symbol)
+				poolLog.DebugContext(symbolCtx, "📈 Signal found", "worker", id, "signal_id", signal.ID)
 			}
 		}()
 	}
-	log.Printf("✅ [Worker %d] Completed all jobs", id)
+	poolLog.DebugContext(ctx, "✅ Worker completed all jobs", "worker", id)
 }
 
 // AddJob adds a symbol to the job queue
@@ -86,7 +104,6 @@ func (p *WorkerPool) AddJob(symbol string) {
 // Wait closes the jobs channel and waits for all workers to finish
 // Returns potential signals and a map of current prices for all scanned symbols
 func (p *WorkerPool) Wait() ([]*model.Signal, map[string]float64) {
-	log.Printf("⏳ [Worker Pool] Waiting for all workers to complete...")
 	close(p.jobs)
 	p.wg.Wait()
 	close(p.results)
@@ -104,6 +121,6 @@ func (p *WorkerPool) Wait() ([]*model.Signal, map[string]float64) {
 		}
 	}
 
-	log.Printf("✅ [Worker Pool] All workers completed. Collected %d signals, %d prices", len(signals), len(prices))
+	poolLog.InfoContext(p.ctx, "✅ Profile scanned", "profile", p.strategy.Profile().Name, "signals", len(signals), "prices", len(prices))
 	return signals, prices
 }